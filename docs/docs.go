@@ -73,6 +73,12 @@ const docTemplate = `{
                     "payment"
                 ],
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，重试时保持不变",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "退款请求",
                         "name": "request",
@@ -93,6 +99,229 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/admin/gift-code-batches": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "description": "创建礼品码批次请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gift_code.CreateGiftCodeBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/gift-code-batches/{batchId}/codes": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Gift Code Batch ID",
+                        "name": "batchId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/gift-code-batches/{batchId}/redemptions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Gift Code Batch ID",
+                        "name": "batchId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/reconciliation/findings": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "enum": [
+                            "available_balance",
+                            "total_receive",
+                            "total_payment",
+                            "total_transfer",
+                            "total_community",
+                            "held_balance",
+                            "ledger_balance"
+                        ],
+                        "type": "string",
+                        "x-enum-comments": {
+                            "ReconciliationFieldLedgerBalance": "可用余额与流水分录合计不一致"
+                        },
+                        "x-enum-descriptions": [
+                            "",
+                            "",
+                            "",
+                            "",
+                            "",
+                            "",
+                            "可用余额与流水分录合计不一致"
+                        ],
+                        "x-enum-varnames": [
+                            "ReconciliationFieldAvailableBalance",
+                            "ReconciliationFieldTotalReceive",
+                            "ReconciliationFieldTotalPayment",
+                            "ReconciliationFieldTotalTransfer",
+                            "ReconciliationFieldTotalCommunity",
+                            "ReconciliationFieldHeldBalance",
+                            "ReconciliationFieldLedgerBalance"
+                        ],
+                        "name": "field",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "run_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/admin/system-configs": {
             "get": {
                 "produces": [
@@ -581,6 +810,38 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/gift-codes/redeem": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gift_code"
+                ],
+                "parameters": [
+                    {
+                        "description": "兑换礼品码请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gift_code.RedeemGiftCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/health": {
             "get": {
                 "produces": [
@@ -739,7 +1000,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/merchant/api-keys/{id}/payment-links": {
+        "/api/v1/merchant/api-keys/{id}/audit-logs": {
             "get": {
                 "produces": [
                     "application/json"
@@ -755,6 +1016,24 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "user_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -765,11 +1044,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/gift-code-batches": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
@@ -786,13 +1064,17 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "创建支付链接请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/link.PaymentLinkRequest"
-                        }
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -803,9 +1085,211 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "创建礼品码批次请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gift_code.CreateMerchantGiftCodeBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
         },
-        "/api/v1/merchant/api-keys/{id}/payment-links/{linkId}": {
+        "/api/v1/merchant/api-keys/{id}/gift-code-batches/{batchId}/codes": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Gift Code Batch ID",
+                        "name": "batchId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/gift-code-batches/{batchId}/redemptions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Gift Code Batch ID",
+                        "name": "batchId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/members": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/member.InviteMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/members/{memberId}": {
             "put": {
                 "consumes": [
                     "application/json"
@@ -828,19 +1312,1957 @@ const docTemplate = `{
                     {
                         "type": "integer",
                         "format": "int64",
-                        "description": "Payment Link ID",
-                        "name": "linkId",
+                        "description": "成员 ID",
+                        "name": "memberId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/member.UpdateMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "成员 ID",
+                        "name": "memberId",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/payment-links": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "创建支付链接请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/link.PaymentLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/payment-links/{linkId}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Payment Link ID",
+                        "name": "linkId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新支付链接请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/link.PaymentLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Payment Link ID",
+                        "name": "linkId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/secrets": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_key.CreateAPISecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/secrets/{secretId}/revoke": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "密钥 ID",
+                        "name": "secretId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/secrets/{secretId}/rotate": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "密钥 ID",
+                        "name": "secretId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_key.RotateAPISecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/signing-key": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/signing-key/revoke-previous": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/signing-key/rotate": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_key.RotateAPISecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/subscription-plans": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "创建订阅计划请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/subscription.SubscriptionPlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/subscription-plans/{planId}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Subscription Plan ID",
+                        "name": "planId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新订阅计划请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/subscription.SubscriptionPlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Subscription Plan ID",
+                        "name": "planId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/subscriptions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "plan_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "past_due",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "x-enum-comments": {
+                            "SubscriptionStatusPastDue": "扣款失败，等待重试"
+                        },
+                        "x-enum-descriptions": [
+                            "",
+                            "扣款失败，等待重试",
+                            ""
+                        ],
+                        "x-enum-varnames": [
+                            "SubscriptionStatusActive",
+                            "SubscriptionStatusPastDue",
+                            "SubscriptionStatusCancelled"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/subscriptions/{subscriptionId}/cancel": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Subscription ID",
+                        "name": "subscriptionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/webhooks": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "order_id",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "success",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/webhooks/{deliveryId}/redeliver": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "投递记录 ID",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/invitations": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/invitations/{memberId}/accept": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "邀请 ID",
+                        "name": "memberId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/invitations/{memberId}/decline": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "邀请 ID",
+                        "name": "memberId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/orders": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-order"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Basic Auth (base64(client_id:client_secret))",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "success",
+                            "pending",
+                            "expired",
+                            "disputing",
+                            "refund",
+                            "refused",
+                            "partially_refunded",
+                            "cancelled",
+                            "authorized"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "payment",
+                            "online",
+                            "test",
+                            "distribute",
+                            "refund"
+                        ],
+                        "type": "string",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-order"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Basic Auth (base64(client_id:client_secret))",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "幂等键，重试时保持不变",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.CreateOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/orders/out-trade-no/{outTradeNo}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-order"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Basic Auth (base64(client_id:client_secret))",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "商户订单号",
+                        "name": "outTradeNo",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/orders/{tradeNo}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-order"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Basic Auth (base64(client_id:client_secret))",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "平台订单号",
+                        "name": "tradeNo",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/orders/{tradeNo}/cancel": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-order"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Basic Auth (base64(client_id:client_secret))",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "平台订单号",
+                        "name": "tradeNo",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/orders/{tradeNo}/capture": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-order"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Basic Auth (base64(client_id:client_secret))",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "幂等键，重试时保持不变",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "平台订单号",
+                        "name": "tradeNo",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/payment.OrderCaptureRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/orders/{tradeNo}/refund": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-order"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Basic Auth (base64(client_id:client_secret))",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "幂等键，重试时保持不变",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "平台订单号",
+                        "name": "tradeNo",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.OrderRefundRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/orders/{tradeNo}/void": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-order"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Basic Auth (base64(client_id:client_secret))",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "平台订单号",
+                        "name": "tradeNo",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/payment": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "parameters": [
+                    {
+                        "description": "支付订单请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.PayOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/payment-links/pay": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "description": "支付请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/link.PayByLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/payment-links/{token}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "支付链接 Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/payment/order": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "order_no",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/subscription-plans/subscribe": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "description": "订阅请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/subscription.SubscribeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/subscription-plans/{token}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "订阅计划 Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/oauth/callback": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/oauth.CallbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/oauth/login": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/oauth/logout": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/oauth/user-info": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/order/dispute": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dispute.CreateDisputeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/order/dispute/close": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dispute.CloseDisputeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/order/disputes": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dispute.ListDisputesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/order/disputes/merchant": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dispute.ListDisputesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/order/refund-review": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dispute.RefundReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/order/transactions": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/order.TransactionListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment/escrow": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，重试时保持不变",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "担保交易请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.TransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment/escrow/confirm": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.ConfirmEscrowRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment/requests": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "sent",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "paid",
+                            "declined",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaymentRequestStatusPending",
+                            "PaymentRequestStatusPaid",
+                            "PaymentRequestStatusDeclined",
+                            "PaymentRequestStatusExpired"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "parameters": [
+                    {
+                        "description": "收款请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.CreatePaymentRequestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment/requests/{id}/decline": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "收款请求 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment/requests/{id}/pay": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，重试时保持不变",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "收款请求 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "支付请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.PayPaymentRequestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment/subscriptions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "plan_id",
+                        "in": "query"
                     },
                     {
-                        "description": "更新支付链接请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/link.PaymentLinkRequest"
-                        }
+                        "enum": [
+                            "active",
+                            "past_due",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "x-enum-comments": {
+                            "SubscriptionStatusPastDue": "扣款失败，等待重试"
+                        },
+                        "x-enum-descriptions": [
+                            "",
+                            "扣款失败，等待重试",
+                            ""
+                        ],
+                        "x-enum-varnames": [
+                            "SubscriptionStatusActive",
+                            "SubscriptionStatusPastDue",
+                            "SubscriptionStatusCancelled"
+                        ],
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -851,30 +3273,24 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/v1/payment/subscriptions/{id}/cancel": {
+            "post": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "merchant"
+                    "payment"
                 ],
                 "parameters": [
                     {
                         "type": "integer",
                         "format": "int64",
-                        "description": "API Key ID",
+                        "description": "Subscription ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Payment Link ID",
-                        "name": "linkId",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -887,7 +3303,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/merchant/payment": {
+        "/api/v1/payment/transfer": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -900,12 +3316,18 @@ const docTemplate = `{
                 ],
                 "parameters": [
                     {
-                        "description": "支付订单请求",
+                        "type": "string",
+                        "description": "幂等键，重试时保持不变",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "转账请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/payment.PayOrderRequest"
+                            "$ref": "#/definitions/payment.TransferRequest"
                         }
                     }
                 ],
@@ -919,7 +3341,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/merchant/payment-links/pay": {
+        "/api/v1/red-envelopes": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "red_envelope"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
             "post": {
                 "consumes": [
                     "application/json"
@@ -928,16 +3381,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "merchant"
+                    "red_envelope"
                 ],
                 "parameters": [
                     {
-                        "description": "支付请求",
+                        "type": "string",
+                        "description": "幂等键，重试时保持不变",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "发红包请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/link.PayByLinkRequest"
+                            "$ref": "#/definitions/red_envelope.CreateRedEnvelopeRequest"
                         }
                     }
                 ],
@@ -951,19 +3410,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/merchant/payment-links/{token}": {
+        "/api/v1/red-envelopes/{code}": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "merchant"
+                    "red_envelope"
                 ],
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "支付链接 Token",
-                        "name": "token",
+                        "description": "红包领取码",
+                        "name": "code",
                         "in": "path",
                         "required": true
                     }
@@ -978,23 +3437,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/merchant/payment/order": {
-            "get": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/v1/red-envelopes/{code}/claim": {
+            "post": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "payment"
+                    "red_envelope"
                 ],
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "订单号",
-                        "name": "order_no",
-                        "in": "query",
+                        "description": "红包领取码",
+                        "name": "code",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -1008,13 +3464,32 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/oauth/callback": {
+        "/api/v1/user/access-tokens": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
             "post": {
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "oauth"
+                    "user"
                 ],
                 "parameters": [
                     {
@@ -1023,7 +3498,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/oauth.CallbackRequest"
+                            "$ref": "#/definitions/user.CreateAccessTokenRequest"
                         }
                     }
                 ],
@@ -1037,13 +3512,23 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/oauth/login": {
-            "get": {
+        "/api/v1/user/access-tokens/{id}": {
+            "delete": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "oauth"
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Access Token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
@@ -1055,13 +3540,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/oauth/logout": {
+        "/api/v1/user/balance": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "oauth"
+                    "user"
                 ],
                 "responses": {
                     "200": {
@@ -1073,13 +3558,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/oauth/user-info": {
-            "get": {
+        "/api/v1/user/pay-key": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "oauth"
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.UpdatePayKeyRequest"
+                        }
+                    }
                 ],
                 "responses": {
                     "200": {
@@ -1091,27 +3590,74 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/order/dispute": {
-            "post": {
-                "consumes": [
+        "/api/v1/user/sessions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/sessions/{id}": {
+            "delete": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "order"
+                    "user"
                 ],
                 "parameters": [
                     {
-                        "description": "request body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "会话 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dispute.CreateDisputeRequest"
+                            "$ref": "#/definitions/util.ResponseAny"
                         }
                     }
+                }
+            }
+        },
+        "/api/v1/user/totp": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
                 ],
                 "responses": {
                     "200": {
@@ -1123,7 +3669,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/order/dispute/close": {
+        "/api/v1/user/totp/disable": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -1132,7 +3678,7 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "order"
+                    "user"
                 ],
                 "parameters": [
                     {
@@ -1141,7 +3687,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dispute.CloseDisputeRequest"
+                            "$ref": "#/definitions/user.DisableTOTPRequest"
                         }
                     }
                 ],
@@ -1155,7 +3701,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/order/disputes": {
+        "/api/v1/user/totp/enable": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -1164,15 +3710,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "order"
+                    "user"
                 ],
                 "parameters": [
                     {
                         "description": "request body",
                         "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dispute.ListDisputesRequest"
+                            "$ref": "#/definitions/user.TOTPCodeRequest"
                         }
                     }
                 ],
@@ -1186,7 +3733,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/order/disputes/merchant": {
+        "/api/v1/user/totp/enroll": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -1195,15 +3742,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "order"
+                    "user"
                 ],
                 "parameters": [
                     {
                         "description": "request body",
                         "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dispute.ListDisputesRequest"
+                            "$ref": "#/definitions/user.EnrollTOTPRequest"
                         }
                     }
                 ],
@@ -1217,7 +3765,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/order/refund-review": {
+        "/api/v1/user/totp/recovery-codes": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -1226,7 +3774,7 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "order"
+                    "user"
                 ],
                 "parameters": [
                     {
@@ -1235,7 +3783,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dispute.RefundReviewRequest"
+                            "$ref": "#/definitions/user.TOTPCodeRequest"
                         }
                     }
                 ],
@@ -1249,8 +3797,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/order/transactions": {
-            "post": {
+        "/api/v1/user/totp/threshold": {
+            "put": {
                 "consumes": [
                     "application/json"
                 ],
@@ -1258,15 +3806,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "order"
+                    "user"
                 ],
                 "parameters": [
                     {
                         "description": "request body",
                         "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/order.TransactionListRequest"
+                            "$ref": "#/definitions/user.UpdateTOTPThresholdRequest"
                         }
                     }
                 ],
@@ -1280,7 +3829,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/payment/transfer": {
+        "/pay/distribute": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -1293,12 +3842,25 @@ const docTemplate = `{
                 ],
                 "parameters": [
                     {
-                        "description": "转账请求",
+                        "type": "string",
+                        "description": "Basic Auth (base64(client_id:client_secret))",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "幂等键，重试时保持不变",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "分发请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/payment.TransferRequest"
+                            "$ref": "#/definitions/payment.MerchantDistributeRequest"
                         }
                     }
                 ],
@@ -1312,26 +3874,32 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/user/pay-key": {
-            "put": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/pay/distribute/batch": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "payment"
                 ],
                 "parameters": [
                     {
-                        "description": "request body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user.UpdatePayKeyRequest"
-                        }
+                        "type": "string",
+                        "description": "Basic Auth (base64(client_id:client_secret))",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "name": "batch_no",
+                        "in": "query"
+                    },
+                    {
+                        "maxLength": 64,
+                        "type": "string",
+                        "name": "out_batch_no",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1342,12 +3910,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/pay/distribute": {
+            },
             "post": {
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
@@ -1364,12 +3931,18 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "分发请求",
+                        "type": "string",
+                        "description": "幂等键，重试时保持不变",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "批量分发请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/payment.MerchantDistributeRequest"
+                            "$ref": "#/definitions/payment.MerchantDistributeBatchRequest"
                         }
                     }
                 ],
@@ -1437,6 +4010,30 @@ const docTemplate = `{
                     "type": "string",
                     "maxLength": 20
                 },
+                "distribute_allowed_ips": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "distribute_daily_limit": {
+                    "type": "number"
+                },
+                "distribute_max_amount": {
+                    "description": "分发限额，0 表示不限制",
+                    "type": "number"
+                },
+                "distribute_monthly_limit": {
+                    "type": "number"
+                },
+                "distribute_recipient_daily_limit": {
+                    "type": "number"
+                },
+                "expire_minutes": {
+                    "type": "integer",
+                    "minimum": 0
+                },
                 "notify_url": {
                     "type": "string",
                     "maxLength": 100
@@ -1445,8 +4042,61 @@ const docTemplate = `{
                     "type": "string",
                     "maxLength": 100
                 },
+                "sign_type": {
+                    "enum": [
+                        "MD5",
+                        "HMAC-SHA256"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SignType"
+                        }
+                    ]
+                },
                 "test_mode": {
                     "type": "boolean"
+                },
+                "webhook_events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "webhook_url": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "api_key.CreateAPISecretRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api_key.RotateAPISecretRequest": {
+            "type": "object",
+            "properties": {
+                "overlap_hours": {
+                    "description": "旧密钥继续可用的小时数，0 表示立即失效",
+                    "type": "integer",
+                    "maximum": 168,
+                    "minimum": 0
                 }
             }
         },
@@ -1465,6 +4115,34 @@ const docTemplate = `{
                     "type": "string",
                     "maxLength": 20
                 },
+                "distribute_allowed_ips": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "distribute_daily_limit": {
+                    "type": "number"
+                },
+                "distribute_max_amount": {
+                    "description": "分发限额仅在请求携带时更新，0 表示不限制",
+                    "type": "number"
+                },
+                "distribute_monthly_limit": {
+                    "type": "number"
+                },
+                "distribute_recipient_daily_limit": {
+                    "type": "number"
+                },
+                "expire_minutes": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "legacy_sign_allowed": {
+                    "description": "旧版签名兼容仅在请求携带时更新",
+                    "type": "boolean"
+                },
                 "notify_url": {
                     "type": "string",
                     "maxLength": 100
@@ -1473,8 +4151,29 @@ const docTemplate = `{
                     "type": "string",
                     "maxLength": 100
                 },
+                "sign_type": {
+                    "enum": [
+                        "MD5",
+                        "HMAC-SHA256"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SignType"
+                        }
+                    ]
+                },
                 "test_mode": {
                     "type": "boolean"
+                },
+                "webhook_events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "webhook_url": {
+                    "type": "string",
+                    "maxLength": 255
                 }
             }
         },
@@ -1557,6 +4256,111 @@ const docTemplate = `{
                 }
             }
         },
+        "gift_code.CreateGiftCodeBatchRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "code_count",
+                "expire_days",
+                "name"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "code_count": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
+                },
+                "expire_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1
+                },
+                "max_redemptions": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "per_user_limit": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "gift_code.CreateMerchantGiftCodeBatchRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "code_count",
+                "expire_days",
+                "name",
+                "pay_key"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "code_count": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
+                },
+                "expire_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1
+                },
+                "max_redemptions": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "pay_key": {
+                    "type": "string",
+                    "maxLength": 6
+                },
+                "per_user_limit": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "totp_code": {
+                    "type": "string",
+                    "maxLength": 16
+                }
+            }
+        },
+        "gift_code.RedeemGiftCodeRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
         "link.PayByLinkRequest": {
             "type": "object",
             "required": [
@@ -1574,6 +4378,10 @@ const docTemplate = `{
                 },
                 "token": {
                     "type": "string"
+                },
+                "totp_code": {
+                    "type": "string",
+                    "maxLength": 16
                 }
             }
         },
@@ -1605,20 +4413,228 @@ const docTemplate = `{
                 }
             }
         },
-        "model.PayLevel": {
+        "member.InviteMemberRequest": {
+            "type": "object",
+            "required": [
+                "role",
+                "user_id",
+                "username"
+            ],
+            "properties": {
+                "role": {
+                    "enum": [
+                        "developer",
+                        "support",
+                        "finance"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.MerchantRole"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "member.UpdateMemberRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "enum": [
+                        "developer",
+                        "support",
+                        "finance"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.MerchantRole"
+                        }
+                    ]
+                }
+            }
+        },
+        "model.MerchantRole": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "developer",
+                "support",
+                "finance"
+            ],
+            "x-enum-comments": {
+                "MerchantRoleDeveloper": "管理应用配置、密钥与回调",
+                "MerchantRoleFinance": "查看订单并执行退款与分发",
+                "MerchantRoleOwner": "应用创建者，拥有全部权限",
+                "MerchantRoleSupport": "查看订单并处理退款争议"
+            },
+            "x-enum-descriptions": [
+                "应用创建者，拥有全部权限",
+                "管理应用配置、密钥与回调",
+                "查看订单并处理退款争议",
+                "查看订单并执行退款与分发"
+            ],
+            "x-enum-varnames": [
+                "MerchantRoleOwner",
+                "MerchantRoleDeveloper",
+                "MerchantRoleSupport",
+                "MerchantRoleFinance"
+            ]
+        },
+        "model.PayLevel": {
+            "type": "integer",
+            "format": "int32",
+            "enum": [
+                0,
+                1,
+                2,
+                3
+            ],
+            "x-enum-varnames": [
+                "PayLevelFree",
+                "PayLevelBasic",
+                "PayLevelStandard",
+                "PayLevelPremium"
+            ]
+        },
+        "model.PaymentRequestStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "paid",
+                "declined",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "PaymentRequestStatusPending",
+                "PaymentRequestStatusPaid",
+                "PaymentRequestStatusDeclined",
+                "PaymentRequestStatusExpired"
+            ]
+        },
+        "model.ReconciliationField": {
+            "type": "string",
+            "enum": [
+                "available_balance",
+                "total_receive",
+                "total_payment",
+                "total_transfer",
+                "total_community",
+                "held_balance",
+                "ledger_balance"
+            ],
+            "x-enum-comments": {
+                "ReconciliationFieldLedgerBalance": "可用余额与流水分录合计不一致"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "可用余额与流水分录合计不一致"
+            ],
+            "x-enum-varnames": [
+                "ReconciliationFieldAvailableBalance",
+                "ReconciliationFieldTotalReceive",
+                "ReconciliationFieldTotalPayment",
+                "ReconciliationFieldTotalTransfer",
+                "ReconciliationFieldTotalCommunity",
+                "ReconciliationFieldHeldBalance",
+                "ReconciliationFieldLedgerBalance"
+            ]
+        },
+        "model.RedEnvelopeSplitType": {
+            "type": "string",
+            "enum": [
+                "fixed",
+                "random"
+            ],
+            "x-enum-comments": {
+                "RedEnvelopeSplitFixed": "每份金额相同",
+                "RedEnvelopeSplitRandom": "拼手气，每份金额随机"
+            },
+            "x-enum-descriptions": [
+                "每份金额相同",
+                "拼手气，每份金额随机"
+            ],
+            "x-enum-varnames": [
+                "RedEnvelopeSplitFixed",
+                "RedEnvelopeSplitRandom"
+            ]
+        },
+        "model.SignType": {
+            "type": "string",
+            "enum": [
+                "MD5",
+                "HMAC-SHA256"
+            ],
+            "x-enum-varnames": [
+                "SignTypeMD5",
+                "SignTypeHMACSHA256"
+            ]
+        },
+        "model.SubscriptionInterval": {
+            "type": "string",
+            "enum": [
+                "day",
+                "week",
+                "month",
+                "year"
+            ],
+            "x-enum-varnames": [
+                "SubscriptionIntervalDay",
+                "SubscriptionIntervalWeek",
+                "SubscriptionIntervalMonth",
+                "SubscriptionIntervalYear"
+            ]
+        },
+        "model.SubscriptionStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "past_due",
+                "cancelled"
+            ],
+            "x-enum-comments": {
+                "SubscriptionStatusPastDue": "扣款失败，等待重试"
+            },
+            "x-enum-descriptions": [
+                "",
+                "扣款失败，等待重试",
+                ""
+            ],
+            "x-enum-varnames": [
+                "SubscriptionStatusActive",
+                "SubscriptionStatusPastDue",
+                "SubscriptionStatusCancelled"
+            ]
+        },
+        "model.TrustLevel": {
             "type": "integer",
             "format": "int32",
             "enum": [
                 0,
                 1,
                 2,
-                3
+                3,
+                4
             ],
             "x-enum-varnames": [
-                "PayLevelFree",
-                "PayLevelBasic",
-                "PayLevelStandard",
-                "PayLevelPremium"
+                "TrustLevelNewUser",
+                "TrustLevelBasicUser",
+                "TrustLevelUser",
+                "TrustLevelActiveUser",
+                "TrustLevelLeader"
             ]
         },
         "oauth.CallbackRequest": {
@@ -1675,7 +4691,10 @@ const docTemplate = `{
                         "expired",
                         "disputing",
                         "refund",
-                        "refused"
+                        "refused",
+                        "partially_refunded",
+                        "cancelled",
+                        "authorized"
                     ]
                 },
                 "type": {
@@ -1687,11 +4706,36 @@ const docTemplate = `{
                         "community",
                         "online",
                         "test",
-                        "distribute"
+                        "distribute",
+                        "refund",
+                        "escrow",
+                        "red_envelope",
+                        "gift_code"
                     ]
                 }
             }
         },
+        "payment.ConfirmEscrowRequest": {
+            "type": "object",
+            "required": [
+                "order_id",
+                "pay_key"
+            ],
+            "properties": {
+                "order_id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "pay_key": {
+                    "type": "string",
+                    "maxLength": 6
+                },
+                "totp_code": {
+                    "type": "string",
+                    "maxLength": 16
+                }
+            }
+        },
         "payment.CreateOrderRequest": {
             "type": "object",
             "required": [
@@ -1702,6 +4746,13 @@ const docTemplate = `{
                 "amount": {
                     "type": "number"
                 },
+                "expire_minutes": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "manual_capture": {
+                    "type": "boolean"
+                },
                 "merchant_order_no": {
                     "type": "string",
                     "maxLength": 64,
@@ -1720,6 +4771,50 @@ const docTemplate = `{
                 }
             }
         },
+        "payment.CreatePaymentRequestRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "payer_id",
+                "payer_username"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "payer_id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "payer_username": {
+                    "type": "string"
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "payment.MerchantDistributeBatchRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payment.MerchantDistributeRequest"
+                    }
+                },
+                "out_batch_no": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 1
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
         "payment.MerchantDistributeRequest": {
             "type": "object",
             "required": [
@@ -1748,6 +4843,25 @@ const docTemplate = `{
                 }
             }
         },
+        "payment.OrderCaptureRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "payment.OrderRefundRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
         "payment.PayOrderRequest": {
             "type": "object",
             "required": [
@@ -1761,6 +4875,26 @@ const docTemplate = `{
                 "pay_key": {
                     "type": "string",
                     "maxLength": 6
+                },
+                "totp_code": {
+                    "type": "string",
+                    "maxLength": 16
+                }
+            }
+        },
+        "payment.PayPaymentRequestRequest": {
+            "type": "object",
+            "required": [
+                "pay_key"
+            ],
+            "properties": {
+                "pay_key": {
+                    "type": "string",
+                    "maxLength": 6
+                },
+                "totp_code": {
+                    "type": "string",
+                    "maxLength": 16
                 }
             }
         },
@@ -1799,6 +4933,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "1001"
                 },
+                "refunded_money": {
+                    "type": "string",
+                    "example": "0.00"
+                },
                 "status": {
                     "type": "integer",
                     "example": 1
@@ -1807,6 +4945,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "123456"
                 },
+                "trade_status": {
+                    "type": "string",
+                    "example": "success"
+                },
                 "type": {
                     "type": "string",
                     "example": "epay"
@@ -1823,6 +4965,18 @@ const docTemplate = `{
                 "msg": {
                     "type": "string",
                     "example": "退款成功"
+                },
+                "refund_no": {
+                    "type": "string",
+                    "example": "123457"
+                },
+                "refunded_money": {
+                    "type": "string",
+                    "example": "5.00"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "partially_refunded"
                 }
             }
         },
@@ -1875,6 +5029,115 @@ const docTemplate = `{
                 "recipient_username": {
                     "type": "string"
                 },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "totp_code": {
+                    "type": "string",
+                    "maxLength": 16
+                }
+            }
+        },
+        "red_envelope.CreateRedEnvelopeRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "pay_key",
+                "shares",
+                "split_type"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "min_trust_level": {
+                    "maximum": 4,
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TrustLevel"
+                        }
+                    ]
+                },
+                "pay_key": {
+                    "type": "string",
+                    "maxLength": 6
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "shares": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
+                },
+                "split_type": {
+                    "enum": [
+                        "fixed",
+                        "random"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.RedEnvelopeSplitType"
+                        }
+                    ]
+                },
+                "totp_code": {
+                    "type": "string",
+                    "maxLength": 16
+                }
+            }
+        },
+        "subscription.SubscribeRequest": {
+            "type": "object",
+            "required": [
+                "pay_key",
+                "token"
+            ],
+            "properties": {
+                "pay_key": {
+                    "type": "string",
+                    "maxLength": 6
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "subscription.SubscriptionPlanRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "interval",
+                "name"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "interval": {
+                    "enum": [
+                        "day",
+                        "week",
+                        "month",
+                        "year"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SubscriptionInterval"
+                        }
+                    ]
+                },
+                "interval_count": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 30
+                },
                 "remark": {
                     "type": "string",
                     "maxLength": 100
@@ -1938,7 +5201,63 @@ const docTemplate = `{
                 }
             }
         },
-        "user.UpdatePayKeyRequest": {
+        "user.CreateAccessTokenRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "pay_key",
+                "scopes"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "description": "有效天数，0 表示永不过期",
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "pay_key": {
+                    "type": "string",
+                    "maxLength": 6
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "transfer_daily_limit": {
+                    "type": "number"
+                },
+                "transfer_max_amount": {
+                    "description": "拥有转账权限时必填",
+                    "type": "number"
+                }
+            }
+        },
+        "user.DisableTOTPRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "pay_key"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 16
+                },
+                "pay_key": {
+                    "type": "string",
+                    "maxLength": 6
+                }
+            }
+        },
+        "user.EnrollTOTPRequest": {
             "type": "object",
             "required": [
                 "pay_key"
@@ -1950,6 +5269,48 @@ const docTemplate = `{
                 }
             }
         },
+        "user.TOTPCodeRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 16
+                }
+            }
+        },
+        "user.UpdatePayKeyRequest": {
+            "type": "object",
+            "required": [
+                "pay_key"
+            ],
+            "properties": {
+                "old_pay_key": {
+                    "type": "string",
+                    "maxLength": 6
+                },
+                "pay_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.UpdateTOTPThresholdRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 16
+                },
+                "threshold": {
+                    "type": "number"
+                }
+            }
+        },
         "user.updateUserStatusRequest": {
             "type": "object",
             "properties": {
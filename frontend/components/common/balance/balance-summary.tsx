@@ -40,8 +40,8 @@ export function BalanceSummary() {
   const { user, loading } = useUser()
 
   const available = parseFloat(user?.available_balance || '0')
-  const total = available
-  const pending = 0
+  const pending = parseFloat(user?.held_balance || '0')
+  const total = available + pending
 
   const percentages = React.useMemo(
     () => calculatePercentages(available, total),
@@ -66,7 +66,7 @@ export function BalanceSummary() {
         <div
           className={`${ COLORS.pending } transition-all duration-300`}
           style={{ width: `${ percentages.pending }%` }}
-          title={`未来积分: ${ percentages.pending.toFixed(1) }%`}
+          title={`冻结中: ${ percentages.pending.toFixed(1) }%`}
         />
       </div>
 
@@ -97,7 +97,7 @@ export function BalanceSummary() {
         <div className="flex justify-between items-center font-bold text-sm pb-2 border-b border-border/80">
           <div className="flex items-center gap-2">
             <div className={`size-2.5 ${ COLORS.pending } rounded-xs`} aria-hidden="true" />
-            <span>未来积分</span>
+            <span>冻结中</span>
           </div>
           <span className="font-semibold">
             {loading ? "-" : (
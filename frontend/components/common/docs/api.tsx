@@ -68,7 +68,9 @@ export const apiSections: PolicySection[] = [
         <h4 className="font-medium text-foreground mt-3 md:mt-4 mb-2">2.4.1 API Key</h4>
         <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-4">
           <li><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">pid</code>：Client ID</li>
-          <li><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">key</code>：Client Secret（妥善保管）</li>
+          <li><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">key</code>：接口密钥（妥善保管），与签名密钥 Client Secret 相互独立</li>
+          <li><strong>接口密钥：</strong>Basic Auth 与 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">key</code> 认证使用接口密钥，创建应用时会生成拥有全部权限的默认密钥，明文仅在创建时返回一次；可在控制台新建仅拥有部分权限（<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">create_order</code>、<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">query</code>、<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">refund</code>、<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">distribute</code>）的密钥，明文仅展示一次。轮换密钥后旧密钥在重叠期内仍可使用，吊销后立即失效；密钥无对应权限时返回 403</li>
+          <li><strong>签名密钥：</strong>Client Secret 仅用于请求签名与回调签名，不能用于接口认证；可在控制台轮换，回调立即使用新密钥签名，旧签名密钥在重叠期内仍可用于请求签名，也可立即吊销。早期应用的 Client Secret 已作为名为 legacy 的接口密钥保留，可继续用于认证，建议在控制台轮换为独立的接口密钥后吊销</li>
           <li><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">notify_url</code>：回调地址, 使用创建应用时设置的 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">notify_url</code>；请求体中的 notify_url 仅参与签名，不会覆盖创建应用时设置的 notify_url。</li>
         </ul>
 
@@ -81,10 +83,17 @@ export const apiSections: PolicySection[] = [
             <li>整体进行 MD5，取小写十六进制作为 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">sign</code></li>
           </ol>
           <CodeBlock
-            code={`payload="money=10&name=Test&out_trade_no=M20250101&pid=001&type=epay"
+            code={`payload="money=10&name=Test&nonce=8f3a2c&out_trade_no=M20250101&pid=001&timestamp=1735689600&type=epay"
 sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
             language="bash"
           />
+          <p className="text-muted-foreground">推荐使用 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">sign_type=HMAC-SHA256</code>：待签名字符串规则相同（需包含 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">timestamp</code> 与 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">nonce</code>），不拼接密钥，改为以应用密钥计算 HMAC-SHA256，取小写十六进制。<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">timestamp</code> 为秒级 Unix 时间戳，与服务器时间偏差不得超过系统配置 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">sign_timestamp_skew_seconds</code>；<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">nonce</code> 为不超过 64 字符的随机串，有效期内不可重复使用。应用切换为 HMAC-SHA256 后将不再接受 MD5 签名，异步通知也会使用相同方式签名。</p>
+          <p className="text-muted-foreground">两种签名方式均须携带 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">timestamp</code> 与 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">nonce</code> 以防止请求被重放。仅开启了旧版签名兼容（<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">legacy_sign_allowed</code>，新建应用默认关闭）的应用可在 MD5 签名时省略这两个字段。</p>
+          <CodeBlock
+            code={`payload="money=10&name=Test&nonce=8f3a2c&out_trade_no=M20250101&pid=001&timestamp=1735689600&type=epay"
+sign=$(echo -n "\${payload}" | openssl dgst -sha256 -hmac "\${SECRET}" | awk '{print $2}')`}
+            language="bash"
+          />
         </div>
 
         <h3 id="2-5-submit" className="text-base md:text-lg font-semibold text-foreground mt-6 md:mt-8 mb-3 md:mb-4">2.5 积分流转服务</h3>
@@ -152,7 +161,22 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
             <DocsTableRow>
               <DocsTableCell className="font-mono text-xs">sign_type</DocsTableCell>
               <DocsTableCell>否</DocsTableCell>
-              <DocsTableCell>固定 <code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">MD5</code></DocsTableCell>
+              <DocsTableCell><code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">MD5</code>（默认）或 <code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">HMAC-SHA256</code></DocsTableCell>
+            </DocsTableRow>
+            <DocsTableRow>
+              <DocsTableCell className="font-mono text-xs">timestamp</DocsTableCell>
+              <DocsTableCell>是</DocsTableCell>
+              <DocsTableCell>秒级 Unix 时间戳，参与签名</DocsTableCell>
+            </DocsTableRow>
+            <DocsTableRow>
+              <DocsTableCell className="font-mono text-xs">nonce</DocsTableCell>
+              <DocsTableCell>是</DocsTableCell>
+              <DocsTableCell>随机串，最多 64 字符，参与签名，不可重复使用</DocsTableCell>
+            </DocsTableRow>
+            <DocsTableRow>
+              <DocsTableCell className="font-mono text-xs">expire_minutes</DocsTableCell>
+              <DocsTableCell>否</DocsTableCell>
+              <DocsTableCell>订单有效期（分钟），参与签名；需在系统允许范围内，未传时使用应用默认值或系统默认值</DocsTableCell>
             </DocsTableRow>
           </DocsTableBody>
         </DocsTable>
@@ -166,6 +190,8 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
   -d "out_trade_no=M20250101" \\
   -d "name=Test" \\
   -d "money=10" \\
+  -d "timestamp=1735689600" \\
+  -d "nonce=8f3a2c" \\
   -d "sign=\${SIGN}" \\
   -d "sign_type=MD5"`}
           language="bash"
@@ -200,7 +226,7 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
             <DocsTableRow>
               <DocsTableCell className="font-mono text-xs">key</DocsTableCell>
               <DocsTableCell>是</DocsTableCell>
-              <DocsTableCell>Client Secret</DocsTableCell>
+              <DocsTableCell>接口密钥</DocsTableCell>
             </DocsTableRow>
             <DocsTableRow>
               <DocsTableCell className="font-mono text-xs">out_trade_no</DocsTableCell>
@@ -223,17 +249,20 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
   "endtime": "2025-01-01 12:01:30",
   "name": "Test",
   "money": "10",
-  "status": 1
+  "status": 1,
+  "trade_status": "success",
+  "refunded_money": "0.00"
 }`}
           language="json"
         />
-        <p className="text-muted-foreground text-xs"><strong className="text-foreground">补充：</strong><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">status</code> 1=成功，0=失败/处理中；不存在会返回 HTTP 404 且 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">{`{"code":-1,"msg":"服务不存在或已完成"}`}</code>。</p>
+        <p className="text-muted-foreground text-xs"><strong className="text-foreground">补充：</strong><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">status</code> 1=成功（含部分退回），0=失败/处理中；<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">trade_status</code> 为详细状态，部分退回时为 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">partially_refunded</code>；不存在会返回 HTTP 404 且 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">{`{"code":-1,"msg":"服务不存在或已完成"}`}</code>。</p>
 
         <h3 id="2-7-refund" className="text-base md:text-lg font-semibold text-foreground mt-6 md:mt-8 mb-3 md:mb-4">2.7 订单退款</h3>
         <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
           <li><strong>方法：</strong>POST <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">/api.php</code></li>
           <li><strong>编码：</strong><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">application/json</code> 或 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">application/x-www-form-urlencoded</code></li>
-          <li><strong>限制：</strong>仅支持对已成功的积分流转服务进行积分的全额退回</li>
+          <li><strong>限制：</strong>仅支持对已成功的积分流转服务退回积分，可多次部分退回，累计不超过原积分数量；手续费按退回比例返还</li>
+          <li><strong>幂等：</strong>可选请求头 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">Idempotency-Key</code>，重试时保持不变；窗口期内相同请求直接返回首次结果（响应头 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">Idempotent-Replayed: true</code>），同一键用于不同请求体返回 HTTP 422</li>
         </ul>
 
         <div>
@@ -254,7 +283,7 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
               <DocsTableRow>
                 <DocsTableCell className="font-mono text-xs">key</DocsTableCell>
                 <DocsTableCell>是</DocsTableCell>
-                <DocsTableCell>Client Secret</DocsTableCell>
+                <DocsTableCell>接口密钥</DocsTableCell>
               </DocsTableRow>
               <DocsTableRow>
                 <DocsTableCell className="font-mono text-xs">trade_no</DocsTableCell>
@@ -264,7 +293,7 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
               <DocsTableRow>
                 <DocsTableCell className="font-mono text-xs">money</DocsTableCell>
                 <DocsTableCell>是</DocsTableCell>
-                <DocsTableCell>必须等于原积分流转服务的积分数量</DocsTableCell>
+                <DocsTableCell>本次退回的积分数量，不超过剩余可退数量</DocsTableCell>
               </DocsTableRow>
               <DocsTableRow>
                 <DocsTableCell className="font-mono text-xs">out_trade_no</DocsTableCell>
@@ -276,8 +305,8 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
         </div>
 
         <p className="text-muted-foreground mb-2">响应：</p>
-        <CodeBlock code={`{ "code": 1, "msg": "退款成功" }`} language="json" />
-        <p className="text-muted-foreground text-xs"><strong className="text-foreground">常见失败：</strong>服务不存在/未认证、金额不合法（&lt;=0 或小数超过 2 位）。</p>
+        <CodeBlock code={`{ "code": 1, "msg": "退款成功", "refund_no": "123457", "refunded_money": "5.00", "status": "partially_refunded" }`} language="json" />
+        <p className="text-muted-foreground text-xs"><strong className="text-foreground">常见失败：</strong>服务不存在/未认证、金额不合法（&lt;=0 或小数超过 2 位）、退款金额超过可退金额。</p>
 
 
         <h3 id="2-8-notify" className="text-base md:text-lg font-semibold text-foreground mt-6 md:mt-8 mb-3 md:mb-4">2.8 异步通知（认证成功）</h3>
@@ -322,7 +351,7 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
               </DocsTableRow>
               <DocsTableRow>
                 <DocsTableCell className="font-mono text-xs">trade_status</DocsTableCell>
-                <DocsTableCell>固定 <code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">TRADE_SUCCESS</code></DocsTableCell>
+                <DocsTableCell><code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">TRADE_SUCCESS</code>；预授权订单付款后为 <code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">TRADE_AUTHORIZED</code>，确认收款后再次通知 <code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">TRADE_SUCCESS</code></DocsTableCell>
               </DocsTableRow>
               <DocsTableRow>
                 <DocsTableCell className="font-mono text-xs">sign_type</DocsTableCell>
@@ -336,6 +365,136 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
           </DocsTable>
         </div>
         <p className="text-muted-foreground text-xs">应用需返回 HTTP 200 且响应体为 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">success</code>（大小写不敏感），否则视为失败并继续重试。</p>
+
+        <h3 id="2-9-events" className="text-base md:text-lg font-semibold text-foreground mt-6 md:mt-8 mb-3 md:mb-4">2.9 事件回调</h3>
+        <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
+          <li><strong>订阅：</strong>在应用设置中配置 <code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">webhook_events</code>，仅推送已订阅的事件</li>
+          <li><strong>目标：</strong>应用设置的 <code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">webhook_url</code>，未设置时使用 notify_url</li>
+          <li><strong>方式：</strong>HTTP POST，<code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">Content-Type: application/json</code>；返回 2xx 视为成功，否则自动重试</li>
+          <li><strong>请求体：</strong><code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">{'{ id, type, created, data }'}</code>，data 包含 trade_no、out_trade_no、name、money、refunded_money、status</li>
+        </ul>
+
+        <div>
+          <DocsTable>
+            <DocsTableHeader>
+              <DocsTableRow>
+                <DocsTableHead>事件</DocsTableHead>
+                <DocsTableHead>说明</DocsTableHead>
+              </DocsTableRow>
+            </DocsTableHeader>
+            <DocsTableBody>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">payment.succeeded</DocsTableCell>
+                <DocsTableCell>认证成功（含支付链接与订阅扣款）</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">payment.refunded</DocsTableCell>
+                <DocsTableCell>订单发生退款（商户退款或争议退款），data 额外包含 refund_no、refund_money</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">payment.authorized</DocsTableCell>
+                <DocsTableCell>预授权订单付款成功，积分已冻结，待确认收款或撤销</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">payment.voided</DocsTableCell>
+                <DocsTableCell>预授权订单被撤销或超时未确认收款，冻结积分已全额退回付款方</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">dispute.opened</DocsTableCell>
+                <DocsTableCell>用户对订单发起争议，data 额外包含 dispute_id、dispute_status、reason</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">dispute.resolved</DocsTableCell>
+                <DocsTableCell>争议处理完成（同意退款、拒绝或用户撤销），data 同上</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">order.expired</DocsTableCell>
+                <DocsTableCell>订单超时未支付</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">order.cancelled</DocsTableCell>
+                <DocsTableCell>商户取消了待支付订单</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">distribute.succeeded</DocsTableCell>
+                <DocsTableCell>分发成功，data 额外包含 user_id、username、fee</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">distribute.batch_completed</DocsTableCell>
+                <DocsTableCell>批量分发的全部明细已处理完成，data 为批次汇总：batch_no、out_batch_no、status、total_count、success_count、failed_count、total_money、success_money，逐条结果可通过批次查询接口获取</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">subscription.created</DocsTableCell>
+                <DocsTableCell>用户确认订阅并完成首期扣款，data 为订阅信息：subscription_id、plan_id、payer_user_id、name、money、interval、interval_count、status、next_billing_at、failed_attempts，以及本期扣款的 trade_no</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">subscription.renewed</DocsTableCell>
+                <DocsTableCell>订阅到期自动扣款成功，data 同上</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">subscription.payment_failed</DocsTableCell>
+                <DocsTableCell>订阅扣款因余额不足或超出每日限额失败，订阅进入 past_due 并按系统配置间隔重试，data 额外包含 reason</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">subscription.cancelled</DocsTableCell>
+                <DocsTableCell>订阅被用户或商户取消，或连续扣款失败超过上限后自动取消，data 额外包含 reason</DocsTableCell>
+              </DocsTableRow>
+            </DocsTableBody>
+          </DocsTable>
+        </div>
+        <p className="text-muted-foreground text-xs">请求头 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">X-Credit-Event</code> 为事件类型，<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">X-Credit-Event-Id</code> 为事件 ID（重试时不变，可用于去重），<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">X-Credit-Signature</code> 为以应用密钥对 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">X-Credit-Timestamp + "." + 请求体</code> 计算的 HMAC-SHA256 小写十六进制。</p>
+
+        <h3 id="2-10-orders-api" className="text-base md:text-lg font-semibold text-foreground mt-6 md:mt-8 mb-3 md:mb-4">2.10 JSON 订单接口</h3>
+        <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
+          <li><strong>鉴权：</strong><code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">Authorization: Basic base64(client_id:接口密钥)</code>；创建订单、取消、确认收款与撤销需 create_order 权限，查询需 query 权限，退款需 refund 权限</li>
+          <li><strong>格式：</strong>请求与响应均为 JSON，响应为 <code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">{'{ error_msg, data }'}</code>，失败时返回对应 HTTP 状态码与错误信息</li>
+          <li><strong>幂等：</strong>创建订单、退款与确认收款支持 <code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">Idempotency-Key</code> 请求头</li>
+        </ul>
+
+        <div>
+          <DocsTable>
+            <DocsTableHeader>
+              <DocsTableRow>
+                <DocsTableHead>接口</DocsTableHead>
+                <DocsTableHead>说明</DocsTableHead>
+              </DocsTableRow>
+            </DocsTableHeader>
+            <DocsTableBody>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">POST /api/v1/merchant/orders</DocsTableCell>
+                <DocsTableCell>创建订单，请求体为 order_name、merchant_order_no、amount、remark、expire_minutes（可选）、manual_capture（可选，为 true 时付款仅冻结积分，需确认收款后结算），返回订单信息与 pay_url</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">GET /api/v1/merchant/orders</DocsTableCell>
+                <DocsTableCell>订单列表，按 trade_no 倒序；参数 cursor、limit（1-100，默认 20）、status、type，返回 next_cursor 与 has_more</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">GET /api/v1/merchant/orders/{'{trade_no}'}</DocsTableCell>
+                <DocsTableCell>按平台订单号查询</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">GET /api/v1/merchant/orders/out-trade-no/{'{out_trade_no}'}</DocsTableCell>
+                <DocsTableCell>按商户订单号查询</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">POST /api/v1/merchant/orders/{'{trade_no}'}/refund</DocsTableCell>
+                <DocsTableCell>退款，请求体为 amount，可多次部分退款</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">POST /api/v1/merchant/orders/{'{trade_no}'}/cancel</DocsTableCell>
+                <DocsTableCell>取消待支付订单，收银台地址立即失效，订阅了 order.cancelled 时推送事件</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">POST /api/v1/merchant/orders/{'{trade_no}'}/capture</DocsTableCell>
+                <DocsTableCell>确认收款预授权订单（status 为 authorized），请求体 amount 可选，不传时确认全部冻结金额，未确认部分退回付款方；确认后发送异步通知与 payment.succeeded 事件</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">POST /api/v1/merchant/orders/{'{trade_no}'}/void</DocsTableCell>
+                <DocsTableCell>撤销预授权订单，冻结积分全额退回付款方；超过系统设定时间未确认收款的订单将自动释放并推送 payment.voided 事件</DocsTableCell>
+              </DocsTableRow>
+            </DocsTableBody>
+          </DocsTable>
+        </div>
       </div>
     ),
     children: [
@@ -347,6 +506,8 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
       { value: "2-6-order", title: "2.6 订单查询" },
       { value: "2-7-refund", title: "2.7 订单退款" },
       { value: "2-8-notify", title: "2.8 异步通知" },
+      { value: "2-9-events", title: "2.9 事件回调" },
+      { value: "2-10-orders-api", title: "2.10 JSON 订单接口" },
     ]
   },
 ]
@@ -25,6 +25,10 @@ export const typeConfig: Record<OrderType, { label: string; color: string }> = {
   community: { label: '社区划转', color: 'bg-purple-100 text-purple-800 dark:bg-purple-900 dark:text-purple-300' },
   online: { label: '在线活动', color: 'bg-teal-100 text-teal-800 dark:bg-teal-900 dark:text-teal-300' },
   distribute: { label: '商户分发', color: 'bg-indigo-100 text-indigo-800 dark:bg-indigo-900 dark:text-indigo-300' },
+  refund: { label: '订单退款', color: 'bg-cyan-100 text-cyan-800 dark:bg-cyan-900 dark:text-cyan-300' },
+  escrow: { label: '担保交易', color: 'bg-amber-100 text-amber-800 dark:bg-amber-900 dark:text-amber-300' },
+  red_envelope: { label: '红包', color: 'bg-rose-100 text-rose-800 dark:bg-rose-900 dark:text-rose-300' },
+  gift_code: { label: '礼品码', color: 'bg-lime-100 text-lime-800 dark:bg-lime-900 dark:text-lime-300' },
   test: { label: '应用测试', color: 'bg-orange-100 text-orange-800 dark:bg-orange-900 dark:text-orange-300 font-bold' }
 }
 
@@ -36,7 +40,10 @@ export const statusConfig: Record<OrderStatus, { label: string; color: string }>
   expired: { label: '已过期', color: 'bg-muted/50 text-gray-800 dark:bg-gray-900 dark:text-gray-300' },
   disputing: { label: '争议中', color: 'bg-orange-100 text-orange-800 dark:bg-orange-900 dark:text-orange-300' },
   refund: { label: '已退回', color: 'bg-muted/50 text-gray-800 dark:bg-gray-900 dark:text-gray-300' },
-  refused: { label: '已拒绝', color: 'bg-red-100 text-red-800 dark:bg-red-900 dark:text-red-300' }
+  refused: { label: '已拒绝', color: 'bg-red-100 text-red-800 dark:bg-red-900 dark:text-red-300' },
+  partially_refunded: { label: '部分退回', color: 'bg-muted/50 text-gray-800 dark:bg-gray-900 dark:text-gray-300' },
+  cancelled: { label: '已取消', color: 'bg-muted/50 text-gray-800 dark:bg-gray-900 dark:text-gray-300' },
+  authorized: { label: '冻结中', color: 'bg-blue-100 text-blue-800 dark:bg-blue-900 dark:text-blue-300' }
 }
 
 /* 时间范围选项 */
@@ -0,0 +1,103 @@
+"use client"
+
+import * as React from "react"
+import { useState } from "react"
+import {
+  Dialog,
+  DialogContent,
+} from "@/components/ui/dialog"
+import { Input } from "@/components/ui/input"
+import { Button } from "@/components/ui/button"
+import { Spinner } from "@/components/ui/spinner"
+
+/** 后端要求提供两步验证码时返回的错误信息 */
+export const TOTP_REQUIRED_ERROR = "该笔支付需要两步验证码"
+
+/**
+ * 判断错误是否为需要两步验证码
+ */
+export function isTOTPRequiredError(error: unknown): boolean {
+  return error instanceof Error && error.message === TOTP_REQUIRED_ERROR
+}
+
+interface TOTPDialogProps {
+  isOpen: boolean
+  onOpenChange: (open: boolean) => void
+  onConfirm: (code: string) => void
+  loading?: boolean
+  description?: string
+}
+
+/**
+ * 两步验证码输入对话框
+ *
+ * 大额支付时输入验证器应用生成的6位验证码，也可使用恢复码
+ */
+export function TOTPDialog({
+  isOpen,
+  onOpenChange,
+  onConfirm,
+  loading = false,
+  description = "该笔支付超过两步验证阈值，请输入验证器中的6位验证码或一个恢复码"
+}: TOTPDialogProps) {
+  const [code, setCode] = useState("")
+
+  const handleConfirm = () => {
+    if (code.trim()) {
+      onConfirm(code.trim())
+    }
+  }
+
+  /* 当对话框关闭时重置验证码*/
+  React.useEffect(() => {
+    if (!isOpen) {
+      setCode("")
+    }
+  }, [isOpen])
+
+  return (
+    <Dialog open={isOpen} onOpenChange={onOpenChange}>
+      <DialogContent>
+        <div className="flex flex-col items-center space-y-6">
+          <div className="text-center">
+            <h2 className="text-lg font-semibold">两步验证</h2>
+            <p className="text-sm text-muted-foreground mt-2">
+              {description}
+            </p>
+          </div>
+
+          <Input
+            value={code}
+            onChange={(e) => setCode(e.target.value)}
+            placeholder="6位验证码或恢复码"
+            maxLength={16}
+            autoComplete="one-time-code"
+            className="h-9 text-center font-mono tracking-widest"
+            disabled={loading}
+            onKeyDown={(e) => {
+              if (e.key === 'Enter' && code.trim() && !loading) {
+                handleConfirm()
+              }
+            }}
+          />
+
+          <Button
+            type="button"
+            className="w-full bg-primary hover:bg-primary/90 h-8 text-xs"
+            onClick={handleConfirm}
+            disabled={!code.trim() || loading}
+          >
+            {loading ? (
+              <>
+                <Spinner className="mr-2 h-4 w-4" />
+                验证中...
+              </>
+            ) : (
+              "确认"
+            )}
+          </Button>
+        </div>
+      </DialogContent>
+    </Dialog>
+  )
+}
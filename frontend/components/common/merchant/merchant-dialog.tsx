@@ -8,7 +8,7 @@ import { Label } from "@/components/ui/label"
 import { Button } from "@/components/ui/button"
 import { Spinner } from "@/components/ui/spinner"
 import { Dialog, DialogClose, DialogContent, DialogDescription, DialogFooter, DialogHeader, DialogTitle, DialogTrigger } from "@/components/ui/dialog"
-import services, { type MerchantAPIKey, type CreateAPIKeyRequest, type UpdateAPIKeyRequest } from "@/lib/services"
+import services, { type MerchantAPIKey, type CreateAPIKeyRequest, type CreateAPIKeyResponse, type UpdateAPIKeyRequest } from "@/lib/services"
 
 interface MerchantDialogProps {
   /** 模式：创建或更新 */
@@ -16,13 +16,13 @@ interface MerchantDialogProps {
   /** API Key*/
   apiKey?: MerchantAPIKey
   /** 创建成功回调 */
-  onSuccess: (newKey: MerchantAPIKey) => void
+  onSuccess: (newKey: CreateAPIKeyResponse) => void
   /** 更新成功回调 */
   onUpdate?: (updatedKey: MerchantAPIKey) => void
   /** 触发按钮 */
   trigger?: React.ReactNode
   /** 自定义创建函数 */
-  createAPIKey?: (data: CreateAPIKeyRequest) => Promise<MerchantAPIKey>
+  createAPIKey?: (data: CreateAPIKeyRequest) => Promise<CreateAPIKeyResponse>
   /** 更新函数 */
   updateAPIKey?: (id: string, data: UpdateAPIKeyRequest) => Promise<void>
 }
@@ -151,7 +151,7 @@ export function MerchantDialog({
         const newKey = await (createAPIKey ? createAPIKey(formData as CreateAPIKeyRequest) : services.merchant.createAPIKey(formData as CreateAPIKeyRequest))
 
         toast.success('创建成功', {
-          description: '新应用已创建，请妥善保管您的 Client Secret'
+          description: '新应用已创建，默认接口密钥仅展示一次，请立即保存'
         })
 
         onSuccess(newKey)
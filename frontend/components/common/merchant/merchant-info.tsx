@@ -1,8 +1,8 @@
 import * as React from "react"
-import { useState } from "react"
+import { useState, useEffect } from "react"
 import Link from "next/link"
 import { toast } from "sonner"
-import { Copy, Eye, EyeOff, Trash2, ExternalLink, Edit } from "lucide-react"
+import { Copy, Eye, EyeOff, Trash2, ExternalLink, Edit, RefreshCw, Ban } from "lucide-react"
 import { Button } from "@/components/ui/button"
 import {
   AlertDialog,
@@ -18,8 +18,13 @@ import {
 import { MerchantDialog } from "@/components/common/merchant/merchant-dialog"
 import { TestModeToggle } from "@/components/common/merchant/merchant-test"
 import { DistributeDialog } from "@/components/common/merchant/merchant-distribute"
+import { MerchantSecrets } from "@/components/common/merchant/merchant-secrets"
+import { MerchantMembers } from "@/components/common/merchant/merchant-members"
 import { formatDateTime } from "@/lib/utils"
-import { type UpdateAPIKeyRequest, type MerchantAPIKey } from "@/lib/services"
+import { MerchantService, type UpdateAPIKeyRequest, type MerchantAPIKey, type MerchantAPIKeyDetail } from "@/lib/services"
+
+/** 轮换签名密钥时旧密钥的重叠时长（小时） */
+const SIGNING_KEY_OVERLAP_HOURS = 24
 
 interface MerchantInfoProps {
   /** API Key */
@@ -30,15 +35,104 @@ interface MerchantInfoProps {
   onDelete: (id: string) => void
   /** 更新 API Key */
   updateAPIKey?: (id: string, data: UpdateAPIKeyRequest) => Promise<void>
+  /** 退出应用回调（非创建者成员） */
+  onLeave?: (id: string) => void
+  /** 新建应用时返回的默认接口密钥明文 */
+  initialSecret?: string
 }
 
 /**
  * 集市中心应用信息组件
  * 显示集市中心应用的凭证信息（Client ID 和 Secret）
  */
-export function MerchantInfo({ apiKey, onUpdate, onDelete, updateAPIKey }: MerchantInfoProps) {
+export function MerchantInfo({ apiKey, onUpdate, onDelete, updateAPIKey, onLeave, initialSecret }: MerchantInfoProps) {
+  /* 列表接口返回当前用户角色，缺省视为创建者 */
+  const isOwner = !apiKey.role || apiKey.role === 'owner'
+  const canManage = isOwner || apiKey.role === 'developer'
+
   const [showClientId, setShowClientId] = useState(false)
   const [showClientSecret, setShowClientSecret] = useState(false)
+  const [signingKey, setSigningKey] = useState<string | null>(null)
+  const [previousExpiresAt, setPreviousExpiresAt] = useState<string | null>(apiKey.previous_signing_key_expires_at ?? null)
+  const [detail, setDetail] = useState<MerchantAPIKeyDetail | null>(null)
+
+  /* 加载分发用量 */
+  useEffect(() => {
+    let cancelled = false
+    MerchantService.getAPIKey(apiKey.id)
+      .then((data) => {
+        if (!cancelled) setDetail(data)
+      })
+      .catch(() => {
+        if (!cancelled) setDetail(null)
+      })
+    return () => {
+      cancelled = true
+    }
+  }, [apiKey.id, apiKey.updated_at])
+
+  /* 切换应用时清空已加载的签名密钥 */
+  useEffect(() => {
+    setSigningKey(null)
+    setShowClientSecret(false)
+    setPreviousExpiresAt(apiKey.previous_signing_key_expires_at ?? null)
+  }, [apiKey.id, apiKey.previous_signing_key_expires_at])
+
+  /* 签名密钥按需加载 */
+  const loadSigningKey = async () => {
+    if (signingKey) return signingKey
+    try {
+      const { client_secret, previous_expires_at } = await MerchantService.getSigningKey(apiKey.id)
+      setSigningKey(client_secret)
+      setPreviousExpiresAt(previous_expires_at)
+      return client_secret
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '获取签名密钥失败'
+      toast.error('获取签名密钥失败', { description: errorMessage })
+      return null
+    }
+  }
+
+  const toggleSigningKey = async () => {
+    if (!showClientSecret && !(await loadSigningKey())) return
+    setShowClientSecret(!showClientSecret)
+  }
+
+  const copySigningKey = async () => {
+    const key = await loadSigningKey()
+    if (key) copyToClipboard(key, '签名密钥')
+  }
+
+  /* 轮换签名密钥，旧密钥在重叠期内仍可用于请求签名 */
+  const rotateSigningKey = async () => {
+    try {
+      const { client_secret, previous_expires_at } = await MerchantService.rotateSigningKey(apiKey.id, { overlap_hours: SIGNING_KEY_OVERLAP_HOURS })
+      setSigningKey(client_secret)
+      setPreviousExpiresAt(previous_expires_at)
+      setShowClientSecret(true)
+      toast.success('签名密钥已轮换', { description: `旧签名密钥将在 ${ SIGNING_KEY_OVERLAP_HOURS } 小时后失效` })
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '轮换失败'
+      toast.error('轮换失败', { description: errorMessage })
+    }
+  }
+
+  const revokePreviousSigningKey = async () => {
+    try {
+      await MerchantService.revokePreviousSigningKey(apiKey.id)
+      setPreviousExpiresAt(null)
+      toast.success('旧签名密钥已吊销')
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '吊销失败'
+      toast.error('吊销失败', { description: errorMessage })
+    }
+  }
+
+  /* 限额展示，0 表示不限制 */
+  const formatLimit = (limit: string | undefined) => {
+    if (!limit || parseFloat(limit) <= 0) return '不限制'
+    return parseFloat(limit).toFixed(2)
+  }
 
   /* 复制到剪贴板 */
   const copyToClipboard = async (text: string, label: string) => {
@@ -147,59 +241,118 @@ export function MerchantInfo({ apiKey, onUpdate, onDelete, updateAPIKey }: Merch
         </div>
       </div>
 
-      <div>
-        <h2 className="font-semibold mb-4">API 配置</h2>
-        <div className="border border-dashed rounded-lg px-3 py-2 space-y-4">
-          <div>
-            <div className="flex items-center justify-between mb-2">
-              <label className="text-xs font-medium text-muted-foreground">Client ID</label>
-              <span className="text-[10px] text-muted-foreground">客户端标识</span>
+      {canManage && (
+        <div>
+          <h2 className="font-semibold mb-4">API 配置</h2>
+          <div className="border border-dashed rounded-lg px-3 py-2 space-y-4">
+            <div>
+              <div className="flex items-center justify-between mb-2">
+                <label className="text-xs font-medium text-muted-foreground">Client ID</label>
+                <span className="text-[10px] text-muted-foreground">客户端标识</span>
+              </div>
+              <div className="flex items-center p-2 h-8 border border-dashed rounded-sm">
+                <code className="text-xs text-muted-foreground font-mono flex-1 overflow-x-auto p-1 [&::-webkit-scrollbar]:hidden [-ms-overflow-style:none] [scrollbar-width:none]">
+                  {showClientId ? apiKey.client_id : maskText(apiKey.client_id, 8)}
+                </code>
+                <Button
+                  variant="ghost"
+                  className="size-6 p-1"
+                  onClick={() => setShowClientId(!showClientId)}
+                >
+                  {showClientId ? <EyeOff className="size-3 text-muted-foreground" /> : <Eye className="size-3 text-muted-foreground" />}
+                </Button>
+                <Button
+                  variant="ghost"
+                  className="size-6 p-1"
+                  onClick={() => copyToClipboard(apiKey.client_id, 'Client ID')}
+                >
+                  <Copy className="size-3 text-muted-foreground" />
+                </Button>
+              </div>
             </div>
-            <div className="flex items-center p-2 h-8 border border-dashed rounded-sm">
-              <code className="text-xs text-muted-foreground font-mono flex-1 overflow-x-auto p-1 [&::-webkit-scrollbar]:hidden [-ms-overflow-style:none] [scrollbar-width:none]">
-                {showClientId ? apiKey.client_id : maskText(apiKey.client_id, 8)}
-              </code>
-              <Button
-                variant="ghost"
-                className="size-6 p-1"
-                onClick={() => setShowClientId(!showClientId)}
-              >
-                {showClientId ? <EyeOff className="size-3 text-muted-foreground" /> : <Eye className="size-3 text-muted-foreground" />}
-              </Button>
-              <Button
-                variant="ghost"
-                className="size-6 p-1"
-                onClick={() => copyToClipboard(apiKey.client_id, 'Client ID')}
-              >
-                <Copy className="size-3 text-muted-foreground" />
-              </Button>
+
+            <div>
+              <div className="flex items-center justify-between mb-2">
+                <label className="text-xs font-medium text-muted-foreground">Client Secret</label>
+                <span className="text-[10px] text-muted-foreground">签名密钥，用于请求签名与回调验签</span>
+              </div>
+              <div className="flex items-center p-2 h-8 border border-dashed rounded-sm">
+                <code className="text-xs text-muted-foreground font-mono flex-1 overflow-x-auto p-1 [&::-webkit-scrollbar]:hidden [-ms-overflow-style:none] [scrollbar-width:none]">
+                  {showClientSecret && signingKey ? signingKey : '•'.repeat(40)}
+                </code>
+                <Button
+                  variant="ghost"
+                  className="size-6 p-1"
+                  onClick={toggleSigningKey}
+                >
+                  {showClientSecret ? <EyeOff className="size-3 text-muted-foreground" /> : <Eye className="size-3 text-muted-foreground" />}
+                </Button>
+                <Button
+                  variant="ghost"
+                  onClick={copySigningKey}
+                  className="size-6 p-1"
+                >
+                  <Copy className="size-3" />
+                </Button>
+                <Button
+                  variant="ghost"
+                  title="轮换"
+                  onClick={rotateSigningKey}
+                  className="size-6 p-1"
+                >
+                  <RefreshCw className="size-3 text-muted-foreground" />
+                </Button>
+              </div>
+              {previousExpiresAt && new Date(previousExpiresAt) > new Date() && (
+                <div className="flex items-center justify-between mt-1">
+                  <span className="text-[10px] text-muted-foreground">旧签名密钥将于 {formatDateTime(previousExpiresAt)} 失效</span>
+                  <Button variant="ghost" className="h-5 px-1 text-[10px]" onClick={revokePreviousSigningKey}>
+                    <Ban className="size-3 mr-1 text-destructive" />
+                    立即吊销
+                  </Button>
+                </div>
+              )}
             </div>
           </div>
+        </div>
+      )}
 
-          <div>
-            <div className="flex items-center justify-between mb-2">
-              <label className="text-xs font-medium text-muted-foreground">Client Secret</label>
-              <span className="text-[10px] text-muted-foreground">客户端密钥</span>
-            </div>
-            <div className="flex items-center p-2 h-8 border border-dashed rounded-sm">
-              <code className="text-xs text-muted-foreground font-mono flex-1 overflow-x-auto p-1 [&::-webkit-scrollbar]:hidden [-ms-overflow-style:none] [scrollbar-width:none]">
-                {showClientSecret ? apiKey.client_secret : '•'.repeat(40)}
-              </code>
-              <Button
-                variant="ghost"
-                className="size-6 p-1"
-                onClick={() => setShowClientSecret(!showClientSecret)}
-              >
-                {showClientSecret ? <EyeOff className="size-3 text-muted-foreground" /> : <Eye className="size-3 text-muted-foreground" />}
-              </Button>
-              <Button
-                variant="ghost"
-                onClick={() => copyToClipboard(apiKey.client_secret, 'Client Secret')}
-                className="size-6 p-1"
-              >
-                <Copy className="size-3" />
-              </Button>
-            </div>
+      {canManage && <MerchantSecrets apiKeyId={apiKey.id} initialPlaintext={initialSecret} />}
+
+      <MerchantMembers apiKey={apiKey} onLeave={onLeave} />
+
+      <div>
+        <h2 className="font-semibold mb-4">分发限额</h2>
+        <div className="border border-dashed rounded-lg">
+          <div className="px-3 py-2 flex items-center justify-between border-b border-dashed last:border-b-0">
+            <label className="text-xs font-medium text-muted-foreground">今日已分发</label>
+            <p className="text-xs font-medium text-right max-w-[70%]">
+              {detail ? parseFloat(detail.distributed_today).toFixed(2) : '-'} / {formatLimit(apiKey.distribute_daily_limit)}
+            </p>
+          </div>
+
+          <div className="px-3 py-2 flex items-center justify-between border-b border-dashed last:border-b-0">
+            <label className="text-xs font-medium text-muted-foreground">本月已分发</label>
+            <p className="text-xs font-medium text-right max-w-[70%]">
+              {detail ? parseFloat(detail.distributed_this_month).toFixed(2) : '-'} / {formatLimit(apiKey.distribute_monthly_limit)}
+            </p>
+          </div>
+
+          <div className="px-3 py-2 flex items-center justify-between border-b border-dashed last:border-b-0">
+            <label className="text-xs font-medium text-muted-foreground">单笔上限</label>
+            <p className="text-xs text-muted-foreground text-right max-w-[70%]">{formatLimit(apiKey.distribute_max_amount)}</p>
+          </div>
+
+          <div className="px-3 py-2 flex items-center justify-between border-b border-dashed last:border-b-0">
+            <label className="text-xs font-medium text-muted-foreground">单个收款人每日上限</label>
+            <p className="text-xs text-muted-foreground text-right max-w-[70%]">{formatLimit(apiKey.distribute_recipient_daily_limit)}</p>
+          </div>
+
+          <div className="px-3 py-2 flex items-center justify-between">
+            <label className="text-xs font-medium text-muted-foreground">IP 白名单</label>
+            <p className="text-xs text-muted-foreground font-mono truncate text-right max-w-[70%]">
+              {apiKey.distribute_allowed_ips?.length ? apiKey.distribute_allowed_ips.join(', ') : '不限制'}
+            </p>
           </div>
         </div>
       </div>
@@ -207,55 +360,61 @@ export function MerchantInfo({ apiKey, onUpdate, onDelete, updateAPIKey }: Merch
       <div>
         <h2 className="font-semibold mb-4">应用管理</h2>
         <div className="grid grid-cols-2 gap-2">
-          <MerchantDialog
-            mode="update"
-            apiKey={apiKey}
-            onSuccess={() => { }}
-            onUpdate={onUpdate}
-            updateAPIKey={updateAPIKey}
-            trigger={
-              <Button variant="outline" className="text-xs text-primary h-8 border-dashed border-primary/50 hover:bg-primary/5 w-full">
-                <Edit className="size-3 mr-1" />
-                编辑应用
-              </Button>
-            }
-          />
+          {canManage && (
+            <MerchantDialog
+              mode="update"
+              apiKey={apiKey}
+              onSuccess={() => { }}
+              onUpdate={onUpdate}
+              updateAPIKey={updateAPIKey}
+              trigger={
+                <Button variant="outline" className="text-xs text-primary h-8 border-dashed border-primary/50 hover:bg-primary/5 w-full">
+                  <Edit className="size-3 mr-1" />
+                  编辑应用
+                </Button>
+              }
+            />
+          )}
 
           <DistributeDialog />
 
-          <AlertDialog>
-            <AlertDialogTrigger asChild>
-              <Button variant="outline" className="text-xs text-destructive h-8 border-dashed border-destructive/50 hover:bg-destructive/5 w-full">
-                <Trash2 className="size-3 mr-1" />
-                删除应用
-              </Button>
-            </AlertDialogTrigger>
-            <AlertDialogContent>
-              <AlertDialogHeader>
-                <AlertDialogTitle>确认删除应用</AlertDialogTitle>
-                <AlertDialogDescription>
-                  确定要删除应用 &ldquo;{apiKey.app_name}&rdquo; 吗？
-                  此操作将永久删除该应用的所有凭证和配置，且无法恢复。
-                  使用此应用凭证的所有集成将立即失效。
-                </AlertDialogDescription>
-              </AlertDialogHeader>
-              <AlertDialogFooter>
-                <AlertDialogCancel>取消</AlertDialogCancel>
-                <AlertDialogAction
-                  onClick={() => onDelete(apiKey.id)}
-                  className="bg-destructive hover:bg-destructive/90"
-                >
-                  确认删除
-                </AlertDialogAction>
-              </AlertDialogFooter>
-            </AlertDialogContent>
-          </AlertDialog>
-
-          <TestModeToggle
-            apiKey={apiKey}
-            onUpdate={onUpdate}
-            updateAPIKey={updateAPIKey}
-          />
+          {isOwner && (
+            <AlertDialog>
+              <AlertDialogTrigger asChild>
+                <Button variant="outline" className="text-xs text-destructive h-8 border-dashed border-destructive/50 hover:bg-destructive/5 w-full">
+                  <Trash2 className="size-3 mr-1" />
+                  删除应用
+                </Button>
+              </AlertDialogTrigger>
+              <AlertDialogContent>
+                <AlertDialogHeader>
+                  <AlertDialogTitle>确认删除应用</AlertDialogTitle>
+                  <AlertDialogDescription>
+                    确定要删除应用 &ldquo;{apiKey.app_name}&rdquo; 吗？
+                    此操作将永久删除该应用的所有凭证和配置，且无法恢复。
+                    使用此应用凭证的所有集成将立即失效。
+                  </AlertDialogDescription>
+                </AlertDialogHeader>
+                <AlertDialogFooter>
+                  <AlertDialogCancel>取消</AlertDialogCancel>
+                  <AlertDialogAction
+                    onClick={() => onDelete(apiKey.id)}
+                    className="bg-destructive hover:bg-destructive/90"
+                  >
+                    确认删除
+                  </AlertDialogAction>
+                </AlertDialogFooter>
+              </AlertDialogContent>
+            </AlertDialog>
+          )}
+
+          {canManage && (
+            <TestModeToggle
+              apiKey={apiKey}
+              onUpdate={onUpdate}
+              updateAPIKey={updateAPIKey}
+            />
+          )}
         </div>
       </div>
     </div>
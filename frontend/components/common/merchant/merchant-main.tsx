@@ -11,7 +11,8 @@ import { MerchantSelector } from "@/components/common/merchant/merchant-selector
 import { MerchantInfo } from "@/components/common/merchant/merchant-info"
 import { MerchantData } from "@/components/common/merchant/merchant-data"
 import { MerchantDialog } from "@/components/common/merchant/merchant-dialog"
-import { type MerchantAPIKey } from "@/lib/services"
+import { MerchantInvitations } from "@/components/common/merchant/merchant-members"
+import { type CreateAPIKeyResponse } from "@/lib/services"
 import { useMerchant } from "@/contexts/merchant-context"
 
 /**
@@ -21,6 +22,8 @@ import { useMerchant } from "@/contexts/merchant-context"
 export function MerchantMain() {
   const { apiKeys, loading, error, loadAPIKeys, createAPIKey, updateAPIKey, deleteAPIKey } = useMerchant()
   const [selectedKeyId, setSelectedKeyId] = useState<string | null>(null)
+  /* 新建应用的默认接口密钥，仅展示一次 */
+  const [createdSecret, setCreatedSecret] = useState<{ apiKeyId: string; secret: string } | null>(null)
 
   const selectedKey = apiKeys.find(key => key.id === selectedKeyId) || null
 
@@ -37,8 +40,9 @@ export function MerchantMain() {
   }, [apiKeys, selectedKeyId])
 
   /* 创建成功回调 */
-  const handleCreateSuccess = (newKey: MerchantAPIKey) => {
+  const handleCreateSuccess = (newKey: CreateAPIKeyResponse) => {
     setSelectedKeyId(newKey.id)
+    setCreatedSecret({ apiKeyId: newKey.id, secret: newKey.api_secret })
   }
 
   /* 删除成功回调 */
@@ -58,6 +62,14 @@ export function MerchantMain() {
     }
   }
 
+  /* 退出应用回调 */
+  const handleLeave = (id: string) => {
+    if (selectedKeyId === id) {
+      setSelectedKeyId(null)
+    }
+    loadAPIKeys()
+  }
+
   /* 正在加载中 */
   if (loading) {
     return <LoadingPage text="集市中心" badgeText="集市" />
@@ -83,6 +95,8 @@ export function MerchantMain() {
         </div>
       </div>
 
+      <MerchantInvitations onAccepted={loadAPIKeys} />
+
       {error ? (
         <motion.div
           key="error"
@@ -128,8 +142,10 @@ export function MerchantMain() {
               <div className="lg:col-span-1">
                 <MerchantInfo
                   apiKey={selectedKey}
+                  initialSecret={createdSecret?.apiKeyId === selectedKey.id ? createdSecret.secret : undefined}
                   onDelete={handleDelete}
                   updateAPIKey={updateAPIKey}
+                  onLeave={handleLeave}
                 />
               </div>
             </div>
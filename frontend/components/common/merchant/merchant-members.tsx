@@ -0,0 +1,319 @@
+"use client"
+
+import * as React from "react"
+import { useState, useEffect, useCallback } from "react"
+import { toast } from "sonner"
+import { UserPlus, X } from "lucide-react"
+import { Button } from "@/components/ui/button"
+import {
+  Dialog,
+  DialogClose,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+  DialogTrigger,
+} from "@/components/ui/dialog"
+import { Label } from "@/components/ui/label"
+import { Input } from "@/components/ui/input"
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select"
+import { Spinner } from "@/components/ui/spinner"
+import { MerchantService, type MerchantAPIKey, type MerchantMember, type MerchantRole } from "@/lib/services"
+import { useUser } from "@/contexts/user-context"
+
+type InvitableRole = Exclude<MerchantRole, 'owner'>
+
+/** 角色名称 */
+export const roleLabels: Record<MerchantRole, string> = {
+  owner: '创建者',
+  developer: '开发',
+  support: '客服',
+  finance: '财务',
+}
+
+/** 可邀请的角色及说明 */
+const invitableRoles: { value: InvitableRole, description: string }[] = [
+  { value: 'developer', description: '管理应用配置、密钥与回调' },
+  { value: 'support', description: '查看订单并处理退款争议' },
+  { value: 'finance', description: '查看订单并执行退款与分发' },
+]
+
+interface MerchantMembersProps {
+  /** API Key */
+  apiKey: MerchantAPIKey
+  /** 退出应用后的回调 */
+  onLeave?: (id: string) => void
+}
+
+/**
+ * 应用成员组件
+ * 创建者可邀请成员、调整角色与移除成员，其他成员可退出应用
+ */
+export function MerchantMembers({ apiKey, onLeave }: MerchantMembersProps) {
+  const { user } = useUser()
+  const isOwner = apiKey.role === undefined || apiKey.role === 'owner'
+
+  const [members, setMembers] = useState<MerchantMember[]>([])
+  const [open, setOpen] = useState(false)
+  const [loading, setLoading] = useState(false)
+  const [userId, setUserId] = useState("")
+  const [username, setUsername] = useState("")
+  const [role, setRole] = useState<InvitableRole>('developer')
+
+  /* 加载成员列表 */
+  const loadMembers = useCallback(async () => {
+    try {
+      setMembers(await MerchantService.listMembers(apiKey.id))
+    } catch {
+      setMembers([])
+    }
+  }, [apiKey.id])
+
+  useEffect(() => {
+    loadMembers()
+  }, [loadMembers])
+
+  const handleInvite = async () => {
+    if (!userId.trim() || !username.trim()) {
+      toast.error('表单验证失败', { description: '请填写用户 ID 与用户名' })
+      return
+    }
+
+    try {
+      setLoading(true)
+      await MerchantService.inviteMember(apiKey.id, { user_id: userId.trim(), username: username.trim(), role })
+      toast.success('邀请已发送', { description: '对方接受后即可加入应用' })
+      setOpen(false)
+      setUserId("")
+      setUsername("")
+      await loadMembers()
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '邀请失败'
+      toast.error('邀请失败', { description: errorMessage })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const handleRoleChange = async (member: MerchantMember, newRole: InvitableRole) => {
+    try {
+      await MerchantService.updateMember(apiKey.id, member.id, newRole)
+      toast.success('角色已更新')
+      await loadMembers()
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '更新失败'
+      toast.error('更新失败', { description: errorMessage })
+    }
+  }
+
+  const handleRemove = async (member: MerchantMember, self: boolean) => {
+    try {
+      await MerchantService.removeMember(apiKey.id, member.id)
+      if (self) {
+        toast.success('已退出应用')
+        onLeave?.(apiKey.id)
+        return
+      }
+      toast.success(member.status === 'pending' ? '邀请已撤回' : '成员已移除')
+      await loadMembers()
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '操作失败'
+      toast.error('操作失败', { description: errorMessage })
+    }
+  }
+
+  /* 非创建者成员可退出应用 */
+  const self = isOwner ? undefined : members.find(member => String(member.user_id) === String(user?.id))
+
+  return (
+    <div>
+      <div className="flex items-center justify-between mb-4">
+        <h2 className="font-semibold">应用成员</h2>
+        {isOwner && (
+          <Dialog open={open} onOpenChange={setOpen}>
+            <DialogTrigger asChild>
+              <Button variant="ghost" className="h-6 px-2 text-xs">
+                <UserPlus className="size-3 mr-1" />
+                邀请
+              </Button>
+            </DialogTrigger>
+            <DialogContent>
+              <DialogHeader>
+                <DialogTitle>邀请成员</DialogTitle>
+                <DialogDescription>
+                  被邀请用户接受后按所选角色获得应用权限，所有操作都会记录在审计日志中。
+                </DialogDescription>
+              </DialogHeader>
+
+              <div className="grid gap-4 py-4">
+                <div className="grid grid-cols-2 gap-4">
+                  <div className="space-y-2">
+                    <Label htmlFor="member-user-id" className="text-xs">
+                      用户 ID <span className="text-destructive">*</span>
+                    </Label>
+                    <Input
+                      id="member-user-id"
+                      placeholder="被邀请者的用户 ID"
+                      value={userId}
+                      onChange={(e) => setUserId(e.target.value)}
+                      disabled={loading}
+                      className="h-8 text-xs"
+                    />
+                  </div>
+                  <div className="space-y-2">
+                    <Label htmlFor="member-username" className="text-xs">
+                      用户名 <span className="text-destructive">*</span>
+                    </Label>
+                    <Input
+                      id="member-username"
+                      placeholder="被邀请者的用户名"
+                      value={username}
+                      onChange={(e) => setUsername(e.target.value)}
+                      disabled={loading}
+                      className="h-8 text-xs"
+                    />
+                  </div>
+                </div>
+
+                <div className="space-y-2">
+                  <Label className="text-xs">角色</Label>
+                  <Select value={role} onValueChange={(value) => setRole(value as InvitableRole)} disabled={loading}>
+                    <SelectTrigger className="w-full h-8 text-xs" size="sm">
+                      <SelectValue />
+                    </SelectTrigger>
+                    <SelectContent>
+                      {invitableRoles.map(item => (
+                        <SelectItem key={item.value} value={item.value}>
+                          <span className="text-xs">{roleLabels[item.value]}</span>
+                          <span className="text-xs text-muted-foreground ml-2">{item.description}</span>
+                        </SelectItem>
+                      ))}
+                    </SelectContent>
+                  </Select>
+                </div>
+              </div>
+
+              <DialogFooter>
+                <DialogClose asChild>
+                  <Button variant="ghost" disabled={loading} className="h-8 text-xs">
+                    取消
+                  </Button>
+                </DialogClose>
+                <Button onClick={handleInvite} disabled={loading} className="h-8 text-xs">
+                  {loading ? <><Spinner /> 邀请中</> : '邀请'}
+                </Button>
+              </DialogFooter>
+            </DialogContent>
+          </Dialog>
+        )}
+      </div>
+
+      <div className="border border-dashed rounded-lg">
+        {members.map(member => (
+          <div key={member.id} className="px-3 py-2 flex items-center justify-between border-b border-dashed last:border-b-0">
+            <span className="text-xs font-medium">
+              {member.username}
+              {member.status === 'pending' && <span className="ml-2 text-[10px] text-muted-foreground">待接受</span>}
+            </span>
+            <div className="flex items-center gap-1">
+              {isOwner && member.role !== 'owner' ? (
+                <>
+                  <Select value={member.role} onValueChange={(value) => handleRoleChange(member, value as InvitableRole)}>
+                    <SelectTrigger className="w-fit h-6 text-xs" size="sm">
+                      <SelectValue />
+                    </SelectTrigger>
+                    <SelectContent>
+                      {invitableRoles.map(item => (
+                        <SelectItem key={item.value} value={item.value}>
+                          <span className="text-xs">{roleLabels[item.value]}</span>
+                        </SelectItem>
+                      ))}
+                    </SelectContent>
+                  </Select>
+                  <Button variant="ghost" className="size-6 p-1" title="移除" onClick={() => handleRemove(member, false)}>
+                    <X className="size-3 text-destructive" />
+                  </Button>
+                </>
+              ) : (
+                <span className="text-xs text-muted-foreground">{roleLabels[member.role]}</span>
+              )}
+            </div>
+          </div>
+        ))}
+      </div>
+
+      {self && (
+        <Button
+          variant="ghost"
+          className="mt-2 h-6 px-2 text-xs text-destructive"
+          onClick={() => handleRemove(self, true)}
+        >
+          退出应用
+        </Button>
+      )}
+    </div>
+  )
+}
+
+interface MerchantInvitationsProps {
+  /** 接受邀请后的回调 */
+  onAccepted?: () => void
+}
+
+/**
+ * 待接受的应用邀请
+ */
+export function MerchantInvitations({ onAccepted }: MerchantInvitationsProps) {
+  const [invitations, setInvitations] = useState<MerchantMember[]>([])
+
+  const loadInvitations = useCallback(async () => {
+    try {
+      setInvitations(await MerchantService.listInvitations())
+    } catch {
+      setInvitations([])
+    }
+  }, [])
+
+  useEffect(() => {
+    loadInvitations()
+  }, [loadInvitations])
+
+  const respond = async (invitation: MerchantMember, accept: boolean) => {
+    try {
+      if (accept) {
+        await MerchantService.acceptInvitation(invitation.id)
+        toast.success('已加入应用', { description: invitation.app_name })
+        onAccepted?.()
+      } else {
+        await MerchantService.declineInvitation(invitation.id)
+      }
+      await loadInvitations()
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '操作失败'
+      toast.error('操作失败', { description: errorMessage })
+    }
+  }
+
+  if (invitations.length === 0) return null
+
+  return (
+    <div className="border border-dashed rounded-lg mb-4">
+      {invitations.map(invitation => (
+        <div key={invitation.id} className="px-3 py-2 flex items-center justify-between border-b border-dashed last:border-b-0">
+          <span className="text-xs">
+            {invitation.inviter_name} 邀请您以「{roleLabels[invitation.role]}」身份加入应用 <span className="font-medium">{invitation.app_name}</span>
+          </span>
+          <div className="flex items-center gap-1">
+            <Button variant="ghost" className="h-6 px-2 text-xs" onClick={() => respond(invitation, false)}>
+              拒绝
+            </Button>
+            <Button className="h-6 px-2 text-xs" onClick={() => respond(invitation, true)}>
+              接受
+            </Button>
+          </div>
+        </div>
+      ))}
+    </div>
+  )
+}
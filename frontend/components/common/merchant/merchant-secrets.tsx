@@ -0,0 +1,269 @@
+"use client"
+
+import * as React from "react"
+import { useState, useEffect, useCallback } from "react"
+import { toast } from "sonner"
+import { Copy, Plus, RefreshCw, Ban } from "lucide-react"
+import { Button } from "@/components/ui/button"
+import {
+  Dialog,
+  DialogClose,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+  DialogTrigger,
+} from "@/components/ui/dialog"
+import { Label } from "@/components/ui/label"
+import { Input } from "@/components/ui/input"
+import { Checkbox } from "@/components/ui/checkbox"
+import { Spinner } from "@/components/ui/spinner"
+import { formatDateTime } from "@/lib/utils"
+import { MerchantService, type APISecretScope, type MerchantAPISecret } from "@/lib/services"
+
+/** 权限名称 */
+const scopeLabels: Record<APISecretScope, string> = {
+  create_order: '创建订单',
+  query: '查询',
+  refund: '退款',
+  distribute: '分发',
+}
+
+/** 轮换时旧密钥的默认重叠时长（小时） */
+const DEFAULT_OVERLAP_HOURS = 24
+
+interface MerchantSecretsProps {
+  /** API Key ID */
+  apiKeyId: string
+  /** 新建应用时返回的默认密钥明文 */
+  initialPlaintext?: string
+}
+
+/**
+ * 接口密钥管理组件
+ * 列出应用的接口密钥，支持创建、轮换与吊销，明文仅在创建或轮换后展示一次
+ */
+export function MerchantSecrets({ apiKeyId, initialPlaintext }: MerchantSecretsProps) {
+  const [secrets, setSecrets] = useState<MerchantAPISecret[]>([])
+  const [plaintext, setPlaintext] = useState<string | null>(initialPlaintext ?? null)
+
+  const [createOpen, setCreateOpen] = useState(false)
+  const [loading, setLoading] = useState(false)
+  const [name, setName] = useState("")
+  const [scopes, setScopes] = useState<APISecretScope[]>([])
+
+  /* 加载密钥列表 */
+  const loadSecrets = useCallback(async () => {
+    try {
+      setSecrets(await MerchantService.listAPISecrets(apiKeyId))
+    } catch {
+      setSecrets([])
+    }
+  }, [apiKeyId])
+
+  useEffect(() => {
+    loadSecrets()
+  }, [loadSecrets])
+
+  /* 切换应用时仅保留该应用新建时返回的默认密钥 */
+  useEffect(() => {
+    setPlaintext(initialPlaintext ?? null)
+  }, [apiKeyId, initialPlaintext])
+
+  /* 密钥状态 */
+  const secretStatus = (secret: MerchantAPISecret) => {
+    if (secret.revoked_at) return '已吊销'
+    if (secret.expires_at) {
+      return new Date(secret.expires_at) > new Date()
+        ? `${ formatDateTime(secret.expires_at) } 失效`
+        : '已过期'
+    }
+    return '有效'
+  }
+
+  const isActive = (secret: MerchantAPISecret) =>
+    !secret.revoked_at && (!secret.expires_at || new Date(secret.expires_at) > new Date())
+
+  const toggleScope = (scope: APISecretScope, checked: boolean) => {
+    setScopes(prev => checked ? [...prev, scope] : prev.filter(s => s !== scope))
+  }
+
+  const handleCreate = async () => {
+    if (!name.trim()) {
+      toast.error('表单验证失败', { description: '请填写密钥名称' })
+      return
+    }
+    if (scopes.length === 0) {
+      toast.error('表单验证失败', { description: '请至少选择一项权限' })
+      return
+    }
+
+    try {
+      setLoading(true)
+      const result = await MerchantService.createAPISecret(apiKeyId, { name: name.trim(), scopes })
+      setPlaintext(result.secret)
+      setCreateOpen(false)
+      setName("")
+      setScopes([])
+      await loadSecrets()
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '创建失败'
+      toast.error('创建失败', { description: errorMessage })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const handleRotate = async (secret: MerchantAPISecret) => {
+    try {
+      const result = await MerchantService.rotateAPISecret(apiKeyId, secret.id, { overlap_hours: DEFAULT_OVERLAP_HOURS })
+      setPlaintext(result.secret)
+      toast.success('轮换成功', { description: `旧密钥将在 ${ DEFAULT_OVERLAP_HOURS } 小时后失效` })
+      await loadSecrets()
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '轮换失败'
+      toast.error('轮换失败', { description: errorMessage })
+    }
+  }
+
+  const handleRevoke = async (secret: MerchantAPISecret) => {
+    try {
+      await MerchantService.revokeAPISecret(apiKeyId, secret.id)
+      toast.success('密钥已吊销')
+      await loadSecrets()
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '吊销失败'
+      toast.error('吊销失败', { description: errorMessage })
+    }
+  }
+
+  const copyPlaintext = async () => {
+    if (!plaintext) return
+    try {
+      await navigator.clipboard.writeText(plaintext)
+      toast.success('密钥已复制')
+    } catch {
+      toast.error('复制失败')
+    }
+  }
+
+  return (
+    <div>
+      <div className="flex items-center justify-between mb-4">
+        <h2 className="font-semibold">接口密钥</h2>
+        <Dialog open={createOpen} onOpenChange={setCreateOpen}>
+          <DialogTrigger asChild>
+            <Button variant="ghost" className="h-6 px-2 text-xs">
+              <Plus className="size-3 mr-1" />
+              新建
+            </Button>
+          </DialogTrigger>
+          <DialogContent>
+            <DialogHeader>
+              <DialogTitle>新建接口密钥</DialogTitle>
+              <DialogDescription>
+                接口密钥用于 Basic Auth 认证，仅在创建后展示一次，请妥善保存。
+              </DialogDescription>
+            </DialogHeader>
+
+            <div className="grid gap-4 py-4">
+              <div className="space-y-2">
+                <Label htmlFor="secret-name" className="text-xs">
+                  名称 <span className="text-destructive">*</span>
+                </Label>
+                <Input
+                  id="secret-name"
+                  placeholder="用于识别密钥用途，最多32字"
+                  value={name}
+                  onChange={(e) => setName(e.target.value)}
+                  maxLength={32}
+                  disabled={loading}
+                  className="h-8 text-xs"
+                />
+              </div>
+
+              <div className="space-y-2">
+                <Label className="text-xs">
+                  权限 <span className="text-destructive">*</span>
+                </Label>
+                <div className="grid grid-cols-2 gap-2">
+                  {(Object.keys(scopeLabels) as APISecretScope[]).map(scope => (
+                    <label key={scope} className="flex items-center gap-2 text-xs">
+                      <Checkbox
+                        checked={scopes.includes(scope)}
+                        onCheckedChange={(checked) => toggleScope(scope, checked === true)}
+                        disabled={loading}
+                      />
+                      {scopeLabels[scope]}
+                    </label>
+                  ))}
+                </div>
+              </div>
+            </div>
+
+            <DialogFooter>
+              <DialogClose asChild>
+                <Button variant="ghost" disabled={loading} className="h-8 text-xs">
+                  取消
+                </Button>
+              </DialogClose>
+              <Button onClick={handleCreate} disabled={loading} className="h-8 text-xs">
+                {loading ? <><Spinner /> 创建中</> : '创建'}
+              </Button>
+            </DialogFooter>
+          </DialogContent>
+        </Dialog>
+      </div>
+
+      {plaintext && (
+        <div className="mb-4 border border-dashed border-amber-500/50 rounded-lg px-3 py-2 space-y-2">
+          <p className="text-xs text-amber-600">新密钥仅展示一次，请立即保存</p>
+          <div className="flex items-center p-2 h-8 border border-dashed rounded-sm">
+            <code className="text-xs text-muted-foreground font-mono flex-1 overflow-x-auto p-1">
+              {plaintext}
+            </code>
+            <Button variant="ghost" className="size-6 p-1" onClick={copyPlaintext}>
+              <Copy className="size-3 text-muted-foreground" />
+            </Button>
+          </div>
+          <Button variant="ghost" className="h-6 px-2 text-xs" onClick={() => setPlaintext(null)}>
+            我已保存
+          </Button>
+        </div>
+      )}
+
+      <div className="border border-dashed rounded-lg">
+        {secrets.length === 0 ? (
+          <p className="px-3 py-2 text-xs text-muted-foreground">暂无接口密钥</p>
+        ) : secrets.map(secret => (
+          <div key={secret.id} className="px-3 py-2 border-b border-dashed last:border-b-0 space-y-1">
+            <div className="flex items-center justify-between">
+              <span className="text-xs font-medium">
+                {secret.name}
+                <code className="ml-2 text-muted-foreground font-mono">{secret.prefix}…</code>
+              </span>
+              {isActive(secret) && (
+                <div className="flex items-center gap-1">
+                  <Button variant="ghost" className="size-6 p-1" title="轮换" onClick={() => handleRotate(secret)}>
+                    <RefreshCw className="size-3 text-muted-foreground" />
+                  </Button>
+                  <Button variant="ghost" className="size-6 p-1" title="吊销" onClick={() => handleRevoke(secret)}>
+                    <Ban className="size-3 text-destructive" />
+                  </Button>
+                </div>
+              )}
+            </div>
+            <p className="text-[10px] text-muted-foreground">
+              {secret.scopes.map(scope => scopeLabels[scope]).join(' / ')}
+              {' · '}
+              {secretStatus(secret)}
+              {' · '}
+              {secret.last_used_at ? `最近使用 ${ formatDateTime(secret.last_used_at) }` : '从未使用'}
+            </p>
+          </div>
+        ))}
+      </div>
+    </div>
+  )
+}
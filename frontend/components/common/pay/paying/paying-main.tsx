@@ -6,6 +6,7 @@ import { toast } from "sonner"
 import { motion } from "motion/react"
 import { PayingNow } from "@/components/common/pay/paying/paying-now"
 import { PayingInfo } from "@/components/common/pay/paying/paying-info"
+import { TOTPDialog, isTOTPRequiredError } from "@/components/common/general/totp-dialog"
 
 import services from "@/lib/services"
 import type { GetMerchantOrderResponse } from "@/lib/services"
@@ -30,6 +31,7 @@ export function PayingMain() {
   const [currentStep, setCurrentStep] = useState<'method' | 'pay'>('method')
   const [selectedMethod, setSelectedMethod] = useState<string>('')
   const [isOpen, setIsOpen] = useState(false)
+  const [totpOpen, setTotpOpen] = useState(false)
 
   const timeoutRef = useRef<NodeJS.Timeout | null>(null)
   const isMountedRef = useRef(true)
@@ -105,8 +107,8 @@ export function PayingMain() {
     }
   }
 
-  /** 执行积分认证操作 */
-  const handlePayOrder = async () => {
+  /** 执行积分认证操作，超过两步验证阈值时需附带验证码 */
+  const handlePayOrder = async (totpCode?: string) => {
     if (!orderInfo) return
 
     if (!payKey.trim()) {
@@ -133,9 +135,11 @@ export function PayingMain() {
 
       await services.merchant.payMerchantOrder({
         order_no: encryptedOrderNo!,
-        pay_key: payKey
+        pay_key: payKey,
+        totp_code: totpCode
       })
 
+      setTotpOpen(false)
       toast.success("积分流转服务认证成功！", { id: 'payment-success' })
 
       /** 支付成功后立即更新订单状态为成功 */
@@ -162,6 +166,10 @@ export function PayingMain() {
         router.push('/home')
       }, 5000)
     } catch (error: unknown) {
+      if (isTOTPRequiredError(error)) {
+        setTotpOpen(true)
+        return
+      }
       handleServiceError(error, "认证")
 
       if (
@@ -230,9 +238,15 @@ export function PayingMain() {
               onCurrentStepChange={setCurrentStep}
               onSelectedMethodChange={setSelectedMethod}
               onIsOpenChange={setIsOpen}
-              onPayOrder={handlePayOrder}
+              onPayOrder={() => handlePayOrder()}
             />
           </motion.div>
+          <TOTPDialog
+            isOpen={totpOpen}
+            onOpenChange={setTotpOpen}
+            onConfirm={(code) => handlePayOrder(code)}
+            loading={paying}
+          />
         </div>
       </div>
     </div>
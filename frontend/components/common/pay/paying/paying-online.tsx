@@ -6,6 +6,7 @@ import { toast } from "sonner"
 import { motion } from "motion/react"
 import { PayingNow } from "@/components/common/pay/paying/paying-now"
 import { PayingInfo } from "@/components/common/pay/paying/paying-info"
+import { TOTPDialog, isTOTPRequiredError } from "@/components/common/general/totp-dialog"
 import { useUser } from "@/contexts/user-context"
 
 import services from "@/lib/services"
@@ -33,6 +34,7 @@ export function PayingOnline() {
   const [currentStep, setCurrentStep] = useState<'method' | 'pay'>('method')
   const [selectedMethod, setSelectedMethod] = useState<string>('')
   const [isOpen, setIsOpen] = useState(false)
+  const [totpOpen, setTotpOpen] = useState(false)
 
   const timeoutRef = useRef<NodeJS.Timeout | null>(null)
   const isMountedRef = useRef(true)
@@ -168,8 +170,8 @@ export function PayingOnline() {
     }
   }
 
-  /* 执行支付操作 */
-  const handlePayOrder = async () => {
+  /* 执行支付操作，超过两步验证阈值时需附带验证码 */
+  const handlePayOrder = async (totpCode?: string) => {
     if (!paymentLink || !token) return
 
     if (!payKey.trim()) {
@@ -187,9 +189,11 @@ export function PayingOnline() {
       await services.merchant.payByLink({
         token: token,
         pay_key: payKey,
+        totp_code: totpCode,
         remark: paymentLink.remark || undefined
       })
 
+      setTotpOpen(false)
       toast.success("积分流转服务认证成功！", { id: 'payment-success' })
 
       /* 支付成功后更新订单状态 */
@@ -216,6 +220,10 @@ export function PayingOnline() {
         window.location.href = '/home'
       }, 5000)
     } catch (error: unknown) {
+      if (isTOTPRequiredError(error)) {
+        setTotpOpen(true)
+        return
+      }
       handleServiceError(error, "认证")
     } finally {
       setPaying(false)
@@ -272,9 +280,15 @@ export function PayingOnline() {
               onCurrentStepChange={setCurrentStep}
               onSelectedMethodChange={setSelectedMethod}
               onIsOpenChange={setIsOpen}
-              onPayOrder={handlePayOrder}
+              onPayOrder={() => handlePayOrder()}
             />
           </motion.div>
+          <TOTPDialog
+            isOpen={totpOpen}
+            onOpenChange={setTotpOpen}
+            onConfirm={(code) => handlePayOrder(code)}
+            loading={paying}
+          />
         </div>
       </div>
     </div>
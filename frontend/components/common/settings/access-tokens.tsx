@@ -0,0 +1,342 @@
+"use client"
+
+import * as React from "react"
+import { useState, useEffect, useCallback } from "react"
+import { toast } from "sonner"
+import { Copy, Plus, Ban } from "lucide-react"
+import { Button } from "@/components/ui/button"
+import {
+  Dialog,
+  DialogClose,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+  DialogTrigger,
+} from "@/components/ui/dialog"
+import { Label } from "@/components/ui/label"
+import { Input } from "@/components/ui/input"
+import { Checkbox } from "@/components/ui/checkbox"
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select"
+import { Spinner } from "@/components/ui/spinner"
+import { formatDateTime } from "@/lib/utils"
+import { UserService } from "@/lib/services/user"
+import type { AccessTokenScope, UserAccessToken } from "@/lib/services"
+
+/** 权限名称 */
+const scopeLabels: Record<AccessTokenScope, string> = {
+  read_transactions: '查询交易记录',
+  read_balance: '查询余额',
+  transfer: '转账',
+}
+
+/** 有效期选项（天），0 表示永不过期 */
+const expiryOptions = [
+  { value: '30', label: '30 天' },
+  { value: '90', label: '90 天' },
+  { value: '365', label: '365 天' },
+  { value: '0', label: '永不过期' },
+]
+
+/**
+ * 个人访问令牌管理
+ * 令牌用于脚本通过 Authorization: Bearer 调用用户接口，明文仅在创建后展示一次
+ */
+export function AccessTokenSettings() {
+  const [tokens, setTokens] = useState<UserAccessToken[]>([])
+  const [plaintext, setPlaintext] = useState<string | null>(null)
+
+  const [open, setOpen] = useState(false)
+  const [loading, setLoading] = useState(false)
+  const [name, setName] = useState("")
+  const [scopes, setScopes] = useState<AccessTokenScope[]>([])
+  const [maxAmount, setMaxAmount] = useState("")
+  const [dailyLimit, setDailyLimit] = useState("")
+  const [expiresInDays, setExpiresInDays] = useState("90")
+  const [payKey, setPayKey] = useState("")
+
+  /* 加载令牌列表 */
+  const loadTokens = useCallback(async () => {
+    try {
+      setTokens(await UserService.listAccessTokens())
+    } catch {
+      setTokens([])
+    }
+  }, [])
+
+  useEffect(() => {
+    loadTokens()
+  }, [loadTokens])
+
+  /* 令牌状态 */
+  const tokenStatus = (token: UserAccessToken) => {
+    if (token.revoked_at) return '已吊销'
+    if (token.expires_at) {
+      return new Date(token.expires_at) > new Date()
+        ? `${ formatDateTime(token.expires_at) } 失效`
+        : '已过期'
+    }
+    return '永不过期'
+  }
+
+  const isActive = (token: UserAccessToken) =>
+    !token.revoked_at && (!token.expires_at || new Date(token.expires_at) > new Date())
+
+  const toggleScope = (scope: AccessTokenScope, checked: boolean) => {
+    setScopes(prev => checked ? [...prev, scope] : prev.filter(s => s !== scope))
+  }
+
+  const resetForm = () => {
+    setName("")
+    setScopes([])
+    setMaxAmount("")
+    setDailyLimit("")
+    setExpiresInDays("90")
+    setPayKey("")
+  }
+
+  const handleCreate = async () => {
+    if (!name.trim()) {
+      toast.error('表单验证失败', { description: '请填写令牌名称' })
+      return
+    }
+    if (scopes.length === 0) {
+      toast.error('表单验证失败', { description: '请至少选择一项权限' })
+      return
+    }
+    const canTransfer = scopes.includes('transfer')
+    if (canTransfer && (!(Number(maxAmount) > 0) || !(Number(dailyLimit) > 0))) {
+      toast.error('表单验证失败', { description: '转账权限需设置单笔与每日上限' })
+      return
+    }
+    if (!/^\d{6}$/.test(payKey)) {
+      toast.error('表单验证失败', { description: '请输入6位数字支付密码' })
+      return
+    }
+
+    try {
+      setLoading(true)
+      const result = await UserService.createAccessToken({
+        name: name.trim(),
+        scopes,
+        transfer_max_amount: canTransfer ? maxAmount : undefined,
+        transfer_daily_limit: canTransfer ? dailyLimit : undefined,
+        expires_in_days: Number(expiresInDays),
+        pay_key: payKey,
+      })
+      setPlaintext(result.token)
+      setOpen(false)
+      resetForm()
+      await loadTokens()
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '创建失败'
+      toast.error('创建失败', { description: errorMessage })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const handleRevoke = async (token: UserAccessToken) => {
+    try {
+      await UserService.revokeAccessToken(token.id)
+      toast.success('令牌已吊销')
+      await loadTokens()
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '吊销失败'
+      toast.error('吊销失败', { description: errorMessage })
+    }
+  }
+
+  const copyPlaintext = async () => {
+    if (!plaintext) return
+    try {
+      await navigator.clipboard.writeText(plaintext)
+      toast.success('令牌已复制')
+    } catch {
+      toast.error('复制失败')
+    }
+  }
+
+  return (
+    <div className="space-y-4 max-w-md">
+      <div className="flex items-center justify-between">
+        <div className="font-medium text-sm text-muted-foreground">访问令牌</div>
+        <Dialog open={open} onOpenChange={setOpen}>
+          <DialogTrigger asChild>
+            <Button variant="ghost" className="h-6 px-2 text-xs">
+              <Plus className="size-3 mr-1" />
+              新建
+            </Button>
+          </DialogTrigger>
+          <DialogContent>
+            <DialogHeader>
+              <DialogTitle>新建访问令牌</DialogTitle>
+              <DialogDescription>
+                访问令牌可在脚本中通过 Authorization: Bearer 调用接口，仅在创建后展示一次，请妥善保存。
+              </DialogDescription>
+            </DialogHeader>
+
+            <div className="grid gap-4 py-4">
+              <div className="space-y-2">
+                <Label htmlFor="token-name" className="text-xs">
+                  名称 <span className="text-destructive">*</span>
+                </Label>
+                <Input
+                  id="token-name"
+                  placeholder="用于识别令牌用途，最多32字"
+                  value={name}
+                  onChange={(e) => setName(e.target.value)}
+                  maxLength={32}
+                  disabled={loading}
+                  className="h-8 text-xs"
+                />
+              </div>
+
+              <div className="space-y-2">
+                <Label className="text-xs">
+                  权限 <span className="text-destructive">*</span>
+                </Label>
+                <div className="grid grid-cols-3 gap-2">
+                  {(Object.keys(scopeLabels) as AccessTokenScope[]).map(scope => (
+                    <label key={scope} className="flex items-center gap-2 text-xs">
+                      <Checkbox
+                        checked={scopes.includes(scope)}
+                        onCheckedChange={(checked) => toggleScope(scope, checked === true)}
+                        disabled={loading}
+                      />
+                      {scopeLabels[scope]}
+                    </label>
+                  ))}
+                </div>
+              </div>
+
+              {scopes.includes('transfer') && (
+                <div className="grid grid-cols-2 gap-4">
+                  <div className="space-y-2">
+                    <Label htmlFor="token-max-amount" className="text-xs">
+                      单笔上限 <span className="text-destructive">*</span>
+                    </Label>
+                    <Input
+                      id="token-max-amount"
+                      type="number"
+                      placeholder="0.00"
+                      value={maxAmount}
+                      onChange={(e) => setMaxAmount(e.target.value)}
+                      disabled={loading}
+                      className="h-8 text-xs"
+                    />
+                  </div>
+                  <div className="space-y-2">
+                    <Label htmlFor="token-daily-limit" className="text-xs">
+                      每日上限 <span className="text-destructive">*</span>
+                    </Label>
+                    <Input
+                      id="token-daily-limit"
+                      type="number"
+                      placeholder="0.00"
+                      value={dailyLimit}
+                      onChange={(e) => setDailyLimit(e.target.value)}
+                      disabled={loading}
+                      className="h-8 text-xs"
+                    />
+                  </div>
+                </div>
+              )}
+
+              <div className="grid grid-cols-2 gap-4">
+                <div className="space-y-2">
+                  <Label className="text-xs">有效期</Label>
+                  <Select value={expiresInDays} onValueChange={setExpiresInDays} disabled={loading}>
+                    <SelectTrigger className="w-full h-8 text-xs" size="sm">
+                      <SelectValue />
+                    </SelectTrigger>
+                    <SelectContent>
+                      {expiryOptions.map(option => (
+                        <SelectItem key={option.value} value={option.value}>
+                          <span className="text-xs">{option.label}</span>
+                        </SelectItem>
+                      ))}
+                    </SelectContent>
+                  </Select>
+                </div>
+                <div className="space-y-2">
+                  <Label htmlFor="token-pay-key" className="text-xs">
+                    支付密码 <span className="text-destructive">*</span>
+                  </Label>
+                  <Input
+                    id="token-pay-key"
+                    type="password"
+                    inputMode="numeric"
+                    placeholder="6位数字"
+                    value={payKey}
+                    onChange={(e) => setPayKey(e.target.value)}
+                    maxLength={6}
+                    disabled={loading}
+                    className="h-8 text-xs"
+                  />
+                </div>
+              </div>
+            </div>
+
+            <DialogFooter>
+              <DialogClose asChild>
+                <Button variant="ghost" disabled={loading} className="h-8 text-xs">
+                  取消
+                </Button>
+              </DialogClose>
+              <Button onClick={handleCreate} disabled={loading} className="h-8 text-xs">
+                {loading ? <><Spinner /> 创建中</> : '创建'}
+              </Button>
+            </DialogFooter>
+          </DialogContent>
+        </Dialog>
+      </div>
+
+      {plaintext && (
+        <div className="border border-dashed border-amber-500/50 rounded-lg px-3 py-2 space-y-2">
+          <p className="text-xs text-amber-600">新令牌仅展示一次，请立即保存</p>
+          <div className="flex items-center p-2 h-8 border border-dashed rounded-sm">
+            <code className="text-xs text-muted-foreground font-mono flex-1 overflow-x-auto p-1">
+              {plaintext}
+            </code>
+            <Button variant="ghost" className="size-6 p-1" onClick={copyPlaintext}>
+              <Copy className="size-3 text-muted-foreground" />
+            </Button>
+          </div>
+          <Button variant="ghost" className="h-6 px-2 text-xs" onClick={() => setPlaintext(null)}>
+            我已保存
+          </Button>
+        </div>
+      )}
+
+      <div className="border border-dashed rounded-lg">
+        {tokens.length === 0 ? (
+          <p className="px-3 py-2 text-xs text-muted-foreground">暂无访问令牌</p>
+        ) : tokens.map(token => (
+          <div key={token.id} className="px-3 py-2 border-b border-dashed last:border-b-0 space-y-1">
+            <div className="flex items-center justify-between">
+              <span className="text-xs font-medium">
+                {token.name}
+                <code className="ml-2 text-muted-foreground font-mono">{token.prefix}…</code>
+              </span>
+              {isActive(token) && (
+                <Button variant="ghost" className="size-6 p-1" title="吊销" onClick={() => handleRevoke(token)}>
+                  <Ban className="size-3 text-destructive" />
+                </Button>
+              )}
+            </div>
+            <p className="text-[10px] text-muted-foreground">
+              {token.scopes.map(scope => scopeLabels[scope]).join(' / ')}
+              {token.scopes.includes('transfer') && ` (单笔 ${ token.transfer_max_amount } · 每日 ${ token.transfer_daily_limit })`}
+              {' · '}
+              {tokenStatus(token)}
+              {' · '}
+              {token.last_used_at ? `最近使用 ${ formatDateTime(token.last_used_at) }` : '从未使用'}
+            </p>
+          </div>
+        ))}
+      </div>
+    </div>
+  )
+}
@@ -12,9 +12,14 @@ import { Button } from "@/components/ui/button"
 import { Form, FormControl, FormField, FormItem, FormLabel, FormMessage } from "@/components/ui/form"
 import { Input } from "@/components/ui/input"
 import { UserService } from "@/lib/services/user"
+import { useUser } from "@/contexts/user-context"
+import { TwoFactorSettings } from "@/components/common/settings/two-factor"
+import { SessionSettings } from "@/components/common/settings/sessions"
+import { AccessTokenSettings } from "@/components/common/settings/access-tokens"
 
 /* 表单验证规则 */
 const payKeySchema = z.object({
+  oldPayKey: z.string(),
   newPayKey: z
     .string()
     .min(6, "密码必须是6位数字")
@@ -29,20 +34,30 @@ const payKeySchema = z.object({
 type PayKeyFormValues = z.infer<typeof payKeySchema>
 
 export function SecurityMain() {
+  const { user } = useUser()
   const [isSubmitting, setIsSubmitting] = React.useState(false)
 
+  /* 已设置过密码时需要验证原密码 */
+  const requireOldPayKey = user?.is_pay_key ?? false
+
   const form = useForm<PayKeyFormValues>({
     resolver: zodResolver(payKeySchema),
     defaultValues: {
+      oldPayKey: "",
       newPayKey: "",
       confirmPayKey: "",
     },
   })
 
   const onSubmit = async (data: PayKeyFormValues) => {
+    if (requireOldPayKey && !/^\d{6}$/.test(data.oldPayKey)) {
+      form.setError("oldPayKey", { message: "请输入6位数字原密码" })
+      return
+    }
+
     try {
       setIsSubmitting(true)
-      await UserService.updatePayKey(data.newPayKey)
+      await UserService.updatePayKey(data.newPayKey, requireOldPayKey ? data.oldPayKey : undefined)
 
       toast.success("修改成功", {
         description: "您的密码已成功更新",
@@ -89,6 +104,28 @@ export function SecurityMain() {
         <div>
           <Form {...form}>
             <form onSubmit={form.handleSubmit(onSubmit)} className="space-y-4 max-w-md">
+              {requireOldPayKey && (
+                <FormField
+                  control={form.control}
+                  name="oldPayKey"
+                  render={({ field }) => (
+                    <FormItem>
+                      <FormLabel className="text-xs text-muted-foreground">原密码</FormLabel>
+                      <FormControl>
+                        <Input
+                          type="password"
+                          placeholder="请输入当前的6位数字密码"
+                          maxLength={6}
+                          className="h-9"
+                          {...field}
+                        />
+                      </FormControl>
+                      <FormMessage className="text-xs" />
+                    </FormItem>
+                  )}
+                />
+              )}
+
               <FormField
                 control={form.control}
                 name="newPayKey"
@@ -140,6 +177,12 @@ export function SecurityMain() {
             </form>
           </Form>
         </div>
+
+        <TwoFactorSettings />
+
+        <SessionSettings />
+
+        <AccessTokenSettings />
       </div>
     </div>
   )
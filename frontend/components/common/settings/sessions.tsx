@@ -0,0 +1,89 @@
+"use client"
+
+import * as React from "react"
+import { useState, useEffect, useCallback } from "react"
+import { toast } from "sonner"
+import { X } from "lucide-react"
+import { Button } from "@/components/ui/button"
+import { formatDateTime } from "@/lib/utils"
+import { UserService } from "@/lib/services/user"
+import type { UserSession } from "@/lib/services"
+
+/**
+ * 登录设备管理
+ * 列出当前账号的登录会话，支持吊销单个会话或退出所有设备
+ */
+export function SessionSettings() {
+  const [sessions, setSessions] = useState<UserSession[]>([])
+  const [loading, setLoading] = useState(false)
+
+  /* 加载会话列表 */
+  const loadSessions = useCallback(async () => {
+    try {
+      setSessions(await UserService.listSessions())
+    } catch {
+      setSessions([])
+    }
+  }, [])
+
+  useEffect(() => {
+    loadSessions()
+  }, [loadSessions])
+
+  const handleRevoke = async (session: UserSession) => {
+    try {
+      await UserService.revokeSession(session.id)
+      if (session.current) {
+        window.location.href = '/login'
+        return
+      }
+      toast.success("已退出该设备")
+      await loadSessions()
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '操作失败'
+      toast.error('操作失败', { description: errorMessage })
+    }
+  }
+
+  const handleRevokeAll = async () => {
+    try {
+      setLoading(true)
+      await UserService.revokeAllSessions()
+      window.location.href = '/login'
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '操作失败'
+      toast.error('操作失败', { description: errorMessage })
+      setLoading(false)
+    }
+  }
+
+  return (
+    <div className="space-y-4 max-w-md">
+      <div className="flex items-center justify-between">
+        <div className="font-medium text-sm text-muted-foreground">登录设备</div>
+        <Button variant="ghost" className="h-6 px-2 text-xs text-destructive" onClick={handleRevokeAll} disabled={loading}>
+          退出所有设备
+        </Button>
+      </div>
+
+      <div className="border border-dashed rounded-lg">
+        {sessions.map(session => (
+          <div key={session.id} className="px-3 py-2 flex items-center justify-between gap-2 border-b border-dashed last:border-b-0">
+            <div className="min-w-0 space-y-0.5">
+              <p className="text-xs font-medium truncate" title={session.user_agent}>
+                {session.user_agent || '未知设备'}
+                {session.current && <span className="ml-2 text-[10px] text-primary">当前设备</span>}
+              </p>
+              <p className="text-[10px] text-muted-foreground">
+                {session.ip} · 登录于 {formatDateTime(session.created_at)} · 最近活跃 {formatDateTime(session.last_seen_at)}
+              </p>
+            </div>
+            <Button variant="ghost" className="size-6 p-1 shrink-0" title="退出该设备" onClick={() => handleRevoke(session)}>
+              <X className="size-3 text-destructive" />
+            </Button>
+          </div>
+        ))}
+      </div>
+    </div>
+  )
+}
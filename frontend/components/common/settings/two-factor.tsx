@@ -0,0 +1,240 @@
+"use client"
+
+import * as React from "react"
+import { useState, useEffect, useCallback } from "react"
+import { toast } from "sonner"
+import { Copy } from "lucide-react"
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+import { Label } from "@/components/ui/label"
+import { UserService } from "@/lib/services/user"
+import type { TOTPStatus, EnrollTOTPResponse } from "@/lib/services"
+
+/**
+ * 两步验证设置
+ * 启用后单笔支付金额超过阈值时需额外输入验证器中的验证码
+ */
+export function TwoFactorSettings() {
+  const [status, setStatus] = useState<TOTPStatus | null>(null)
+  const [enrollment, setEnrollment] = useState<EnrollTOTPResponse | null>(null)
+  const [recoveryCodes, setRecoveryCodes] = useState<string[] | null>(null)
+  const [payKey, setPayKey] = useState("")
+  const [code, setCode] = useState("")
+  const [threshold, setThreshold] = useState("")
+  const [loading, setLoading] = useState(false)
+
+  /* 加载两步验证状态 */
+  const loadStatus = useCallback(async () => {
+    try {
+      const result = await UserService.getTOTPStatus()
+      setStatus(result)
+      setThreshold(result.threshold ?? "")
+    } catch {
+      setStatus(null)
+    }
+  }, [])
+
+  useEffect(() => {
+    loadStatus()
+  }, [loadStatus])
+
+  /* 执行需要验证码的操作，成功后清空输入并刷新状态 */
+  const run = async (action: () => Promise<void>, failTitle: string) => {
+    try {
+      setLoading(true)
+      await action()
+      setPayKey("")
+      setCode("")
+      await loadStatus()
+    } catch (error: unknown) {
+      const errorMessage = error instanceof Error ? error.message : '请稍后重试'
+      toast.error(failTitle, { description: errorMessage })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const handleEnroll = () => {
+    if (!/^\d{6}$/.test(payKey)) {
+      toast.error("请输入6位数字安全密码")
+      return
+    }
+    run(async () => {
+      setEnrollment(await UserService.enrollTOTP(payKey))
+    }, "生成密钥失败")
+  }
+
+  const handleEnable = () => run(async () => {
+    const result = await UserService.enableTOTP(code)
+    setEnrollment(null)
+    setRecoveryCodes(result.recovery_codes)
+    toast.success("两步验证已启用")
+  }, "启用失败")
+
+  const handleDisable = () => {
+    if (!/^\d{6}$/.test(payKey)) {
+      toast.error("请输入6位数字安全密码")
+      return
+    }
+    run(async () => {
+      await UserService.disableTOTP(payKey, code)
+      setRecoveryCodes(null)
+      toast.success("两步验证已关闭")
+    }, "关闭失败")
+  }
+
+  const handleThreshold = () => {
+    if (threshold && !/^\d+(\.\d{1,2})?$/.test(threshold)) {
+      toast.error("阈值格式不正确，最多2位小数")
+      return
+    }
+    run(async () => {
+      await UserService.updateTOTPThreshold(threshold || null, code)
+      toast.success("验证阈值已更新")
+    }, "更新失败")
+  }
+
+  const handleRegenerate = () => run(async () => {
+    const result = await UserService.regenerateRecoveryCodes(code)
+    setRecoveryCodes(result.recovery_codes)
+  }, "生成恢复码失败")
+
+  const copyText = async (text: string) => {
+    try {
+      await navigator.clipboard.writeText(text)
+      toast.success("已复制")
+    } catch {
+      toast.error("复制失败")
+    }
+  }
+
+  if (!status) return null
+
+  return (
+    <div className="space-y-4 max-w-md">
+      <div className="font-medium text-sm text-muted-foreground">两步验证</div>
+
+      {recoveryCodes && (
+        <div className="border border-dashed border-amber-500/50 rounded-lg px-3 py-2 space-y-2">
+          <p className="text-xs text-amber-600">恢复码仅展示一次，每个只能使用一次，请妥善保存</p>
+          <div className="grid grid-cols-2 gap-1">
+            {recoveryCodes.map(item => (
+              <code key={item} className="text-xs font-mono text-muted-foreground">{item}</code>
+            ))}
+          </div>
+          <div className="flex gap-2">
+            <Button variant="ghost" className="h-6 px-2 text-xs" onClick={() => copyText(recoveryCodes.join("\n"))}>
+              <Copy className="size-3 mr-1" />
+              复制
+            </Button>
+            <Button variant="ghost" className="h-6 px-2 text-xs" onClick={() => setRecoveryCodes(null)}>
+              我已保存
+            </Button>
+          </div>
+        </div>
+      )}
+
+      {!status.enabled && !enrollment && (
+        <div className="space-y-2">
+          <p className="text-xs text-muted-foreground">
+            启用后单笔支付超过 {status.default_threshold} LDC 时需要输入验证器应用中的验证码
+          </p>
+          <div className="flex gap-2">
+            <Input
+              type="password"
+              placeholder="6位安全密码"
+              maxLength={6}
+              value={payKey}
+              onChange={(e) => setPayKey(e.target.value)}
+              disabled={loading}
+              className="h-9"
+            />
+            <Button size="sm" onClick={handleEnroll} disabled={loading}>生成密钥</Button>
+          </div>
+        </div>
+      )}
+
+      {!status.enabled && enrollment && (
+        <div className="space-y-2">
+          <p className="text-xs text-muted-foreground">
+            在验证器应用中添加以下密钥（或<a href={enrollment.uri} className="text-primary underline">直接打开</a>），然后输入生成的6位验证码
+          </p>
+          <div className="flex items-center p-2 h-8 border border-dashed rounded-sm">
+            <code className="text-xs text-muted-foreground font-mono flex-1 overflow-x-auto p-1">{enrollment.secret}</code>
+            <Button variant="ghost" className="size-6 p-1" onClick={() => copyText(enrollment.secret)}>
+              <Copy className="size-3 text-muted-foreground" />
+            </Button>
+          </div>
+          <div className="flex gap-2">
+            <Input
+              placeholder="6位验证码"
+              maxLength={6}
+              value={code}
+              onChange={(e) => setCode(e.target.value)}
+              disabled={loading}
+              className="h-9 font-mono"
+            />
+            <Button size="sm" onClick={handleEnable} disabled={loading || code.length !== 6}>启用</Button>
+          </div>
+        </div>
+      )}
+
+      {status.enabled && (
+        <div className="space-y-3">
+          <p className="text-xs text-muted-foreground">
+            已启用，单笔支付超过 {status.threshold ?? status.default_threshold} LDC 时需要验证码，剩余恢复码 {status.recovery_codes_remaining} 个
+          </p>
+
+          <div className="space-y-2">
+            <Label htmlFor="totp-code" className="text-xs text-muted-foreground">验证码</Label>
+            <Input
+              id="totp-code"
+              placeholder="6位验证码或恢复码，以下操作均需验证"
+              maxLength={16}
+              value={code}
+              onChange={(e) => setCode(e.target.value)}
+              disabled={loading}
+              className="h-9 font-mono"
+            />
+          </div>
+
+          <div className="space-y-2">
+            <Label htmlFor="totp-threshold" className="text-xs text-muted-foreground">验证阈值</Label>
+            <div className="flex gap-2">
+              <Input
+                id="totp-threshold"
+                placeholder={`留空使用默认值 ${ status.default_threshold }`}
+                value={threshold}
+                onChange={(e) => setThreshold(e.target.value)}
+                disabled={loading}
+                className="h-9"
+              />
+              <Button size="sm" variant="secondary" onClick={handleThreshold} disabled={loading || !code}>保存</Button>
+            </div>
+          </div>
+
+          <div className="flex flex-wrap gap-2">
+            <Button size="sm" variant="secondary" onClick={handleRegenerate} disabled={loading || !code}>
+              重新生成恢复码
+            </Button>
+          </div>
+
+          <div className="flex gap-2">
+            <Input
+              type="password"
+              placeholder="6位安全密码"
+              maxLength={6}
+              value={payKey}
+              onChange={(e) => setPayKey(e.target.value)}
+              disabled={loading}
+              className="h-9"
+            />
+            <Button size="sm" variant="destructive" onClick={handleDisable} disabled={loading || !code}>
+              关闭两步验证
+            </Button>
+          </div>
+        </div>
+      )}
+    </div>
+  )
+}
@@ -2,7 +2,7 @@
 
 import { createContext, useContext, useState, useRef, useCallback, useEffect } from "react"
 
-import services, { type MerchantAPIKey, type UpdateAPIKeyRequest } from "@/lib/services"
+import services, { type MerchantAPIKey, type CreateAPIKeyResponse, type UpdateAPIKeyRequest } from "@/lib/services"
 import { handleContextError } from "@/lib/utils/error-handling"
 
 
@@ -21,7 +21,7 @@ interface MerchantContextType extends MerchantDataState {
     app_homepage_url: string
     redirect_uri?: string
     notify_url: string
-  }) => Promise<MerchantAPIKey>
+  }) => Promise<CreateAPIKeyResponse>
   updateAPIKey: (id: string, data: UpdateAPIKeyRequest) => Promise<void>
   deleteAPIKey: (id: string) => Promise<void>
   refresh: () => Promise<void>
@@ -91,7 +91,7 @@ export function MerchantProvider({ children }: { children: React.ReactNode }) {
     app_homepage_url: string
     redirect_uri?: string
     notify_url: string
-  }): Promise<MerchantAPIKey> => {
+  }): Promise<CreateAPIKeyResponse> => {
     const newKey = await services.merchant.createAPIKey(data)
 
     if (!isMountedRef.current) return newKey
@@ -16,7 +16,7 @@ interface UserState {
 /** 用户上下文接口 */
 interface UserContextValue extends UserState {
   refetch: () => Promise<void>
-  updatePayKey: (payKey: string) => Promise<void>
+  updatePayKey: (payKey: string, oldPayKey?: string) => Promise<void>
   getTrustLevelLabel: (trustLevel: TrustLevel) => string
   getPayLevelLabel: (payLevel: PayLevel) => string
   logout: () => Promise<void>
@@ -101,8 +101,8 @@ export function UserProvider({ children }: { children: ReactNode }) {
   }, [fetchUser])
 
   /** 更新支付密码 */
-  const updatePayKey = useCallback(async (payKey: string) => {
-    await services.user.updatePayKey(payKey)
+  const updatePayKey = useCallback(async (payKey: string, oldPayKey?: string) => {
+    await services.user.updatePayKey(payKey, oldPayKey)
     await fetchUser()
   }, [fetchUser])
 
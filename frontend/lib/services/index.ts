@@ -133,7 +133,21 @@ export type {
 export { MerchantService } from './merchant';
 export type {
   MerchantAPIKey,
+  MerchantAPIKeyDetail,
   CreateAPIKeyRequest,
+  CreateAPIKeyResponse,
+  SigningKeyResponse,
+  APISecretScope,
+  MerchantAPISecret,
+  MerchantAPISecretWithPlaintext,
+  CreateAPISecretRequest,
+  RotateAPISecretRequest,
+  MerchantRole,
+  MerchantMember,
+  InviteMemberRequest,
+  MerchantAuditLog,
+  ListAuditLogsRequest,
+  ListAuditLogsResponse,
   UpdateAPIKeyRequest,
   PayMerchantOrderRequest,
   GetMerchantOrderRequest,
@@ -145,6 +159,14 @@ export type {
   RefundMerchantOrderRequest,
   RefundMerchantOrderResponse,
   GetPaymentLinkInfoResponse,
+  SubscriptionPlan,
+  SubscriptionPlanRequest,
+  SubscribeRequest,
+  Subscription,
+  GiftCodeBatch,
+  GiftCode,
+  GiftCodeRedemption,
+  CreateGiftCodeBatchRequest,
 } from './merchant';
 
 // 管理员服务
@@ -166,7 +188,7 @@ export type {
 
 // 用户服务
 export { UserService } from './user';
-export type { UpdatePayKeyRequest } from './user';
+export type { UpdatePayKeyRequest, TOTPStatus, EnrollTOTPResponse, RecoveryCodesResponse, UserSession, AccessTokenScope, UserAccessToken, CreateAccessTokenRequest, UserAccessTokenWithPlaintext } from './user';
 
 // 仪表板服务
 export { DashboardService } from './dashboard';
@@ -28,7 +28,21 @@
 export { MerchantService } from './merchant.service';
 export type {
   MerchantAPIKey,
+  MerchantAPIKeyDetail,
   CreateAPIKeyRequest,
+  CreateAPIKeyResponse,
+  SigningKeyResponse,
+  APISecretScope,
+  MerchantAPISecret,
+  MerchantAPISecretWithPlaintext,
+  CreateAPISecretRequest,
+  RotateAPISecretRequest,
+  MerchantRole,
+  MerchantMember,
+  InviteMemberRequest,
+  MerchantAuditLog,
+  ListAuditLogsRequest,
+  ListAuditLogsResponse,
   UpdateAPIKeyRequest,
   PayMerchantOrderRequest,
   GetMerchantOrderRequest,
@@ -37,6 +51,14 @@ export type {
   CreatePaymentLinkRequest,
   PayByLinkRequest,
   GetPaymentLinkInfoResponse,
+  SubscriptionPlan,
+  SubscriptionPlanRequest,
+  SubscribeRequest,
+  Subscription,
+  GiftCodeBatch,
+  GiftCode,
+  GiftCodeRedemption,
+  CreateGiftCodeBatchRequest,
   QueryMerchantOrderRequest,
   QueryMerchantOrderResponse,
   RefundMerchantOrderRequest,
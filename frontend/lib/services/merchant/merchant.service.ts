@@ -1,7 +1,19 @@
 import { BaseService } from '../core/base.service';
 import type {
   MerchantAPIKey,
+  MerchantAPIKeyDetail,
   CreateAPIKeyRequest,
+  CreateAPIKeyResponse,
+  SigningKeyResponse,
+  MerchantAPISecret,
+  MerchantAPISecretWithPlaintext,
+  CreateAPISecretRequest,
+  RotateAPISecretRequest,
+  MerchantRole,
+  MerchantMember,
+  InviteMemberRequest,
+  ListAuditLogsRequest,
+  ListAuditLogsResponse,
   UpdateAPIKeyRequest,
   PayMerchantOrderRequest,
   GetMerchantOrderRequest,
@@ -16,6 +28,20 @@ import type {
   RefundMerchantOrderResponse,
   MerchantDistributeRequest,
   MerchantDistributeResponse,
+  ListWebhookDeliveriesRequest,
+  ListWebhookDeliveriesResponse,
+  SubscriptionPlan,
+  SubscriptionPlanRequest,
+  SubscribeRequest,
+  Subscription,
+  ListSubscriptionsRequest,
+  ListSubscriptionsResponse,
+  CreateGiftCodeBatchRequest,
+  CreateGiftCodeBatchResponse,
+  ListGiftCodeBatchesRequest,
+  ListGiftCodeBatchesResponse,
+  ListGiftCodesResponse,
+  ListGiftCodeRedemptionsResponse,
 } from './types';
 
 /**
@@ -30,7 +56,7 @@ export class MerchantService extends BaseService {
   /**
    * 创建商户 API Key
    * @param request - 创建 API Key 的请求参数
-   * @returns 创建的 API Key 信息（包含 client_secret）
+   * @returns 创建的 API Key 信息（包含签名密钥 client_secret 与仅返回一次的默认接口密钥 api_secret）
    * @throws {UnauthorizedError} 当未登录时
    * @throws {ValidationError} 当参数验证失败时
    * 
@@ -43,11 +69,12 @@ export class MerchantService extends BaseService {
    *   redirect_uri: 'https://example.com/callback'
    * });
    * console.log('Client ID:', apiKey.client_id);
-   * console.log('Client Secret:', apiKey.client_secret); // 请保存，之后无法再次获取
+   * console.log('Client Secret:', apiKey.client_secret); // 签名密钥，之后可通过签名密钥接口查看
+   * console.log('API Secret:', apiKey.api_secret); // 默认接口密钥，请保存，之后无法再次获取
    * ```
    */
-  static async createAPIKey(request: CreateAPIKeyRequest): Promise<MerchantAPIKey> {
-    return this.post<MerchantAPIKey>('/api-keys', request);
+  static async createAPIKey(request: CreateAPIKeyRequest): Promise<CreateAPIKeyResponse> {
+    return this.post<CreateAPIKeyResponse>('/api-keys', request);
   }
 
   /**
@@ -79,8 +106,219 @@ export class MerchantService extends BaseService {
    * console.log('应用名称:', apiKey.app_name);
    * ```
    */
-  static async getAPIKey(id: string): Promise<MerchantAPIKey> {
-    return this.get<MerchantAPIKey>(`/api-keys/${ id }`);
+  static async getAPIKey(id: string): Promise<MerchantAPIKeyDetail> {
+    return this.get<MerchantAPIKeyDetail>(`/api-keys/${ id }`);
+  }
+
+  /**
+   * 获取应用签名密钥
+   * @param id - API Key ID
+   * @returns 签名密钥
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当 API Key 不存在时
+   * 
+   * @example
+   * ```typescript
+   * const { client_secret } = await MerchantService.getSigningKey('123');
+   * ```
+   */
+  static async getSigningKey(id: string): Promise<SigningKeyResponse> {
+    return this.get<SigningKeyResponse>(`/api-keys/${ id }/signing-key`);
+  }
+
+  /**
+   * 轮换签名密钥，回调立即使用新密钥签名，旧密钥在重叠期内仍可用于请求签名
+   * @param id - API Key ID
+   * @param request - 重叠时长
+   * @returns 新签名密钥
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当 API Key 不存在时
+   */
+  static async rotateSigningKey(id: string, request: RotateAPISecretRequest): Promise<SigningKeyResponse> {
+    return this.post<SigningKeyResponse>(`/api-keys/${ id }/signing-key/rotate`, request);
+  }
+
+  /**
+   * 吊销重叠期内的旧签名密钥，立即失效
+   * @param id - API Key ID
+   * @returns void
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {ValidationError} 当没有仍可用的旧签名密钥时
+   */
+  static async revokePreviousSigningKey(id: string): Promise<void> {
+    return this.post<void>(`/api-keys/${ id }/signing-key/revoke-previous`);
+  }
+
+  // ==================== 成员与审计 ====================
+
+  /**
+   * 获取应用成员列表（含待接受的邀请）
+   * @param apiKeyId - API Key ID
+   * @returns 成员列表
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当 API Key 不存在或当前用户不是成员时
+   */
+  static async listMembers(apiKeyId: string): Promise<MerchantMember[]> {
+    return this.get<MerchantMember[]>(`/api-keys/${ apiKeyId }/members`);
+  }
+
+  /**
+   * 邀请用户加入应用（仅创建者），对方接受后生效
+   * @param apiKeyId - API Key ID
+   * @param request - 被邀请用户与角色
+   * @returns 邀请记录
+   * @throws {ForbiddenError} 当当前角色无权邀请时
+   * @throws {NotFoundError} 当被邀请用户不存在时
+   * @throws {ValidationError} 当用户已是成员或已被邀请时
+   * 
+   * @example
+   * ```typescript
+   * await MerchantService.inviteMember('123', {
+   *   user_id: '456',
+   *   username: 'alice',
+   *   role: 'support'
+   * });
+   * ```
+   */
+  static async inviteMember(apiKeyId: string, request: InviteMemberRequest): Promise<MerchantMember> {
+    return this.post<MerchantMember>(`/api-keys/${ apiKeyId }/members`, request);
+  }
+
+  /**
+   * 修改成员角色（仅创建者）
+   * @param apiKeyId - API Key ID
+   * @param memberId - 成员 ID
+   * @param role - 新角色
+   * @returns void
+   * @throws {ForbiddenError} 当当前角色无权修改时
+   * @throws {ValidationError} 当目标为创建者时
+   */
+  static async updateMember(
+    apiKeyId: string,
+    memberId: string,
+    role: Exclude<MerchantRole, 'owner'>,
+  ): Promise<void> {
+    return this.put<void>(`/api-keys/${ apiKeyId }/members/${ memberId }`, { role });
+  }
+
+  /**
+   * 移除成员或撤回邀请，非创建者只能移除自己（退出应用）
+   * @param apiKeyId - API Key ID
+   * @param memberId - 成员 ID
+   * @returns void
+   * @throws {ForbiddenError} 当无权移除该成员时
+   * @throws {ValidationError} 当目标为创建者时
+   */
+  static async removeMember(apiKeyId: string, memberId: string): Promise<void> {
+    return this.delete<void>(`/api-keys/${ apiKeyId }/members/${ memberId }`);
+  }
+
+  /**
+   * 获取应用操作审计记录（仅创建者）
+   * @param apiKeyId - API Key ID
+   * @param params - 分页与筛选参数
+   * @returns 审计记录列表
+   * @throws {ForbiddenError} 当当前角色无权查看时
+   */
+  static async listAuditLogs(apiKeyId: string, params: ListAuditLogsRequest): Promise<ListAuditLogsResponse> {
+    return this.get<ListAuditLogsResponse>(`/api-keys/${ apiKeyId }/audit-logs`, { ...params });
+  }
+
+  /**
+   * 获取当前用户待接受的应用邀请
+   * @returns 邀请列表
+   * @throws {UnauthorizedError} 当未登录时
+   */
+  static async listInvitations(): Promise<MerchantMember[]> {
+    return this.get<MerchantMember[]>('/invitations');
+  }
+
+  /**
+   * 接受应用邀请
+   * @param memberId - 邀请 ID
+   * @returns void
+   * @throws {NotFoundError} 当邀请不存在或已处理时
+   */
+  static async acceptInvitation(memberId: string): Promise<void> {
+    return this.post<void>(`/invitations/${ memberId }/accept`);
+  }
+
+  /**
+   * 拒绝应用邀请
+   * @param memberId - 邀请 ID
+   * @returns void
+   * @throws {NotFoundError} 当邀请不存在或已处理时
+   */
+  static async declineInvitation(memberId: string): Promise<void> {
+    return this.post<void>(`/invitations/${ memberId }/decline`);
+  }
+
+  // ==================== 接口密钥 ====================
+
+  /**
+   * 获取应用的接口密钥列表
+   * @param apiKeyId - API Key ID
+   * @returns 接口密钥列表（不含明文）
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当 API Key 不存在时
+   */
+  static async listAPISecrets(apiKeyId: string): Promise<MerchantAPISecret[]> {
+    return this.get<MerchantAPISecret[]>(`/api-keys/${ apiKeyId }/secrets`);
+  }
+
+  /**
+   * 创建接口密钥
+   * @param apiKeyId - API Key ID
+   * @param request - 密钥名称与权限
+   * @returns 新密钥，明文仅返回一次
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {ValidationError} 当参数验证失败或有效密钥数量已达上限时
+   * 
+   * @example
+   * ```typescript
+   * const secret = await MerchantService.createAPISecret('123', {
+   *   name: '对账服务',
+   *   scopes: ['query']
+   * });
+   * console.log('Secret:', secret.secret); // 请保存，之后无法再次获取
+   * ```
+   */
+  static async createAPISecret(
+    apiKeyId: string,
+    request: CreateAPISecretRequest,
+  ): Promise<MerchantAPISecretWithPlaintext> {
+    return this.post<MerchantAPISecretWithPlaintext>(`/api-keys/${ apiKeyId }/secrets`, request);
+  }
+
+  /**
+   * 轮换接口密钥，重叠期内新旧密钥同时可用
+   * @param apiKeyId - API Key ID
+   * @param secretId - 密钥 ID
+   * @param request - 重叠时长
+   * @returns 新密钥，明文仅返回一次
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当密钥不存在时
+   * @throws {ValidationError} 当密钥已吊销或已过期时
+   */
+  static async rotateAPISecret(
+    apiKeyId: string,
+    secretId: string,
+    request: RotateAPISecretRequest,
+  ): Promise<MerchantAPISecretWithPlaintext> {
+    return this.post<MerchantAPISecretWithPlaintext>(`/api-keys/${ apiKeyId }/secrets/${ secretId }/rotate`, request);
+  }
+
+  /**
+   * 吊销接口密钥，立即失效
+   * @param apiKeyId - API Key ID
+   * @param secretId - 密钥 ID
+   * @returns void
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当密钥不存在时
+   * @throws {ValidationError} 当密钥已吊销或已过期时
+   */
+  static async revokeAPISecret(apiKeyId: string, secretId: string): Promise<void> {
+    return this.post<void>(`/api-keys/${ apiKeyId }/secrets/${ secretId }/revoke`);
   }
 
   /**
@@ -216,6 +454,232 @@ export class MerchantService extends BaseService {
     return this.put<void>(`/api-keys/${ apiKeyId }/payment-links/${ linkId }`, request);
   }
 
+  // ==================== 订阅计划管理 ====================
+
+  /**
+   * 创建订阅计划
+   * @param apiKeyId - API Key ID
+   * @param request - 创建订阅计划请求参数
+   * @returns 创建的订阅计划信息
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当 API Key 不存在时
+   *
+   * @example
+   * ```typescript
+   * const plan = await MerchantService.createSubscriptionPlan('123', {
+   *   name: '月度会员',
+   *   amount: 30,
+   *   interval: 'month'
+   * });
+   * ```
+   */
+  static async createSubscriptionPlan(apiKeyId: string, request: SubscriptionPlanRequest): Promise<SubscriptionPlan> {
+    return this.post<SubscriptionPlan>(`/api-keys/${ apiKeyId }/subscription-plans`, request);
+  }
+
+  /**
+   * 获取订阅计划列表
+   * @param apiKeyId - API Key ID
+   * @returns 订阅计划列表
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当 API Key 不存在时
+   */
+  static async listSubscriptionPlans(apiKeyId: string): Promise<SubscriptionPlan[]> {
+    return this.get<SubscriptionPlan[]>(`/api-keys/${ apiKeyId }/subscription-plans`);
+  }
+
+  /**
+   * 更新订阅计划
+   * @param apiKeyId - API Key ID
+   * @param planId - 订阅计划 ID
+   * @param request - 更新订阅计划请求参数
+   * @returns void
+   * @throws {NotFoundError} 当 API Key 或订阅计划不存在时
+   *
+   * @remarks
+   * - 已订阅的用户仍按订阅时的金额与周期扣款
+   */
+  static async updateSubscriptionPlan(
+    apiKeyId: string,
+    planId: string,
+    request: SubscriptionPlanRequest
+  ): Promise<void> {
+    return this.put<void>(`/api-keys/${ apiKeyId }/subscription-plans/${ planId }`, request);
+  }
+
+  /**
+   * 删除订阅计划
+   * @param apiKeyId - API Key ID
+   * @param planId - 订阅计划 ID
+   * @returns void
+   * @throws {NotFoundError} 当 API Key 或订阅计划不存在时
+   *
+   * @remarks
+   * - 删除后不可再订阅，已有订阅继续按期扣款
+   */
+  static async deleteSubscriptionPlan(apiKeyId: string, planId: string): Promise<void> {
+    return this.delete<void>(`/api-keys/${ apiKeyId }/subscription-plans/${ planId }`);
+  }
+
+  /**
+   * 获取应用的订阅用户列表
+   * @param apiKeyId - API Key ID
+   * @param request - 查询参数
+   * @returns 订阅列表
+   * @throws {NotFoundError} 当 API Key 不存在时
+   */
+  static async listSubscriptions(
+    apiKeyId: string,
+    request: ListSubscriptionsRequest
+  ): Promise<ListSubscriptionsResponse> {
+    return this.get<ListSubscriptionsResponse>(`/api-keys/${ apiKeyId }/subscriptions`, { ...request });
+  }
+
+  /**
+   * 商户取消用户订阅
+   * @param apiKeyId - API Key ID
+   * @param subscriptionId - 订阅 ID
+   * @returns 取消后的订阅信息
+   * @throws {NotFoundError} 当订阅不存在或已取消时
+   */
+  static async cancelSubscription(apiKeyId: string, subscriptionId: string): Promise<Subscription> {
+    return this.post<Subscription>(`/api-keys/${ apiKeyId }/subscriptions/${ subscriptionId }/cancel`);
+  }
+
+  /**
+   * 通过 Token 获取订阅计划信息
+   * @param token - 订阅计划 Token
+   * @returns 订阅计划信息
+   * @throws {NotFoundError} 当订阅计划不存在时
+   */
+  static async getSubscriptionPlanByToken(token: string): Promise<SubscriptionPlan> {
+    return this.get<SubscriptionPlan>(`/subscription-plans/${ token }`);
+  }
+
+  /**
+   * 确认订阅
+   *
+   * @description
+   * 用户授权商户按期自动扣款，并立即支付首期。
+   *
+   * @param request - 订阅请求参数（token 和 pay_key）
+   * @returns 订阅信息
+   * @throws {NotFoundError} 当订阅计划不存在时
+   * @throws {ApiErrorBase} 当余额不足、支付密码错误或已订阅时
+   */
+  static async subscribe(request: SubscribeRequest): Promise<Subscription> {
+    return this.post<Subscription>('/subscription-plans/subscribe', request);
+  }
+
+  // ==================== 礼品码 ====================
+
+  /**
+   * 创建礼品码批次
+   *
+   * @description
+   * 按最大可兑换总额（每次兑换金额 × 礼品码数量 × 每码可兑换次数）从商户余额预先扣除，
+   * 过期后未兑换的金额自动退回。
+   *
+   * @param apiKeyId - API Key ID
+   * @param request - 创建参数
+   * @returns 批次信息与生成的礼品码
+   * @throws {ApiErrorBase} 当余额不足或支付密码错误时
+   */
+  static async createGiftCodeBatch(
+    apiKeyId: string,
+    request: CreateGiftCodeBatchRequest
+  ): Promise<CreateGiftCodeBatchResponse> {
+    return this.post<CreateGiftCodeBatchResponse>(`/api-keys/${ apiKeyId }/gift-code-batches`, request);
+  }
+
+  /**
+   * 获取礼品码批次列表
+   * @param apiKeyId - API Key ID
+   * @param request - 分页参数
+   * @returns 批次列表
+   */
+  static async listGiftCodeBatches(
+    apiKeyId: string,
+    request: ListGiftCodeBatchesRequest
+  ): Promise<ListGiftCodeBatchesResponse> {
+    return this.get<ListGiftCodeBatchesResponse>(`/api-keys/${ apiKeyId }/gift-code-batches`, { ...request });
+  }
+
+  /**
+   * 获取批次下的礼品码及兑换状态
+   * @param apiKeyId - API Key ID
+   * @param batchId - 批次 ID
+   * @param request - 分页参数
+   * @returns 礼品码列表
+   * @throws {NotFoundError} 当批次不存在时
+   */
+  static async listGiftCodes(
+    apiKeyId: string,
+    batchId: string,
+    request: ListGiftCodeBatchesRequest
+  ): Promise<ListGiftCodesResponse> {
+    return this.get<ListGiftCodesResponse>(`/api-keys/${ apiKeyId }/gift-code-batches/${ batchId }/codes`, { ...request });
+  }
+
+  /**
+   * 获取批次下的兑换记录
+   * @param apiKeyId - API Key ID
+   * @param batchId - 批次 ID
+   * @param request - 分页参数
+   * @returns 兑换记录列表
+   * @throws {NotFoundError} 当批次不存在时
+   */
+  static async listGiftCodeRedemptions(
+    apiKeyId: string,
+    batchId: string,
+    request: ListGiftCodeBatchesRequest
+  ): Promise<ListGiftCodeRedemptionsResponse> {
+    return this.get<ListGiftCodeRedemptionsResponse>(
+      `/api-keys/${ apiKeyId }/gift-code-batches/${ batchId }/redemptions`,
+      { ...request }
+    );
+  }
+
+  // ==================== 回调投递记录 ====================
+
+  /**
+   * 获取回调投递记录
+   * @param apiKeyId - API Key ID
+   * @param request - 查询参数
+   * @returns 投递记录列表
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当 API Key 不存在时
+   *
+   * @example
+   * ```typescript
+   * const { deliveries } = await MerchantService.listWebhookDeliveries('123', { page: 1, page_size: 20 });
+   * ```
+   */
+  static async listWebhookDeliveries(
+    apiKeyId: string,
+    request: ListWebhookDeliveriesRequest
+  ): Promise<ListWebhookDeliveriesResponse> {
+    return this.get<ListWebhookDeliveriesResponse>(`/api-keys/${ apiKeyId }/webhooks`, { ...request });
+  }
+
+  /**
+   * 重新投递回调
+   * @param apiKeyId - API Key ID
+   * @param deliveryId - 投递记录 ID
+   * @returns void
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当投递记录不存在时
+   * @throws {ValidationError} 当订单状态不支持重新投递时
+   *
+   * @example
+   * ```typescript
+   * await MerchantService.redeliverWebhook('123', '456');
+   * ```
+   */
+  static async redeliverWebhook(apiKeyId: string, deliveryId: string): Promise<void> {
+    return this.post<void>(`/api-keys/${ apiKeyId }/webhooks/${ deliveryId }/redeliver`);
+  }
+
 
   /**
    * 通过 Token 获取支付链接信息
@@ -1,3 +1,36 @@
+/**
+ * 签名方式
+ */
+export type SignType = 'MD5' | 'HMAC-SHA256';
+
+/**
+ * 事件回调类型
+ */
+export type WebhookEvent =
+  | 'payment.succeeded'
+  | 'payment.refunded'
+  | 'payment.authorized'
+  | 'payment.voided'
+  | 'dispute.opened'
+  | 'dispute.resolved'
+  | 'order.expired'
+  | 'order.cancelled'
+  | 'distribute.succeeded'
+  | 'distribute.batch_completed'
+  | 'subscription.created'
+  | 'subscription.renewed'
+  | 'subscription.payment_failed'
+  | 'subscription.cancelled';
+
+/**
+ * 应用成员角色
+ * - owner: 创建者，拥有全部权限
+ * - developer: 管理应用配置、密钥与回调
+ * - support: 查看订单并处理退款争议
+ * - finance: 查看订单并执行退款与分发
+ */
+export type MerchantRole = 'owner' | 'developer' | 'support' | 'finance';
+
 /**
  * 商户 API Key 信息
  */
@@ -8,8 +41,6 @@ export interface MerchantAPIKey {
   user_id: string;
   /** 客户端 ID */
   client_id: string;
-  /** 客户端密钥 */
-  client_secret: string;
   /** 应用名称 */
   app_name: string;
   /** 应用主页 URL */
@@ -22,18 +53,231 @@ export interface MerchantAPIKey {
   notify_url: string;
   /** 测试模式 */
   test_mode: boolean;
+  /** 签名方式 */
+  sign_type: SignType;
+  /** 是否允许 MD5 签名不携带 timestamp 与 nonce（旧版签名兼容） */
+  legacy_sign_allowed: boolean;
+  /** 签名密钥轮换后旧签名密钥的失效时间 */
+  previous_signing_key_expires_at?: string | null;
+  /** 默认订单过期时间（分钟，0 表示使用系统默认值） */
+  expire_minutes: number;
+  /** 事件回调 URL（为空时使用通知 URL） */
+  webhook_url: string;
+  /** 订阅的事件 */
+  webhook_events: WebhookEvent[];
+  /** 单笔分发金额上限（0 表示不限制） */
+  distribute_max_amount: string;
+  /** 每日分发总额上限（0 表示不限制） */
+  distribute_daily_limit: string;
+  /** 每月分发总额上限（0 表示不限制） */
+  distribute_monthly_limit: string;
+  /** 单个收款人每日分发上限（0 表示不限制） */
+  distribute_recipient_daily_limit: string;
+  /** 分发来源 IP 白名单（IP 或 CIDR，为空表示不限制） */
+  distribute_allowed_ips: string[];
   /** 创建时间 */
   created_at: string;
   /** 更新时间 */
   updated_at: string;
   /** 删除时间（软删除） */
   deleted_at: string | null;
+  /** 当前用户在应用中的角色（列表接口返回） */
+  role?: MerchantRole;
+}
+
+/**
+ * 应用成员
+ */
+export interface MerchantMember {
+  /** 成员 ID */
+  id: string;
+  /** API Key ID */
+  api_key_id: string;
+  /** 用户 ID */
+  user_id: number;
+  /** 用户名 */
+  username: string;
+  /** 角色 */
+  role: MerchantRole;
+  /** 状态：pending 待接受，active 已加入 */
+  status: 'pending' | 'active';
+  /** 邀请人用户 ID */
+  invited_by: number;
+  /** 应用名称（邀请列表返回） */
+  app_name?: string;
+  /** 邀请人用户名（邀请列表返回） */
+  inviter_name?: string;
+  /** 创建时间 */
+  created_at: string;
+  /** 更新时间 */
+  updated_at: string;
+}
+
+/**
+ * 邀请成员请求参数
+ */
+export interface InviteMemberRequest {
+  /** 被邀请用户 ID */
+  user_id: string;
+  /** 被邀请用户名 */
+  username: string;
+  /** 角色 */
+  role: Exclude<MerchantRole, 'owner'>;
+}
+
+/**
+ * 应用操作审计记录
+ */
+export interface MerchantAuditLog {
+  /** 记录 ID */
+  id: string;
+  /** API Key ID */
+  api_key_id: string;
+  /** 操作人用户 ID */
+  user_id: number;
+  /** 操作人用户名 */
+  username: string;
+  /** 操作人角色 */
+  role: MerchantRole;
+  /** 请求方法与路由 */
+  action: string;
+  /** 路由中的资源 ID */
+  target: string;
+  /** 响应状态码 */
+  status_code: number;
+  /** 来源 IP */
+  ip: string;
+  /** 创建时间 */
+  created_at: string;
+}
+
+/**
+ * 审计记录查询参数
+ */
+export interface ListAuditLogsRequest {
+  /** 页码 */
+  page: number;
+  /** 每页数量（1-100） */
+  page_size: number;
+  /** 按操作人筛选 */
+  user_id?: number;
+}
+
+/**
+ * 审计记录列表响应
+ */
+export interface ListAuditLogsResponse {
+  /** 总数 */
+  total: number;
+  /** 记录列表 */
+  logs: MerchantAuditLog[];
+}
+
+/**
+ * 创建商户 API Key 响应
+ */
+export interface CreateAPIKeyResponse extends MerchantAPIKey {
+  /** 签名密钥，用于计算请求签名与校验回调签名 */
+  client_secret: string;
+  /** 默认接口密钥明文，用于 Basic Auth 与 key 认证，仅在创建时返回一次 */
+  api_secret: string;
+}
+
+/**
+ * 签名密钥响应
+ */
+export interface SigningKeyResponse {
+  /** 签名密钥，用于计算请求签名与校验回调签名 */
+  client_secret: string;
+  /** 轮换后旧签名密钥的失效时间，为空表示没有仍可用的旧签名密钥 */
+  previous_expires_at: string | null;
+}
+
+/**
+ * 接口密钥权限
+ */
+export type APISecretScope = 'create_order' | 'query' | 'refund' | 'distribute';
+
+/**
+ * 接口密钥
+ */
+export interface MerchantAPISecret {
+  /** 密钥 ID */
+  id: string;
+  /** API Key ID */
+  api_key_id: string;
+  /** 密钥名称 */
+  name: string;
+  /** 密钥前 8 位，用于识别 */
+  prefix: string;
+  /** 权限 */
+  scopes: APISecretScope[];
+  /** 过期时间（轮换后旧密钥的失效时间） */
+  expires_at: string | null;
+  /** 最近使用时间 */
+  last_used_at: string | null;
+  /** 吊销时间 */
+  revoked_at: string | null;
+  /** 创建时间 */
+  created_at: string;
+}
+
+/**
+ * 新建或轮换后的接口密钥，明文仅返回一次
+ */
+export interface MerchantAPISecretWithPlaintext extends MerchantAPISecret {
+  /** 密钥明文 */
+  secret: string;
+}
+
+/**
+ * 创建接口密钥请求参数
+ */
+export interface CreateAPISecretRequest {
+  /** 密钥名称（最大32字符） */
+  name: string;
+  /** 权限 */
+  scopes: APISecretScope[];
+}
+
+/**
+ * 轮换接口密钥请求参数
+ */
+export interface RotateAPISecretRequest {
+  /** 旧密钥继续可用的小时数（0-168，0 表示立即失效） */
+  overlap_hours: number;
+}
+
+/**
+ * 商户 API Key 详情（含分发用量）
+ */
+export interface MerchantAPIKeyDetail extends MerchantAPIKey {
+  /** 当日已分发金额 */
+  distributed_today: string;
+  /** 当月已分发金额 */
+  distributed_this_month: string;
+}
+
+/**
+ * 分发限额配置
+ */
+export interface DistributeLimits {
+  /** 单笔分发金额上限（0 表示不限制） */
+  distribute_max_amount?: number | string;
+  /** 每日分发总额上限（0 表示不限制） */
+  distribute_daily_limit?: number | string;
+  /** 每月分发总额上限（0 表示不限制） */
+  distribute_monthly_limit?: number | string;
+  /** 单个收款人每日分发上限（0 表示不限制） */
+  distribute_recipient_daily_limit?: number | string;
+  /** 分发来源 IP 白名单（IP 或 CIDR，最多 20 条，传空数组表示不限制） */
+  distribute_allowed_ips?: string[];
 }
 
 /**
  * 创建商户 API Key 请求参数
  */
-export interface CreateAPIKeyRequest {
+export interface CreateAPIKeyRequest extends DistributeLimits {
   /** 应用名称（最大20字符） */
   app_name: string;
   /** 应用主页 URL（最大100字符，必须是有效的 URL） */
@@ -46,12 +290,20 @@ export interface CreateAPIKeyRequest {
   notify_url: string;
   /** 测试模式（可选，默认为 false） */
   test_mode?: boolean;
+  /** 签名方式（可选，默认为 MD5） */
+  sign_type?: SignType;
+  /** 默认订单过期时间（分钟，可选，0 表示使用系统默认值） */
+  expire_minutes?: number;
+  /** 事件回调 URL（最大255字符，可选） */
+  webhook_url?: string;
+  /** 订阅的事件（可选） */
+  webhook_events?: WebhookEvent[];
 }
 
 /**
  * 更新商户 API Key 请求参数
  */
-export interface UpdateAPIKeyRequest {
+export interface UpdateAPIKeyRequest extends DistributeLimits {
   /** 应用名称（最大20字符，可选） */
   app_name?: string;
   /** 应用主页 URL（最大100字符，必须是有效的 URL，可选） */
@@ -64,6 +316,16 @@ export interface UpdateAPIKeyRequest {
   notify_url?: string;
   /** 测试模式（可选） */
   test_mode?: boolean;
+  /** 签名方式（可选） */
+  sign_type?: SignType;
+  /** 旧版签名兼容（可选），开启后 MD5 签名可不携带 timestamp 与 nonce */
+  legacy_sign_allowed?: boolean;
+  /** 默认订单过期时间（分钟，可选，0 表示使用系统默认值） */
+  expire_minutes?: number;
+  /** 事件回调 URL（可选，传空字符串清空） */
+  webhook_url?: string;
+  /** 订阅的事件（可选，传空数组取消全部订阅） */
+  webhook_events?: WebhookEvent[];
 }
 
 /**
@@ -74,6 +336,8 @@ export interface PayMerchantOrderRequest {
   order_no: string;
   /** 支付密码（6位数字） */
   pay_key: string;
+  /** 两步验证码或恢复码，金额超过两步验证阈值时必填 */
+  totp_code?: string;
 }
 
 /**
@@ -222,6 +486,8 @@ export interface PayByLinkRequest {
   token: string;
   /** 支付密码（6位数字） */
   pay_key: string;
+  /** 两步验证码或恢复码，金额超过两步验证阈值时必填 */
+  totp_code?: string;
   /** 备注（可选，最大100字符） */
   remark?: string;
 }
@@ -354,3 +620,356 @@ export interface MerchantDistributeResponse {
   /** 商户订单号 */
   out_trade_no: string;
 }
+
+/**
+ * 回调投递记录
+ */
+export interface WebhookDelivery {
+  /** 记录 ID */
+  id: string;
+  /** 商户 API Key ID */
+  api_key_id: string;
+  /** 订单 ID */
+  order_id: string;
+  /** 事件类型，为空表示异步通知 */
+  event: WebhookEvent | '';
+  /** 回调地址 */
+  url: string;
+  /** 回调参数 */
+  params: string;
+  /** 响应状态码 */
+  status_code: number;
+  /** 响应内容（截断） */
+  response_body: string;
+  /** 错误信息 */
+  error_msg: string;
+  /** 是否投递成功 */
+  success: boolean;
+  /** 耗时（毫秒） */
+  latency_ms: number;
+  /** 重试次数 */
+  retry_count: number;
+  /** 是否为手动重新投递 */
+  manual: boolean;
+  /** 创建时间 */
+  created_at: string;
+}
+
+/**
+ * 回调投递记录查询参数
+ */
+export interface ListWebhookDeliveriesRequest {
+  /** 页码 */
+  page: number;
+  /** 每页数量 */
+  page_size: number;
+  /** 订单 ID (可选) */
+  order_id?: string;
+  /** 事件类型 (可选) */
+  event?: WebhookEvent;
+  /** 是否投递成功 (可选) */
+  success?: boolean;
+}
+
+/**
+ * 回调投递记录列表响应
+ */
+export interface ListWebhookDeliveriesResponse {
+  /** 总数 */
+  total: number;
+  /** 投递记录 */
+  deliveries: WebhookDelivery[];
+}
+
+/**
+ * 订阅扣款周期单位
+ */
+export type SubscriptionInterval = 'day' | 'week' | 'month' | 'year';
+
+/**
+ * 订阅状态
+ */
+export type SubscriptionStatus = 'active' | 'past_due' | 'cancelled';
+
+/**
+ * 订阅计划信息
+ */
+export interface SubscriptionPlan {
+  /** 计划 ID */
+  id: string;
+  /** 订阅计划 Token */
+  token: string;
+  /** 计划名称 */
+  name: string;
+  /** 每期金额 */
+  amount: string;
+  /** 扣款周期单位 */
+  interval: SubscriptionInterval;
+  /** 扣款周期数（每 interval_count 个 interval 扣款一次） */
+  interval_count: number;
+  /** 备注 */
+  remark: string;
+  /** 创建时间 */
+  created_at: string;
+  /** 应用名称 */
+  app_name: string;
+  /** 重定向 URI */
+  redirect_uri: string;
+}
+
+/**
+ * 创建/更新订阅计划请求参数
+ */
+export interface SubscriptionPlanRequest {
+  /** 计划名称 */
+  name: string;
+  /** 每期金额 */
+  amount: number | string;
+  /** 扣款周期单位 */
+  interval: SubscriptionInterval;
+  /** 扣款周期数（可选，默认 1） */
+  interval_count?: number;
+  /** 备注（可选） */
+  remark?: string;
+}
+
+/**
+ * 订阅请求参数
+ */
+export interface SubscribeRequest {
+  /** 订阅计划 Token */
+  token: string;
+  /** 支付密码（6位数字） */
+  pay_key: string;
+}
+
+/**
+ * 用户订阅信息
+ */
+export interface Subscription {
+  /** 订阅 ID */
+  id: string;
+  /** 订阅计划 ID */
+  plan_id: string;
+  /** 商户 API Key ID */
+  merchant_api_key_id: string;
+  /** 客户端 ID */
+  client_id: string;
+  /** 订阅用户 ID */
+  payer_user_id: number;
+  /** 商户用户 ID */
+  payee_user_id: number;
+  /** 计划名称 */
+  name: string;
+  /** 每期金额 */
+  amount: string;
+  /** 扣款周期单位 */
+  interval: SubscriptionInterval;
+  /** 扣款周期数 */
+  interval_count: number;
+  /** 订阅状态 */
+  status: SubscriptionStatus;
+  /** 下次扣款时间 */
+  next_billing_at: string;
+  /** 连续扣款失败次数 */
+  failed_attempts: number;
+  /** 最近一次失败或取消原因 */
+  last_error: string;
+  /** 最近一期扣款订单 ID */
+  last_order_id?: string;
+  /** 取消时间 */
+  cancelled_at?: string;
+  /** 创建时间 */
+  created_at: string;
+  /** 应用名称 */
+  app_name: string;
+}
+
+/**
+ * 订阅查询参数
+ */
+export interface ListSubscriptionsRequest {
+  /** 页码 */
+  page: number;
+  /** 每页数量 */
+  page_size: number;
+  /** 订阅状态 (可选) */
+  status?: SubscriptionStatus;
+  /** 订阅计划 ID (可选) */
+  plan_id?: string;
+}
+
+/**
+ * 订阅列表响应
+ */
+export interface ListSubscriptionsResponse {
+  /** 总数 */
+  total: number;
+  /** 订阅列表 */
+  subscriptions: Subscription[];
+}
+
+/**
+ * 礼品码批次状态
+ */
+export type GiftCodeBatchStatus = 'active' | 'expired';
+
+/**
+ * 礼品码批次信息
+ */
+export interface GiftCodeBatch {
+  /** 批次 ID */
+  id: string;
+  /** 发行方类型 */
+  issuer_type: 'admin' | 'merchant';
+  /** 发行人用户 ID */
+  issuer_user_id: number;
+  /** 商户 API Key ID */
+  merchant_api_key_id?: number;
+  /** 批次名称 */
+  name: string;
+  /** 每次兑换金额 */
+  amount: string;
+  /** 礼品码数量 */
+  code_count: number;
+  /** 每个礼品码可兑换次数 */
+  max_redemptions: number;
+  /** 每个用户在该批次内可兑换次数 */
+  per_user_limit: number;
+  /** 最大可兑换总额 */
+  total_amount: string;
+  /** 已兑换次数 */
+  redeemed_count: number;
+  /** 已兑换金额 */
+  redeemed_amount: string;
+  /** 出资订单 ID */
+  fund_order_id?: string;
+  /** 备注 */
+  remark: string;
+  /** 批次状态 */
+  status: GiftCodeBatchStatus;
+  /** 过期时间 */
+  expires_at: string;
+  /** 创建时间 */
+  created_at: string;
+  /** 更新时间 */
+  updated_at: string;
+}
+
+/**
+ * 礼品码信息
+ */
+export interface GiftCode {
+  /** 礼品码 ID */
+  id: string;
+  /** 批次 ID */
+  batch_id: string;
+  /** 礼品码 */
+  code: string;
+  /** 可兑换次数 */
+  max_redemptions: number;
+  /** 已兑换次数 */
+  redeemed_count: number;
+  /** 创建时间 */
+  created_at: string;
+  /** 更新时间 */
+  updated_at: string;
+}
+
+/**
+ * 礼品码兑换记录
+ */
+export interface GiftCodeRedemption {
+  /** 兑换记录 ID */
+  id: string;
+  /** 批次 ID */
+  batch_id: string;
+  /** 礼品码 ID */
+  gift_code_id: string;
+  /** 兑换用户 ID */
+  user_id: number;
+  /** 兑换用户名 */
+  username: string;
+  /** 礼品码 */
+  code: string;
+  /** 兑换金额 */
+  amount: string;
+  /** 兑换订单 ID */
+  order_id: string;
+  /** 兑换时间 */
+  created_at: string;
+}
+
+/**
+ * 创建礼品码批次请求参数
+ */
+export interface CreateGiftCodeBatchRequest {
+  /** 批次名称 */
+  name: string;
+  /** 每次兑换金额 */
+  amount: number | string;
+  /** 礼品码数量 */
+  code_count: number;
+  /** 每个礼品码可兑换次数（可选，默认 1） */
+  max_redemptions?: number;
+  /** 每个用户在该批次内可兑换次数（可选，默认 1） */
+  per_user_limit?: number;
+  /** 有效天数 */
+  expire_days: number;
+  /** 备注（可选） */
+  remark?: string;
+  /** 支付密码（6位数字） */
+  pay_key: string;
+  /** 两步验证码或恢复码，最大可兑换总额超过两步验证阈值时必填 */
+  totp_code?: string;
+}
+
+/**
+ * 创建礼品码批次响应
+ */
+export interface CreateGiftCodeBatchResponse {
+  /** 批次信息 */
+  batch: GiftCodeBatch;
+  /** 生成的礼品码 */
+  codes: GiftCode[];
+}
+
+/**
+ * 礼品码分页查询参数
+ */
+export interface ListGiftCodeBatchesRequest {
+  /** 页码 */
+  page: number;
+  /** 每页数量 */
+  page_size: number;
+}
+
+/**
+ * 礼品码批次列表响应
+ */
+export interface ListGiftCodeBatchesResponse {
+  /** 总数 */
+  total: number;
+  /** 批次列表 */
+  batches: GiftCodeBatch[];
+}
+
+/**
+ * 礼品码列表响应
+ */
+export interface ListGiftCodesResponse {
+  /** 总数 */
+  total: number;
+  /** 礼品码列表 */
+  codes: GiftCode[];
+}
+
+/**
+ * 礼品码兑换记录列表响应
+ */
+export interface ListGiftCodeRedemptionsResponse {
+  /** 总数 */
+  total: number;
+  /** 兑换记录列表 */
+  redemptions: GiftCodeRedemption[];
+}
@@ -1,12 +1,12 @@
 /**
  * 订单类型
  */
-export type OrderType = 'receive' | 'payment' | 'transfer' | 'community' | 'online' | 'test' | 'distribute';
+export type OrderType = 'receive' | 'payment' | 'transfer' | 'community' | 'online' | 'test' | 'distribute' | 'refund' | 'escrow' | 'red_envelope' | 'gift_code';
 
 /**
  * 订单状态
  */
-export type OrderStatus = 'success' | 'pending' | 'failed' | 'expired' | 'disputing' | 'refund' | 'refused';
+export type OrderStatus = 'success' | 'pending' | 'failed' | 'expired' | 'disputing' | 'refund' | 'refused' | 'partially_refunded' | 'cancelled' | 'authorized';
 
 /**
  * 订单信息
@@ -34,6 +34,12 @@ export interface Order {
   payee_avatar_url?: string;
   /** 交易金额（decimal字符串） */
   amount: string;
+  /** 手续费（decimal字符串） */
+  fee: string;
+  /** 已退款金额（decimal字符串） */
+  refunded_amount: string;
+  /** 退款子订单关联的原订单 ID（可选） */
+  parent_order_id?: string;
   /** 订单状态 */
   status: OrderStatus;
   /** 订单类型 */
@@ -118,6 +124,8 @@ export interface TransferRequest {
   amount: number | string;
   /** 支付密码（6-10位） */
   pay_key: string;
+  /** 两步验证码或恢复码，金额超过两步验证阈值时必填 */
+  totp_code?: string;
   /** 备注（可选，最大200字符） */
   remark?: string;
 }
@@ -4,6 +4,8 @@
  * @description
  * 提供用户个人设置相关的功能，包括：
  * - 更新支付密钥
+ * - 两步验证（TOTP）的启用、关闭与恢复码管理
+ * - 登录会话的查看与吊销
  * 
  * @example
  * ```typescript
@@ -15,4 +17,4 @@
  */
 
 export { UserService } from './user.service';
-export type { UpdatePayKeyRequest } from './types';
+export type { UpdatePayKeyRequest, TOTPStatus, EnrollTOTPResponse, RecoveryCodesResponse, UserSession, AccessTokenScope, UserAccessToken, CreateAccessTokenRequest, UserAccessTokenWithPlaintext } from './types';
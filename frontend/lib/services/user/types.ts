@@ -4,5 +4,114 @@
 export interface UpdatePayKeyRequest {
   /** 新的支付密钥（6位数字） */
   pay_key: string;
+  /** 原支付密钥，已设置过支付密钥时必填 */
+  old_pay_key?: string;
 }
 
+
+/**
+ * 两步验证状态
+ */
+export interface TOTPStatus {
+  /** 是否已启用 */
+  enabled: boolean;
+  /** 用户自定义的支付验证阈值，为空时使用系统默认值 */
+  threshold: string | null;
+  /** 系统默认的支付验证阈值 */
+  default_threshold: string;
+  /** 剩余可用的恢复码数量 */
+  recovery_codes_remaining: number;
+}
+
+/**
+ * 生成两步验证密钥响应
+ */
+export interface EnrollTOTPResponse {
+  /** base32 编码的密钥，可手动输入验证器 */
+  secret: string;
+  /** otpauth 链接，可生成二维码供验证器扫描 */
+  uri: string;
+}
+
+/**
+ * 恢复码响应
+ */
+export interface RecoveryCodesResponse {
+  /** 恢复码明文，仅展示一次 */
+  recovery_codes: string[];
+}
+
+/**
+ * 登录会话
+ */
+export interface UserSession {
+  /** 会话 ID */
+  id: string;
+  /** 最近一次访问的 IP */
+  ip: string;
+  /** 最近一次访问的 User-Agent */
+  user_agent: string;
+  /** 登录时间 */
+  created_at: string;
+  /** 最近活跃时间 */
+  last_seen_at: string;
+  /** 是否为当前会话 */
+  current: boolean;
+}
+
+/**
+ * 个人访问令牌权限
+ */
+export type AccessTokenScope = 'read_transactions' | 'read_balance' | 'transfer';
+
+/**
+ * 个人访问令牌
+ */
+export interface UserAccessToken {
+  /** 令牌 ID */
+  id: string;
+  /** 令牌名称 */
+  name: string;
+  /** 明文前缀，用于识别令牌 */
+  prefix: string;
+  /** 权限列表 */
+  scopes: AccessTokenScope[];
+  /** 单笔转账上限 */
+  transfer_max_amount: string;
+  /** 每日转账上限 */
+  transfer_daily_limit: string;
+  /** 过期时间，为空表示永不过期 */
+  expires_at: string | null;
+  /** 最近使用时间 */
+  last_used_at: string | null;
+  /** 吊销时间 */
+  revoked_at: string | null;
+  /** 创建时间 */
+  created_at: string;
+}
+
+/**
+ * 创建个人访问令牌请求
+ */
+export interface CreateAccessTokenRequest {
+  /** 令牌名称，最多32字 */
+  name: string;
+  /** 权限列表 */
+  scopes: AccessTokenScope[];
+  /** 单笔转账上限，拥有转账权限时必填 */
+  transfer_max_amount?: string;
+  /** 每日转账上限，拥有转账权限时必填 */
+  transfer_daily_limit?: string;
+  /** 有效天数，0 表示永不过期 */
+  expires_in_days: number;
+  /** 支付密码 */
+  pay_key: string;
+}
+
+/**
+ * 新建的个人访问令牌，明文仅返回一次
+ */
+export interface UserAccessTokenWithPlaintext extends UserAccessToken {
+  /** 令牌明文 */
+  token: string;
+}
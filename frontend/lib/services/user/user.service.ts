@@ -1,4 +1,5 @@
 import { BaseService } from '../core/base.service';
+import type { TOTPStatus, EnrollTOTPResponse, RecoveryCodesResponse, UserSession, UserAccessToken, CreateAccessTokenRequest, UserAccessTokenWithPlaintext } from './types';
 
 /**
  * 用户服务
@@ -10,6 +11,7 @@ export class UserService extends BaseService {
   /**
    * 更新用户支付密钥
    * @param payKey - 新的支付密钥
+   * @param oldPayKey - 原支付密钥，已设置过支付密钥时必填
    * @returns void
    * @throws {UnauthorizedError} 当用户未登录时
    * @throws {ValidationError} 当支付密钥格式无效时
@@ -22,9 +24,110 @@ export class UserService extends BaseService {
    * @remarks
    * - 支付密钥必须为6位数字
    * - 只能更新当前登录用户的支付密钥
+   * - 原支付密钥输错次数过多时会暂时锁定
    */
-  static async updatePayKey(payKey: string): Promise<void> {
-    return this.put<void>('/pay-key', { pay_key: payKey });
+  static async updatePayKey(payKey: string, oldPayKey?: string): Promise<void> {
+    return this.put<void>('/pay-key', { pay_key: payKey, old_pay_key: oldPayKey });
+  }
+
+  /**
+   * 查询两步验证状态
+   * @returns 两步验证状态
+   */
+  static async getTOTPStatus(): Promise<TOTPStatus> {
+    return this.get<TOTPStatus>('/totp');
+  }
+
+  /**
+   * 生成待启用的两步验证密钥
+   * @param payKey - 支付密钥
+   * @returns 密钥与 otpauth 链接
+   */
+  static async enrollTOTP(payKey: string): Promise<EnrollTOTPResponse> {
+    return this.post<EnrollTOTPResponse>('/totp/enroll', { pay_key: payKey });
+  }
+
+  /**
+   * 使用验证器生成的验证码启用两步验证
+   * @param code - 6位验证码
+   * @returns 恢复码，仅展示一次
+   */
+  static async enableTOTP(code: string): Promise<RecoveryCodesResponse> {
+    return this.post<RecoveryCodesResponse>('/totp/enable', { code });
+  }
+
+  /**
+   * 关闭两步验证
+   * @param payKey - 支付密钥
+   * @param code - 验证码或恢复码
+   */
+  static async disableTOTP(payKey: string, code: string): Promise<void> {
+    return this.post<void>('/totp/disable', { pay_key: payKey, code });
+  }
+
+  /**
+   * 更新需要验证码的单笔支付金额阈值
+   * @param threshold - 阈值，为 null 时使用系统默认值
+   * @param code - 验证码或恢复码
+   */
+  static async updateTOTPThreshold(threshold: string | null, code: string): Promise<void> {
+    return this.put<void>('/totp/threshold', { threshold, code });
+  }
+
+  /**
+   * 重新生成恢复码，原有恢复码全部作废
+   * @param code - 验证码或恢复码
+   * @returns 新的恢复码，仅展示一次
+   */
+  static async regenerateRecoveryCodes(code: string): Promise<RecoveryCodesResponse> {
+    return this.post<RecoveryCodesResponse>('/totp/recovery-codes', { code });
   }
-}
 
+  /**
+   * 列出当前用户的登录会话
+   * @returns 会话列表，按最近活跃时间倒序
+   */
+  static async listSessions(): Promise<UserSession[]> {
+    return this.get<UserSession[]>('/sessions');
+  }
+
+  /**
+   * 吊销指定会话，吊销当前会话等同于退出登录
+   * @param id - 会话 ID
+   */
+  static async revokeSession(id: string): Promise<void> {
+    return this.delete<void>(`/sessions/${ id }`);
+  }
+
+  /**
+   * 退出所有设备，包括当前会话
+   */
+  static async revokeAllSessions(): Promise<void> {
+    return this.delete<void>('/sessions');
+  }
+
+  /**
+   * 列出当前用户的个人访问令牌
+   * @returns 令牌列表，按创建时间倒序
+   */
+  static async listAccessTokens(): Promise<UserAccessToken[]> {
+    return this.get<UserAccessToken[]>('/access-tokens');
+  }
+
+  /**
+   * 创建个人访问令牌
+   * @param request - 创建参数
+   * @returns 新建的令牌，明文仅返回一次
+   */
+  static async createAccessToken(request: CreateAccessTokenRequest): Promise<UserAccessTokenWithPlaintext> {
+    return this.post<UserAccessTokenWithPlaintext>('/access-tokens', request);
+  }
+
+  /**
+   * 吊销个人访问令牌
+   * @param id - 令牌 ID
+   */
+  static async revokeAccessToken(id: string): Promise<void> {
+    return this.delete<void>(`/access-tokens/${ id }`);
+  }
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciliation
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+)
+
+// listFindingsRequest 对账差异查询请求
+type listFindingsRequest struct {
+	Page     int                       `form:"page" binding:"min=1"`
+	PageSize int                       `form:"page_size" binding:"min=1,max=100"`
+	RunID    uint64                    `form:"run_id"`
+	UserID   uint64                    `form:"user_id"`
+	Field    model.ReconciliationField `form:"field" binding:"omitempty,oneof=available_balance total_receive total_payment total_transfer total_community ledger_balance"`
+}
+
+type finding struct {
+	ID        uint64                    `json:"id,string"`
+	RunID     uint64                    `json:"run_id,string"`
+	UserID    uint64                    `json:"user_id"`
+	Username  string                    `json:"username"`
+	Field     model.ReconciliationField `json:"field"`
+	Expected  decimal.Decimal           `json:"expected"`
+	Actual    decimal.Decimal           `json:"actual"`
+	Diff      decimal.Decimal           `json:"diff"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+// listFindingsResponse 对账差异列表响应
+type listFindingsResponse struct {
+	Findings []finding `json:"findings"`
+	Total    int64     `json:"total"`
+}
+
+// ListFindings 获取对账差异列表
+// @Tags admin
+// @Produce json
+// @Param request query listFindingsRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/admin/reconciliation/findings [get]
+func ListFindings(c *gin.Context) {
+	var req listFindingsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	var findings []finding
+	var total int64
+
+	query := db.DB(c.Request.Context()).Model(&model.ReconciliationFinding{})
+
+	if req.RunID != 0 {
+		query = query.Where("reconciliation_findings.run_id = ?", req.RunID)
+	}
+	if req.UserID != 0 {
+		query = query.Where("reconciliation_findings.user_id = ?", req.UserID)
+	}
+	if req.Field != "" {
+		query = query.Where("reconciliation_findings.field = ?", req.Field)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.
+		Select("reconciliation_findings.*, users.username").
+		Joins("LEFT JOIN users ON users.id = reconciliation_findings.user_id").
+		Order("reconciliation_findings.id DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Scan(&findings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(listFindingsResponse{
+		Findings: findings,
+		Total:    total,
+	}))
+}
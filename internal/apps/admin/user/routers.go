@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/model"
 	"github.com/linux-do/credit/internal/util"
@@ -159,5 +160,13 @@ func UpdateUserStatus(c *gin.Context) {
 		return
 	}
 
+	// 禁用账号时吊销其全部登录会话
+	if !req.IsActive {
+		if err := oauth.RevokeUserSessions(c.Request.Context(), targetUser.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, util.OKNil())
 }
@@ -50,9 +50,10 @@ func queryDailyAmounts(ctx context.Context, userID uint64, isIncome bool, startD
 
 	var results []dailyAmountResult
 	err := db.DB(ctx).Model(&model.Order{}).
-		Select("DATE_TRUNC('day', created_at) as date, SUM(amount) as amount").
+		// 部分退款的订单按扣除已退款后的净额统计
+		Select("DATE_TRUNC('day', created_at) as date, SUM(amount - refunded_amount) as amount").
 		Where(userIDField+" = ?", userID).
-		Where("status = ?", model.OrderStatusSuccess).
+		Where("status IN ?", model.OrderPaidStatuses).
 		Where("created_at >= ? AND created_at < ?", startDate, endDate).
 		Group("DATE_TRUNC('day', created_at)").
 		Scan(&results).Error
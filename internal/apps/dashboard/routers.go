@@ -117,12 +117,12 @@ func GetTopCustomers(c *gin.Context) {
 		Select(`
 			orders.payer_user_id as user_id,
 			users.username,
-			SUM(orders.amount) as total_amount,
+			SUM(orders.amount - orders.refunded_amount) as total_amount,
 			COUNT(*) as order_count
 		`).
 		Joins("LEFT JOIN users ON orders.payer_user_id = users.id").
 		Where("orders.payee_user_id = ?", user.ID).
-		Where("orders.status = ?", model.OrderStatusSuccess).
+		Where("orders.status IN ?", model.OrderPaidStatuses).
 		Where("orders.type in ?", []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline}).
 		Where("orders.created_at >= ? AND orders.created_at < ?", startDate, endDate).
 		Group("orders.payer_user_id, users.username").
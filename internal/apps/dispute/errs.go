@@ -19,8 +19,10 @@ package dispute
 const (
 	OrderNotFoundForDispute  = "订单不存在"
 	DisputeNotFound          = "争议不存在"
-	NotOrderMerchant         = "您不是该订单的商家"
+	NotDisputeReviewer       = "您无权处理该争议"
 	ReasonRequiredForRefusal = "拒绝退款时必须提供理由"
 	DisputeTimeWindowExpired = "订单已交易完成,超过争议时间窗口,无法发起争议"
 	DuplicateDispute         = "无法重复发起争议，如仍有疑问请联系商家或LINUX DO Credit 团队"
+	CannotReviewOwnDispute   = "不能处理自己发起的争议"
+	EscrowHoldNotFound       = "担保交易冻结记录不存在"
 )
@@ -26,6 +26,7 @@ import (
 	"github.com/linux-do/credit/internal/apps/oauth"
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/linux-do/credit/internal/util"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
@@ -105,7 +106,7 @@ func ListDisputes(c *gin.Context) {
 	c.JSON(http.StatusOK, util.OK(response))
 }
 
-// ListMerchantDisputes 查询当前用户作为商家的争议订单
+// ListMerchantDisputes 查询当前用户作为商家或应用客服成员的争议订单，以及收款方发起、需由当前用户作为付款方处理的担保交易争议
 // @Tags order
 // @Accept json
 // @Produce json
@@ -127,7 +128,9 @@ func ListMerchantDisputes(c *gin.Context) {
 		Joins("JOIN users as payee_user ON orders.payee_user_id = payee_user.id").
 		Joins("JOIN users as initiator_user ON disputes.initiator_user_id = initiator_user.id").
 		Joins("LEFT JOIN users as handler_user ON disputes.handler_user_id = handler_user.id").
-		Where("orders.payee_user_id = ?", user.ID)
+		Where("orders.payee_user_id = ? OR orders.client_id IN (?) OR (orders.type = ? AND orders.payer_user_id = ? AND disputes.initiator_user_id = orders.payee_user_id)",
+			user.ID, model.MemberClientIDs(db.DB(c.Request.Context()), user.ID, model.MerchantRoleSupport),
+			model.OrderTypeEscrow, user.ID)
 
 	if req.Status != "" {
 		baseQuery = baseQuery.Where("disputes.status = ?", model.DisputeStatus(req.Status))
@@ -193,11 +196,15 @@ func CreateDispute(c *gin.Context) {
 		Status:          model.DisputeStatusDisputing,
 	}
 
+	var order model.Order
 	if err := db.DB(c.Request.Context()).Transaction(
 		func(tx *gorm.DB) error {
-			var order model.Order
+			// 商户订单仅付款方可发起争议；担保交易在放款前付款方与收款方均可发起
 			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
-				Where("id = ? AND payer_user_id = ? AND status = ? AND type IN ?", req.OrderID, user.ID, model.OrderStatusSuccess, []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline}).
+				Where("id = ? AND ((payer_user_id = ? AND status = ? AND type IN ?) OR ((payer_user_id = ? OR payee_user_id = ?) AND status = ? AND type = ?))",
+					req.OrderID,
+					user.ID, model.OrderStatusSuccess, []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline},
+					user.ID, user.ID, model.OrderStatusAuthorized, model.OrderTypeEscrow).
 				First(&order).Error; err != nil {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
 					return errors.New(OrderNotFoundForDispute)
@@ -205,10 +212,10 @@ func CreateDispute(c *gin.Context) {
 				return err
 			}
 
-			// 检查是否在争议时间窗口内
+			// 检查是否在争议时间窗口内，担保交易放款前不受限制
 			// 订单支付时间 + 争议时间窗口 <= 当前时间，则无法发起争议
 			disputeDeadline := order.TradeTime.Add(time.Duration(disputeTimeHours) * time.Hour)
-			if time.Now().After(disputeDeadline) {
+			if order.Type != model.OrderTypeEscrow && time.Now().After(disputeDeadline) {
 				return errors.New(DisputeTimeWindowExpired)
 			}
 
@@ -221,7 +228,7 @@ func CreateDispute(c *gin.Context) {
 				return err
 			}
 
-			return nil
+			return service.DispatchWebhookEvent(tx, order.ClientID, model.WebhookEventDisputeOpened, order.ID, service.DisputeEventData(&dispute, &order))
 		},
 	); err != nil {
 		errMsg := err.Error()
@@ -248,6 +255,7 @@ type RefundReviewRequest struct {
 }
 
 // RefundReview 退款审核（同意/拒绝）
+// 担保交易中 refund 表示冻结积分退回付款方，closed 表示放款给收款方
 // @Tags order
 // @Accept json
 // @Produce json
@@ -262,16 +270,13 @@ func RefundReview(c *gin.Context) {
 	}
 	status := model.DisputeStatus(req.Status)
 
-	if status == model.DisputeStatusClosed && req.Reason == "" {
-		c.JSON(http.StatusBadRequest, util.Err(ReasonRequiredForRefusal))
-		return
-	}
-
-	merchantUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
 
+	var dispute model.Dispute
+	var order model.Order
+	var refundOrder *model.Order
 	if err := db.DB(c.Request.Context()).Transaction(
 		func(tx *gorm.DB) error {
-			var dispute model.Dispute
 			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
 				Where("id = ? AND status = ?", req.DisputeID, model.DisputeStatusDisputing).
 				First(&dispute).Error; err != nil {
@@ -281,68 +286,77 @@ func RefundReview(c *gin.Context) {
 				return err
 			}
 
-			var order model.Order
+			// 由发起方的对手方审核：付款方发起时为收款方本人或订单所属应用的客服成员，担保交易收款方发起时为付款方
 			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
-				Where("id = ? AND payee_user_id = ? AND status = ? AND type IN ?", dispute.OrderID, merchantUser.ID, model.OrderStatusDisputing, []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline}).
+				Where("id = ? AND status = ? AND type IN ?",
+					dispute.OrderID, model.OrderStatusDisputing, []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline, model.OrderTypeEscrow}).
+				Where("(payer_user_id = ? AND (payee_user_id = ? OR client_id IN (?))) OR (type = ? AND payee_user_id = ? AND payer_user_id = ?)",
+					dispute.InitiatorUserID, currentUser.ID, model.MemberClientIDs(tx, currentUser.ID, model.MerchantRoleSupport),
+					model.OrderTypeEscrow, dispute.InitiatorUserID, currentUser.ID).
 				First(&order).Error; err != nil {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return errors.New(NotOrderMerchant)
+					return errors.New(NotDisputeReviewer)
 				}
 				return err
 			}
 
-			if status == model.DisputeStatusRefund {
-				var payerUser model.User
-				if err := payerUser.GetByID(tx, order.PayerUserID); err != nil {
-					return err
-				}
+			if dispute.InitiatorUserID == currentUser.ID {
+				return errors.New(CannotReviewOwnDispute)
+			}
 
-				// 获取商家的支付配置
-				var merchantPayConfig model.UserPayConfig
-				if err := merchantPayConfig.GetByPayScore(tx, merchantUser.PayScore); err != nil {
-					return err
-				}
+			// 收款方一侧拒绝退款时需说明理由，付款方审核时同意放款无需理由
+			if status == model.DisputeStatusClosed && req.Reason == "" && currentUser.ID != order.PayerUserID {
+				return errors.New(ReasonRequiredForRefusal)
+			}
 
-				merchantScoreDecrease := order.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
-				if err := tx.Model(&model.User{}).
-					Where("id = ?", merchantUser.ID).
-					UpdateColumns(map[string]interface{}{
-						"available_balance": gorm.Expr("available_balance - ?", order.Amount),
-						"total_receive":     gorm.Expr("total_receive - ?", order.Amount),
-						"pay_score":         gorm.Expr("pay_score - ?", merchantScoreDecrease),
-					}).Error; err != nil {
+			var merchantUser model.User
+			if err := merchantUser.GetByID(tx, order.PayeeUserID); err != nil {
+				return err
+			}
+
+			if order.ClientID != "" {
+				if err := recordDisputeReviewAudit(tx, c, &dispute, &order, currentUser, req.Status); err != nil {
 					return err
 				}
+			}
 
-				if err := tx.Model(&model.User{}).
-					Where("id = ?", payerUser.ID).
-					UpdateColumns(map[string]interface{}{
-						"available_balance": gorm.Expr("available_balance + ?", order.Amount),
-						"total_payment":     gorm.Expr("total_payment - ?", order.Amount),
-						"pay_score":         gorm.Expr("pay_score - ?", order.Amount.Round(0).IntPart()),
-					}).Error; err != nil {
-					return err
+			if status == model.DisputeStatusRefund {
+				if order.Type == model.OrderTypeEscrow {
+					// 担保交易：冻结积分全额退回付款方
+					if err := settleEscrowDispute(tx, &order, true); err != nil {
+						return err
+					}
+				} else {
+					// 获取商家的支付配置
+					var merchantPayConfig model.UserPayConfig
+					if err := merchantPayConfig.GetByPayScore(tx, merchantUser.PayScore); err != nil {
+						return err
+					}
+
+					var err error
+					refundOrder, err = service.RefundOrder(tx, &order, order.Amount.Sub(order.RefundedAmount), merchantPayConfig.ScoreRate)
+					if err != nil {
+						return err
+					}
 				}
 
 				if err := tx.Model(&model.Dispute{}).
 					Where("id = ?", dispute.ID).
 					Updates(map[string]interface{}{
 						"status":          model.DisputeStatusRefund,
-						"handler_user_id": merchantUser.ID,
+						"handler_user_id": currentUser.ID,
 					}).Error; err != nil {
 					return err
 				}
-
-				if err := tx.Model(&model.Order{}).
-					Where("id = ?", order.ID).
-					Update("status", model.OrderStatusRefund).Error; err != nil {
-					return err
-				}
+				dispute.Status = model.DisputeStatusRefund
 			} else if status == model.DisputeStatusClosed {
 				updateData := map[string]interface{}{
 					"status":          model.DisputeStatusClosed,
-					"handler_user_id": merchantUser.ID,
-					"reason":          dispute.Reason + " [受托方拒绝理由: " + req.Reason + "]",
+					"handler_user_id": currentUser.ID,
+					"reason":          dispute.Reason,
+				}
+				if req.Reason != "" {
+					updateData["reason"] = dispute.Reason + " [受托方拒绝理由: " + req.Reason + "]"
 				}
 
 				if err := tx.Model(&model.Dispute{}).
@@ -350,20 +364,32 @@ func RefundReview(c *gin.Context) {
 					Updates(updateData).Error; err != nil {
 					return err
 				}
-
-				if err := tx.Model(&model.Order{}).
+				dispute.Status = model.DisputeStatusClosed
+				dispute.Reason = updateData["reason"].(string)
+
+				if order.Type == model.OrderTypeEscrow {
+					// 担保交易：拒绝退款即放款给收款方
+					if err := settleEscrowDispute(tx, &order, false); err != nil {
+						return err
+					}
+				} else if err := tx.Model(&model.Order{}).
 					Where("id = ?", order.ID).
 					Update("status", model.OrderStatusRefused).Error; err != nil {
 					return err
 				}
+				order.Status = model.OrderStatusRefused
 			}
 
-			return nil
+			return dispatchResolvedEvents(tx, &dispute, &order, refundOrder)
 		},
 	); err != nil {
 		errMsg := err.Error()
 		if errMsg == DisputeNotFound {
 			c.JSON(http.StatusNotFound, util.Err(DisputeNotFound))
+		} else if errMsg == NotDisputeReviewer {
+			c.JSON(http.StatusForbidden, util.Err(NotDisputeReviewer))
+		} else if errMsg == CannotReviewOwnDispute || errMsg == ReasonRequiredForRefusal {
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
 		} else {
 			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
 		}
@@ -394,9 +420,10 @@ func CloseDispute(c *gin.Context) {
 
 	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
 
+	var dispute model.Dispute
+	var order model.Order
 	if err := db.DB(c.Request.Context()).Transaction(
 		func(tx *gorm.DB) error {
-			var dispute model.Dispute
 			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
 				Where("id = ? AND initiator_user_id = ? AND status = ?", req.DisputeID, user.ID, model.DisputeStatusDisputing).
 				First(&dispute).Error; err != nil {
@@ -406,9 +433,8 @@ func CloseDispute(c *gin.Context) {
 				return err
 			}
 
-			var order model.Order
 			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
-				Where("id = ? AND status = ? AND type IN ?", dispute.OrderID, model.OrderStatusDisputing, []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline}).
+				Where("id = ? AND status = ? AND type IN ?", dispute.OrderID, model.OrderStatusDisputing, []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline, model.OrderTypeEscrow}).
 				First(&order).Error; err != nil {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
 					return errors.New(OrderNotFoundForDispute)
@@ -425,13 +451,20 @@ func CloseDispute(c *gin.Context) {
 				return err
 			}
 
+			// 担保交易撤销争议后恢复为担保中，等待确认收货或自动放款
+			orderStatus := model.OrderStatusSuccess
+			if order.Type == model.OrderTypeEscrow {
+				orderStatus = model.OrderStatusAuthorized
+			}
 			if err := tx.Model(&model.Order{}).
 				Where("id = ?", order.ID).
-				Update("status", model.OrderStatusSuccess).Error; err != nil {
+				Update("status", orderStatus).Error; err != nil {
 				return err
 			}
+			dispute.Status = model.DisputeStatusClosed
+			order.Status = orderStatus
 
-			return nil
+			return dispatchResolvedEvents(tx, &dispute, &order, nil)
 		},
 	); err != nil {
 		errMsg := err.Error()
@@ -28,6 +28,7 @@ import (
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/linux-do/credit/internal/task"
 	"github.com/linux-do/credit/internal/task/scheduler"
 	"gorm.io/gorm"
@@ -101,8 +102,9 @@ func HandleAutoRefundSingleDispute(ctx context.Context, t *asynq.Task) error {
 		return fmt.Errorf("解析任务参数失败: %w", err)
 	}
 
+	var dispute model.Dispute
+	var order model.Order
 	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
-		var dispute model.Dispute
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
 			Where("id = ? AND status = ?", payload.DisputeID, model.DisputeStatusDisputing).
 			First(&dispute).Error; err != nil {
@@ -113,9 +115,8 @@ func HandleAutoRefundSingleDispute(ctx context.Context, t *asynq.Task) error {
 			return err
 		}
 
-		var order model.Order
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
-			Where("id = ? AND status = ? AND type = ?", dispute.OrderID, model.OrderStatusDisputing, model.OrderTypePayment).
+			Where("id = ? AND status = ? AND type IN ?", dispute.OrderID, model.OrderStatusDisputing, []model.OrderType{model.OrderTypePayment, model.OrderTypeEscrow}).
 			First(&order).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				logger.ErrorF(ctx, "争议[ID:%d]关联订单[ID:%d]不存在或状态异常", payload.DisputeID, dispute.OrderID)
@@ -133,35 +134,37 @@ func HandleAutoRefundSingleDispute(ctx context.Context, t *asynq.Task) error {
 			return fmt.Errorf("查询收款方用户失败: %w", err)
 		}
 
+		// 担保交易：无论哪一方发起，超时未处理的争议均将冻结积分退回付款方，放款必须经付款方确认
+		if order.Type == model.OrderTypeEscrow {
+			if err := settleEscrowDispute(tx, &order, true); err != nil {
+				return fmt.Errorf("担保交易争议结算失败: %w", err)
+			}
+
+			if err := tx.Model(&model.Dispute{}).
+				Where("id = ?", dispute.ID).
+				Updates(map[string]interface{}{
+					"status":          model.DisputeStatusRefund,
+					"handler_user_id": 0,
+				}).Error; err != nil {
+				return fmt.Errorf("更新争议状态失败: %w", err)
+			}
+			dispute.Status = model.DisputeStatusRefund
+
+			logger.InfoF(ctx, "担保交易争议自动退回成功: 争议[ID:%d] 订单[ID:%d] 金额[%s] 付款方[%s] 收款方[%s]",
+				dispute.ID, order.ID, order.Amount.String(), payerUser.Username, payeeUser.Username)
+			return nil
+		}
+
 		// 获取商家的支付配置
 		var merchantPayConfig model.UserPayConfig
 		if err := merchantPayConfig.GetByPayScore(tx, payeeUser.PayScore); err != nil {
 			return fmt.Errorf("查询商家支付配置失败: %w", err)
 		}
 
-		// 计算商家积分减少：订单金额 × 商家的 score_rate
-		merchantScoreDecrease := order.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
-
-		// 商家(收款方)退款：扣除可用余额、总收款和积分
-		if err := tx.Model(&model.User{}).
-			Where("id = ?", payeeUser.ID).
-			UpdateColumns(map[string]interface{}{
-				"available_balance": gorm.Expr("available_balance - ?", order.Amount),
-				"total_receive":     gorm.Expr("total_receive - ?", order.Amount),
-				"pay_score":         gorm.Expr("pay_score - ?", merchantScoreDecrease),
-			}).Error; err != nil {
-			return fmt.Errorf("商家退款失败: %w", err)
-		}
-
-		// 付款方收到退款：增加可用余额，减少总支付和支付积分
-		if err := tx.Model(&model.User{}).
-			Where("id = ?", payerUser.ID).
-			UpdateColumns(map[string]interface{}{
-				"available_balance": gorm.Expr("available_balance + ?", order.Amount),
-				"total_payment":     gorm.Expr("total_payment - ?", order.Amount),
-				"pay_score":         gorm.Expr("pay_score - ?", order.Amount.Round(0).IntPart()),
-			}).Error; err != nil {
-			return fmt.Errorf("付款方退款失败: %w", err)
+		// 全额退款：商家(收款方)扣回实收金额和积分，付款方退回余额并扣减支付积分，订单状态更新为已退款
+		refundOrder, err := service.RefundOrder(tx, &order, order.Amount.Sub(order.RefundedAmount), merchantPayConfig.ScoreRate)
+		if err != nil {
+			return fmt.Errorf("退款失败: %w", err)
 		}
 
 		// 更新争议状态为已退款，handler_user_id 设为 0（系统自动处理）
@@ -173,12 +176,10 @@ func HandleAutoRefundSingleDispute(ctx context.Context, t *asynq.Task) error {
 			}).Error; err != nil {
 			return fmt.Errorf("更新争议状态失败: %w", err)
 		}
+		dispute.Status = model.DisputeStatusRefund
 
-		// 更新订单状态为已退款
-		if err := tx.Model(&model.Order{}).
-			Where("id = ?", order.ID).
-			Update("status", model.OrderStatusRefund).Error; err != nil {
-			return fmt.Errorf("更新订单状态失败: %w", err)
+		if err := dispatchResolvedEvents(tx, &dispute, &order, refundOrder); err != nil {
+			return err
 		}
 
 		logger.InfoF(ctx, "自动退款成功: 争议[ID:%d] 订单[ID:%d] 金额[%s] 付款方[%s] 商家[%s]",
@@ -0,0 +1,85 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dispute
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// settleEscrowDispute 担保交易争议结算，refund 为 true 时冻结积分退回付款方，否则放款给收款方
+// order 需由调用方加行锁
+func settleEscrowDispute(tx *gorm.DB, order *model.Order, refund bool) error {
+	var hold model.PaymentHold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status = ?", order.ID, model.PaymentHoldStatusHeld).
+		First(&hold).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(EscrowHoldNotFound)
+		}
+		return err
+	}
+
+	if refund {
+		return service.ReleaseHold(tx, &hold, order, model.PaymentHoldStatusVoided, model.OrderStatusRefund)
+	}
+	return service.ReleaseEscrow(tx, &hold, order, model.OrderStatusRefused)
+}
+
+// dispatchResolvedEvents 在事务内下发争议处理结果事件，退款时额外下发退款事件
+func dispatchResolvedEvents(tx *gorm.DB, dispute *model.Dispute, order *model.Order, refundOrder *model.Order) error {
+	if refundOrder != nil {
+		if err := service.DispatchWebhookEvent(tx, order.ClientID, model.WebhookEventPaymentRefunded, order.ID, service.RefundEventData(order, refundOrder)); err != nil {
+			return err
+		}
+	}
+
+	return service.DispatchWebhookEvent(tx, order.ClientID, model.WebhookEventDisputeResolved, order.ID, service.DisputeEventData(dispute, order))
+}
+
+// recordDisputeReviewAudit 商户订单的退款审核写入应用审计记录，审核人不是应用成员时跳过
+func recordDisputeReviewAudit(tx *gorm.DB, c *gin.Context, dispute *model.Dispute, order *model.Order, user *model.User, status string) error {
+	var member model.MerchantMember
+	if err := tx.Model(&model.MerchantMember{}).
+		Select("merchant_members.*").
+		Joins("JOIN merchant_api_keys ON merchant_api_keys.id = merchant_members.api_key_id").
+		Where("merchant_api_keys.client_id = ? AND merchant_members.user_id = ? AND merchant_members.status = ?",
+			order.ClientID, user.ID, model.MerchantMemberStatusActive).
+		First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return tx.Create(&model.MerchantAuditLog{
+		APIKeyID:   member.APIKeyID,
+		UserID:     user.ID,
+		Role:       member.Role,
+		Action:     c.Request.Method + " " + c.FullPath(),
+		Target:     fmt.Sprintf("dispute_id=%d,status=%s", dispute.ID, status),
+		StatusCode: http.StatusOK,
+		IP:         c.ClientIP(),
+	}).Error
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gift_code
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+)
+
+// CreateAdminGiftCodeBatch 管理员创建礼品码批次，兑换时由平台系统账户发放
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body CreateGiftCodeBatchRequest true "创建礼品码批次请求"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/admin/gift-code-batches [post]
+func CreateAdminGiftCodeBatch(c *gin.Context) {
+	var req CreateGiftCodeBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	createGiftCodeBatch(c, &req, &model.GiftCodeBatch{
+		IssuerType:   model.GiftCodeIssuerAdmin,
+		IssuerUserID: currentUser.ID,
+	}, nil)
+}
+
+// ListAdminGiftCodeBatches 获取管理员发行的礼品码批次列表
+// @Tags admin
+// @Produce json
+// @Param request query ListGiftCodeBatchesRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/admin/gift-code-batches [get]
+func ListAdminGiftCodeBatches(c *gin.Context) {
+	listGiftCodeBatches(c, db.DB(c.Request.Context()).
+		Model(&model.GiftCodeBatch{}).
+		Where("issuer_type = ?", model.GiftCodeIssuerAdmin))
+}
+
+// ListAdminGiftCodes 获取管理员礼品码批次下的礼品码及兑换状态
+// @Tags admin
+// @Produce json
+// @Param batchId path uint64 true "Gift Code Batch ID"
+// @Param request query ListGiftCodeBatchesRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/admin/gift-code-batches/{batchId}/codes [get]
+func ListAdminGiftCodes(c *gin.Context) {
+	batch, ok := findGiftCodeBatch(c, db.DB(c.Request.Context()).Where("issuer_type = ?", model.GiftCodeIssuerAdmin))
+	if !ok {
+		return
+	}
+	listGiftCodes(c, batch)
+}
+
+// ListAdminGiftCodeRedemptions 获取管理员礼品码批次下的兑换记录
+// @Tags admin
+// @Produce json
+// @Param batchId path uint64 true "Gift Code Batch ID"
+// @Param request query ListGiftCodeBatchesRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/admin/gift-code-batches/{batchId}/redemptions [get]
+func ListAdminGiftCodeRedemptions(c *gin.Context) {
+	batch, ok := findGiftCodeBatch(c, db.DB(c.Request.Context()).Where("issuer_type = ?", model.GiftCodeIssuerAdmin))
+	if !ok {
+		return
+	}
+	listGiftCodeRedemptions(c, batch)
+}
@@ -0,0 +1,26 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gift_code
+
+const (
+	GiftCodeBatchNotFound   = "礼品码批次不存在"
+	GiftCodeNotFound        = "礼品码不存在"
+	GiftCodeUnavailable     = "礼品码已过期或已兑换完"
+	GiftCodeAlreadyRedeemed = "您已兑换过该礼品码"
+	RedeemLimitExceeded     = "已达到该批次的兑换次数上限"
+	CannotRedeemOwnGiftCode = "不能兑换自己发行的礼品码"
+)
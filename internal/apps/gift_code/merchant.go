@@ -0,0 +1,129 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gift_code
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/merchant"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// CreateMerchantGiftCodeBatchRequest 商户创建礼品码批次请求
+type CreateMerchantGiftCodeBatchRequest struct {
+	CreateGiftCodeBatchRequest
+	PayKey   string `json:"pay_key" binding:"required,max=6"`
+	TOTPCode string `json:"totp_code" binding:"max=16"`
+}
+
+// CreateMerchantGiftCodeBatch 商户创建礼品码批次，按最大可兑换总额从商户余额预先扣除
+// @Tags merchant
+// @Accept json
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param request body CreateMerchantGiftCodeBatchRequest true "创建礼品码批次请求"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/gift-code-batches [post]
+func CreateMerchantGiftCodeBatch(c *gin.Context) {
+	var req CreateMerchantGiftCodeBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	if err := service.VerifyPayKey(c.Request.Context(), currentUser, req.PayKey); err != nil {
+		switch err.Error() {
+		case common.PayKeyIncorrect, common.PayKeyLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	createGiftCodeBatch(c, &req.CreateGiftCodeBatchRequest, &model.GiftCodeBatch{
+		IssuerType:       model.GiftCodeIssuerMerchant,
+		IssuerUserID:     apiKey.UserID,
+		MerchantAPIKeyID: &apiKey.ID,
+	}, func(tx *gorm.DB, totalAmount decimal.Decimal) error {
+		// 按最大可兑换总额校验两步验证码
+		return service.CheckPaymentTOTP(tx, currentUser, totalAmount, req.TOTPCode, time.Now())
+	})
+}
+
+// ListMerchantGiftCodeBatches 获取商户礼品码批次列表
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param request query ListGiftCodeBatchesRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/gift-code-batches [get]
+func ListMerchantGiftCodeBatches(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	listGiftCodeBatches(c, db.DB(c.Request.Context()).
+		Model(&model.GiftCodeBatch{}).
+		Where("merchant_api_key_id = ?", apiKey.ID))
+}
+
+// ListMerchantGiftCodes 获取商户礼品码批次下的礼品码及兑换状态
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param batchId path uint64 true "Gift Code Batch ID"
+// @Param request query ListGiftCodeBatchesRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/gift-code-batches/{batchId}/codes [get]
+func ListMerchantGiftCodes(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	batch, ok := findGiftCodeBatch(c, db.DB(c.Request.Context()).Where("merchant_api_key_id = ?", apiKey.ID))
+	if !ok {
+		return
+	}
+	listGiftCodes(c, batch)
+}
+
+// ListMerchantGiftCodeRedemptions 获取商户礼品码批次下的兑换记录
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param batchId path uint64 true "Gift Code Batch ID"
+// @Param request query ListGiftCodeBatchesRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/gift-code-batches/{batchId}/redemptions [get]
+func ListMerchantGiftCodeRedemptions(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	batch, ok := findGiftCodeBatch(c, db.DB(c.Request.Context()).Where("merchant_api_key_id = ?", apiKey.ID))
+	if !ok {
+		return
+	}
+	listGiftCodeRedemptions(c, batch)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gift_code
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RedeemGiftCodeRequest 兑换礼品码请求
+type RedeemGiftCodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// RedeemGiftCode 兑换礼品码，兑换金额计入可用余额
+// @Tags gift_code
+// @Accept json
+// @Produce json
+// @Param request body RedeemGiftCodeRequest true "兑换礼品码请求"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/gift-codes/redeem [post]
+func RedeemGiftCode(c *gin.Context) {
+	var req RedeemGiftCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	var redemption *model.GiftCodeRedemption
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			var code model.GiftCode
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("code = ?", strings.ToUpper(strings.TrimSpace(req.Code))).
+				First(&code).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New(GiftCodeNotFound)
+				}
+				return err
+			}
+
+			var batch model.GiftCodeBatch
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", code.BatchID).
+				First(&batch).Error; err != nil {
+				return err
+			}
+
+			if batch.IssuerType == model.GiftCodeIssuerMerchant && batch.IssuerUserID == currentUser.ID {
+				return errors.New(CannotRedeemOwnGiftCode)
+			}
+			if batch.Status != model.GiftCodeBatchStatusActive || !batch.ExpiresAt.After(time.Now()) ||
+				code.RedeemedCount >= code.MaxRedemptions {
+				return errors.New(GiftCodeUnavailable)
+			}
+
+			var codeRedeemed int64
+			if err := tx.Model(&model.GiftCodeRedemption{}).
+				Where("gift_code_id = ? AND user_id = ?", code.ID, currentUser.ID).
+				Count(&codeRedeemed).Error; err != nil {
+				return err
+			}
+			if codeRedeemed > 0 {
+				return errors.New(GiftCodeAlreadyRedeemed)
+			}
+
+			var batchRedeemed int64
+			if err := tx.Model(&model.GiftCodeRedemption{}).
+				Where("batch_id = ? AND user_id = ?", batch.ID, currentUser.ID).
+				Count(&batchRedeemed).Error; err != nil {
+				return err
+			}
+			if batchRedeemed >= int64(batch.PerUserLimit) {
+				return errors.New(RedeemLimitExceeded)
+			}
+
+			var err error
+			redemption, err = service.RedeemGiftCode(tx, &batch, &code, currentUser.ID)
+			return err
+		},
+	); err != nil {
+		errMsg := err.Error()
+		switch errMsg {
+		case GiftCodeNotFound:
+			c.JSON(http.StatusNotFound, util.Err(errMsg))
+		case GiftCodeUnavailable, GiftCodeAlreadyRedeemed, RedeemLimitExceeded, CannotRedeemOwnGiftCode:
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
+		}
+		return
+	}
+
+	redemption.Username = currentUser.Username
+	c.JSON(http.StatusOK, util.OK(redemption))
+}
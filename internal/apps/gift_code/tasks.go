@@ -0,0 +1,79 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gift_code
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HandleExpireGiftCodeBatches 处理过期礼品码批次，商户批次未兑换的金额退回商户
+func HandleExpireGiftCodeBatches(ctx context.Context, _ *asynq.Task) error {
+	pageSize := 500
+	lastID := uint64(0)
+	totalExpired := 0
+	now := time.Now()
+
+	for {
+		var batches []model.GiftCodeBatch
+		if err := db.DB(ctx).
+			Where("id > ? AND status = ? AND expires_at <= ?", lastID, model.GiftCodeBatchStatusActive, now).
+			Order("id ASC").
+			Limit(pageSize).
+			Find(&batches).Error; err != nil {
+			logger.ErrorF(ctx, "查询过期礼品码批次失败: %v", err)
+			return err
+		}
+
+		if len(batches) == 0 {
+			break
+		}
+
+		for _, b := range batches {
+			if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+				var batch model.GiftCodeBatch
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
+					Where("id = ? AND status = ?", b.ID, model.GiftCodeBatchStatusActive).
+					First(&batch).Error; err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil
+					}
+					return err
+				}
+				return service.ExpireGiftCodeBatch(tx, &batch)
+			}); err != nil {
+				logger.ErrorF(ctx, "处理过期礼品码批次[ID:%d]失败: %v", b.ID, err)
+				continue
+			}
+			totalExpired++
+		}
+
+		lastID = batches[len(batches)-1].ID
+	}
+
+	logger.InfoF(ctx, "过期礼品码批次处理完成，共处理 %d 个", totalExpired)
+	return nil
+}
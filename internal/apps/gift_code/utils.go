@@ -0,0 +1,241 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gift_code
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// giftCodeAlphabet 礼品码字符集，去除了易混淆的 0、1、I、O
+const giftCodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+const giftCodeLength = 16
+
+// CreateGiftCodeBatchRequest 创建礼品码批次请求
+type CreateGiftCodeBatchRequest struct {
+	Name           string          `json:"name" binding:"required,max=50"`
+	Amount         decimal.Decimal `json:"amount" binding:"required"`
+	CodeCount      int             `json:"code_count" binding:"required,min=1,max=1000"`
+	MaxRedemptions int             `json:"max_redemptions" binding:"omitempty,min=1,max=10000"`
+	PerUserLimit   int             `json:"per_user_limit" binding:"omitempty,min=1,max=1000"`
+	ExpireDays     int             `json:"expire_days" binding:"required,min=1,max=365"`
+	Remark         string          `json:"remark" binding:"max=100"`
+}
+
+// ListGiftCodeBatchesRequest 礼品码批次查询参数
+type ListGiftCodeBatchesRequest struct {
+	Page     int `form:"page" binding:"min=1"`
+	PageSize int `form:"page_size" binding:"min=1,max=100"`
+}
+
+// ListGiftCodeBatchesResponse 礼品码批次列表响应
+type ListGiftCodeBatchesResponse struct {
+	Total   int64                 `json:"total"`
+	Batches []model.GiftCodeBatch `json:"batches"`
+}
+
+// ListGiftCodesResponse 礼品码列表响应
+type ListGiftCodesResponse struct {
+	Total int64            `json:"total"`
+	Codes []model.GiftCode `json:"codes"`
+}
+
+// ListGiftCodeRedemptionsResponse 礼品码兑换记录列表响应
+type ListGiftCodeRedemptionsResponse struct {
+	Total       int64                      `json:"total"`
+	Redemptions []model.GiftCodeRedemption `json:"redemptions"`
+}
+
+// generateGiftCode 生成随机礼品码
+func generateGiftCode() (string, error) {
+	b := make([]byte, giftCodeLength)
+	base := big.NewInt(int64(len(giftCodeAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, base)
+		if err != nil {
+			return "", err
+		}
+		b[i] = giftCodeAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// createGiftCodeBatch 校验请求并创建礼品码批次
+// authorize 在扣款事务内按批次总额校验发行方的支付凭证，为 nil 时不校验
+func createGiftCodeBatch(c *gin.Context, req *CreateGiftCodeBatchRequest, batch *model.GiftCodeBatch, authorize func(tx *gorm.DB, totalAmount decimal.Decimal) error) {
+	if err := util.ValidateAmount(req.Amount); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	batch.Name = req.Name
+	batch.Amount = req.Amount
+	batch.CodeCount = req.CodeCount
+	batch.MaxRedemptions = max(req.MaxRedemptions, 1)
+	batch.PerUserLimit = max(req.PerUserLimit, 1)
+	batch.TotalAmount = req.Amount.Mul(decimal.NewFromInt(int64(batch.CodeCount * batch.MaxRedemptions)))
+	batch.Remark = req.Remark
+	batch.ExpiresAt = time.Now().AddDate(0, 0, req.ExpireDays)
+
+	codes := make([]model.GiftCode, batch.CodeCount)
+	for i := range codes {
+		code, err := generateGiftCode()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+			return
+		}
+		codes[i] = model.GiftCode{Code: code, MaxRedemptions: batch.MaxRedemptions}
+	}
+
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			if authorize != nil {
+				if err := authorize(tx, batch.TotalAmount); err != nil {
+					return err
+				}
+			}
+			return service.CreateGiftCodeBatch(tx, batch, codes)
+		},
+	); err != nil {
+		switch err.Error() {
+		case common.InsufficientBalance, common.TOTPRequired, common.TOTPIncorrect, common.TOTPLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(gin.H{"batch": batch, "codes": codes}))
+}
+
+// listGiftCodeBatches 分页查询礼品码批次
+func listGiftCodeBatches(c *gin.Context, query *gorm.DB) {
+	var req ListGiftCodeBatchesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	var response ListGiftCodeBatchesResponse
+	if err := query.Count(&response.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&response.Batches).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
+
+// findGiftCodeBatch 查询批次，scope 用于限定可访问的批次范围
+func findGiftCodeBatch(c *gin.Context, scope *gorm.DB) (*model.GiftCodeBatch, bool) {
+	var batch model.GiftCodeBatch
+	if err := scope.Where("id = ?", c.Param("batchId")).First(&batch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, util.Err(GiftCodeBatchNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return nil, false
+	}
+	return &batch, true
+}
+
+// listGiftCodes 分页查询批次下的礼品码及兑换状态
+func listGiftCodes(c *gin.Context, batch *model.GiftCodeBatch) {
+	var req ListGiftCodeBatchesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	query := db.DB(c.Request.Context()).Model(&model.GiftCode{}).Where("batch_id = ?", batch.ID)
+
+	var response ListGiftCodesResponse
+	if err := query.Count(&response.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.
+		Order("id ASC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&response.Codes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
+
+// listGiftCodeRedemptions 分页查询批次下的兑换记录
+func listGiftCodeRedemptions(c *gin.Context, batch *model.GiftCodeBatch) {
+	var req ListGiftCodeBatchesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	query := db.DB(c.Request.Context()).
+		Model(&model.GiftCodeRedemption{}).
+		Where("gift_code_redemptions.batch_id = ?", batch.ID)
+
+	var response ListGiftCodeRedemptionsResponse
+	if err := query.Count(&response.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.
+		Select("gift_code_redemptions.*, users.username, gift_codes.code").
+		Joins("LEFT JOIN users ON users.id = gift_code_redemptions.user_id").
+		Joins("LEFT JOIN gift_codes ON gift_codes.id = gift_code_redemptions.gift_code_id").
+		Order("gift_code_redemptions.created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&response.Redemptions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
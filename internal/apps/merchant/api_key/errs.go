@@ -19,4 +19,12 @@ package api_key
 const (
 	APIKeyNotFound   = "API Key 不存在"
 	NoFieldsToUpdate = "没有需要更新的字段"
+	ExpireOutOfRange = "默认订单过期时间超出系统允许的范围"
+	LimitNegative    = "分发限额不能为负数"
+	SecretNotFound   = "密钥不存在"
+	SecretInactive   = "密钥已吊销或已过期"
+	SecretLimit      = "有效密钥数量已达上限"
+	RoleForbidden    = "当前角色无权执行该操作"
+
+	NoPreviousSigningKey = "没有仍在重叠期内的旧签名密钥"
 )
@@ -18,29 +18,101 @@ package api_key
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/linux-do/credit/internal/apps/merchant"
 	"github.com/linux-do/credit/internal/apps/oauth"
 	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
 	"github.com/linux-do/credit/internal/util"
 )
 
+// RequireAPIKey 校验当前用户为应用的有效成员，并将应用与成员信息保存到上下文
 func RequireAPIKey() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
 
-		var apiKey model.MerchantAPIKey
+		var member model.MerchantMember
 		if err := db.DB(c.Request.Context()).
-			Where("id = ? AND user_id = ?", c.Param("id"), user.ID).
-			First(&apiKey).Error; err != nil {
+			Where("api_key_id = ? AND user_id = ? AND status = ?", c.Param("id"), user.ID, model.MerchantMemberStatusActive).
+			First(&member).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, util.Err(APIKeyNotFound))
+			return
+		}
+
+		var apiKey model.MerchantAPIKey
+		if err := apiKey.GetByID(db.DB(c.Request.Context()), member.APIKeyID); err != nil {
 			c.AbortWithStatusJSON(http.StatusNotFound, util.Err(APIKeyNotFound))
 			return
 		}
 
 		util.SetToContext(c, merchant.APIKeyObjKey, &apiKey)
+		util.SetToContext(c, merchant.MemberObjKey, &member)
+
+		c.Next()
+	}
+}
 
+// RequireRole 要求成员拥有任一指定角色，owner 始终放行
+func RequireRole(roles ...model.MerchantRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		member, _ := util.GetFromContext[*model.MerchantMember](c, merchant.MemberObjKey)
+		if !member.HasRole(roles...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, util.Err(RoleForbidden))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AuditWrites 记录成员对应用的写操作
+func AuditWrites() gin.HandlerFunc {
+	return func(c *gin.Context) {
 		c.Next()
+
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			return
+		}
+		recordAudit(c)
+	}
+}
+
+// AuditRead 记录敏感的读操作，如查看签名密钥
+func AuditRead() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		recordAudit(c)
+	}
+}
+
+// recordAudit 写入审计记录，被中间件拦截的请求不记录，写入失败不影响响应
+func recordAudit(c *gin.Context) {
+	member, ok := util.GetFromContext[*model.MerchantMember](c, merchant.MemberObjKey)
+	if !ok || c.IsAborted() {
+		return
+	}
+
+	targets := make([]string, 0, len(c.Params))
+	for _, param := range c.Params {
+		if param.Key != "id" {
+			targets = append(targets, param.Key+"="+param.Value)
+		}
+	}
+
+	auditLog := model.MerchantAuditLog{
+		APIKeyID:   member.APIKeyID,
+		UserID:     member.UserID,
+		Role:       member.Role,
+		Action:     c.Request.Method + " " + strings.TrimPrefix(c.FullPath(), "/api/v1/merchant/api-keys/:id"),
+		Target:     strings.Join(targets, ","),
+		StatusCode: c.Writer.Status(),
+		IP:         c.ClientIP(),
+	}
+	if err := db.DB(c.Request.Context()).Create(&auditLog).Error; err != nil {
+		logger.ErrorF(c.Request.Context(), "写入商户审计记录失败: 应用[ID:%d] 错误: %v", member.APIKeyID, err)
 	}
 }
@@ -17,32 +17,61 @@ limitations under the License.
 package api_key
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/linux-do/credit/internal/apps/merchant"
 	"github.com/linux-do/credit/internal/apps/oauth"
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CreateAPIKeyRequest struct {
-	AppName        string `json:"app_name" binding:"required,max=20"`
-	AppHomepageURL string `json:"app_homepage_url" binding:"required,max=100,url"`
-	AppDescription string `json:"app_description" binding:"max=100"`
-	RedirectURI    string `json:"redirect_uri" binding:"omitempty,max=100,url"`
-	NotifyURL      string `json:"notify_url" binding:"required,max=100,url"`
-	TestMode       bool   `json:"test_mode"`
+	AppName        string         `json:"app_name" binding:"required,max=20"`
+	AppHomepageURL string         `json:"app_homepage_url" binding:"required,max=100,url"`
+	AppDescription string         `json:"app_description" binding:"max=100"`
+	RedirectURI    string         `json:"redirect_uri" binding:"omitempty,max=100,url"`
+	NotifyURL      string         `json:"notify_url" binding:"required,max=100,url"`
+	TestMode       bool           `json:"test_mode"`
+	SignType       model.SignType `json:"sign_type" binding:"omitempty,oneof=MD5 HMAC-SHA256"`
+	ExpireMinutes  int            `json:"expire_minutes" binding:"omitempty,min=0"`
+	WebhookURL     string         `json:"webhook_url" binding:"omitempty,max=255,url"`
+	WebhookEvents  []string       `json:"webhook_events" binding:"omitempty,dive,oneof=payment.succeeded payment.refunded payment.authorized payment.voided dispute.opened dispute.resolved order.expired order.cancelled distribute.succeeded distribute.batch_completed subscription.created subscription.renewed subscription.payment_failed subscription.cancelled"`
+	// 分发限额，0 表示不限制
+	DistributeMaxAmount           decimal.Decimal `json:"distribute_max_amount"`
+	DistributeDailyLimit          decimal.Decimal `json:"distribute_daily_limit"`
+	DistributeMonthlyLimit        decimal.Decimal `json:"distribute_monthly_limit"`
+	DistributeRecipientDailyLimit decimal.Decimal `json:"distribute_recipient_daily_limit"`
+	DistributeAllowedIPs          []string        `json:"distribute_allowed_ips" binding:"omitempty,max=20,dive,ip|cidr"`
 }
 
 type UpdateAPIKeyRequest struct {
-	AppName        string `json:"app_name" binding:"omitempty,max=20"`
-	AppHomepageURL string `json:"app_homepage_url" binding:"omitempty,max=100,url"`
-	AppDescription string `json:"app_description" binding:"omitempty,max=100"`
-	RedirectURI    string `json:"redirect_uri" binding:"omitempty,max=100,url"`
-	NotifyURL      string `json:"notify_url" binding:"omitempty,max=100,url"`
-	TestMode       bool   `json:"test_mode"`
+	AppName        string         `json:"app_name" binding:"omitempty,max=20"`
+	AppHomepageURL string         `json:"app_homepage_url" binding:"omitempty,max=100,url"`
+	AppDescription string         `json:"app_description" binding:"omitempty,max=100"`
+	RedirectURI    string         `json:"redirect_uri" binding:"omitempty,max=100,url"`
+	NotifyURL      string         `json:"notify_url" binding:"omitempty,max=100,url"`
+	TestMode       bool           `json:"test_mode"`
+	SignType       model.SignType `json:"sign_type" binding:"omitempty,oneof=MD5 HMAC-SHA256"`
+	ExpireMinutes  *int           `json:"expire_minutes" binding:"omitnil,min=0"`
+	WebhookURL     *string        `json:"webhook_url" binding:"omitnil,max=255,eq=|url"`
+	WebhookEvents  []string       `json:"webhook_events" binding:"omitempty,dive,oneof=payment.succeeded payment.refunded payment.authorized payment.voided dispute.opened dispute.resolved order.expired order.cancelled distribute.succeeded distribute.batch_completed subscription.created subscription.renewed subscription.payment_failed subscription.cancelled"`
+	// 旧版签名兼容仅在请求携带时更新
+	LegacySignAllowed *bool `json:"legacy_sign_allowed"`
+	// 分发限额仅在请求携带时更新，0 表示不限制
+	DistributeMaxAmount           *decimal.Decimal `json:"distribute_max_amount"`
+	DistributeDailyLimit          *decimal.Decimal `json:"distribute_daily_limit"`
+	DistributeMonthlyLimit        *decimal.Decimal `json:"distribute_monthly_limit"`
+	DistributeRecipientDailyLimit *decimal.Decimal `json:"distribute_recipient_daily_limit"`
+	DistributeAllowedIPs          []string         `json:"distribute_allowed_ips" binding:"omitempty,max=20,dive,ip|cidr"`
 }
 
 type APIKeyListResponse struct {
@@ -50,6 +79,33 @@ type APIKeyListResponse struct {
 	Data  []model.MerchantAPIKey `json:"data"`
 }
 
+// MemberAPIKey 当前用户参与的应用及其角色
+type MemberAPIKey struct {
+	model.MerchantAPIKey
+	Role model.MerchantRole `json:"role"`
+}
+
+// CreateAPIKeyResponse 创建应用响应，ClientSecret 为签名密钥，APISecret 为默认接口密钥明文，仅在此返回一次
+type CreateAPIKeyResponse struct {
+	model.MerchantAPIKey
+	ClientSecret string `json:"client_secret"`
+	APISecret    string `json:"api_secret"`
+}
+
+// SigningKeyResponse 签名密钥响应
+type SigningKeyResponse struct {
+	ClientSecret string `json:"client_secret"`
+	// 轮换后旧签名密钥的失效时间，为空表示没有仍可用的旧签名密钥
+	PreviousExpiresAt *time.Time `json:"previous_expires_at"`
+}
+
+// APIKeyDetail API Key 详情，包含当日与当月已分发金额
+type APIKeyDetail struct {
+	model.MerchantAPIKey
+	DistributedToday     decimal.Decimal `json:"distributed_today"`
+	DistributedThisMonth decimal.Decimal `json:"distributed_this_month"`
+}
+
 // CreateAPIKey 创建商户 API Key
 // @Tags merchant
 // @Accept json
@@ -66,6 +122,20 @@ func CreateAPIKey(c *gin.Context) {
 
 	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
 
+	if err := checkExpireMinutes(c.Request.Context(), req.ExpireMinutes); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	if err := checkDistributeLimits(&req.DistributeMaxAmount, &req.DistributeDailyLimit, &req.DistributeMonthlyLimit, &req.DistributeRecipientDailyLimit); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	if req.SignType == "" {
+		req.SignType = model.SignTypeMD5
+	}
+
 	apiKey := model.MerchantAPIKey{
 		UserID:         user.ID,
 		ClientID:       util.GenerateUniqueIDSimple(),
@@ -76,17 +146,44 @@ func CreateAPIKey(c *gin.Context) {
 		RedirectURI:    req.RedirectURI,
 		NotifyURL:      req.NotifyURL,
 		TestMode:       req.TestMode,
+		SignType:       req.SignType,
+		ExpireMinutes:  req.ExpireMinutes,
+		WebhookURL:     req.WebhookURL,
+		WebhookEvents:  req.WebhookEvents,
+
+		DistributeMaxAmount:           req.DistributeMaxAmount,
+		DistributeDailyLimit:          req.DistributeDailyLimit,
+		DistributeMonthlyLimit:        req.DistributeMonthlyLimit,
+		DistributeRecipientDailyLimit: req.DistributeRecipientDailyLimit,
+		DistributeAllowedIPs:          req.DistributeAllowedIPs,
 	}
 
-	if err := db.DB(c.Request.Context()).Create(&apiKey).Error; err != nil {
+	var apiSecret string
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&apiKey).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&model.MerchantMember{
+			APIKeyID:  apiKey.ID,
+			UserID:    user.ID,
+			Role:      model.MerchantRoleOwner,
+			Status:    model.MerchantMemberStatusActive,
+			InvitedBy: user.ID,
+		}).Error; err != nil {
+			return err
+		}
+		var err error
+		_, apiSecret, err = service.CreateDefaultAPISecret(tx, apiKey.ID)
+		return err
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, util.OK(apiKey))
+	c.JSON(http.StatusOK, util.OK(CreateAPIKeyResponse{MerchantAPIKey: apiKey, ClientSecret: apiKey.ClientSecret, APISecret: apiSecret}))
 }
 
-// ListAPIKeys 获取商户 API Key 列表
+// ListAPIKeys 获取当前用户创建或加入的商户 API Key 列表
 // @Tags merchant
 // @Produce json
 // @Success 200 {object} util.ResponseAny
@@ -94,11 +191,14 @@ func CreateAPIKey(c *gin.Context) {
 func ListAPIKeys(c *gin.Context) {
 	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
 
-	var apiKeys []model.MerchantAPIKey
+	var apiKeys []MemberAPIKey
 	if err := db.DB(c.Request.Context()).
-		Where("user_id = ?", user.ID).
-		Order("created_at DESC").
-		Find(&apiKeys).Error; err != nil {
+		Model(&model.MerchantAPIKey{}).
+		Select("merchant_api_keys.*, merchant_members.role").
+		Joins("JOIN merchant_members ON merchant_members.api_key_id = merchant_api_keys.id").
+		Where("merchant_members.user_id = ? AND merchant_members.status = ?", user.ID, model.MerchantMemberStatusActive).
+		Order("merchant_api_keys.created_at DESC").
+		Scan(&apiKeys).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
 		return
 	}
@@ -106,7 +206,7 @@ func ListAPIKeys(c *gin.Context) {
 	c.JSON(http.StatusOK, util.OK(apiKeys))
 }
 
-// GetAPIKey 获取单个商户 API Key
+// GetAPIKey 获取单个商户 API Key 及分发用量
 // @Tags merchant
 // @Produce json
 // @Param id path uint64 true "API Key ID"
@@ -114,7 +214,115 @@ func ListAPIKeys(c *gin.Context) {
 // @Router /api/v1/merchant/api-keys/{id} [get]
 func GetAPIKey(c *gin.Context) {
 	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
-	c.JSON(http.StatusOK, util.OK(apiKey))
+
+	now := time.Now()
+	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	detail := APIKeyDetail{MerchantAPIKey: *apiKey}
+	var err error
+	if detail.DistributedToday, err = service.GetDistributedAmount(db.DB(c.Request.Context()), apiKey.ClientID, 0, todayStart); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+	if detail.DistributedThisMonth, err = service.GetDistributedAmount(db.DB(c.Request.Context()), apiKey.ClientID, 0, monthStart); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(detail))
+}
+
+// GetSigningKey 获取应用签名密钥，用于计算请求签名与校验回调签名
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/signing-key [get]
+func GetSigningKey(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	c.JSON(http.StatusOK, util.OK(signingKeyResponse(apiKey)))
+}
+
+// RotateSigningKey 轮换签名密钥，回调立即使用新密钥签名，旧密钥在重叠期内仍可用于请求签名
+// @Tags merchant
+// @Accept json
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param request body RotateAPISecretRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/signing-key/rotate [post]
+func RotateSigningKey(c *gin.Context) {
+	var req RotateAPISecretRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	var locked model.MerchantAPIKey
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", apiKey.ID).First(&locked).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"client_secret":                     util.GenerateUniqueIDSimple(),
+			"previous_client_secret":            "",
+			"previous_client_secret_expires_at": nil,
+		}
+		if req.OverlapHours > 0 {
+			updates["previous_client_secret"] = locked.ClientSecret
+			updates["previous_client_secret_expires_at"] = time.Now().Add(time.Duration(req.OverlapHours) * time.Hour)
+		}
+		if err := tx.Model(&locked).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+		return locked.GetByID(tx, locked.ID)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(signingKeyResponse(&locked)))
+}
+
+// RevokePreviousSigningKey 吊销轮换后仍在重叠期内的旧签名密钥，立即失效
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/signing-key/revoke-previous [post]
+func RevokePreviousSigningKey(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	result := db.DB(c.Request.Context()).
+		Model(&model.MerchantAPIKey{}).
+		Where("id = ? AND previous_client_secret <> '' AND previous_client_secret_expires_at > ?", apiKey.ID, time.Now()).
+		UpdateColumns(map[string]interface{}{
+			"previous_client_secret":            "",
+			"previous_client_secret_expires_at": nil,
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(result.Error.Error()))
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, util.Err(NoPreviousSigningKey))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+func signingKeyResponse(apiKey *model.MerchantAPIKey) SigningKeyResponse {
+	resp := SigningKeyResponse{ClientSecret: apiKey.ClientSecret}
+	if len(apiKey.SigningSecrets(time.Now())) > 1 {
+		resp.PreviousExpiresAt = apiKey.PreviousClientSecretExpiresAt
+	}
+	return resp
 }
 
 // UpdateAPIKey 更新商户 API Key
@@ -142,6 +350,45 @@ func UpdateAPIKey(c *gin.Context) {
 		"notify_url":       req.NotifyURL,
 		"test_mode":        req.TestMode,
 	}
+	if req.SignType != "" {
+		updates["sign_type"] = req.SignType
+	}
+	if req.LegacySignAllowed != nil {
+		updates["legacy_sign_allowed"] = *req.LegacySignAllowed
+	}
+	// 默认过期时间与事件订阅仅在请求携带时更新，传空字符串或空数组表示清空
+	if req.ExpireMinutes != nil {
+		if err := checkExpireMinutes(c.Request.Context(), *req.ExpireMinutes); err != nil {
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+			return
+		}
+		updates["expire_minutes"] = *req.ExpireMinutes
+	}
+	if req.WebhookURL != nil {
+		updates["webhook_url"] = *req.WebhookURL
+	}
+	if req.WebhookEvents != nil {
+		updates["webhook_events"] = util.StringArray(req.WebhookEvents)
+	}
+	if err := checkDistributeLimits(req.DistributeMaxAmount, req.DistributeDailyLimit, req.DistributeMonthlyLimit, req.DistributeRecipientDailyLimit); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+	if req.DistributeMaxAmount != nil {
+		updates["distribute_max_amount"] = *req.DistributeMaxAmount
+	}
+	if req.DistributeDailyLimit != nil {
+		updates["distribute_daily_limit"] = *req.DistributeDailyLimit
+	}
+	if req.DistributeMonthlyLimit != nil {
+		updates["distribute_monthly_limit"] = *req.DistributeMonthlyLimit
+	}
+	if req.DistributeRecipientDailyLimit != nil {
+		updates["distribute_recipient_daily_limit"] = *req.DistributeRecipientDailyLimit
+	}
+	if req.DistributeAllowedIPs != nil {
+		updates["distribute_allowed_ips"] = util.StringArray(req.DistributeAllowedIPs)
+	}
 
 	if err := db.DB(c.Request.Context()).
 		Model(&apiKey).
@@ -169,3 +416,34 @@ func DeleteAPIKey(c *gin.Context) {
 
 	c.JSON(http.StatusOK, util.OKNil())
 }
+
+// checkExpireMinutes 校验应用默认订单过期时间，0 表示使用系统默认值
+func checkExpireMinutes(ctx context.Context, minutes int) error {
+	if minutes == 0 {
+		return nil
+	}
+
+	minMinutes, err := model.GetIntByKey(ctx, model.ConfigKeyMerchantOrderExpireMin)
+	if err != nil {
+		return err
+	}
+	maxMinutes, err := model.GetIntByKey(ctx, model.ConfigKeyMerchantOrderExpireMax)
+	if err != nil {
+		return err
+	}
+
+	if minutes < minMinutes || minutes > maxMinutes {
+		return errors.New(ExpireOutOfRange)
+	}
+	return nil
+}
+
+// checkDistributeLimits 校验分发限额，未携带的限额跳过
+func checkDistributeLimits(limits ...*decimal.Decimal) error {
+	for _, limit := range limits {
+		if limit != nil && limit.IsNegative() {
+			return errors.New(LimitNegative)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api_key
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/merchant"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxActiveSecrets 单个应用有效密钥数量上限
+const maxActiveSecrets = 10
+
+type CreateAPISecretRequest struct {
+	Name   string   `json:"name" binding:"required,max=32"`
+	Scopes []string `json:"scopes" binding:"required,min=1,unique,dive,oneof=create_order query refund distribute"`
+}
+
+type RotateAPISecretRequest struct {
+	// 旧密钥继续可用的小时数，0 表示立即失效
+	OverlapHours int `json:"overlap_hours" binding:"min=0,max=168"`
+}
+
+// APISecretWithPlaintext 新建或轮换后的密钥，明文仅返回一次
+type APISecretWithPlaintext struct {
+	model.MerchantAPISecret
+	Secret string `json:"secret"`
+}
+
+// ListAPISecrets 获取应用的接口密钥列表
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/secrets [get]
+func ListAPISecrets(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	var secrets []model.MerchantAPISecret
+	if err := db.DB(c.Request.Context()).
+		Where("api_key_id = ?", apiKey.ID).
+		Order("created_at DESC").
+		Find(&secrets).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(secrets))
+}
+
+// CreateAPISecret 创建接口密钥
+// @Tags merchant
+// @Accept json
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param request body CreateAPISecretRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/secrets [post]
+func CreateAPISecret(c *gin.Context) {
+	var req CreateAPISecretRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	var result APISecretWithPlaintext
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := checkActiveSecretCount(tx, apiKey.ID); err != nil {
+			return err
+		}
+
+		secret, plaintext, err := service.CreateAPISecret(tx, apiKey.ID, req.Name, req.Scopes)
+		if err != nil {
+			return err
+		}
+		result = APISecretWithPlaintext{MerchantAPISecret: *secret, Secret: plaintext}
+		return nil
+	}); err != nil {
+		switch err.Error() {
+		case SecretLimit:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(result))
+}
+
+// RotateAPISecret 轮换接口密钥，生成同名同权限的新密钥，旧密钥在重叠期结束后失效
+// @Tags merchant
+// @Accept json
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param secretId path uint64 true "密钥 ID"
+// @Param request body RotateAPISecretRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/secrets/{secretId}/rotate [post]
+func RotateAPISecret(c *gin.Context) {
+	var req RotateAPISecretRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	var result APISecretWithPlaintext
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		old, err := lockActiveSecret(tx, apiKey.ID, c.Param("secretId"))
+		if err != nil {
+			return err
+		}
+
+		// 旧密钥已有更早的过期时间时保持不变
+		expiresAt := time.Now().Add(time.Duration(req.OverlapHours) * time.Hour)
+		if old.ExpiresAt == nil || old.ExpiresAt.After(expiresAt) {
+			if err := tx.Model(old).UpdateColumn("expires_at", expiresAt).Error; err != nil {
+				return err
+			}
+		}
+		if err := checkActiveSecretCount(tx, apiKey.ID); err != nil {
+			return err
+		}
+
+		secret, plaintext, err := service.CreateAPISecret(tx, apiKey.ID, old.Name, old.Scopes)
+		if err != nil {
+			return err
+		}
+		result = APISecretWithPlaintext{MerchantAPISecret: *secret, Secret: plaintext}
+		return nil
+	}); err != nil {
+		respondSecretError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(result))
+}
+
+// RevokeAPISecret 吊销接口密钥，立即失效
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param secretId path uint64 true "密钥 ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/secrets/{secretId}/revoke [post]
+func RevokeAPISecret(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		secret, err := lockActiveSecret(tx, apiKey.ID, c.Param("secretId"))
+		if err != nil {
+			return err
+		}
+		return tx.Model(secret).UpdateColumn("revoked_at", time.Now()).Error
+	}); err != nil {
+		respondSecretError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// lockActiveSecret 锁定应用下仍有效的密钥
+func lockActiveSecret(tx *gorm.DB, apiKeyID uint64, secretID string) (*model.MerchantAPISecret, error) {
+	var secret model.MerchantAPISecret
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND api_key_id = ?", secretID, apiKeyID).
+		First(&secret).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(SecretNotFound)
+		}
+		return nil, err
+	}
+	if !secret.IsActive(time.Now()) {
+		return nil, errors.New(SecretInactive)
+	}
+	return &secret, nil
+}
+
+// checkActiveSecretCount 校验应用有效密钥数量未达上限
+func checkActiveSecretCount(tx *gorm.DB, apiKeyID uint64) error {
+	var count int64
+	if err := tx.Model(&model.MerchantAPISecret{}).
+		Where("api_key_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", apiKeyID, time.Now()).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count >= maxActiveSecrets {
+		return errors.New(SecretLimit)
+	}
+	return nil
+}
+
+func respondSecretError(c *gin.Context, err error) {
+	switch err.Error() {
+	case SecretNotFound:
+		c.JSON(http.StatusNotFound, util.Err(err.Error()))
+	case SecretInactive, SecretLimit:
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+	}
+}
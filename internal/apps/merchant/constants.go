@@ -18,5 +18,6 @@ package merchant
 
 const (
 	APIKeyObjKey      = "merchant_api_key_obj"
+	MemberObjKey      = "merchant_member_obj"
 	PaymentLinkObjKey = "payment_link_obj"
 )
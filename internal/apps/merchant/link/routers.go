@@ -37,9 +37,10 @@ import (
 
 // PayByLinkRequest 通过支付链接支付请求
 type PayByLinkRequest struct {
-	Token  string `json:"token" binding:"required"`
-	PayKey string `json:"pay_key" binding:"required,max=6"`
-	Remark string `json:"remark" binding:"max=100"`
+	Token    string `json:"token" binding:"required"`
+	PayKey   string `json:"pay_key" binding:"required,max=6"`
+	TOTPCode string `json:"totp_code" binding:"max=16"`
+	Remark   string `json:"remark" binding:"max=100"`
 }
 
 // PaymentLinkRequest 创建支付链接请求
@@ -254,8 +255,23 @@ func PayByLink(c *gin.Context) {
 
 	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
 
-	if !currentUser.VerifyPayKey(req.PayKey) {
-		c.JSON(http.StatusBadRequest, util.Err(common.PayKeyIncorrect))
+	if err := service.VerifyPayKey(c.Request.Context(), currentUser, req.PayKey); err != nil {
+		switch err.Error() {
+		case common.PayKeyIncorrect, common.PayKeyLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	if err := service.CheckPaymentTOTP(db.DB(c.Request.Context()), currentUser, paymentLink.Amount, req.TOTPCode, time.Now()); err != nil {
+		switch err.Error() {
+		case common.TOTPRequired, common.TOTPIncorrect, common.TOTPLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
 		return
 	}
 
@@ -301,6 +317,7 @@ func PayByLink(c *gin.Context) {
 
 	isTestMode := merchantAPIKey.TestMode
 
+	var order model.Order
 	if err := db.DB(c.Request.Context()).Transaction(
 		func(tx *gorm.DB) error {
 			// 非测试模式
@@ -315,7 +332,7 @@ func PayByLink(c *gin.Context) {
 				if paymentLink.TotalLimit != nil {
 					var totalCount int64
 					if err := tx.Table("orders").
-						Where("payment_link_id = ? AND status = ?", paymentLink.ID, model.OrderStatusSuccess).
+						Where("payment_link_id = ? AND status IN ?", paymentLink.ID, model.OrderPaidStatuses).
 						Count(&totalCount).Error; err != nil {
 						return err
 					}
@@ -328,8 +345,8 @@ func PayByLink(c *gin.Context) {
 				if paymentLink.UserLimit != nil {
 					var userCount int64
 					if err := tx.Table("orders").
-						Where("payment_link_id = ? AND status = ? AND payer_user_id = ?",
-							paymentLink.ID, model.OrderStatusSuccess, currentUser.ID).
+						Where("payment_link_id = ? AND status IN ? AND payer_user_id = ?",
+							paymentLink.ID, model.OrderPaidStatuses, currentUser.ID).
 						Count(&userCount).Error; err != nil {
 						return err
 					}
@@ -345,16 +362,18 @@ func PayByLink(c *gin.Context) {
 			}
 
 			// 计算手续费
-			_, merchantAmount, feePercent := service.CalculateFee(paymentLink.Amount, merchantPayConfig.FeeRate)
+			fee, merchantAmount, feePercent := service.CalculateFee(paymentLink.Amount, merchantPayConfig.FeeRate)
 
 			var remark string
 			var orderType model.OrderType
 			var paymentLinkID *uint64
+			orderFee := decimal.Zero
 
 			if isTestMode {
 				remark = common.TestModeOrderRemark
 				orderType = model.OrderTypeTest
 			} else {
+				orderFee = fee
 				feeRemark := fmt.Sprintf("[系统]: 收取商家%d%%手续费", feePercent)
 				if req.Remark != "" {
 					remark = req.Remark + " " + feeRemark
@@ -366,12 +385,13 @@ func PayByLink(c *gin.Context) {
 			}
 
 			// 创建订单
-			order := model.Order{
+			order = model.Order{
 				OrderName:     paymentLink.ProductName,
 				PayerUserID:   currentUser.ID,
 				PayeeUserID:   merchantUser.ID,
 				ClientID:      merchantAPIKey.ClientID,
 				Amount:        paymentLink.Amount,
+				Fee:           orderFee,
 				Status:        model.OrderStatusSuccess,
 				Type:          orderType,
 				Remark:        remark,
@@ -386,34 +406,48 @@ func PayByLink(c *gin.Context) {
 			// 非测试模式：扣减用户余额和增加商户余额
 			if !isTestMode {
 				if err := service.UpdateBalance(tx, service.BalanceUpdateOptions{
-					UserID:       currentUser.ID,
-					Amount:       paymentLink.Amount,
-					Operation:    service.BalanceDeduct,
-					ScoreChange:  paymentLink.Amount.Round(0).IntPart(),
-					TotalField:   "total_payment",
-					CheckBalance: true,
+					UserID:        currentUser.ID,
+					CounterUserID: merchantUser.ID,
+					OrderID:       order.ID,
+					EntryType:     model.LedgerEntryTypePayment,
+					Amount:        paymentLink.Amount,
+					Operation:     service.BalanceDeduct,
+					ScoreChange:   paymentLink.Amount.Round(0).IntPart(),
+					TotalField:    "total_payment",
+					CheckBalance:  true,
 				}); err != nil {
 					return err
 				}
 
 				merchantScoreIncrease := paymentLink.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
 				if err := service.UpdateBalance(tx, service.BalanceUpdateOptions{
-					UserID:       merchantUser.ID,
-					Amount:       merchantAmount,
-					Operation:    service.BalanceAdd,
-					ScoreChange:  merchantScoreIncrease,
-					TotalField:   "total_receive",
-					CheckBalance: false,
+					UserID:        merchantUser.ID,
+					CounterUserID: currentUser.ID,
+					OrderID:       order.ID,
+					EntryType:     model.LedgerEntryTypePayment,
+					Amount:        merchantAmount,
+					Operation:     service.BalanceAdd,
+					ScoreChange:   merchantScoreIncrease,
+					TotalField:    "total_receive",
+					CheckBalance:  false,
 				}); err != nil {
 					return err
 				}
+
+				if err := model.CreatePlatformLedgerEntry(tx, merchantUser.ID, order.ID, fee, model.LedgerEntryTypeFee); err != nil {
+					return err
+				}
+			}
+
+			if err := service.DispatchWebhookEvent(tx, merchantAPIKey.ClientID, model.WebhookEventPaymentSucceeded, order.ID, service.OrderEventData(&order)); err != nil {
+				return err
 			}
 
 			if config.Config.App.IsProduction() && util.IsLocalhost(merchantAPIKey.NotifyURL) {
 				return nil
 			}
 
-			return service.EnqueueMerchantNotify(order.ID, merchantAPIKey.ClientID)
+			return service.EnqueueMerchantNotify(tx, order.ID, merchantAPIKey.ClientID)
 		},
 	); err != nil {
 		errMsg := err.Error()
@@ -0,0 +1,27 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package member
+
+const (
+	MemberNotFound     = "成员不存在"
+	InviteeNotFound    = "被邀请用户不存在"
+	AlreadyMember      = "该用户已是应用成员或已被邀请"
+	CannotChangeOwner  = "不能修改或移除应用创建者"
+	InvitationNotFound = "邀请不存在或已处理"
+	CannotRemoveOthers = "仅应用创建者可以移除其他成员"
+	CannotInviteSelf   = "不能邀请自己"
+)
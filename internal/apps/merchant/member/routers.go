@@ -0,0 +1,331 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package member
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/merchant"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+)
+
+type InviteMemberRequest struct {
+	UserID   uint64             `json:"user_id,string" binding:"required"`
+	Username string             `json:"username" binding:"required"`
+	Role     model.MerchantRole `json:"role" binding:"required,oneof=developer support finance"`
+}
+
+type UpdateMemberRequest struct {
+	Role model.MerchantRole `json:"role" binding:"required,oneof=developer support finance"`
+}
+
+// ListAuditLogsRequest 审计记录查询请求
+type ListAuditLogsRequest struct {
+	Page     int    `form:"page" binding:"min=1"`
+	PageSize int    `form:"page_size" binding:"min=1,max=100"`
+	UserID   uint64 `form:"user_id"`
+}
+
+// ListAuditLogsResponse 审计记录列表响应
+type ListAuditLogsResponse struct {
+	Total int64                    `json:"total"`
+	Logs  []model.MerchantAuditLog `json:"logs"`
+}
+
+// ListMembers 获取应用成员列表，包含待接受的邀请
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/members [get]
+func ListMembers(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	var members []model.MerchantMember
+	if err := db.DB(c.Request.Context()).
+		Model(&model.MerchantMember{}).
+		Select("merchant_members.*, users.username").
+		Joins("JOIN users ON users.id = merchant_members.user_id").
+		Where("merchant_members.api_key_id = ?", apiKey.ID).
+		Order("merchant_members.created_at ASC").
+		Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(members))
+}
+
+// InviteMember 邀请用户加入应用，对方接受后生效
+// @Tags merchant
+// @Accept json
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param request body InviteMemberRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/members [post]
+func InviteMember(c *gin.Context) {
+	var req InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	if req.UserID == currentUser.ID {
+		c.JSON(http.StatusBadRequest, util.Err(CannotInviteSelf))
+		return
+	}
+
+	var invitee model.User
+	if err := db.DB(c.Request.Context()).
+		Where("id = ? AND username = ? AND is_active = ?", req.UserID, req.Username, true).
+		First(&invitee).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, util.Err(InviteeNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	member := model.MerchantMember{
+		APIKeyID:  apiKey.ID,
+		UserID:    invitee.ID,
+		Role:      req.Role,
+		Status:    model.MerchantMemberStatusPending,
+		InvitedBy: currentUser.ID,
+	}
+	if err := db.DB(c.Request.Context()).Create(&member).Error; err != nil {
+		if strings.Contains(err.Error(), "SQLSTATE 23505") {
+			c.JSON(http.StatusBadRequest, util.Err(AlreadyMember))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+	member.Username = invitee.Username
+
+	c.JSON(http.StatusOK, util.OK(member))
+}
+
+// UpdateMember 修改成员角色
+// @Tags merchant
+// @Accept json
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param memberId path uint64 true "成员 ID"
+// @Param request body UpdateMemberRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/members/{memberId} [put]
+func UpdateMember(c *gin.Context) {
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	target, ok := findMember(c, apiKey.ID)
+	if !ok {
+		return
+	}
+	if target.Role == model.MerchantRoleOwner {
+		c.JSON(http.StatusBadRequest, util.Err(CannotChangeOwner))
+		return
+	}
+
+	if err := db.DB(c.Request.Context()).Model(target).Update("role", req.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// RemoveMember 移除成员或撤回邀请，非创建者成员只能移除自己（退出应用）
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param memberId path uint64 true "成员 ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/members/{memberId} [delete]
+func RemoveMember(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+	currentMember, _ := util.GetFromContext[*model.MerchantMember](c, merchant.MemberObjKey)
+
+	target, ok := findMember(c, apiKey.ID)
+	if !ok {
+		return
+	}
+	if target.Role == model.MerchantRoleOwner {
+		c.JSON(http.StatusBadRequest, util.Err(CannotChangeOwner))
+		return
+	}
+	if currentMember.Role != model.MerchantRoleOwner && target.ID != currentMember.ID {
+		c.JSON(http.StatusForbidden, util.Err(CannotRemoveOthers))
+		return
+	}
+
+	if err := db.DB(c.Request.Context()).Delete(target).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// ListAuditLogs 获取应用操作审计记录
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param request query ListAuditLogsRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/audit-logs [get]
+func ListAuditLogs(c *gin.Context) {
+	var req ListAuditLogsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	query := db.DB(c.Request.Context()).
+		Model(&model.MerchantAuditLog{}).
+		Where("merchant_audit_logs.api_key_id = ?", apiKey.ID)
+	if req.UserID != 0 {
+		query = query.Where("merchant_audit_logs.user_id = ?", req.UserID)
+	}
+
+	var response ListAuditLogsResponse
+	if err := query.Count(&response.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.
+		Select("merchant_audit_logs.*, users.username").
+		Joins("JOIN users ON users.id = merchant_audit_logs.user_id").
+		Order("merchant_audit_logs.created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&response.Logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
+
+// ListInvitations 获取当前用户待接受的应用邀请
+// @Tags merchant
+// @Produce json
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/invitations [get]
+func ListInvitations(c *gin.Context) {
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	var invitations []model.MerchantMember
+	if err := db.DB(c.Request.Context()).
+		Model(&model.MerchantMember{}).
+		Select("merchant_members.*, merchant_api_keys.app_name, inviter.username as inviter_name").
+		Joins("JOIN merchant_api_keys ON merchant_api_keys.id = merchant_members.api_key_id AND merchant_api_keys.deleted_at IS NULL").
+		Joins("JOIN users as inviter ON inviter.id = merchant_members.invited_by").
+		Where("merchant_members.user_id = ? AND merchant_members.status = ?", currentUser.ID, model.MerchantMemberStatusPending).
+		Order("merchant_members.created_at DESC").
+		Find(&invitations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(invitations))
+}
+
+// AcceptInvitation 接受应用邀请
+// @Tags merchant
+// @Produce json
+// @Param memberId path uint64 true "邀请 ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/invitations/{memberId}/accept [post]
+func AcceptInvitation(c *gin.Context) {
+	respondInvitation(c, func(tx *gorm.DB, invitation *model.MerchantMember) error {
+		return tx.Model(invitation).Update("status", model.MerchantMemberStatusActive).Error
+	})
+}
+
+// DeclineInvitation 拒绝应用邀请
+// @Tags merchant
+// @Produce json
+// @Param memberId path uint64 true "邀请 ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/invitations/{memberId}/decline [post]
+func DeclineInvitation(c *gin.Context) {
+	respondInvitation(c, func(tx *gorm.DB, invitation *model.MerchantMember) error {
+		return tx.Delete(invitation).Error
+	})
+}
+
+func respondInvitation(c *gin.Context, apply func(tx *gorm.DB, invitation *model.MerchantMember) error) {
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	var invitation model.MerchantMember
+	if err := db.DB(c.Request.Context()).
+		Where("id = ? AND user_id = ? AND status = ?", c.Param("memberId"), currentUser.ID, model.MerchantMemberStatusPending).
+		First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, util.Err(InvitationNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	if err := apply(db.DB(c.Request.Context()), &invitation); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// findMember 查询应用下的成员
+func findMember(c *gin.Context, apiKeyID uint64) (*model.MerchantMember, bool) {
+	var member model.MerchantMember
+	if err := db.DB(c.Request.Context()).
+		Where("id = ? AND api_key_id = ?", c.Param("memberId"), apiKeyID).
+		First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, util.Err(MemberNotFound))
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return nil, false
+	}
+	return &member, true
+}
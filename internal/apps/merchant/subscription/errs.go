@@ -0,0 +1,32 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subscription
+
+const (
+	SubscriptionPlanNotFound = "订阅计划不存在"
+	SubscriptionNotFound     = "订阅不存在或已取消"
+	AlreadySubscribed        = "您已订阅该计划"
+)
+
+// 订阅取消原因
+const (
+	CancelledByUser      = "用户取消订阅"
+	CancelledByMerchant  = "商户取消订阅"
+	CancelledAPIKeyGone  = "商户应用已删除"
+	CancelledRetryExceed = "连续扣款失败次数超过上限"
+	CancelledInactive    = "付款方或商户账号已被禁用"
+)
@@ -0,0 +1,325 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subscription
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/merchant"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// SubscriptionPlanRequest 创建/更新订阅计划请求
+type SubscriptionPlanRequest struct {
+	Name          string                     `json:"name" binding:"required,max=30"`
+	Amount        decimal.Decimal            `json:"amount" binding:"required"`
+	Interval      model.SubscriptionInterval `json:"interval" binding:"required,oneof=day week month year"`
+	IntervalCount int                        `json:"interval_count" binding:"omitempty,min=1,max=365"`
+	Remark        string                     `json:"remark" binding:"max=100"`
+}
+
+// SubscribeRequest 用户订阅请求
+type SubscribeRequest struct {
+	Token  string `json:"token" binding:"required"`
+	PayKey string `json:"pay_key" binding:"required,max=6"`
+}
+
+// SubscriptionPlanDetail 订阅计划详情
+type SubscriptionPlanDetail struct {
+	ID            uint64                     `json:"id,string"`
+	Token         string                     `json:"token"`
+	Name          string                     `json:"name"`
+	Amount        decimal.Decimal            `json:"amount"`
+	Interval      model.SubscriptionInterval `json:"interval"`
+	IntervalCount int                        `json:"interval_count"`
+	Remark        string                     `json:"remark"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	AppName       string                     `json:"app_name"`
+	RedirectURI   string                     `json:"redirect_uri"`
+}
+
+const planDetailColumns = "subscription_plans.id, subscription_plans.token, subscription_plans.name, subscription_plans.amount, subscription_plans.interval, subscription_plans.interval_count, subscription_plans.remark, subscription_plans.created_at, merchant_api_keys.app_name, merchant_api_keys.redirect_uri"
+
+// CreateSubscriptionPlan 创建订阅计划
+// @Tags merchant
+// @Accept json
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param request body SubscriptionPlanRequest true "创建订阅计划请求"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/subscription-plans [post]
+func CreateSubscriptionPlan(c *gin.Context) {
+	var req SubscriptionPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	// 验证金额
+	if err := util.ValidateAmount(req.Amount); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	plan := model.SubscriptionPlan{
+		MerchantAPIKeyID: apiKey.ID,
+		Token:            util.GenerateUniqueIDSimple(),
+		Name:             req.Name,
+		Amount:           req.Amount,
+		Interval:         req.Interval,
+		IntervalCount:    max(req.IntervalCount, 1),
+		Remark:           req.Remark,
+	}
+
+	if err := db.DB(c.Request.Context()).Create(&plan).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(plan))
+}
+
+// ListSubscriptionPlans 获取订阅计划列表
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/subscription-plans [get]
+func ListSubscriptionPlans(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	var plans []SubscriptionPlanDetail
+	if err := db.DB(c.Request.Context()).
+		Table("subscription_plans").
+		Select(planDetailColumns).
+		Joins("JOIN merchant_api_keys ON merchant_api_keys.id = subscription_plans.merchant_api_key_id").
+		Where("subscription_plans.merchant_api_key_id = ? AND subscription_plans.deleted_at IS NULL", apiKey.ID).
+		Order("subscription_plans.created_at DESC").
+		Find(&plans).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(plans))
+}
+
+// UpdateSubscriptionPlan 更新订阅计划，已订阅的用户仍按订阅时的金额与周期扣款
+// @Tags merchant
+// @Accept json
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param planId path uint64 true "Subscription Plan ID"
+// @Param request body SubscriptionPlanRequest true "更新订阅计划请求"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/subscription-plans/{planId} [put]
+func UpdateSubscriptionPlan(c *gin.Context) {
+	var req SubscriptionPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	// 验证金额
+	if err := util.ValidateAmount(req.Amount); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	result := db.DB(c.Request.Context()).
+		Model(&model.SubscriptionPlan{}).
+		Where("id = ? AND merchant_api_key_id = ?", c.Param("planId"), apiKey.ID).
+		Updates(map[string]interface{}{
+			"name":           req.Name,
+			"amount":         req.Amount,
+			"interval":       req.Interval,
+			"interval_count": max(req.IntervalCount, 1),
+			"remark":         req.Remark,
+		})
+
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(result.Error.Error()))
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, util.Err(SubscriptionPlanNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// DeleteSubscriptionPlan 删除订阅计划，删除后不可再订阅，已有订阅继续按期扣款
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param planId path uint64 true "Subscription Plan ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/subscription-plans/{planId} [delete]
+func DeleteSubscriptionPlan(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	result := db.DB(c.Request.Context()).
+		Where("id = ? AND merchant_api_key_id = ?", c.Param("planId"), apiKey.ID).
+		Delete(&model.SubscriptionPlan{})
+
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(result.Error.Error()))
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, util.Err(SubscriptionPlanNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// GetSubscriptionPlanByToken 通过 Token 查询订阅计划信息
+// @Tags merchant
+// @Produce json
+// @Param token path string true "订阅计划 Token"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/subscription-plans/{token} [get]
+func GetSubscriptionPlanByToken(c *gin.Context) {
+	var plan SubscriptionPlanDetail
+	if err := db.DB(c.Request.Context()).
+		Table("subscription_plans").
+		Select(planDetailColumns).
+		Joins("JOIN merchant_api_keys ON merchant_api_keys.id = subscription_plans.merchant_api_key_id").
+		Where("subscription_plans.token = ? AND subscription_plans.deleted_at IS NULL", c.Param("token")).
+		First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, util.Err(SubscriptionPlanNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(plan))
+}
+
+// Subscribe 用户确认订阅，授权商户按期自动扣款并立即支付首期
+// @Tags merchant
+// @Accept json
+// @Produce json
+// @Param request body SubscribeRequest true "订阅请求"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/subscription-plans/subscribe [post]
+func Subscribe(c *gin.Context) {
+	var req SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	var plan model.SubscriptionPlan
+	if err := plan.GetByToken(db.DB(c.Request.Context()), req.Token); err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, util.Err(SubscriptionPlanNotFound))
+		return
+	}
+
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if err := service.VerifyPayKey(c.Request.Context(), currentUser, req.PayKey); err != nil {
+		switch err.Error() {
+		case common.PayKeyIncorrect, common.PayKeyLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	// 查询商户 API Key
+	var merchantAPIKey model.MerchantAPIKey
+	if err := merchantAPIKey.GetByID(db.DB(c.Request.Context()), plan.MerchantAPIKeyID); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	// 验证测试模式下的支付权限
+	if err := service.ValidateTestModePayment(currentUser.ID, merchantAPIKey.UserID, merchantAPIKey.TestMode); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	sub := model.Subscription{
+		PlanID:           plan.ID,
+		MerchantAPIKeyID: merchantAPIKey.ID,
+		ClientID:         merchantAPIKey.ClientID,
+		PayerUserID:      currentUser.ID,
+		PayeeUserID:      merchantAPIKey.UserID,
+		Name:             plan.Name,
+		Amount:           plan.Amount,
+		Interval:         plan.Interval,
+		IntervalCount:    plan.IntervalCount,
+		Status:           model.SubscriptionStatusActive,
+		NextBillingAt:    time.Now(),
+	}
+
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			var count int64
+			if err := tx.Model(&model.Subscription{}).
+				Where("plan_id = ? AND payer_user_id = ? AND status <> ?", plan.ID, currentUser.ID, model.SubscriptionStatusCancelled).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return errors.New(AlreadySubscribed)
+			}
+
+			if err := tx.Create(&sub).Error; err != nil {
+				return err
+			}
+
+			order, err := service.ChargeSubscription(tx, &sub, &merchantAPIKey, time.Now())
+			if err != nil {
+				return err
+			}
+			return dispatchChargeEvents(tx, &sub, order, model.WebhookEventSubscriptionCreated)
+		},
+	); err != nil {
+		errMsg := err.Error()
+		switch errMsg {
+		case common.InsufficientBalance, common.DailyLimitExceeded, common.SubscriptionPartyInactive, AlreadySubscribed:
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(sub))
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subscription
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/merchant"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+)
+
+// ListSubscriptionsRequest 订阅查询请求
+type ListSubscriptionsRequest struct {
+	Page     int                      `form:"page" binding:"min=1"`
+	PageSize int                      `form:"page_size" binding:"min=1,max=100"`
+	Status   model.SubscriptionStatus `form:"status" binding:"omitempty,oneof=active past_due cancelled"`
+	PlanID   uint64                   `form:"plan_id"`
+}
+
+// SubscriptionDetail 订阅详情
+type SubscriptionDetail struct {
+	model.Subscription
+	AppName string `json:"app_name"`
+}
+
+// ListSubscriptionsResponse 订阅列表响应
+type ListSubscriptionsResponse struct {
+	Total         int64                `json:"total"`
+	Subscriptions []SubscriptionDetail `json:"subscriptions"`
+}
+
+// ListMerchantSubscriptions 获取应用的订阅用户列表
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param request query ListSubscriptionsRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/subscriptions [get]
+func ListMerchantSubscriptions(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+	listSubscriptions(c, "subscriptions.merchant_api_key_id", apiKey.ID)
+}
+
+// CancelMerchantSubscription 商户取消用户订阅
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param subscriptionId path uint64 true "Subscription ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/subscriptions/{subscriptionId}/cancel [post]
+func CancelMerchantSubscription(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+	subscriptionID, _ := strconv.ParseUint(c.Param("subscriptionId"), 10, 64)
+	respondCancel(c, subscriptionID, "merchant_api_key_id", apiKey.ID, CancelledByMerchant)
+}
+
+// ListUserSubscriptions 获取当前用户的订阅列表
+// @Tags payment
+// @Produce json
+// @Param request query ListSubscriptionsRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/payment/subscriptions [get]
+func ListUserSubscriptions(c *gin.Context) {
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+	listSubscriptions(c, "subscriptions.payer_user_id", currentUser.ID)
+}
+
+// CancelUserSubscription 用户取消订阅，取消后不再自动扣款
+// @Tags payment
+// @Produce json
+// @Param id path uint64 true "Subscription ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/payment/subscriptions/{id}/cancel [post]
+func CancelUserSubscription(c *gin.Context) {
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+	subscriptionID, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	respondCancel(c, subscriptionID, "payer_user_id", currentUser.ID, CancelledByUser)
+}
+
+func listSubscriptions(c *gin.Context, ownerColumn string, ownerID uint64) {
+	var req ListSubscriptionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	query := db.DB(c.Request.Context()).
+		Table("subscriptions").
+		Joins("JOIN merchant_api_keys ON merchant_api_keys.id = subscriptions.merchant_api_key_id").
+		Where(ownerColumn+" = ?", ownerID)
+	if req.Status != "" {
+		query = query.Where("subscriptions.status = ?", req.Status)
+	}
+	if req.PlanID != 0 {
+		query = query.Where("subscriptions.plan_id = ?", req.PlanID)
+	}
+
+	var response ListSubscriptionsResponse
+	if err := query.Count(&response.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.
+		Select("subscriptions.*, merchant_api_keys.app_name").
+		Order("subscriptions.created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&response.Subscriptions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
+
+func respondCancel(c *gin.Context, subscriptionID uint64, ownerColumn string, ownerID uint64, reason string) {
+	sub, err := cancelSubscription(c.Request.Context(), subscriptionID, ownerColumn, ownerID, reason)
+	if err != nil {
+		if err.Error() == SubscriptionNotFound {
+			c.JSON(http.StatusNotFound, util.Err(SubscriptionNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(sub))
+}
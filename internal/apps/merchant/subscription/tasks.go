@@ -0,0 +1,188 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subscription
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// chargeResult 单笔订阅扣款结果
+type chargeResult struct {
+	sub   model.Subscription
+	order *model.Order
+	event model.WebhookEvent
+}
+
+// HandleChargeDueSubscriptions 对到期的订阅自动扣款
+// 余额不足或超出每日限额时按配置间隔重试，连续失败超过上限后自动取消订阅
+func HandleChargeDueSubscriptions(ctx context.Context, _ *asynq.Task) error {
+	retryHours, err := model.GetIntByKey(ctx, model.ConfigKeySubscriptionRetryHours)
+	if err != nil {
+		logger.ErrorF(ctx, "获取订阅重试间隔配置失败: %v", err)
+		return err
+	}
+	maxRetries, err := model.GetIntByKey(ctx, model.ConfigKeySubscriptionMaxRetries)
+	if err != nil {
+		logger.ErrorF(ctx, "获取订阅重试次数配置失败: %v", err)
+		return err
+	}
+
+	pageSize := 500
+	lastID := uint64(0)
+	totalCharged, totalFailed := 0, 0
+	now := time.Now()
+
+	for {
+		var subs []model.Subscription
+		if err := db.DB(ctx).
+			Where("id > ? AND status IN ? AND next_billing_at <= ?", lastID,
+				[]model.SubscriptionStatus{model.SubscriptionStatusActive, model.SubscriptionStatusPastDue}, now).
+			Order("id ASC").
+			Limit(pageSize).
+			Find(&subs).Error; err != nil {
+			logger.ErrorF(ctx, "查询到期订阅失败: %v", err)
+			return err
+		}
+
+		if len(subs) == 0 {
+			break
+		}
+
+		for _, s := range subs {
+			result, err := chargeDueSubscription(ctx, s.ID, now, time.Duration(retryHours)*time.Hour, maxRetries)
+			if err != nil {
+				logger.ErrorF(ctx, "订阅[ID:%d]扣款失败: %v", s.ID, err)
+				continue
+			}
+			if result == nil {
+				continue
+			}
+
+			if result.order != nil {
+				totalCharged++
+			} else {
+				totalFailed++
+			}
+		}
+
+		lastID = subs[len(subs)-1].ID
+	}
+
+	logger.InfoF(ctx, "订阅自动扣款完成，成功 %d 笔，失败 %d 笔", totalCharged, totalFailed)
+	return nil
+}
+
+// chargeDueSubscription 处理单笔到期订阅，并在同一事务内下发扣款与订阅事件，已被取消、已扣款或正在处理时返回 nil
+func chargeDueSubscription(ctx context.Context, subscriptionID uint64, now time.Time, retryInterval time.Duration, maxRetries int) (*chargeResult, error) {
+	var result *chargeResult
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = chargeDueSubscriptionInTx(tx, subscriptionID, now, retryInterval, maxRetries)
+		if err != nil || result == nil {
+			return err
+		}
+		if result.order != nil {
+			return dispatchChargeEvents(tx, &result.sub, result.order, result.event)
+		}
+		return dispatchSubscriptionEvent(tx, &result.sub, nil, result.event)
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// chargeDueSubscriptionInTx 在事务内扣款，余额不足或超出每日限额时记录失败并安排重试，付款方或商户被禁用时取消订阅
+func chargeDueSubscriptionInTx(tx *gorm.DB, subscriptionID uint64, now time.Time, retryInterval time.Duration, maxRetries int) (*chargeResult, error) {
+	var sub model.Subscription
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
+		Where("id = ? AND status <> ? AND next_billing_at <= ?", subscriptionID, model.SubscriptionStatusCancelled, now).
+		First(&sub).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var apiKey model.MerchantAPIKey
+	if err := apiKey.GetByID(tx, sub.MerchantAPIKeyID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := service.CancelSubscription(tx, &sub, CancelledAPIKeyGone); err != nil {
+				return nil, err
+			}
+			return &chargeResult{sub: sub, event: model.WebhookEventSubscriptionCancelled}, nil
+		}
+		return nil, err
+	}
+
+	// 扣款在保存点内执行，余额不足等业务失败时仅回滚本期扣款，继续记录失败状态
+	var order *model.Order
+	chargeErr := tx.Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = service.ChargeSubscription(tx, &sub, &apiKey, now)
+		return err
+	})
+	if chargeErr == nil {
+		return &chargeResult{sub: sub, order: order, event: model.WebhookEventSubscriptionRenewed}, nil
+	}
+
+	errMsg := chargeErr.Error()
+	if errMsg == common.SubscriptionPartyInactive {
+		if err := service.CancelSubscription(tx, &sub, CancelledInactive); err != nil {
+			return nil, err
+		}
+		return &chargeResult{sub: sub, event: model.WebhookEventSubscriptionCancelled}, nil
+	}
+	if errMsg != common.InsufficientBalance && errMsg != common.DailyLimitExceeded {
+		return nil, chargeErr
+	}
+
+	if sub.FailedAttempts+1 > maxRetries {
+		if err := service.CancelSubscription(tx, &sub, CancelledRetryExceed+": "+errMsg); err != nil {
+			return nil, err
+		}
+		return &chargeResult{sub: sub, event: model.WebhookEventSubscriptionCancelled}, nil
+	}
+
+	sub.Status = model.SubscriptionStatusPastDue
+	sub.FailedAttempts++
+	sub.LastError = errMsg
+	sub.NextBillingAt = now.Add(retryInterval)
+	if err := tx.Model(&model.Subscription{}).
+		Where("id = ?", sub.ID).
+		UpdateColumns(map[string]interface{}{
+			"status":          sub.Status,
+			"failed_attempts": sub.FailedAttempts,
+			"last_error":      sub.LastError,
+			"next_billing_at": sub.NextBillingAt,
+			"updated_at":      now,
+		}).Error; err != nil {
+		return nil, err
+	}
+	return &chargeResult{sub: sub, event: model.WebhookEventSubscriptionFailed}, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subscription
+
+import (
+	"context"
+	"errors"
+
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// cancelSubscription 取消归属于 ownerID 的订阅，并在同一事务内下发 subscription.cancelled 事件
+func cancelSubscription(ctx context.Context, subscriptionID uint64, ownerColumn string, ownerID uint64, reason string) (*model.Subscription, error) {
+	var sub model.Subscription
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND "+ownerColumn+" = ? AND status <> ?", subscriptionID, ownerID, model.SubscriptionStatusCancelled).
+			First(&sub).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(SubscriptionNotFound)
+			}
+			return err
+		}
+		if err := service.CancelSubscription(tx, &sub, reason); err != nil {
+			return err
+		}
+		return dispatchSubscriptionEvent(tx, &sub, nil, model.WebhookEventSubscriptionCancelled)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+// dispatchChargeEvents 在事务内下发扣款成功的 payment.succeeded 与对应的订阅事件
+func dispatchChargeEvents(tx *gorm.DB, sub *model.Subscription, order *model.Order, event model.WebhookEvent) error {
+	if err := service.DispatchWebhookEvent(tx, order.ClientID, model.WebhookEventPaymentSucceeded, order.ID, service.OrderEventData(order)); err != nil {
+		return err
+	}
+	return dispatchSubscriptionEvent(tx, sub, order, event)
+}
+
+func dispatchSubscriptionEvent(tx *gorm.DB, sub *model.Subscription, order *model.Order, event model.WebhookEvent) error {
+	var orderID uint64
+	if order != nil {
+		orderID = order.ID
+	}
+	return service.DispatchWebhookEvent(tx, sub.ClientID, event, orderID, service.SubscriptionEventData(sub, order))
+}
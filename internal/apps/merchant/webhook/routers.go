@@ -0,0 +1,143 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/merchant"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+)
+
+// ListDeliveriesRequest 投递记录查询请求
+type ListDeliveriesRequest struct {
+	Page     int    `form:"page" binding:"min=1"`
+	PageSize int    `form:"page_size" binding:"min=1,max=100"`
+	OrderID  uint64 `form:"order_id"`
+	Event    string `form:"event"`
+	Success  *bool  `form:"success"`
+}
+
+// ListDeliveriesResponse 投递记录列表响应
+type ListDeliveriesResponse struct {
+	Total      int64                   `json:"total"`
+	Deliveries []model.WebhookDelivery `json:"deliveries"`
+}
+
+// ListDeliveries 获取回调投递记录
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param request query ListDeliveriesRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/webhooks [get]
+func ListDeliveries(c *gin.Context) {
+	var req ListDeliveriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	query := db.DB(c.Request.Context()).Model(&model.WebhookDelivery{}).Where("api_key_id = ?", apiKey.ID)
+	if req.OrderID != 0 {
+		query = query.Where("order_id = ?", req.OrderID)
+	}
+	if req.Event != "" {
+		query = query.Where("event = ?", req.Event)
+	}
+	if req.Success != nil {
+		query = query.Where("success = ?", *req.Success)
+	}
+
+	var response ListDeliveriesResponse
+	if err := query.Count(&response.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&response.Deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
+
+// RedeliverWebhook 重新投递回调
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param deliveryId path uint64 true "投递记录 ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/webhooks/{deliveryId}/redeliver [post]
+func RedeliverWebhook(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	var delivery model.WebhookDelivery
+	if err := db.DB(c.Request.Context()).
+		Where("id = ? AND api_key_id = ?", c.Param("deliveryId"), apiKey.ID).
+		First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, util.Err(DeliveryNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	// 事件回调按原请求体重新投递
+	if delivery.Event != "" {
+		if err := service.RedeliverWebhookEvent(&delivery); err != nil {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, util.OKNil())
+		return
+	}
+
+	var order model.Order
+	if err := db.DB(c.Request.Context()).
+		Where("id = ? AND client_id = ? AND status IN ?", delivery.OrderID, apiKey.ClientID, model.OrderPaidStatuses).
+		First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, util.Err(OrderNotRedeliverable))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	if err := service.RedeliverMerchantNotify(order.ID, apiKey.ClientID); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
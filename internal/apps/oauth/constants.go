@@ -21,12 +21,20 @@ import (
 )
 
 const (
-	UserNameKey = "username"
-	UserIDKey   = "user_id"
-	UserObjKey  = "user_obj"
+	UserNameKey       = "username"
+	UserIDKey         = "user_id"
+	UserObjKey        = "user_obj"
+	SessionIDKey      = "session_id"
+	AccessTokenObjKey = "access_token_obj"
 )
 
 const (
 	OAuthStateCacheKeyFormat     = "oauth:state:%s"
 	OAuthStateCacheKeyExpiration = 10 * time.Minute
 )
+
+const (
+	UserSessionsCacheKeyFormat = "oauth:sessions:%d"
+	// SessionTouchInterval 会话最近活跃时间的刷新间隔，避免每次请求都写 Redis
+	SessionTouchInterval = time.Minute
+)
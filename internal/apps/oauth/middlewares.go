@@ -17,7 +17,9 @@ limitations under the License.
 package oauth
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/linux-do/credit/internal/common"
@@ -25,7 +27,9 @@ import (
 	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
 	"github.com/linux-do/credit/internal/otel_trace"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
 )
 
 func LoginRequired() gin.HandlerFunc {
@@ -41,6 +45,25 @@ func LoginRequired() gin.HandlerFunc {
 			return
 		}
 
+		// check session is still in the user's session index
+		// sessions created before the index existed carry no session id and must log in again
+		sessionID := GetSessionID(c)
+		if sessionID == "" {
+			_ = ClearSession(c)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error_msg": common.UnAuthorized, "data": nil})
+			return
+		}
+		active, err := touchSession(c, userId, sessionID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error_msg": err.Error(), "data": nil})
+			return
+		}
+		if !active {
+			_ = ClearSession(c)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error_msg": common.UnAuthorized, "data": nil})
+			return
+		}
+
 		// load user from db to make sure is active
 		var user model.User
 		tx := db.DB(ctx).Where("id = ? AND is_active = ?", userId, true).First(&user)
@@ -59,3 +82,55 @@ func LoginRequired() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// LoginOrTokenRequired 登录校验，同时接受 Authorization: Bearer 个人访问令牌
+// 使用令牌时要求令牌拥有指定权限，未携带令牌时按会话校验
+func LoginOrTokenRequired(scope model.AccessTokenScope) gin.HandlerFunc {
+	sessionAuth := LoginRequired()
+	return func(c *gin.Context) {
+		plaintext, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok {
+			sessionAuth(c)
+			return
+		}
+
+		// init trace
+		ctx, span := otel_trace.Start(c.Request.Context(), "LoginOrTokenRequired")
+		defer span.End()
+
+		// authenticate token
+		token, err := service.AuthenticateAccessToken(db.DB(ctx), strings.TrimSpace(plaintext), scope)
+		if err != nil {
+			switch err.Error() {
+			case common.AccessTokenInvalid:
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error_msg": err.Error(), "data": nil})
+			case common.AccessTokenScopeDenied:
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error_msg": err.Error(), "data": nil})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error_msg": err.Error(), "data": nil})
+			}
+			return
+		}
+
+		// load token owner to make sure is active
+		var user model.User
+		if err := db.DB(ctx).Where("id = ? AND is_active = ?", token.UserID, true).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error_msg": common.AccessTokenInvalid, "data": nil})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error_msg": err.Error(), "data": nil})
+			return
+		}
+
+		// log
+		logger.InfoF(ctx, "[LoginOrTokenRequired] %d %s token=%d", user.ID, user.Username, token.ID)
+
+		// set user and token info
+		util.SetToContext(c, UserObjKey, &user)
+		util.SetToContext(c, AccessTokenObjKey, token)
+
+		// next
+		c.Next()
+	}
+}
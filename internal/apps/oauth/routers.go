@@ -95,9 +95,16 @@ func Callback(c *gin.Context) {
 		return
 	}
 
+	sessionID, err := registerSession(c, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
 	session := sessions.Default(c)
 	session.Set(UserIDKey, user.ID)
 	session.Set(UserNameKey, user.Username)
+	session.Set(SessionIDKey, sessionID)
 	if err := session.Save(); err != nil {
 		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
 		return
@@ -118,6 +125,7 @@ type BasicUserInfo struct {
 	TotalCommunity   decimal.Decimal  `json:"total_community"`
 	CommunityBalance decimal.Decimal  `json:"community_balance"`
 	AvailableBalance decimal.Decimal  `json:"available_balance"`
+	HeldBalance      decimal.Decimal  `json:"held_balance"`
 	PayScore         int64            `json:"pay_score"`
 	IsPayKey         bool             `json:"is_pay_key"`
 	IsAdmin          bool             `json:"is_admin"`
@@ -165,6 +173,7 @@ func UserInfo(c *gin.Context) {
 			TotalCommunity:   user.TotalCommunity,
 			CommunityBalance: user.CommunityBalance,
 			AvailableBalance: user.AvailableBalance,
+			HeldBalance:      user.HeldBalance,
 			PayScore:         user.PayScore,
 			IsPayKey:         user.PayKey != "",
 			IsAdmin:          user.IsAdmin,
@@ -181,10 +190,14 @@ func UserInfo(c *gin.Context) {
 // @Success 200 {object} util.ResponseAny
 // @Router /api/v1/oauth/logout [get]
 func Logout(c *gin.Context) {
-	session := sessions.Default(c)
-	session.Options(util.GetSessionOptions(-1))
-	session.Clear()
-	if err := session.Save(); err != nil {
+	if userID, sessionID := GetUserIDFromContext(c), GetSessionID(c); userID > 0 && sessionID != "" {
+		if _, err := RevokeUserSession(c.Request.Context(), userID, sessionID); err != nil {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+			return
+		}
+	}
+
+	if err := ClearSession(c); err != nil {
 		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
 		return
 	}
@@ -0,0 +1,171 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/linux-do/credit/internal/config"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/redis/go-redis/v9"
+)
+
+// UserSession 用户登录会话，按用户保存在 Redis Hash 中，字段为会话 ID
+type UserSession struct {
+	ID         string    `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+// sessionTTL 会话有效期，与 Cookie 有效期一致
+func sessionTTL() time.Duration {
+	return time.Duration(config.Config.App.SessionAge) * time.Second
+}
+
+func userSessionsKey(userID uint64) string {
+	return db.PrefixedKey(fmt.Sprintf(UserSessionsCacheKeyFormat, userID))
+}
+
+// saveUserSession 写入会话索引，并按会话有效期续期整个索引
+func saveUserSession(ctx context.Context, userID uint64, s *UserSession) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	key := userSessionsKey(userID)
+	_, err = db.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, s.ID, data)
+		pipe.Expire(ctx, key, sessionTTL())
+		return nil
+	})
+	return err
+}
+
+// registerSession 登录成功后登记新会话，返回会话 ID
+func registerSession(c *gin.Context, userID uint64) (string, error) {
+	now := time.Now()
+	s := &UserSession{
+		ID:         uuid.NewString(),
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+	if err := saveUserSession(c.Request.Context(), userID, s); err != nil {
+		return "", err
+	}
+	return s.ID, nil
+}
+
+// touchSession 校验会话仍在索引中，并按间隔刷新最近活跃时间与来源
+// return: 会话已被吊销或过期时返回 false
+func touchSession(c *gin.Context, userID uint64, sessionID string) (bool, error) {
+	ctx := c.Request.Context()
+	data, err := db.Redis.HGet(ctx, userSessionsKey(userID), sessionID).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var s UserSession
+	if err := json.Unmarshal(data, &s); err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	if now.Sub(s.LastSeenAt) < SessionTouchInterval {
+		return true, nil
+	}
+	s.LastSeenAt = now
+	s.IP = c.ClientIP()
+	s.UserAgent = c.Request.UserAgent()
+	return true, saveUserSession(ctx, userID, &s)
+}
+
+// GetSessionID 获取当前请求的会话 ID
+func GetSessionID(c *gin.Context) string {
+	sessionID, _ := sessions.Default(c).Get(SessionIDKey).(string)
+	return sessionID
+}
+
+// ListUserSessions 列出用户的有效会话，按最近活跃时间倒序，并清理已过期的会话
+func ListUserSessions(ctx context.Context, userID uint64) ([]UserSession, error) {
+	key := userSessionsKey(userID)
+	entries, err := db.Redis.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	expiredBefore := time.Now().Add(-sessionTTL())
+	result := make([]UserSession, 0, len(entries))
+	var expired []string
+	for id, data := range entries {
+		var s UserSession
+		if err := json.Unmarshal([]byte(data), &s); err != nil || s.LastSeenAt.Before(expiredBefore) {
+			expired = append(expired, id)
+			continue
+		}
+		result = append(result, s)
+	}
+	if len(expired) > 0 {
+		if err := db.Redis.HDel(ctx, key, expired...).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastSeenAt.After(result[j].LastSeenAt)
+	})
+	return result, nil
+}
+
+// RevokeUserSession 吊销用户的指定会话
+// return: 会话不存在时返回 false
+func RevokeUserSession(ctx context.Context, userID uint64, sessionID string) (bool, error) {
+	n, err := db.Redis.HDel(ctx, userSessionsKey(userID), sessionID).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// RevokeUserSessions 吊销用户的全部会话
+func RevokeUserSessions(ctx context.Context, userID uint64) error {
+	return db.Redis.Del(ctx, userSessionsKey(userID)).Err()
+}
+
+// ClearSession 清除当前请求的会话 Cookie
+func ClearSession(c *gin.Context) error {
+	session := sessions.Default(c)
+	session.Options(util.GetSessionOptions(-1))
+	session.Clear()
+	return session.Save()
+}
@@ -30,8 +30,8 @@ import (
 type TransactionListRequest struct {
 	Page          int        `json:"page" form:"page" binding:"min=1"`
 	PageSize      int        `json:"page_size" form:"page_size" binding:"min=1,max=100"`
-	Type          string     `json:"type" form:"type" binding:"omitempty,oneof=receive payment transfer community online test distribute"`
-	Status        string     `json:"status" form:"status" binding:"omitempty,oneof=success pending failed expired disputing refund refused"`
+	Type          string     `json:"type" form:"type" binding:"omitempty,oneof=receive payment transfer community online test distribute refund escrow red_envelope gift_code"`
+	Status        string     `json:"status" form:"status" binding:"omitempty,oneof=success pending failed expired disputing refund refused partially_refunded cancelled authorized"`
 	ClientID      string     `json:"client_id" form:"client_id" binding:"omitempty"`
 	StartTime     *time.Time `json:"startTime" form:"startTime" binding:"omitempty"`
 	EndTime       *time.Time `json:"endTime" form:"endTime" binding:"omitempty,gtfield=StartTime"`
@@ -93,13 +93,17 @@ func ListTransactions(c *gin.Context) {
 		case model.OrderTypeCommunity:
 			// community 类型：查询当前用户作为收款方的 community 订单
 			baseQuery = baseQuery.Where("orders.type = ? AND orders.payee_user_id = ?", orderType, user.ID)
+		case model.OrderTypeRefund, model.OrderTypeEscrow, model.OrderTypeRedEnvelope, model.OrderTypeGiftCode:
+			// refund、escrow、red_envelope、gift_code 类型：查询与当前用户相关的退款子订单、担保交易、红包与礼品码（付款或收款）
+			baseQuery = baseQuery.Where("orders.type = ? AND (orders.payer_user_id = ? OR orders.payee_user_id = ?)", orderType, user.ID, user.ID)
 		case model.OrderTypeOnline:
-			// online 类型：商家可查看自己 client_id 的所有订单，普通用户只能查看与自己相关的订单
+			// online 类型：商家及其客服、财务成员可查看 client_id 的所有订单，普通用户只能查看与自己相关的订单
 			if req.ClientID != "" {
 				clientIDHandled = true
 				var count int64
 				if err := db.DB(c.Request.Context()).Model(&model.MerchantAPIKey{}).
-					Where("client_id = ? AND user_id = ?", req.ClientID, user.ID).
+					Where("client_id = ? AND client_id IN (?)", req.ClientID,
+						model.MemberClientIDs(db.DB(c.Request.Context()), user.ID, model.MerchantRoleSupport, model.MerchantRoleFinance)).
 					Count(&count).Error; err != nil {
 					c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
 					return
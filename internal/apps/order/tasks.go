@@ -18,15 +18,19 @@ package order
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"time"
 
 	"github.com/hibiken/asynq"
 	"github.com/linux-do/credit/internal/config"
 	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/db/idgen"
 	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
 	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 // HandleSyncOrdersToClickHouse 同步订单数据
@@ -127,3 +131,255 @@ func batchInsertToClickHouse(ctx context.Context, orders []model.Order) error {
 
 	return batch.Send()
 }
+
+// reconcileSettledStatuses 已完成资金划转的订单状态
+var reconcileSettledStatuses = []model.OrderStatus{
+	model.OrderStatusSuccess,
+	model.OrderStatusDisputing,
+	model.OrderStatusRefused,
+	model.OrderStatusRefund,
+	model.OrderStatusPartiallyRefunded,
+}
+
+// reconcileExpectedSQL 根据订单推算用户余额与累计字段
+// 收款方：商户订单与分发按扣除手续费后的金额入账，转账与社区积分按订单金额入账
+// 付款方：按订单金额扣款
+// 退款子订单：商户扣回退款金额减去退回的手续费，原付款方收到全额退款
+// 未生成退款子订单的历史已退款订单（refunded_amount 为 0）按原路全额退回处理
+// 预授权订单：付款方按订单金额从可用余额转入冻结余额
+// 担保交易：担保中（含争议中）同预授权订单，放款后付款方计入累计转账、收款方计入累计收入，退回付款方后不产生变动
+// 红包：发起时发起人计入累计转账，领取人按领取金额计入累计收入，过期退回时冲减发起人累计转账
+// 礼品码：商户出资计入累计转账，兑换人计入累计收入，过期退回时冲减商户累计转账
+const reconcileExpectedSQL = `
+SELECT user_id,
+	COALESCE(SUM(available), 0) AS available_balance,
+	COALESCE(SUM(receive), 0) AS total_receive,
+	COALESCE(SUM(payment), 0) AS total_payment,
+	COALESCE(SUM(transfer), 0) AS total_transfer,
+	COALESCE(SUM(community), 0) AS total_community,
+	COALESCE(SUM(held), 0) AS held_balance
+FROM (
+	SELECT payee_user_id AS user_id,
+		(CASE WHEN type IN @fee_types THEN amount - fee ELSE amount END) - (CASE WHEN status = @refund AND refunded_amount = 0 THEN amount ELSE 0 END) AS available,
+		(CASE WHEN type IN @fee_types THEN amount - fee ELSE amount END) - (CASE WHEN status = @refund AND refunded_amount = 0 THEN amount ELSE 0 END) AS receive,
+		0 AS payment,
+		0 AS transfer,
+		CASE WHEN type = @community AND order_name = @community_name THEN amount ELSE 0 END AS community,
+		0 AS held
+	FROM orders
+	WHERE payee_user_id IN @user_ids AND status IN @statuses AND type IN @payee_types
+	UNION ALL
+	SELECT payer_user_id AS user_id,
+		(CASE WHEN status = @refund AND refunded_amount = 0 THEN amount ELSE 0 END) - amount AS available,
+		0 AS receive,
+		CASE WHEN type IN @fee_types THEN amount - (CASE WHEN status = @refund AND refunded_amount = 0 THEN amount ELSE 0 END) ELSE 0 END AS payment,
+		CASE WHEN type = @transfer THEN amount ELSE 0 END AS transfer,
+		0 AS community,
+		0 AS held
+	FROM orders
+	WHERE payer_user_id IN @user_ids AND status IN @statuses AND type IN @payer_types
+	UNION ALL
+	SELECT payer_user_id AS user_id, fee - amount AS available, fee - amount AS receive, 0 AS payment, 0 AS transfer, 0 AS community, 0 AS held
+	FROM orders
+	WHERE payer_user_id IN @user_ids AND status = @success AND type = @refund_type
+	UNION ALL
+	SELECT payee_user_id AS user_id, amount AS available, 0 AS receive, -amount AS payment, 0 AS transfer, 0 AS community, 0 AS held
+	FROM orders
+	WHERE payee_user_id IN @user_ids AND status = @success AND type = @refund_type
+	UNION ALL
+	SELECT payer_user_id AS user_id, -amount AS available, 0 AS receive, 0 AS payment, 0 AS transfer, 0 AS community, amount AS held
+	FROM orders
+	WHERE payer_user_id IN @user_ids AND status = @authorized AND type IN @payer_types
+	UNION ALL
+	SELECT payer_user_id AS user_id, -amount AS available, 0 AS receive, 0 AS payment, 0 AS transfer, 0 AS community, amount AS held
+	FROM orders
+	WHERE payer_user_id IN @user_ids AND status IN @escrow_held AND type = @escrow
+	UNION ALL
+	SELECT payer_user_id AS user_id, -amount AS available, 0 AS receive, 0 AS payment, amount AS transfer, 0 AS community, 0 AS held
+	FROM orders
+	WHERE payer_user_id IN @user_ids AND status IN @escrow_released AND type = @escrow
+	UNION ALL
+	SELECT payee_user_id AS user_id, amount AS available, amount AS receive, 0 AS payment, 0 AS transfer, 0 AS community, 0 AS held
+	FROM orders
+	WHERE payee_user_id IN @user_ids AND status IN @escrow_released AND type = @escrow
+	UNION ALL
+	SELECT payer_user_id AS user_id, -amount AS available, 0 AS receive, 0 AS payment, amount AS transfer, 0 AS community, 0 AS held
+	FROM orders
+	WHERE payer_user_id IN @user_ids AND payee_user_id = @platform AND status = @success AND type = @red_envelope
+	UNION ALL
+	SELECT payee_user_id AS user_id, amount AS available, amount AS receive, 0 AS payment, 0 AS transfer, 0 AS community, 0 AS held
+	FROM orders
+	WHERE payee_user_id IN @user_ids AND payer_user_id <> @platform AND status = @success AND type = @red_envelope
+	UNION ALL
+	SELECT payee_user_id AS user_id, amount AS available, 0 AS receive, 0 AS payment, -amount AS transfer, 0 AS community, 0 AS held
+	FROM orders
+	WHERE payee_user_id IN @user_ids AND payer_user_id = @platform AND status = @success AND type = @red_envelope
+	UNION ALL
+	SELECT payer_user_id AS user_id, -amount AS available, 0 AS receive, 0 AS payment, amount AS transfer, 0 AS community, 0 AS held
+	FROM orders
+	WHERE payer_user_id IN @user_ids AND order_name = @gift_code_fund AND status = @success AND type = @gift_code
+	UNION ALL
+	SELECT payee_user_id AS user_id, amount AS available, amount AS receive, 0 AS payment, 0 AS transfer, 0 AS community, 0 AS held
+	FROM orders
+	WHERE payee_user_id IN @user_ids AND order_name = @gift_code_redeem AND status = @success AND type = @gift_code
+	UNION ALL
+	SELECT payee_user_id AS user_id, amount AS available, 0 AS receive, 0 AS payment, -amount AS transfer, 0 AS community, 0 AS held
+	FROM orders
+	WHERE payee_user_id IN @user_ids AND order_name = @gift_code_refund AND status = @success AND type = @gift_code
+) legs
+GROUP BY user_id`
+
+type reconcileBalances struct {
+	UserID           uint64
+	AvailableBalance decimal.Decimal
+	TotalReceive     decimal.Decimal
+	TotalPayment     decimal.Decimal
+	TotalTransfer    decimal.Decimal
+	TotalCommunity   decimal.Decimal
+	HeldBalance      decimal.Decimal
+}
+
+// HandleReconcileBalances 余额对账
+// 按订单重新计算每个用户的可用余额与累计字段，并核对可用余额与流水分录合计，差异写入对账记录
+func HandleReconcileBalances(ctx context.Context, _ *asynq.Task) error {
+	runID := idgen.NextUint64ID()
+	logger.InfoF(ctx, "开始余额对账: run_id=%d", runID)
+
+	pageSize := 1000
+	lastID := uint64(0)
+	totalChecked := 0
+	totalFindings := 0
+
+	for {
+		var users []model.User
+		var findings []model.ReconciliationFinding
+
+		// 同一批次在可重复读快照中读取，避免对账期间的正常交易造成误报
+		if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Select("id, available_balance, held_balance, total_receive, total_payment, total_transfer, total_community").
+				Where("id > ?", lastID).
+				Order("id ASC").
+				Limit(pageSize).
+				Find(&users).Error; err != nil {
+				return err
+			}
+			if len(users) == 0 {
+				return nil
+			}
+
+			var err error
+			findings, err = reconcileUsers(tx, runID, users)
+			return err
+		}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}); err != nil {
+			logger.ErrorF(ctx, "余额对账失败: last_id=%d, error=%v", lastID, err)
+			return err
+		}
+
+		if len(users) == 0 {
+			break
+		}
+
+		if len(findings) > 0 {
+			if err := db.DB(ctx).Create(&findings).Error; err != nil {
+				logger.ErrorF(ctx, "写入对账差异失败: %v", err)
+				return err
+			}
+		}
+
+		totalChecked += len(users)
+		totalFindings += len(findings)
+		lastID = users[len(users)-1].ID
+	}
+
+	logger.InfoF(ctx, "余额对账完成: run_id=%d, 共核对 %d 个用户，发现 %d 处差异", runID, totalChecked, totalFindings)
+	return nil
+}
+
+// reconcileUsers 核对一批用户，返回差异记录
+func reconcileUsers(tx *gorm.DB, runID uint64, users []model.User) ([]model.ReconciliationFinding, error) {
+	userIDs := make([]uint64, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+
+	var expectedRows []reconcileBalances
+	if err := tx.Raw(reconcileExpectedSQL, map[string]interface{}{
+		"user_ids":         userIDs,
+		"statuses":         reconcileSettledStatuses,
+		"fee_types":        []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline, model.OrderTypeDistribute},
+		"payee_types":      []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline, model.OrderTypeDistribute, model.OrderTypeTransfer, model.OrderTypeCommunity},
+		"payer_types":      []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline, model.OrderTypeDistribute, model.OrderTypeTransfer},
+		"refund":           model.OrderStatusRefund,
+		"authorized":       model.OrderStatusAuthorized,
+		"escrow":           model.OrderTypeEscrow,
+		"red_envelope":     model.OrderTypeRedEnvelope,
+		"platform":         model.PlatformAccountID,
+		"gift_code":        model.OrderTypeGiftCode,
+		"gift_code_fund":   model.OrderNameGiftCodeFund,
+		"gift_code_redeem": model.OrderNameGiftCodeRedeem,
+		"gift_code_refund": model.OrderNameGiftCodeRefund,
+		"escrow_held":      []model.OrderStatus{model.OrderStatusAuthorized, model.OrderStatusDisputing},
+		"escrow_released":  []model.OrderStatus{model.OrderStatusSuccess, model.OrderStatusRefused},
+		"success":          model.OrderStatusSuccess,
+		"refund_type":      model.OrderTypeRefund,
+		"transfer":         model.OrderTypeTransfer,
+		"community":        model.OrderTypeCommunity,
+		"community_name":   model.OrderNameCommunityUpdate,
+	}).Scan(&expectedRows).Error; err != nil {
+		return nil, err
+	}
+
+	var ledgerRows []struct {
+		UserID uint64
+		Total  decimal.Decimal
+	}
+	if err := tx.Model(&model.LedgerEntry{}).
+		Select("user_id, COALESCE(SUM(amount), 0) AS total").
+		Where("user_id IN ?", userIDs).
+		Group("user_id").
+		Scan(&ledgerRows).Error; err != nil {
+		return nil, err
+	}
+
+	expectedMap := make(map[uint64]reconcileBalances, len(expectedRows))
+	for _, row := range expectedRows {
+		expectedMap[row.UserID] = row
+	}
+	ledgerMap := make(map[uint64]decimal.Decimal, len(ledgerRows))
+	for _, row := range ledgerRows {
+		ledgerMap[row.UserID] = row.Total
+	}
+
+	var findings []model.ReconciliationFinding
+	for _, user := range users {
+		expected := expectedMap[user.ID]
+		checks := []struct {
+			field    model.ReconciliationField
+			expected decimal.Decimal
+			actual   decimal.Decimal
+		}{
+			{model.ReconciliationFieldAvailableBalance, expected.AvailableBalance, user.AvailableBalance},
+			{model.ReconciliationFieldTotalReceive, expected.TotalReceive, user.TotalReceive},
+			{model.ReconciliationFieldTotalPayment, expected.TotalPayment, user.TotalPayment},
+			{model.ReconciliationFieldTotalTransfer, expected.TotalTransfer, user.TotalTransfer},
+			{model.ReconciliationFieldTotalCommunity, expected.TotalCommunity, user.TotalCommunity},
+			{model.ReconciliationFieldHeldBalance, expected.HeldBalance, user.HeldBalance},
+			{model.ReconciliationFieldLedgerBalance, ledgerMap[user.ID], user.AvailableBalance},
+		}
+		for _, check := range checks {
+			if check.expected.Equal(check.actual) {
+				continue
+			}
+			findings = append(findings, model.ReconciliationFinding{
+				RunID:    runID,
+				UserID:   user.ID,
+				Field:    check.field,
+				Expected: check.expected,
+				Actual:   check.actual,
+				Diff:     check.actual.Sub(check.expected),
+			})
+		}
+	}
+
+	return findings, nil
+}
@@ -16,6 +16,8 @@ limitations under the License.
 
 package payment
 
+import "time"
+
 const (
 	APIKeyObjKey          = "payment_api_key_obj"
 	CreateOrderRequestKey = "payment_create_order_request"
@@ -26,4 +28,30 @@ const (
 	OrderMerchantIDCacheKeyFormat = "payment:order:%s"
 	// OrderExpireKeyFormat Redis key 格式，用于订单过期监听，key中包含订单ID
 	OrderExpireKeyFormat = "payment:order:expire:%d"
+	// OrderTokenKeyFormat Redis key 格式，用于存储订单ID对应的加密订单号，key中包含订单ID
+	OrderTokenKeyFormat = "payment:order:token:%d"
+	// IdempotencyKeyFormat Redis key 格式，用于存储幂等键对应的请求指纹与响应，key中包含调用方范围与幂等键
+	IdempotencyKeyFormat = "payment:idempotency:%s:%s"
+	// PaymentRequestExpireKeyFormat Redis key 格式，用于收款请求过期监听，key中包含收款请求ID
+	PaymentRequestExpireKeyFormat = "payment:request:expire:%d"
+	// SignNonceKeyFormat Redis key 格式，用于签名请求 nonce 去重，key中包含ClientID与nonce
+	SignNonceKeyFormat = "payment:sign:nonce:%s:%s"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotencyMaxKeyLength   = 64
+	idempotencyProcessingFlag = "processing"
+	// idempotencyProcessingLease 处理中记录的租约时长，处理异常中断时到期自动释放
+	idempotencyProcessingLease = 5 * time.Minute
+	signNonceMaxLength         = 64
+)
+
+// 事件回调请求头
+const (
+	WebhookEventHeader     = "X-Credit-Event"
+	WebhookEventIDHeader   = "X-Credit-Event-Id"
+	WebhookTimestampHeader = "X-Credit-Timestamp"
+	WebhookSignatureHeader = "X-Credit-Signature"
 )
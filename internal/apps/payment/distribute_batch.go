@@ -0,0 +1,578 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payment
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/task"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MerchantDistributeBatchRequest 商户批量分发请求
+// 使用 multipart/form-data 上传时，file 为 CSV 文件，表头需包含 user_id、username、amount，可选 out_trade_no、remark
+type MerchantDistributeBatchRequest struct {
+	MerchantBatchNo *string                     `json:"out_batch_no" form:"out_batch_no" binding:"omitempty,min=1,max=64"`
+	Remark          string                      `json:"remark" form:"remark" binding:"max=100"`
+	Items           []MerchantDistributeRequest `json:"items" form:"-" binding:"dive"`
+}
+
+// DistributeBatchItemError 批量分发明细校验错误
+type DistributeBatchItemError struct {
+	Seq      int    `json:"seq"`
+	ErrorMsg string `json:"error_msg"`
+}
+
+// QueryDistributeBatchRequest 批量分发批次查询参数，batch_no 与 out_batch_no 二选一
+type QueryDistributeBatchRequest struct {
+	BatchNo         uint64 `form:"batch_no"`
+	MerchantBatchNo string `form:"out_batch_no" binding:"required_without=BatchNo,max=64"`
+}
+
+// DistributeBatchDetail 批量分发批次详情
+type DistributeBatchDetail struct {
+	model.DistributeBatch
+	Items []model.DistributeBatchItem `json:"items"`
+}
+
+// MerchantDistributeBatch 商户批量分发接口，校验全部明细与商户余额后异步逐条分发
+// 应用的每日、每月及单个收款人分发限额在逐条分发时检查，超限的明细标记为失败
+// @Tags payment
+// @Accept json,mpfd
+// @Produce json
+// @Param Authorization header string true "Basic Auth (base64(client_id:client_secret))"
+// @Param Idempotency-Key header string false "幂等键，重试时保持不变"
+// @Param request body MerchantDistributeBatchRequest true "批量分发请求"
+// @Success 200 {object} util.ResponseAny
+// @Router /pay/distribute/batch [post]
+func MerchantDistributeBatch(c *gin.Context) {
+	var req MerchantDistributeBatchRequest
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		if err := c.ShouldBind(&req); err != nil {
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+			return
+		}
+		items, err := parseDistributeCSV(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+			return
+		}
+		req.Items = items
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	if len(req.Items) == 0 {
+		c.JSON(http.StatusBadRequest, util.Err(DistributeBatchEmpty))
+		return
+	}
+
+	maxItems, errGet := model.GetIntByKey(c.Request.Context(), model.ConfigKeyDistributeBatchMaxItems)
+	if errGet != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(errGet.Error()))
+		return
+	}
+	if len(req.Items) > maxItems {
+		c.JSON(http.StatusBadRequest, util.Err(DistributeBatchTooLarge))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	if !apiKey.AllowsDistributeIP(c.ClientIP()) {
+		c.JSON(http.StatusForbidden, util.Err(DistributeIPNotAllowed))
+		return
+	}
+
+	var batch model.DistributeBatch
+	var itemErrors []DistributeBatchItemError
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var merchantUser model.User
+		if err := tx.Where("id = ? AND is_active = ?", apiKey.UserID, true).
+			First(&merchantUser).Error; err != nil {
+			return errors.New(MerchantInfoNotFound)
+		}
+
+		var totalAmount decimal.Decimal
+		itemErrors, totalAmount = validateDistributeItems(tx, apiKey, &merchantUser, req.Items)
+		if len(itemErrors) > 0 {
+			return errors.New(DistributeBatchInvalid)
+		}
+
+		if merchantUser.AvailableBalance.LessThan(totalAmount) {
+			return errors.New(common.InsufficientBalance)
+		}
+
+		if req.MerchantBatchNo != nil {
+			var count int64
+			if err := tx.Model(&model.DistributeBatch{}).
+				Where("client_id = ? AND merchant_batch_no = ?", apiKey.ClientID, *req.MerchantBatchNo).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return errors.New(DistributeBatchNoExists)
+			}
+		}
+
+		batch = model.DistributeBatch{
+			MerchantAPIKeyID: apiKey.ID,
+			ClientID:         apiKey.ClientID,
+			MerchantBatchNo:  req.MerchantBatchNo,
+			TotalCount:       len(req.Items),
+			TotalAmount:      totalAmount,
+			Remark:           req.Remark,
+			Status:           model.DistributeBatchStatusPending,
+		}
+		if err := tx.Create(&batch).Error; err != nil {
+			return err
+		}
+
+		items := make([]model.DistributeBatchItem, len(req.Items))
+		for i, item := range req.Items {
+			items[i] = model.DistributeBatchItem{
+				BatchID:           batch.ID,
+				Seq:               i + 1,
+				RecipientID:       item.RecipientID,
+				RecipientUsername: item.RecipientUsername,
+				Amount:            item.Amount,
+				MerchantOrderNo:   item.MerchantOrderNo,
+				Remark:            item.Remark,
+				Status:            model.DistributeBatchItemStatusPending,
+			}
+		}
+		if err := tx.CreateInBatches(items, 500).Error; err != nil {
+			return err
+		}
+
+		payload, _ := json.Marshal(map[string]interface{}{"batch_id": batch.ID})
+		return model.CreateOutboxEvent(tx, &model.OutboxEvent{
+			TaskType:       task.DistributeBatchTask,
+			Payload:        string(payload),
+			Queue:          task.QueueDefault,
+			MaxRetry:       5,
+			TimeoutSeconds: 600,
+		})
+	}); err != nil {
+		errMsg := err.Error()
+		switch errMsg {
+		case DistributeBatchInvalid:
+			c.JSON(http.StatusBadRequest, util.Response[[]DistributeBatchItemError]{ErrorMsg: errMsg, Data: itemErrors})
+		case MerchantInfoNotFound, DistributeBatchNoExists, common.InsufficientBalance:
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(gin.H{
+		"batch_no":     strconv.FormatUint(batch.ID, 10),
+		"out_batch_no": batch.MerchantBatchNo,
+		"status":       batch.Status,
+		"total_count":  batch.TotalCount,
+		"total_money":  batch.TotalAmount.StringFixed(2),
+	}))
+}
+
+// QueryDistributeBatch 查询批量分发批次状态与逐条结果
+// @Tags payment
+// @Produce json
+// @Param Authorization header string true "Basic Auth (base64(client_id:client_secret))"
+// @Param request query QueryDistributeBatchRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /pay/distribute/batch [get]
+func QueryDistributeBatch(c *gin.Context) {
+	var req QueryDistributeBatchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	query := db.DB(c.Request.Context()).Where("client_id = ?", apiKey.ClientID)
+	if req.BatchNo != 0 {
+		query = query.Where("id = ?", req.BatchNo)
+	} else {
+		query = query.Where("merchant_batch_no = ?", req.MerchantBatchNo)
+	}
+
+	var detail DistributeBatchDetail
+	if err := query.First(&detail.DistributeBatch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, util.Err(DistributeBatchNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	if err := db.DB(c.Request.Context()).
+		Where("batch_id = ?", detail.ID).
+		Order("seq ASC").
+		Find(&detail.Items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(detail))
+}
+
+// parseDistributeCSV 解析上传的 CSV 分发明细
+func parseDistributeCSV(c *gin.Context) ([]MerchantDistributeRequest, error) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New(DistributeCSVInvalid)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range []string{"user_id", "username", "amount"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.New(DistributeCSVInvalid)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var items []MerchantDistributeRequest
+	for line := 2; ; line++ {
+		record, errRead := reader.Read()
+		if errors.Is(errRead, io.EOF) {
+			break
+		}
+		if errRead != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", line, errRead)
+		}
+
+		recipientID, errParse := strconv.ParseUint(field(record, "user_id"), 10, 64)
+		if errParse != nil {
+			return nil, fmt.Errorf("第 %d 行: user_id 格式错误", line)
+		}
+		amount, errParse := decimal.NewFromString(field(record, "amount"))
+		if errParse != nil {
+			return nil, fmt.Errorf("第 %d 行: amount 格式错误", line)
+		}
+
+		item := MerchantDistributeRequest{
+			RecipientID:       recipientID,
+			RecipientUsername: field(record, "username"),
+			Amount:            amount,
+			Remark:            field(record, "remark"),
+		}
+		if orderNo := field(record, "out_trade_no"); orderNo != "" {
+			item.MerchantOrderNo = &orderNo
+		}
+		if errValidate := binding.Validator.ValidateStruct(&item); errValidate != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", line, errValidate)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// validateDistributeItems 校验全部分发明细，返回逐条错误与分发总额
+func validateDistributeItems(tx *gorm.DB, apiKey *model.MerchantAPIKey, merchantUser *model.User, items []MerchantDistributeRequest) ([]DistributeBatchItemError, decimal.Decimal) {
+	var itemErrors []DistributeBatchItemError
+	totalAmount := decimal.Zero
+
+	recipientIDs := make([]uint64, 0, len(items))
+	var orderNos []string
+	for _, item := range items {
+		recipientIDs = append(recipientIDs, item.RecipientID)
+		if item.MerchantOrderNo != nil {
+			orderNos = append(orderNos, *item.MerchantOrderNo)
+		}
+	}
+
+	var recipients []model.User
+	if err := tx.Select("id, username").Where("id IN ?", recipientIDs).Find(&recipients).Error; err != nil {
+		return []DistributeBatchItemError{{ErrorMsg: err.Error()}}, totalAmount
+	}
+	usernames := make(map[uint64]string, len(recipients))
+	for _, u := range recipients {
+		usernames[u.ID] = u.Username
+	}
+
+	existingOrderNos := make(map[string]bool)
+	if len(orderNos) > 0 {
+		var existing []string
+		if err := tx.Model(&model.Order{}).
+			Where("client_id = ? AND merchant_order_no IN ?", apiKey.ClientID, orderNos).
+			Pluck("merchant_order_no", &existing).Error; err != nil {
+			return []DistributeBatchItemError{{ErrorMsg: err.Error()}}, totalAmount
+		}
+		for _, orderNo := range existing {
+			existingOrderNos[orderNo] = true
+		}
+	}
+
+	seenOrderNos := make(map[string]bool)
+	for i, item := range items {
+		var errMsg string
+		if err := util.ValidateAmount(item.Amount); err != nil {
+			errMsg = err.Error()
+		} else if apiKey.DistributeMaxAmount.IsPositive() && item.Amount.GreaterThan(apiKey.DistributeMaxAmount) {
+			errMsg = common.DistributeAmountExceeded
+		} else if username, ok := usernames[item.RecipientID]; !ok || username != item.RecipientUsername {
+			errMsg = RecipientNotFound
+		} else if item.RecipientID == merchantUser.ID {
+			errMsg = CannotTransferToSelf
+		} else if item.MerchantOrderNo != nil && existingOrderNos[*item.MerchantOrderNo] {
+			errMsg = MerchantOrderNoExists
+		} else if item.MerchantOrderNo != nil && seenOrderNos[*item.MerchantOrderNo] {
+			errMsg = DistributeOrderNoDuplicate
+		}
+
+		if item.MerchantOrderNo != nil {
+			seenOrderNos[*item.MerchantOrderNo] = true
+		}
+		if errMsg != "" {
+			itemErrors = append(itemErrors, DistributeBatchItemError{Seq: i + 1, ErrorMsg: errMsg})
+			continue
+		}
+		totalAmount = totalAmount.Add(item.Amount)
+	}
+
+	return itemErrors, totalAmount
+}
+
+// HandleDistributeBatch 处理批量分发任务，逐条分发待处理明细，全部处理后下发 distribute.batch_completed 事件
+// 每条明细在独立事务内完成分发与状态更新，任务重试时仅处理仍为待处理的明细
+func HandleDistributeBatch(ctx context.Context, t *asynq.Task) error {
+	var payload struct {
+		BatchID uint64 `json:"batch_id"`
+	}
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	var batch model.DistributeBatch
+	if err := db.DB(ctx).Where("id = ?", payload.BatchID).First(&batch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.ErrorF(ctx, "批量分发批次[ID:%d]不存在", payload.BatchID)
+			return nil
+		}
+		return err
+	}
+	if batch.Status == model.DistributeBatchStatusCompleted {
+		return nil
+	}
+
+	var apiKey model.MerchantAPIKey
+	apiKeyErr := apiKey.GetByID(db.DB(ctx), batch.MerchantAPIKeyID)
+	if apiKeyErr != nil && !errors.Is(apiKeyErr, gorm.ErrRecordNotFound) {
+		return apiKeyErr
+	}
+
+	if err := db.DB(ctx).Model(&model.DistributeBatch{}).
+		Where("id = ? AND status = ?", batch.ID, model.DistributeBatchStatusPending).
+		UpdateColumns(map[string]interface{}{
+			"status":     model.DistributeBatchStatusProcessing,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+		return err
+	}
+
+	for {
+		var items []model.DistributeBatchItem
+		if err := db.DB(ctx).
+			Where("batch_id = ? AND status = ?", batch.ID, model.DistributeBatchItemStatusPending).
+			Order("seq ASC").
+			Limit(100).
+			Find(&items).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			break
+		}
+
+		for i := range items {
+			item := &items[i]
+			if apiKeyErr != nil {
+				if err := failDistributeBatchItem(ctx, item, MerchantInfoNotFound); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := distributeBatchItem(ctx, &apiKey, item); err != nil {
+				if errFail := failDistributeBatchItem(ctx, item, err.Error()); errFail != nil {
+					return errFail
+				}
+				continue
+			}
+		}
+	}
+
+	// 批次完成状态与完成事件在同一事务内写入
+	completed := false
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.DistributeBatch{}).
+			Where("id = ? AND status <> ?", batch.ID, model.DistributeBatchStatusCompleted).
+			UpdateColumns(map[string]interface{}{
+				"status":       model.DistributeBatchStatusCompleted,
+				"completed_at": now,
+				"updated_at":   now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		completed = true
+
+		if err := tx.Where("id = ?", batch.ID).First(&batch).Error; err != nil {
+			return err
+		}
+		return service.DispatchWebhookEvent(tx, batch.ClientID, model.WebhookEventDistributeBatchDone, 0, distributeBatchEventData(&batch))
+	}); err != nil {
+		return err
+	}
+
+	if completed {
+		logger.InfoF(ctx, "批量分发批次[ID:%d]处理完成: 成功 %d 条, 失败 %d 条", batch.ID, batch.SuccessCount, batch.FailedCount)
+	}
+	return nil
+}
+
+// distributeBatchItem 分发单条明细，明细已被处理时跳过
+func distributeBatchItem(ctx context.Context, apiKey *model.MerchantAPIKey, item *model.DistributeBatchItem) error {
+	return db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked model.DistributeBatchItem
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", item.ID, model.DistributeBatchItemStatusPending).
+			First(&locked).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		order, err := distributeInTx(tx, apiKey, &MerchantDistributeRequest{
+			RecipientID:       item.RecipientID,
+			RecipientUsername: item.RecipientUsername,
+			Amount:            item.Amount,
+			MerchantOrderNo:   item.MerchantOrderNo,
+			Remark:            item.Remark,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.DistributeBatchItem{}).
+			Where("id = ?", item.ID).
+			UpdateColumns(map[string]interface{}{
+				"status":     model.DistributeBatchItemStatusSuccess,
+				"order_id":   order.ID,
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.DistributeBatch{}).
+			Where("id = ?", item.BatchID).
+			UpdateColumns(map[string]interface{}{
+				"success_count":  gorm.Expr("success_count + 1"),
+				"success_amount": gorm.Expr("success_amount + ?", item.Amount),
+				"updated_at":     time.Now(),
+			}).Error
+	})
+}
+
+// failDistributeBatchItem 将明细标记为失败并累计批次失败数
+func failDistributeBatchItem(ctx context.Context, item *model.DistributeBatchItem, errMsg string) error {
+	return db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.DistributeBatchItem{}).
+			Where("id = ? AND status = ?", item.ID, model.DistributeBatchItemStatusPending).
+			UpdateColumns(map[string]interface{}{
+				"status":     model.DistributeBatchItemStatusFailed,
+				"error_msg":  errMsg,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		return tx.Model(&model.DistributeBatch{}).
+			Where("id = ?", item.BatchID).
+			UpdateColumns(map[string]interface{}{
+				"failed_count": gorm.Expr("failed_count + 1"),
+				"updated_at":   time.Now(),
+			}).Error
+	})
+}
+
+// distributeBatchEventData 构建批量分发完成事件数据
+func distributeBatchEventData(batch *model.DistributeBatch) map[string]interface{} {
+	return map[string]interface{}{
+		"batch_no":      strconv.FormatUint(batch.ID, 10),
+		"out_batch_no":  batch.MerchantBatchNo,
+		"status":        batch.Status,
+		"total_count":   batch.TotalCount,
+		"success_count": batch.SuccessCount,
+		"failed_count":  batch.FailedCount,
+		"total_money":   batch.TotalAmount.StringFixed(2),
+		"success_money": batch.SuccessAmount.StringFixed(2),
+	}
+}
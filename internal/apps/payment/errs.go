@@ -17,12 +17,44 @@ limitations under the License.
 package payment
 
 const (
-	OrderNotFound        = "订单不存在或已完成"
-	OrderStatusInvalid   = "订单状态不允许支付"
-	OrderExpired         = "订单已过期"
-	MerchantInfoNotFound = "商户信息不存在"
-	RecipientNotFound    = "收款人不存在"
-	OrderNoFormatError   = "订单号格式错误"
-	CannotTransferToSelf = "不能转账给自己"
-	PayConfigNotFound    = "支付配置不存在"
+	OrderNotFound          = "订单不存在或已完成"
+	OrderStatusInvalid     = "订单状态不允许支付"
+	OrderExpired           = "订单已过期"
+	MerchantInfoNotFound   = "商户信息不存在"
+	RecipientNotFound      = "收款人不存在"
+	OrderNoFormatError     = "订单号格式错误"
+	CannotTransferToSelf   = "不能转账给自己"
+	PayConfigNotFound      = "支付配置不存在"
+	MerchantOrderNoExists  = "商户订单号已存在"
+	OrderNotCancelable     = "订单不存在或不可取消"
+	OrderCancelled         = "订单已被商户取消"
+	OrderNotAuthorized     = "订单不存在或不处于待确认收款状态"
+	EscrowNotFound         = "担保交易不存在或已完成"
+	PaymentRequestNotFound = "收款请求不存在或已处理"
+	PayerNotFound          = "付款人不存在"
+	CannotRequestFromSelf  = "不能向自己发起收款请求"
+	ExpireMinutesInvalid   = "expire_minutes 必须为正整数"
+	ExpireMinutesOutRange  = "expire_minutes 超出系统允许的范围"
+	TradeNoFormatError     = "trade_no 格式错误"
+
+	DistributeBatchEmpty       = "分发明细不能为空"
+	DistributeBatchTooLarge    = "分发明细数量超出单批上限"
+	DistributeBatchInvalid     = "分发明细校验失败"
+	DistributeBatchNotFound    = "批量分发批次不存在"
+	DistributeBatchNoExists    = "商户批次号已存在"
+	DistributeCSVInvalid       = "CSV 文件格式错误，表头需包含 user_id、username、amount"
+	DistributeOrderNoDuplicate = "商户订单号在批次内重复"
+	DistributeIPNotAllowed     = "请求来源 IP 不在应用分发白名单内"
+
+	SignatureInvalid      = "签名验证失败"
+	SignTypeUnsupported   = "不支持的签名类型"
+	SignTypeMismatch      = "该应用要求使用 HMAC-SHA256 签名"
+	SignTimestampRequired = "缺少 timestamp 或 nonce 参数"
+	SignTimestampInvalid  = "timestamp 格式错误或超出允许的时间偏差"
+	SignNonceInvalid      = "nonce 长度需在 1 到 64 之间"
+	SignNonceReused       = "nonce 已被使用"
+
+	IdempotencyKeyInvalid    = "Idempotency-Key 长度需在 1 到 64 之间"
+	IdempotencyKeyMismatch   = "Idempotency-Key 已被用于不同的请求"
+	IdempotencyKeyProcessing = "相同 Idempotency-Key 的请求正在处理中"
 )
@@ -0,0 +1,226 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payment
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EscrowTransferResponse 担保交易响应
+type EscrowTransferResponse struct {
+	OrderID       string    `json:"order_id"`
+	AutoReleaseAt time.Time `json:"auto_release_at"`
+}
+
+// ConfirmEscrowRequest 确认收货请求
+type ConfirmEscrowRequest struct {
+	OrderID  uint64 `json:"order_id,string" binding:"required"`
+	PayKey   string `json:"pay_key" binding:"required,max=6"`
+	TOTPCode string `json:"totp_code" binding:"max=16"`
+}
+
+// EscrowTransfer 发起担保交易，积分从付款方转入担保，付款方确认收货后放款给收款方
+// 到期未确认且无争议时自动放款，任意一方有异议时可通过争议流程处理
+// @Tags payment
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "幂等键，重试时保持不变"
+// @Param request body TransferRequest true "担保交易请求"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/payment/escrow [post]
+func EscrowTransfer(c *gin.Context) {
+	var req TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	if err := util.ValidateAmount(req.Amount); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if err := service.VerifyPayKey(c.Request.Context(), currentUser, req.PayKey); err != nil {
+		switch err.Error() {
+		case common.PayKeyIncorrect, common.PayKeyLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	if currentUser.ID == req.RecipientID {
+		c.JSON(http.StatusBadRequest, util.Err(CannotTransferToSelf))
+		return
+	}
+
+	releaseDays, errGet := model.GetIntByKey(c.Request.Context(), model.ConfigKeyEscrowAutoReleaseDays)
+	if errGet != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(errGet.Error()))
+		return
+	}
+
+	now := time.Now()
+	order := model.Order{
+		OrderName:   "担保交易",
+		PayerUserID: currentUser.ID,
+		PayeeUserID: req.RecipientID,
+		Amount:      req.Amount,
+		Status:      model.OrderStatusAuthorized,
+		Type:        model.OrderTypeEscrow,
+		Remark:      req.Remark,
+		TradeTime:   now,
+		ExpiresAt:   now.AddDate(0, 0, releaseDays),
+	}
+
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			var recipient model.User
+			if err := tx.Where("id = ? AND username = ? AND is_active = ?", req.RecipientID, req.RecipientUsername, true).First(&recipient).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New(RecipientNotFound)
+				}
+				return err
+			}
+
+			// 超过阈值时校验两步验证码
+			if err := service.CheckPaymentTOTP(tx, currentUser, order.Amount, req.TOTPCode, now); err != nil {
+				return err
+			}
+
+			if err := tx.Create(&order).Error; err != nil {
+				return err
+			}
+
+			_, err := service.AuthorizeOrder(tx, &order, order.ExpiresAt)
+			return err
+		},
+	); err != nil {
+		errMsg := err.Error()
+		switch errMsg {
+		case common.InsufficientBalance, RecipientNotFound, common.TOTPRequired, common.TOTPIncorrect, common.TOTPLocked:
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(EscrowTransferResponse{
+		OrderID:       strconv.FormatUint(order.ID, 10),
+		AutoReleaseAt: order.ExpiresAt,
+	}))
+}
+
+// ConfirmEscrow 付款方确认收货，担保积分放款给收款方；争议中确认视为撤销争议
+// @Tags payment
+// @Accept json
+// @Produce json
+// @Param request body ConfirmEscrowRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/payment/escrow/confirm [post]
+func ConfirmEscrow(c *gin.Context) {
+	var req ConfirmEscrowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if err := service.VerifyPayKey(c.Request.Context(), currentUser, req.PayKey); err != nil {
+		switch err.Error() {
+		case common.PayKeyIncorrect, common.PayKeyLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			var order model.Order
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
+				Where("id = ? AND payer_user_id = ? AND type = ? AND status IN ?", req.OrderID, currentUser.ID, model.OrderTypeEscrow,
+					[]model.OrderStatus{model.OrderStatusAuthorized, model.OrderStatusDisputing}).
+				First(&order).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New(EscrowNotFound)
+				}
+				return err
+			}
+
+			// 超过阈值时校验两步验证码
+			if err := service.CheckPaymentTOTP(tx, currentUser, order.Amount, req.TOTPCode, time.Now()); err != nil {
+				return err
+			}
+
+			if order.Status == model.OrderStatusDisputing {
+				if err := tx.Model(&model.Dispute{}).
+					Where("order_id = ? AND status = ?", order.ID, model.DisputeStatusDisputing).
+					Updates(map[string]interface{}{
+						"status":          model.DisputeStatusClosed,
+						"handler_user_id": currentUser.ID,
+					}).Error; err != nil {
+					return err
+				}
+			}
+
+			var hold model.PaymentHold
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("order_id = ? AND status = ?", order.ID, model.PaymentHoldStatusHeld).
+				First(&hold).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New(EscrowNotFound)
+				}
+				return err
+			}
+
+			return service.ReleaseEscrow(tx, &hold, &order, model.OrderStatusSuccess)
+		},
+	); err != nil {
+		errMsg := err.Error()
+		switch errMsg {
+		case EscrowNotFound:
+			c.JSON(http.StatusNotFound, util.Err(errMsg))
+		case common.TOTPRequired, common.TOTPIncorrect, common.TOTPLocked:
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
@@ -17,15 +17,30 @@ limitations under the License.
 package payment
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/merchant"
+	"github.com/linux-do/credit/internal/apps/oauth"
 	"github.com/linux-do/credit/internal/common"
 	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/linux-do/credit/internal/util"
+	"github.com/redis/go-redis/v9"
 	"github.com/shopspring/decimal"
 )
 
@@ -36,6 +51,8 @@ type CreateOrderRequest struct {
 	Amount          decimal.Decimal `json:"amount" binding:"required"`
 	Remark          string          `json:"remark" binding:"max=100"`
 	PaymentType     string          `json:"payment_type"`
+	ExpireMinutes   int             `json:"expire_minutes" binding:"omitempty,min=1"`
+	ManualCapture   bool            `json:"manual_capture"`
 }
 
 // EPayRequest 易支付请求
@@ -50,6 +67,9 @@ type EPayRequest struct {
 	Sign            string          `form:"sign" binding:"required"`
 	PayType         string          `form:"type" binding:"required"`
 	SignType        string          `form:"sign_type"`
+	Timestamp       string          `form:"timestamp"`
+	Nonce           string          `form:"nonce"`
+	ExpireMinutes   string          `form:"expire_minutes"`
 }
 
 // ToCreateOrderRequest 转换为通用创建订单请求
@@ -62,8 +82,8 @@ func (r *EPayRequest) ToCreateOrderRequest() *CreateOrderRequest {
 	}
 }
 
-// RequireMerchantAuth 验证商户 ClientID/ClientSecret（Basic Auth）
-func RequireMerchantAuth() gin.HandlerFunc {
+// RequireMerchantAuth 验证商户 ClientID 与接口密钥（Basic Auth），密钥须拥有指定权限
+func RequireMerchantAuth(scope model.APISecretScope) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Authorization: Basic base64(ClientID:ClientSecret)
 		authHeader := c.GetHeader("Authorization")
@@ -96,15 +116,61 @@ func RequireMerchantAuth() gin.HandlerFunc {
 		clientID := credentials[0]
 		clientSecret := credentials[1]
 
-		var apiKey model.MerchantAPIKey
-		if err := db.DB(c.Request.Context()).
-			Where("client_secret = ? AND client_id = ?", clientSecret, clientID).
-			First(&apiKey).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, util.Err("认证失败"))
+		apiKey, err := service.AuthenticateAPISecret(db.DB(c.Request.Context()), clientID, clientSecret, scope)
+		if err != nil {
+			switch err.Error() {
+			case common.APISecretInvalid:
+				c.AbortWithStatusJSON(http.StatusUnauthorized, util.Err(err.Error()))
+			case common.APISecretScopeDenied:
+				c.AbortWithStatusJSON(http.StatusForbidden, util.Err(err.Error()))
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, util.Err(err.Error()))
+			}
 			return
 		}
 
-		util.SetToContext(c, APIKeyObjKey, &apiKey)
+		util.SetToContext(c, APIKeyObjKey, apiKey)
+
+		c.Next()
+	}
+}
+
+// RequireEPayRefundAuth 验证易支付退款接口请求体中的 pid 与 key，密钥须拥有退款权限
+// 需在幂等中间件之前完成认证，使幂等键按已认证的商户应用隔离
+func RequireEPayRefundAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"code": -1, "msg": err.Error()})
+			return
+		}
+
+		var req RefundOrderRequest
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		errBind := c.ShouldBind(&req)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if errBind != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"code": -1, "msg": errBind.Error()})
+			return
+		}
+
+		apiKey, err := service.AuthenticateAPISecret(db.DB(c.Request.Context()), req.ClientID, req.ClientSecret, model.APISecretScopeRefund)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"code": -1, "msg": MerchantInfoNotFound})
+			return
+		}
+
+		util.SetToContext(c, APIKeyObjKey, apiKey)
+
+		c.Next()
+	}
+}
+
+// BindMemberAPIKey 将控制台成员所在的应用作为商户接口的调用方，成员权限由 api_key.RequireRole 校验
+func BindMemberAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+		util.SetToContext(c, APIKeyObjKey, apiKey)
 
 		c.Next()
 	}
@@ -135,3 +201,197 @@ func RequireSignatureAuth() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// idempotencyRecord 幂等键记录，请求处理中时 Status 为 processing
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      string `json:"status"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// idempotencyResponseWriter 记录响应内容以便缓存
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// RequireIdempotency 幂等键中间件
+// 携带 Idempotency-Key 时，在配置的时间窗口内相同请求直接返回首次响应，不同请求体复用同一幂等键将被拒绝
+// 未携带时不做处理；服务端错误（5xx）不缓存，允许客户端使用同一幂等键重试
+func RequireIdempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+		if idempotencyKey == "" {
+			c.Next()
+			return
+		}
+		if len(idempotencyKey) > idempotencyMaxKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, util.Err(IdempotencyKeyInvalid))
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, util.Err(err.Error()))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		windowMinutes, err := model.GetIntByKey(ctx, model.ConfigKeyIdempotencyWindowMinutes)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, util.Err(err.Error()))
+			return
+		}
+		window := time.Duration(windowMinutes) * time.Minute
+
+		scope, ok := idempotencyScope(c)
+		if !ok {
+			logger.ErrorF(ctx, "[Idempotency] 未找到已认证的调用方，跳过幂等处理: path=%s", c.Request.URL.Path)
+			c.Next()
+			return
+		}
+
+		fingerprint := idempotencyFingerprint(c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery, c.ContentType(), body)
+		redisKey := db.PrefixedKey(fmt.Sprintf(IdempotencyKeyFormat, scope, idempotencyKey))
+
+		// 处理中记录只持有较短的租约，避免处理异常中断后幂等键长时间不可用
+		processing, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint, Status: idempotencyProcessingFlag})
+		acquired, err := db.Redis.SetNX(ctx, redisKey, processing, idempotencyProcessingLease).Result()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, util.Err(err.Error()))
+			return
+		}
+
+		if !acquired {
+			raw, errGet := db.Redis.Get(ctx, redisKey).Bytes()
+			if errGet != nil && !errors.Is(errGet, redis.Nil) {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, util.Err(errGet.Error()))
+				return
+			}
+
+			var record idempotencyRecord
+			if errGet == nil {
+				_ = json.Unmarshal(raw, &record)
+			}
+			if record.Fingerprint != "" && record.Fingerprint != fingerprint {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, util.Err(IdempotencyKeyMismatch))
+				return
+			}
+			// 首次请求仍在处理中（或记录恰好被释放），由客户端稍后重试
+			if record.Status == idempotencyProcessingFlag || record.StatusCode == 0 {
+				c.AbortWithStatusJSON(http.StatusConflict, util.Err(IdempotencyKeyProcessing))
+				return
+			}
+
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(record.StatusCode, record.ContentType, record.Body)
+			c.Abort()
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		// 请求上下文可能已取消，使用独立的上下文写入结果
+		storeCtx := context.WithoutCancel(ctx)
+
+		// 未产生可缓存的最终响应（服务端错误或处理中 panic）时释放幂等键，允许客户端重试
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if errDel := idempotencyReleaseScript.Run(storeCtx, db.Redis, []string{redisKey}, processing).Err(); errDel != nil {
+				logger.ErrorF(storeCtx, "[Idempotency] 释放幂等键失败: key=%s, error=%v", redisKey, errDel)
+			}
+		}()
+
+		c.Next()
+
+		statusCode := writer.Status()
+		if statusCode >= http.StatusInternalServerError {
+			return
+		}
+		completed = true
+
+		record, _ := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			StatusCode:  statusCode,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		})
+		if errSet := db.Redis.Set(storeCtx, redisKey, record, window).Err(); errSet != nil {
+			logger.ErrorF(storeCtx, "[Idempotency] 保存幂等响应失败: key=%s, error=%v", redisKey, errSet)
+		}
+	}
+}
+
+// idempotencyReleaseScript 仅当幂等键仍为本次请求的处理中记录时删除，避免误删租约到期后其他请求写入的记录
+var idempotencyReleaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// idempotencyCredentialFields 计算请求指纹时剔除的凭证字段，避免将商户密钥写入缓存
+var idempotencyCredentialFields = []string{"key", "sign"}
+
+// idempotencyScope 幂等键的调用方范围，不同调用方的幂等键互不影响
+// 仅使用已认证的登录用户或商户应用，幂等中间件必须注册在认证中间件之后
+func idempotencyScope(c *gin.Context) (string, bool) {
+	if user, ok := util.GetFromContext[*model.User](c, oauth.UserObjKey); ok && user != nil {
+		return fmt.Sprintf("user:%d", user.ID), true
+	}
+	if apiKey, ok := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey); ok && apiKey != nil {
+		return "client:" + apiKey.ClientID, true
+	}
+	return "", false
+}
+
+// idempotencyFingerprint 计算请求指纹，表单与 JSON 请求体中的凭证字段不参与计算
+func idempotencyFingerprint(method, path, rawQuery, contentType string, body []byte) string {
+	if query, err := url.ParseQuery(rawQuery); err == nil {
+		for _, field := range idempotencyCredentialFields {
+			query.Del(field)
+		}
+		rawQuery = query.Encode()
+	}
+
+	switch {
+	case strings.HasPrefix(contentType, "application/json"):
+		var payload map[string]json.RawMessage
+		if err := json.Unmarshal(body, &payload); err == nil {
+			for _, field := range idempotencyCredentialFields {
+				delete(payload, field)
+			}
+			body, _ = json.Marshal(payload)
+		}
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			for _, field := range idempotencyCredentialFields {
+				values.Del(field)
+			}
+			body = []byte(values.Encode())
+		}
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "?" + rawQuery + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
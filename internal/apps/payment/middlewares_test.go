@@ -0,0 +1,111 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payment
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/model"
+)
+
+func TestIdempotencyFingerprint(t *testing.T) {
+	const (
+		jsonType = "application/json; charset=utf-8"
+		formType = "application/x-www-form-urlencoded"
+	)
+	base := idempotencyFingerprint("POST", "/pay/submit.php", "pid=client&money=10", formType, []byte("name=test&key=k1&sign=s1"))
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		rawQuery    string
+		contentType string
+		body        string
+		same        bool
+	}{
+		{name: "identical request", method: "POST", path: "/pay/submit.php", rawQuery: "pid=client&money=10", contentType: formType, body: "name=test&key=k1&sign=s1", same: true},
+		{name: "different credentials in form", method: "POST", path: "/pay/submit.php", rawQuery: "pid=client&money=10", contentType: formType, body: "name=test&key=k2&sign=s2", same: true},
+		{name: "credentials moved to query", method: "POST", path: "/pay/submit.php", rawQuery: "pid=client&money=10&key=k1&sign=s1", contentType: formType, body: "name=test", same: true},
+		{name: "reordered query and form", method: "POST", path: "/pay/submit.php", rawQuery: "money=10&pid=client", contentType: formType, body: "sign=s1&name=test&key=k1", same: true},
+		{name: "different form value", method: "POST", path: "/pay/submit.php", rawQuery: "pid=client&money=10", contentType: formType, body: "name=other&key=k1&sign=s1"},
+		{name: "different query value", method: "POST", path: "/pay/submit.php", rawQuery: "pid=client&money=20", contentType: formType, body: "name=test&key=k1&sign=s1"},
+		{name: "different path", method: "POST", path: "/api/v1/refund", rawQuery: "pid=client&money=10", contentType: formType, body: "name=test&key=k1&sign=s1"},
+		{name: "different method", method: "PUT", path: "/pay/submit.php", rawQuery: "pid=client&money=10", contentType: formType, body: "name=test&key=k1&sign=s1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := idempotencyFingerprint(tt.method, tt.path, tt.rawQuery, tt.contentType, []byte(tt.body))
+			if (got == base) != tt.same {
+				t.Errorf("idempotencyFingerprint() same = %v, want %v", got == base, tt.same)
+			}
+		})
+	}
+
+	jsonBase := idempotencyFingerprint("POST", "/api/v1/refund", "", jsonType, []byte(`{"trade_no":"T1","money":"1.00","key":"k1","sign":"s1"}`))
+	jsonTests := []struct {
+		name string
+		body string
+		same bool
+	}{
+		{name: "different json credentials", body: `{"trade_no":"T1","money":"1.00","key":"k2","sign":"s2"}`, same: true},
+		{name: "reordered json fields", body: `{"money":"1.00","sign":"s1","key":"k1","trade_no":"T1"}`, same: true},
+		{name: "without json credentials", body: `{"trade_no":"T1","money":"1.00"}`, same: true},
+		{name: "different json value", body: `{"trade_no":"T1","money":"2.00","key":"k1","sign":"s1"}`},
+	}
+	for _, tt := range jsonTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := idempotencyFingerprint("POST", "/api/v1/refund", "", jsonType, []byte(tt.body))
+			if (got == jsonBase) != tt.same {
+				t.Errorf("idempotencyFingerprint() same = %v, want %v", got == jsonBase, tt.same)
+			}
+		})
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		user      *model.User
+		apiKey    *model.MerchantAPIKey
+		wantScope string
+		wantOK    bool
+	}{
+		{name: "anonymous"},
+		{name: "user", user: &model.User{ID: 42}, wantScope: "user:42", wantOK: true},
+		{name: "merchant client", apiKey: &model.MerchantAPIKey{ClientID: "client"}, wantScope: "client:client", wantOK: true},
+		{name: "user takes precedence", user: &model.User{ID: 42}, apiKey: &model.MerchantAPIKey{ClientID: "client"}, wantScope: "user:42", wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			if tt.user != nil {
+				c.Set(oauth.UserObjKey, tt.user)
+			}
+			if tt.apiKey != nil {
+				c.Set(APIKeyObjKey, tt.apiKey)
+			}
+			scope, ok := idempotencyScope(c)
+			if scope != tt.wantScope || ok != tt.wantOK {
+				t.Errorf("idempotencyScope() = (%q, %v), want (%q, %v)", scope, ok, tt.wantScope, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,444 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payment
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// MerchantOrderResponse 商户订单信息
+type MerchantOrderResponse struct {
+	TradeNo        string                     `json:"trade_no" example:"123456"`
+	OutTradeNo     string                     `json:"out_trade_no" example:"M202312080001"`
+	ParentTradeNo  string                     `json:"parent_trade_no,omitempty"`
+	Name           string                     `json:"name" example:"商品名称"`
+	Amount         decimal.Decimal            `json:"amount" example:"10.00"`
+	Fee            decimal.Decimal            `json:"fee" example:"0.10"`
+	RefundedAmount decimal.Decimal            `json:"refunded_amount" example:"0.00"`
+	Status         model.OrderStatus          `json:"status" example:"pending"`
+	Type           model.OrderType            `json:"type" example:"payment"`
+	ManualCapture  bool                       `json:"manual_capture"`
+	Hold           *MerchantOrderHoldResponse `json:"hold,omitempty"`
+	PayURL         string                     `json:"pay_url,omitempty"`
+	CreatedAt      time.Time                  `json:"created_at"`
+	PaidAt         *time.Time                 `json:"paid_at"`
+	ExpiresAt      time.Time                  `json:"expires_at"`
+}
+
+// MerchantOrderHoldResponse 预授权订单的冻结信息
+type MerchantOrderHoldResponse struct {
+	Amount         decimal.Decimal         `json:"amount" example:"10.00"`
+	CapturedAmount decimal.Decimal         `json:"captured_amount" example:"0.00"`
+	Status         model.PaymentHoldStatus `json:"status" example:"held"`
+	ExpiresAt      time.Time               `json:"expires_at"`
+}
+
+func newMerchantOrderHoldResponse(hold *model.PaymentHold) *MerchantOrderHoldResponse {
+	return &MerchantOrderHoldResponse{
+		Amount:         hold.Amount,
+		CapturedAmount: hold.CapturedAmount,
+		Status:         hold.Status,
+		ExpiresAt:      hold.ExpiresAt,
+	}
+}
+
+func newMerchantOrderResponse(order *model.Order) MerchantOrderResponse {
+	resp := MerchantOrderResponse{
+		TradeNo:        strconv.FormatUint(order.ID, 10),
+		OutTradeNo:     util.DerefString(order.MerchantOrderNo),
+		Name:           order.OrderName,
+		Amount:         order.Amount,
+		Fee:            order.Fee,
+		RefundedAmount: order.RefundedAmount,
+		Status:         order.Status,
+		Type:           order.Type,
+		ManualCapture:  order.ManualCapture,
+		CreatedAt:      order.CreatedAt,
+		ExpiresAt:      order.ExpiresAt,
+	}
+	if order.ParentOrderID != nil {
+		resp.ParentTradeNo = strconv.FormatUint(*order.ParentOrderID, 10)
+	}
+	if !order.TradeTime.IsZero() {
+		paidAt := order.TradeTime
+		resp.PaidAt = &paidAt
+	}
+	return resp
+}
+
+// ListOrdersRequest 商户订单列表请求
+type ListOrdersRequest struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Status string `form:"status" binding:"omitempty,oneof=success pending expired disputing refund refused partially_refunded cancelled authorized"`
+	Type   string `form:"type" binding:"omitempty,oneof=payment online test distribute refund"`
+}
+
+// ListOrdersResponse 商户订单列表响应
+type ListOrdersResponse struct {
+	Orders     []MerchantOrderResponse `json:"orders"`
+	NextCursor string                  `json:"next_cursor"`
+	HasMore    bool                    `json:"has_more"`
+}
+
+// OrderRefundRequest 商户订单退款请求
+type OrderRefundRequest struct {
+	Amount decimal.Decimal `json:"amount" binding:"required"`
+}
+
+// OrderCaptureRequest 商户确认收款请求，amount 为空或 0 时确认全部冻结金额
+type OrderCaptureRequest struct {
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// OrderRefundResponse 商户订单退款响应
+type OrderRefundResponse struct {
+	RefundNo string                `json:"refund_no"`
+	Order    MerchantOrderResponse `json:"order"`
+}
+
+// CreateOrder 商户创建订单（JSON），返回收银台地址
+// @Tags merchant-order
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Basic Auth (base64(client_id:client_secret))"
+// @Param Idempotency-Key header string false "幂等键，重试时保持不变"
+// @Param request body CreateOrderRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/orders [post]
+func CreateOrder(c *gin.Context) {
+	var req CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	if err := util.ValidateAmount(req.Amount); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+	req.PaymentType = common.PayTypeLDPay
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	order, payURL, err := createMerchantOrder(c.Request.Context(), apiKey, &req)
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case strings.Contains(errMsg, "SQLSTATE 23505"):
+			c.JSON(http.StatusBadRequest, util.Err(MerchantOrderNoExists))
+		case errMsg == MerchantInfoNotFound, errMsg == ExpireMinutesOutRange:
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
+		}
+		return
+	}
+
+	resp := newMerchantOrderResponse(order)
+	resp.PayURL = payURL
+	c.JSON(http.StatusOK, util.OK(resp))
+}
+
+// GetOrder 通过平台订单号查询商户订单
+// @Tags merchant-order
+// @Produce json
+// @Param Authorization header string true "Basic Auth (base64(client_id:client_secret))"
+// @Param tradeNo path string true "平台订单号"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/orders/{tradeNo} [get]
+func GetOrder(c *gin.Context) {
+	tradeNo, err := strconv.ParseUint(c.Param("tradeNo"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(TradeNoFormatError))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+	respondMerchantOrder(c, db.DB(c.Request.Context()).Where("id = ? AND client_id = ?", tradeNo, apiKey.ClientID))
+}
+
+// GetOrderByOutTradeNo 通过商户订单号查询商户订单
+// @Tags merchant-order
+// @Produce json
+// @Param Authorization header string true "Basic Auth (base64(client_id:client_secret))"
+// @Param outTradeNo path string true "商户订单号"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/orders/out-trade-no/{outTradeNo} [get]
+func GetOrderByOutTradeNo(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+	respondMerchantOrder(c, db.DB(c.Request.Context()).Where("client_id = ? AND merchant_order_no = ?", apiKey.ClientID, c.Param("outTradeNo")))
+}
+
+// respondMerchantOrder 查询单个订单并返回，待支付订单附带收银台地址
+func respondMerchantOrder(c *gin.Context, query *gorm.DB) {
+	var order model.Order
+	if err := query.First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, util.Err(OrderNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	resp := newMerchantOrderResponse(&order)
+	if order.Status == model.OrderStatusPending {
+		resp.PayURL = getOrderPayURL(c.Request.Context(), order.ID)
+	}
+	if order.ManualCapture {
+		var hold model.PaymentHold
+		if err := db.DB(c.Request.Context()).Where("order_id = ?", order.ID).First(&hold).Error; err == nil {
+			resp.Hold = newMerchantOrderHoldResponse(&hold)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+			return
+		}
+	}
+	c.JSON(http.StatusOK, util.OK(resp))
+}
+
+// ListOrders 商户订单列表，按订单号倒序游标分页
+// @Tags merchant-order
+// @Produce json
+// @Param Authorization header string true "Basic Auth (base64(client_id:client_secret))"
+// @Param request query ListOrdersRequest false "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/orders [get]
+func ListOrders(c *gin.Context) {
+	var req ListOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	query := db.DB(c.Request.Context()).Where("client_id = ?", apiKey.ClientID)
+	if req.Cursor != "" {
+		cursor, err := strconv.ParseUint(req.Cursor, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, util.Err(TradeNoFormatError))
+			return
+		}
+		query = query.Where("id < ?", cursor)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+
+	var orders []model.Order
+	if err := query.Order("id DESC").Limit(req.Limit + 1).Find(&orders).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	resp := ListOrdersResponse{Orders: make([]MerchantOrderResponse, 0, len(orders))}
+	if len(orders) > req.Limit {
+		orders = orders[:req.Limit]
+		resp.HasMore = true
+	}
+	for i := range orders {
+		resp.Orders = append(resp.Orders, newMerchantOrderResponse(&orders[i]))
+	}
+	if resp.HasMore {
+		resp.NextCursor = resp.Orders[len(resp.Orders)-1].TradeNo
+	}
+
+	c.JSON(http.StatusOK, util.OK(resp))
+}
+
+// RefundOrder 商户订单退款，支持多次部分退款
+// @Tags merchant-order
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Basic Auth (base64(client_id:client_secret))"
+// @Param Idempotency-Key header string false "幂等键，重试时保持不变"
+// @Param tradeNo path string true "平台订单号"
+// @Param request body OrderRefundRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/orders/{tradeNo}/refund [post]
+func RefundOrder(c *gin.Context) {
+	tradeNo, err := strconv.ParseUint(c.Param("tradeNo"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(TradeNoFormatError))
+		return
+	}
+
+	var req OrderRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	if err := util.ValidateAmount(req.Amount); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	order, refundOrder, err := refundMerchantOrder(c.Request.Context(), apiKey, tradeNo, req.Amount)
+	if err != nil {
+		errMsg := err.Error()
+		switch errMsg {
+		case OrderNotFound:
+			c.JSON(http.StatusNotFound, util.Err(errMsg))
+		case common.RefundAmountExceeded, common.InsufficientBalance:
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(OrderRefundResponse{
+		RefundNo: strconv.FormatUint(refundOrder.ID, 10),
+		Order:    newMerchantOrderResponse(order),
+	}))
+}
+
+// CancelOrder 商户取消待支付订单
+// @Tags merchant-order
+// @Produce json
+// @Param Authorization header string true "Basic Auth (base64(client_id:client_secret))"
+// @Param tradeNo path string true "平台订单号"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/orders/{tradeNo}/cancel [post]
+func CancelOrder(c *gin.Context) {
+	tradeNo, err := strconv.ParseUint(c.Param("tradeNo"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(TradeNoFormatError))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	order, err := cancelMerchantOrder(c.Request.Context(), apiKey, tradeNo)
+	if err != nil {
+		if err.Error() == OrderNotCancelable {
+			c.JSON(http.StatusBadRequest, util.Err(OrderNotCancelable))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(newMerchantOrderResponse(order)))
+}
+
+// CaptureOrder 商户确认收款预授权订单，支持部分确认，未确认部分退回付款方
+// @Tags merchant-order
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Basic Auth (base64(client_id:client_secret))"
+// @Param Idempotency-Key header string false "幂等键，重试时保持不变"
+// @Param tradeNo path string true "平台订单号"
+// @Param request body OrderCaptureRequest false "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/orders/{tradeNo}/capture [post]
+func CaptureOrder(c *gin.Context) {
+	tradeNo, err := strconv.ParseUint(c.Param("tradeNo"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(TradeNoFormatError))
+		return
+	}
+
+	var req OrderCaptureRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+			return
+		}
+	}
+
+	if !req.Amount.IsZero() {
+		if err := util.ValidateAmount(req.Amount); err != nil {
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+			return
+		}
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	order, hold, err := captureMerchantOrder(c.Request.Context(), apiKey, tradeNo, req.Amount)
+	if err != nil {
+		errMsg := err.Error()
+		switch errMsg {
+		case OrderNotAuthorized:
+			c.JSON(http.StatusNotFound, util.Err(errMsg))
+		case common.CaptureAmountExceeded:
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
+		}
+		return
+	}
+
+	resp := newMerchantOrderResponse(order)
+	resp.Hold = newMerchantOrderHoldResponse(hold)
+	c.JSON(http.StatusOK, util.OK(resp))
+}
+
+// VoidOrder 商户撤销预授权订单，冻结积分全额退回付款方
+// @Tags merchant-order
+// @Produce json
+// @Param Authorization header string true "Basic Auth (base64(client_id:client_secret))"
+// @Param tradeNo path string true "平台订单号"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/orders/{tradeNo}/void [post]
+func VoidOrder(c *gin.Context) {
+	tradeNo, err := strconv.ParseUint(c.Param("tradeNo"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(TradeNoFormatError))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	order, hold, err := voidMerchantOrder(c.Request.Context(), apiKey, tradeNo)
+	if err != nil {
+		if err.Error() == OrderNotAuthorized {
+			c.JSON(http.StatusNotFound, util.Err(OrderNotAuthorized))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	resp := newMerchantOrderResponse(order)
+	resp.Hold = newMerchantOrderHoldResponse(hold)
+	c.JSON(http.StatusOK, util.OK(resp))
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreatePaymentRequestRequest 发起收款请求
+type CreatePaymentRequestRequest struct {
+	PayerID       uint64          `json:"payer_id,string" binding:"required"`
+	PayerUsername string          `json:"payer_username" binding:"required"`
+	Amount        decimal.Decimal `json:"amount" binding:"required"`
+	Remark        string          `json:"remark" binding:"max=100"`
+}
+
+// ListPaymentRequestsRequest 收款请求查询参数
+type ListPaymentRequestsRequest struct {
+	Page     int                        `form:"page" binding:"min=1"`
+	PageSize int                        `form:"page_size" binding:"min=1,max=100"`
+	Sent     bool                       `form:"sent"`
+	Status   model.PaymentRequestStatus `form:"status" binding:"omitempty,oneof=pending paid declined expired"`
+}
+
+// ListPaymentRequestsResponse 收款请求列表响应
+type ListPaymentRequestsResponse struct {
+	Total    int64                  `json:"total"`
+	Requests []model.PaymentRequest `json:"requests"`
+}
+
+// PayPaymentRequestRequest 支付收款请求
+type PayPaymentRequestRequest struct {
+	PayKey   string `json:"pay_key" binding:"required,max=6"`
+	TOTPCode string `json:"totp_code" binding:"max=16"`
+}
+
+// CreatePaymentRequest 向指定用户发起收款请求，对方在收款请求列表中支付或拒绝
+// @Tags payment
+// @Accept json
+// @Produce json
+// @Param request body CreatePaymentRequestRequest true "收款请求"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/payment/requests [post]
+func CreatePaymentRequest(c *gin.Context) {
+	var req CreatePaymentRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	if err := util.ValidateAmount(req.Amount); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if currentUser.ID == req.PayerID {
+		c.JSON(http.StatusBadRequest, util.Err(CannotRequestFromSelf))
+		return
+	}
+
+	expireHours, errGet := model.GetIntByKey(c.Request.Context(), model.ConfigKeyPaymentRequestExpireHours)
+	if errGet != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(errGet.Error()))
+		return
+	}
+	expireDuration := time.Duration(expireHours) * time.Hour
+
+	var payer model.User
+	if err := db.DB(c.Request.Context()).
+		Where("id = ? AND username = ? AND is_active = ?", req.PayerID, req.PayerUsername, true).
+		First(&payer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, util.Err(PayerNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	paymentRequest := model.PaymentRequest{
+		RequesterUserID: currentUser.ID,
+		PayerUserID:     payer.ID,
+		Amount:          req.Amount,
+		Remark:          req.Remark,
+		Status:          model.PaymentRequestStatusPending,
+		ExpiresAt:       time.Now().Add(expireDuration),
+	}
+	if err := db.DB(c.Request.Context()).Create(&paymentRequest).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	expireKey := db.PrefixedKey(fmt.Sprintf(PaymentRequestExpireKeyFormat, paymentRequest.ID))
+	if err := db.Redis.Set(c.Request.Context(), expireKey, paymentRequest.ID, expireDuration).Err(); err != nil {
+		log.Printf("[Payment] 设置收款请求过期key失败: request_id=%d, error=%v", paymentRequest.ID, err)
+	}
+
+	paymentRequest.RequesterUsername = currentUser.Username
+	paymentRequest.PayerUsername = payer.Username
+	c.JSON(http.StatusOK, util.OK(paymentRequest))
+}
+
+// ListPaymentRequests 获取收款请求列表，默认为待当前用户支付的请求，sent 为 true 时为当前用户发起的请求
+// @Tags payment
+// @Produce json
+// @Param request query ListPaymentRequestsRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/payment/requests [get]
+func ListPaymentRequests(c *gin.Context) {
+	var req ListPaymentRequestsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	query := db.DB(c.Request.Context()).Model(&model.PaymentRequest{})
+	if req.Sent {
+		query = query.Where("payment_requests.requester_user_id = ?", currentUser.ID)
+	} else {
+		query = query.Where("payment_requests.payer_user_id = ?", currentUser.ID)
+	}
+	if req.Status != "" {
+		query = query.Where("payment_requests.status = ?", req.Status)
+	}
+
+	var response ListPaymentRequestsResponse
+	if err := query.Count(&response.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.
+		Select("payment_requests.*, requester.username AS requester_username, payer.username AS payer_username").
+		Joins("LEFT JOIN users AS requester ON requester.id = payment_requests.requester_user_id").
+		Joins("LEFT JOIN users AS payer ON payer.id = payment_requests.payer_user_id").
+		Order("payment_requests.created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&response.Requests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
+
+// PayPaymentRequest 支付收款请求，以转账方式向发起人付款
+// @Tags payment
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "幂等键，重试时保持不变"
+// @Param id path uint64 true "收款请求 ID"
+// @Param request body PayPaymentRequestRequest true "支付请求"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/payment/requests/{id}/pay [post]
+func PayPaymentRequest(c *gin.Context) {
+	var req PayPaymentRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if err := service.VerifyPayKey(c.Request.Context(), currentUser, req.PayKey); err != nil {
+		switch err.Error() {
+		case common.PayKeyIncorrect, common.PayKeyLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	var paymentRequest model.PaymentRequest
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			if err := lockPendingPaymentRequest(tx, c.Param("id"), currentUser.ID, &paymentRequest); err != nil {
+				return err
+			}
+
+			// 超过阈值时校验两步验证码
+			if err := service.CheckPaymentTOTP(tx, currentUser, paymentRequest.Amount, req.TOTPCode, time.Now()); err != nil {
+				return err
+			}
+
+			var requester model.User
+			if err := tx.Where("id = ?", paymentRequest.RequesterUserID).First(&requester).Error; err != nil {
+				return err
+			}
+
+			order, err := transferInTx(tx, currentUser.ID, &requester, paymentRequest.Amount, paymentRequest.Remark)
+			if err != nil {
+				return err
+			}
+
+			paymentRequest.Status = model.PaymentRequestStatusPaid
+			paymentRequest.OrderID = &order.ID
+			return tx.Model(&paymentRequest).UpdateColumns(map[string]interface{}{
+				"status":     paymentRequest.Status,
+				"order_id":   order.ID,
+				"updated_at": time.Now(),
+			}).Error
+		},
+	); err != nil {
+		errMsg := err.Error()
+		switch errMsg {
+		case PaymentRequestNotFound:
+			c.JSON(http.StatusNotFound, util.Err(errMsg))
+		case common.InsufficientBalance, common.TOTPRequired, common.TOTPIncorrect, common.TOTPLocked:
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
+		}
+		return
+	}
+
+	deletePaymentRequestExpireKey(c, paymentRequest.ID)
+
+	c.JSON(http.StatusOK, util.OK(paymentRequest))
+}
+
+// DeclinePaymentRequest 拒绝收款请求
+// @Tags payment
+// @Produce json
+// @Param id path uint64 true "收款请求 ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/payment/requests/{id}/decline [post]
+func DeclinePaymentRequest(c *gin.Context) {
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	var paymentRequest model.PaymentRequest
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			if err := lockPendingPaymentRequest(tx, c.Param("id"), currentUser.ID, &paymentRequest); err != nil {
+				return err
+			}
+
+			paymentRequest.Status = model.PaymentRequestStatusDeclined
+			return tx.Model(&paymentRequest).UpdateColumns(map[string]interface{}{
+				"status":     paymentRequest.Status,
+				"updated_at": time.Now(),
+			}).Error
+		},
+	); err != nil {
+		if err.Error() == PaymentRequestNotFound {
+			c.JSON(http.StatusNotFound, util.Err(PaymentRequestNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	deletePaymentRequestExpireKey(c, paymentRequest.ID)
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// lockPendingPaymentRequest 锁定待当前用户处理且未过期的收款请求
+func lockPendingPaymentRequest(tx *gorm.DB, requestID string, payerUserID uint64, paymentRequest *model.PaymentRequest) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
+		Where("id = ? AND payer_user_id = ? AND status = ? AND expires_at > ?",
+			requestID, payerUserID, model.PaymentRequestStatusPending, time.Now()).
+		First(paymentRequest).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(PaymentRequestNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+func deletePaymentRequestExpireKey(c *gin.Context, requestID uint64) {
+	expireKey := db.PrefixedKey(fmt.Sprintf(PaymentRequestExpireKeyFormat, requestID))
+	if err := db.Redis.Del(c.Request.Context(), expireKey).Err(); err != nil {
+		log.Printf("[Payment] 删除收款请求过期key失败: request_id=%d, error=%v", requestID, err)
+	}
+}
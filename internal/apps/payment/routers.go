@@ -21,13 +21,11 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/linux-do/credit/internal/apps/oauth"
 	"github.com/linux-do/credit/internal/common"
-	"github.com/linux-do/credit/internal/config"
 	"github.com/linux-do/credit/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -41,8 +39,9 @@ import (
 
 // PayOrderRequest 用户支付订单请求
 type PayOrderRequest struct {
-	OrderNo string `json:"order_no" binding:"required"`
-	PayKey  string `json:"pay_key" binding:"required,max=6"`
+	OrderNo  string `json:"order_no" binding:"required"`
+	PayKey   string `json:"pay_key" binding:"required,max=6"`
+	TOTPCode string `json:"totp_code" binding:"max=16"`
 }
 
 // GetOrderRequest 查询订单请求
@@ -69,6 +68,7 @@ type TransferRequest struct {
 	RecipientUsername string          `json:"recipient_username" binding:"required"`
 	Amount            decimal.Decimal `json:"amount" binding:"required"`
 	PayKey            string          `json:"pay_key" binding:"required,max=6"`
+	TOTPCode          string          `json:"totp_code" binding:"max=16"`
 	Remark            string          `json:"remark" binding:"max=100"`
 }
 
@@ -100,60 +100,12 @@ func CreateMerchantOrder(c *gin.Context) {
 	req, _ := util.GetFromContext[*CreateOrderRequest](c, CreateOrderRequestKey)
 	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
 
-	// 获取商户用户信息
-	var merchantUser model.User
-	if err := db.DB(c.Request.Context()).Where("id = ? AND is_active = ?", apiKey.UserID, true).First(&merchantUser).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, util.Err(MerchantInfoNotFound))
-		return
-	}
-
-	// 获取商家订单过期时间（分钟）
-	expireMinutes, errGet := model.GetIntByKey(c.Request.Context(), model.ConfigKeyMerchantOrderExpireMinutes)
-	if errGet != nil {
-		c.JSON(http.StatusInternalServerError, util.Err(errGet.Error()))
-		return
-	}
-
-	var payURL string
-
-	if err := db.DB(c.Request.Context()).Transaction(
-		func(tx *gorm.DB) error {
-			// 创建订单
-			order := model.Order{
-				OrderName:       req.OrderName,
-				ClientID:        apiKey.ClientID,
-				MerchantOrderNo: req.MerchantOrderNo,
-				PayeeUserID:     merchantUser.ID,
-				Amount:          req.Amount,
-				Status:          model.OrderStatusPending,
-				Type:            model.OrderTypePayment,
-				Remark:          req.Remark,
-				PaymentType:     req.PaymentType,
-				ExpiresAt:       time.Now().Add(time.Duration(expireMinutes) * time.Minute),
-			}
-			if err := tx.Create(&order).Error; err != nil {
-				return err
-			}
-
-			encryptString, err := util.Encrypt(merchantUser.SignKey, strconv.FormatUint(order.ID, 10))
-			if err != nil {
-				return err
-			}
-
-			merchantIDStr := strconv.FormatUint(merchantUser.ID, 10)
-			if errSet := db.Redis.Set(c.Request.Context(), db.PrefixedKey(fmt.Sprintf(OrderMerchantIDCacheKeyFormat, encryptString)), merchantIDStr, time.Duration(expireMinutes)*time.Minute).Err(); errSet != nil {
-				return fmt.Errorf("failed to set redis key: %w", errSet)
-			}
-
-			expireKey := db.PrefixedKey(fmt.Sprintf(OrderExpireKeyFormat, order.ID))
-			if errSet := db.Redis.Set(c.Request.Context(), expireKey, order.ID, time.Duration(expireMinutes)*time.Minute).Err(); errSet != nil {
-				return fmt.Errorf("failed to set order expire key: %w", errSet)
-			}
-
-			payURL = fmt.Sprintf("%s?order_no=%s", config.Config.App.FrontendPayURL, url.QueryEscape(encryptString))
-			return nil
-		},
-	); err != nil {
+	_, payURL, err := createMerchantOrder(c.Request.Context(), apiKey, req)
+	if err != nil {
+		if err.Error() == ExpireMinutesOutRange {
+			c.JSON(http.StatusBadRequest, util.Err(ExpireMinutesOutRange))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
 		return
 	}
@@ -163,17 +115,19 @@ func CreateMerchantOrder(c *gin.Context) {
 
 // QueryMerchantOrderResponse 查询订单响应
 type QueryMerchantOrderResponse struct {
-	Code       int    `json:"code" example:"1"`
-	Msg        string `json:"msg" example:"查询订单号成功！"`
-	TradeNo    string `json:"trade_no" example:"123456"`
-	OutTradeNo string `json:"out_trade_no" example:"M202312080001"`
-	Type       string `json:"type" example:"epay"`
-	Pid        string `json:"pid" example:"1001"`
-	AddTime    string `json:"addtime" example:"2023-12-08 12:00:00"`
-	EndTime    string `json:"endtime" example:"2023-12-08 12:05:00"`
-	Name       string `json:"name" example:"商品名称"`
-	Money      string `json:"money" example:"10.00"`
-	Status     int    `json:"status" example:"1"`
+	Code          int    `json:"code" example:"1"`
+	Msg           string `json:"msg" example:"查询订单号成功！"`
+	TradeNo       string `json:"trade_no" example:"123456"`
+	OutTradeNo    string `json:"out_trade_no" example:"M202312080001"`
+	Type          string `json:"type" example:"epay"`
+	Pid           string `json:"pid" example:"1001"`
+	AddTime       string `json:"addtime" example:"2023-12-08 12:00:00"`
+	EndTime       string `json:"endtime" example:"2023-12-08 12:05:00"`
+	Name          string `json:"name" example:"商品名称"`
+	Money         string `json:"money" example:"10.00"`
+	Status        int    `json:"status" example:"1"`
+	TradeStatus   string `json:"trade_status" example:"success"`
+	RefundedMoney string `json:"refunded_money" example:"0.00"`
 }
 
 // QueryMerchantOrder 商户主动查询订单状态接口
@@ -190,8 +144,7 @@ func QueryMerchantOrder(c *gin.Context) {
 		return
 	}
 
-	var apiKey model.MerchantAPIKey
-	if err := db.DB(c.Request.Context()).Where("client_id = ? AND client_secret = ?", req.ClientID, req.ClientSecret).First(&apiKey).Error; err != nil {
+	if _, err := service.AuthenticateAPISecret(db.DB(c.Request.Context()), req.ClientID, req.ClientSecret, model.APISecretScopeQuery); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": MerchantInfoNotFound})
 		return
 	}
@@ -206,36 +159,43 @@ func QueryMerchantOrder(c *gin.Context) {
 		return
 	}
 
+	// 部分退款的订单仍视为已支付
 	statusInt := 0
-	if order.Status == model.OrderStatusSuccess {
+	if order.Status == model.OrderStatusSuccess || order.Status == model.OrderStatusPartiallyRefunded {
 		statusInt = 1
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"code":         1,
-		"msg":          "查询订单号成功！",
-		"trade_no":     strconv.FormatUint(order.ID, 10),
-		"out_trade_no": order.MerchantOrderNo,
-		"type":         order.PaymentType,
-		"pid":          order.ClientID,
-		"addtime":      order.CreatedAt.Format("2006-01-02 15:04:05"),
-		"endtime":      order.TradeTime.Format("2006-01-02 15:04:05"),
-		"name":         order.OrderName,
-		"money":        order.Amount.Truncate(2).StringFixed(2),
-		"status":       statusInt,
+		"code":           1,
+		"msg":            "查询订单号成功！",
+		"trade_no":       strconv.FormatUint(order.ID, 10),
+		"out_trade_no":   order.MerchantOrderNo,
+		"type":           order.PaymentType,
+		"pid":            order.ClientID,
+		"addtime":        order.CreatedAt.Format("2006-01-02 15:04:05"),
+		"endtime":        order.TradeTime.Format("2006-01-02 15:04:05"),
+		"name":           order.OrderName,
+		"money":          order.Amount.Truncate(2).StringFixed(2),
+		"status":         statusInt,
+		"trade_status":   order.Status,
+		"refunded_money": order.RefundedAmount.StringFixed(2),
 	})
 }
 
 // RefundMerchantOrderResponse 退款响应
 type RefundMerchantOrderResponse struct {
-	Code int    `json:"code" example:"1"`
-	Msg  string `json:"msg" example:"退款成功"`
+	Code          int    `json:"code" example:"1"`
+	Msg           string `json:"msg" example:"退款成功"`
+	RefundNo      string `json:"refund_no" example:"123457"`
+	RefundedMoney string `json:"refunded_money" example:"5.00"`
+	Status        string `json:"status" example:"partially_refunded"`
 }
 
-// RefundMerchantOrder 商户退款接口
+// RefundMerchantOrder 商户退款接口，支持对同一订单多次部分退款，累计不超过订单金额
 // @Tags payment
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "幂等键，重试时保持不变"
 // @Param request body RefundOrderRequest true "退款请求"
 // @Success 200 {object} RefundMerchantOrderResponse
 // @Router /api.php [post]
@@ -251,74 +211,20 @@ func RefundMerchantOrder(c *gin.Context) {
 		return
 	}
 
-	var apiKey model.MerchantAPIKey
-	if err := db.DB(c.Request.Context()).Where("client_id = ? AND client_secret = ?", req.ClientID, req.ClientSecret).First(&apiKey).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": MerchantInfoNotFound})
-		return
-	}
-
-	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
-		var order model.Order
-		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("id = ? AND client_id = ? AND status = ? AND amount = ? AND type IN ?", req.TradeNo, req.ClientID, model.OrderStatusSuccess, req.Amount, []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline}).
-			First(&order).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return errors.New(OrderNotFound)
-			}
-			return err
-		}
-
-		var payerUser model.User
-		if err := payerUser.GetByID(tx, order.PayerUserID); err != nil {
-			return err
-		}
-
-		var merchantUser model.User
-		if err := tx.Where("id = ? AND is_active = ?", apiKey.UserID, true).First(&merchantUser).Error; err != nil {
-			return err
-		}
-
-		var merchantPayConfig model.UserPayConfig
-		if err := merchantPayConfig.GetByPayScore(tx, merchantUser.PayScore); err != nil {
-			return err
-		}
-
-		merchantScoreDecrease := order.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
-		if err := tx.Model(&model.User{}).
-			Where("id = ?", merchantUser.ID).
-			UpdateColumns(map[string]interface{}{
-				"available_balance": gorm.Expr("available_balance - ?", order.Amount),
-				"total_receive":     gorm.Expr("total_receive - ?", order.Amount),
-				"pay_score":         gorm.Expr("pay_score - ?", merchantScoreDecrease),
-			}).Error; err != nil {
-			return err
-		}
-
-		if err := tx.Model(&model.User{}).
-			Where("id = ?", payerUser.ID).
-			UpdateColumns(map[string]interface{}{
-				"available_balance": gorm.Expr("available_balance + ?", order.Amount),
-				"total_payment":     gorm.Expr("total_payment - ?", order.Amount),
-				"pay_score":         gorm.Expr("pay_score - ?", order.Amount.Round(0).IntPart()),
-			}).Error; err != nil {
-			return err
-		}
-
-		if err := tx.Model(&model.Order{}).
-			Where("id = ?", order.ID).
-			Update("status", model.OrderStatusRefund).Error; err != nil {
-			return err
-		}
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
 
-		return nil
-	}); err != nil {
+	order, refundOrder, err := refundMerchantOrder(c.Request.Context(), apiKey, req.TradeNo, req.Amount)
+	if err != nil {
 		c.JSON(http.StatusOK, gin.H{"code": -1, "msg": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"code": 1,
-		"msg":  "退款成功",
+		"code":           1,
+		"msg":            "退款成功",
+		"refund_no":      strconv.FormatUint(refundOrder.ID, 10),
+		"refunded_money": order.RefundedAmount.StringFixed(2),
+		"status":         order.Status,
 	})
 }
 
@@ -336,6 +242,7 @@ type MerchantDistributeRequest struct {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Basic Auth (base64(client_id:client_secret))"
+// @Param Idempotency-Key header string false "幂等键，重试时保持不变"
 // @Param request body MerchantDistributeRequest true "分发请求"
 // @Success 200 {object} util.ResponseAny
 // @Router /pay/distribute [post]
@@ -353,96 +260,24 @@ func MerchantDistribute(c *gin.Context) {
 
 	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
 
-	var orderID uint64
-
-	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
-		// 验证收款人是否存在且用户名匹配
-		var recipient model.User
-		if err := tx.Where("id = ? AND username = ?", req.RecipientID, req.RecipientUsername).First(&recipient).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return errors.New(RecipientNotFound)
-			}
-			return err
-		}
-
-		// 获取商户用户信息
-		var merchantUser model.User
-		if err := tx.Where("id = ? AND is_active = ?", apiKey.UserID, true).
-			First(&merchantUser).Error; err != nil {
-			return errors.New(MerchantInfoNotFound)
-		}
-
-		// 不能分发给自己
-		if recipient.ID == merchantUser.ID {
-			return errors.New(CannotTransferToSelf)
-		}
-
-		// 获取商户支付配置（用于计算分发费率和分数）
-		var merchantPayConfig model.UserPayConfig
-		if err := merchantPayConfig.GetByPayScore(tx, merchantUser.PayScore); err != nil {
-			return errors.New(PayConfigNotFound)
-		}
-
-		_, recipientAmount, distributePercent := service.CalculateFee(req.Amount, merchantPayConfig.DistributeRate)
-		merchantScore := req.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
-
-		order := model.Order{
-			OrderName:       "商户分发",
-			ClientID:        apiKey.ClientID,
-			MerchantOrderNo: req.MerchantOrderNo,
-			PayerUserID:     merchantUser.ID,
-			PayeeUserID:     recipient.ID,
-			Amount:          req.Amount,
-			Status:          model.OrderStatusSuccess,
-			Type:            model.OrderTypeDistribute,
-			Remark:          req.Remark,
-			TradeTime:       time.Now(),
-			ExpiresAt:       time.Now().Add(24 * time.Hour),
-		}
-
-		distributeRemark := fmt.Sprintf("[系统]: 分发费率%d%%", distributePercent)
-		if order.Remark != "" {
-			order.Remark = order.Remark + " " + distributeRemark
-		} else {
-			order.Remark = distributeRemark
-		}
+	if !apiKey.AllowsDistributeIP(c.ClientIP()) {
+		c.JSON(http.StatusForbidden, util.Err(DistributeIPNotAllowed))
+		return
+	}
 
-		if err := tx.Create(&order).Error; err != nil {
-			return err
-		}
-		orderID = order.ID
-
-		// 扣减商户余额，同时增加平台分数
-		if err := service.UpdateBalance(tx, service.BalanceUpdateOptions{
-			UserID:       merchantUser.ID,
-			Amount:       req.Amount,
-			Operation:    service.BalanceDeduct,
-			ScoreChange:  merchantScore,
-			TotalField:   "total_payment",
-			CheckBalance: true,
-		}); err != nil {
-			return err
-		}
+	var order *model.Order
 
-		// 增加收款人余额（按分发费率计算后的金额）
-		if err := service.UpdateBalance(tx, service.BalanceUpdateOptions{
-			UserID:       recipient.ID,
-			Amount:       recipientAmount,
-			Operation:    service.BalanceAdd,
-			TotalField:   "total_receive",
-			CheckBalance: false,
-		}); err != nil {
-			return err
-		}
-
-		return nil
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = distributeInTx(tx, apiKey, &req)
+		return err
 	}); err != nil {
 		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusOK, util.OK(gin.H{
-		"trade_no":     strconv.FormatUint(orderID, 10),
+		"trade_no":     strconv.FormatUint(order.ID, 10),
 		"out_trade_no": req.MerchantOrderNo,
 	}))
 }
@@ -517,16 +352,21 @@ func PayMerchantOrder(c *gin.Context) {
 		return
 	}
 
-	if !orderCtx.CurrentUser.VerifyPayKey(req.PayKey) {
-		c.JSON(http.StatusBadRequest, util.Err(common.PayKeyIncorrect))
+	if err := service.VerifyPayKey(c.Request.Context(), orderCtx.CurrentUser, req.PayKey); err != nil {
+		switch err.Error() {
+		case common.PayKeyIncorrect, common.PayKeyLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
 		return
 	}
 
+	var order model.Order
 	if err := db.DB(c.Request.Context()).Transaction(
 		func(tx *gorm.DB) error {
-			var order model.Order
 			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
-				Where("id = ? AND status = ?", orderCtx.OrderID, model.OrderStatusPending).
+				Where("id = ?", orderCtx.OrderID).
 				First(&order).Error; err != nil {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
 					return errors.New(OrderNotFound)
@@ -534,11 +374,25 @@ func PayMerchantOrder(c *gin.Context) {
 				return err
 			}
 
+			// 商户已取消的订单不可再支付
+			switch order.Status {
+			case model.OrderStatusPending:
+			case model.OrderStatusCancelled:
+				return errors.New(OrderCancelled)
+			default:
+				return errors.New(OrderNotFound)
+			}
+
 			// 检查订单是否过期
 			if order.ExpiresAt.Before(time.Now()) {
 				return errors.New(OrderExpired)
 			}
 
+			// 超过阈值时校验两步验证码
+			if err := service.CheckPaymentTOTP(tx, orderCtx.CurrentUser, order.Amount, req.TOTPCode, time.Now()); err != nil {
+				return err
+			}
+
 			isTestMode := orderCtx.MerchantAPIKey.TestMode
 
 			// 非测试模式：检查每日限额
@@ -548,8 +402,13 @@ func PayMerchantOrder(c *gin.Context) {
 				}
 			}
 
+			// 预授权订单：仅冻结付款方积分，待商户确认收款或撤销后结算
+			if order.ManualCapture {
+				return authorizeMerchantOrder(c.Request.Context(), tx, &order, orderCtx.CurrentUser.ID, isTestMode)
+			}
+
 			// 计算手续费
-			_, merchantAmount, feePercent := service.CalculateFee(order.Amount, orderCtx.MerchantPayConfig.FeeRate)
+			fee, merchantAmount, feePercent := service.CalculateFee(order.Amount, orderCtx.MerchantPayConfig.FeeRate)
 
 			// 更新订单状态
 			order.Status = model.OrderStatusSuccess
@@ -560,6 +419,7 @@ func PayMerchantOrder(c *gin.Context) {
 				order.Type = model.OrderTypeTest
 				order.Remark = common.TestModeOrderRemark
 			} else {
+				order.Fee = fee
 				feeRemark := fmt.Sprintf("[系统]: 收取商家%d%%手续费", feePercent)
 				if order.Remark != "" {
 					order.Remark = order.Remark + " " + feeRemark
@@ -575,27 +435,41 @@ func PayMerchantOrder(c *gin.Context) {
 			// 非测试模式：扣减用户余额和增加商户余额
 			if !isTestMode {
 				if err := service.UpdateBalance(tx, service.BalanceUpdateOptions{
-					UserID:       orderCtx.CurrentUser.ID,
-					Amount:       order.Amount,
-					Operation:    service.BalanceDeduct,
-					ScoreChange:  order.Amount.Round(0).IntPart(),
-					TotalField:   "total_payment",
-					CheckBalance: true,
+					UserID:        orderCtx.CurrentUser.ID,
+					CounterUserID: orderCtx.MerchantUser.ID,
+					OrderID:       order.ID,
+					EntryType:     model.LedgerEntryTypePayment,
+					Amount:        order.Amount,
+					Operation:     service.BalanceDeduct,
+					ScoreChange:   order.Amount.Round(0).IntPart(),
+					TotalField:    "total_payment",
+					CheckBalance:  true,
 				}); err != nil {
 					return err
 				}
 
 				merchantScoreIncrease := order.Amount.Mul(orderCtx.MerchantPayConfig.ScoreRate).Round(0).IntPart()
 				if err := service.UpdateBalance(tx, service.BalanceUpdateOptions{
-					UserID:       orderCtx.MerchantUser.ID,
-					Amount:       merchantAmount,
-					Operation:    service.BalanceAdd,
-					ScoreChange:  merchantScoreIncrease,
-					TotalField:   "total_receive",
-					CheckBalance: false,
+					UserID:        orderCtx.MerchantUser.ID,
+					CounterUserID: orderCtx.CurrentUser.ID,
+					OrderID:       order.ID,
+					EntryType:     model.LedgerEntryTypePayment,
+					Amount:        merchantAmount,
+					Operation:     service.BalanceAdd,
+					ScoreChange:   merchantScoreIncrease,
+					TotalField:    "total_receive",
+					CheckBalance:  false,
 				}); err != nil {
 					return err
 				}
+
+				if err := model.CreatePlatformLedgerEntry(tx, orderCtx.MerchantUser.ID, order.ID, fee, model.LedgerEntryTypeFee); err != nil {
+					return err
+				}
+			}
+
+			if err := service.DispatchWebhookEvent(tx, order.ClientID, model.WebhookEventPaymentSucceeded, order.ID, service.OrderEventData(&order)); err != nil {
+				return err
 			}
 
 			expireKey := db.PrefixedKey(fmt.Sprintf(OrderExpireKeyFormat, order.ID))
@@ -603,12 +477,13 @@ func PayMerchantOrder(c *gin.Context) {
 				log.Printf("[Payment] 删除订单过期key失败: order_id=%d, error=%v", order.ID, err)
 			}
 
-			return service.EnqueueMerchantNotify(order.ID, order.ClientID)
+			return service.EnqueueMerchantNotify(tx, order.ID, order.ClientID)
 		},
 	); err != nil {
 		errMsg := err.Error()
 		switch errMsg {
-		case common.InsufficientBalance, OrderExpired, common.DailyLimitExceeded:
+		case common.InsufficientBalance, OrderExpired, OrderCancelled, common.DailyLimitExceeded,
+			common.TOTPRequired, common.TOTPIncorrect, common.TOTPLocked:
 			c.JSON(http.StatusBadRequest, util.Err(errMsg))
 		case OrderNotFound:
 			c.JSON(http.StatusNotFound, util.Err(errMsg))
@@ -625,6 +500,7 @@ func PayMerchantOrder(c *gin.Context) {
 // @Tags payment
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "幂等键，重试时保持不变"
 // @Param request body TransferRequest true "转账请求"
 // @Success 200 {object} util.ResponseAny
 // @Router /api/v1/payment/transfer [post]
@@ -642,8 +518,23 @@ func Transfer(c *gin.Context) {
 
 	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
 
-	if !currentUser.VerifyPayKey(req.PayKey) {
-		c.JSON(http.StatusBadRequest, util.Err(common.PayKeyIncorrect))
+	if err := service.VerifyPayKey(c.Request.Context(), currentUser, req.PayKey); err != nil {
+		switch err.Error() {
+		case common.PayKeyIncorrect, common.PayKeyLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	if err := service.CheckPaymentTOTP(db.DB(c.Request.Context()), currentUser, req.Amount, req.TOTPCode, time.Now()); err != nil {
+		switch err.Error() {
+		case common.TOTPRequired, common.TOTPIncorrect, common.TOTPLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
 		return
 	}
 
@@ -663,55 +554,15 @@ func Transfer(c *gin.Context) {
 				return err
 			}
 
-			var payer model.User
-			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
-				Where("id = ?", currentUser.ID).
-				First(&payer).Error; err != nil {
-				return err
-			}
-
-			if payer.AvailableBalance.LessThan(req.Amount) {
-				return errors.New(common.InsufficientBalance)
-			}
-
-			// 创建转账订单
-			order := model.Order{
-				OrderName:   "转账",
-				PayerUserID: payer.ID,
-				PayeeUserID: recipient.ID,
-				Amount:      req.Amount,
-				Status:      model.OrderStatusSuccess,
-				Type:        model.OrderTypeTransfer,
-				Remark:      req.Remark,
-				TradeTime:   time.Now(),
-				ExpiresAt:   time.Now().Add(24 * time.Hour),
-			}
-
-			if err := tx.Create(&order).Error; err != nil {
-				return err
-			}
-
-			// 扣减付款人余额
-			if err := tx.Model(&model.User{}).
-				Where("id = ?", payer.ID).
-				UpdateColumns(map[string]interface{}{
-					"available_balance": gorm.Expr("available_balance - ?", req.Amount),
-					"total_transfer":    gorm.Expr("total_transfer + ?", req.Amount),
-				}).Error; err != nil {
-				return err
-			}
-
-			// 增加收款人余额
-			if err := tx.Model(&model.User{}).
-				Where("id = ?", recipient.ID).
-				UpdateColumns(map[string]interface{}{
-					"available_balance": gorm.Expr("available_balance + ?", req.Amount),
-					"total_receive":     gorm.Expr("total_receive + ?", req.Amount),
-				}).Error; err != nil {
-				return err
+			// 通过个人访问令牌转账时校验令牌的转账限额
+			if token, ok := util.GetFromContext[*model.UserAccessToken](c, oauth.AccessTokenObjKey); ok {
+				if err := service.ConsumeAccessTokenTransfer(tx, token.ID, req.Amount); err != nil {
+					return err
+				}
 			}
 
-			return nil
+			_, err := transferInTx(tx, currentUser.ID, &recipient, req.Amount, req.Remark)
+			return err
 		},
 	); err != nil {
 		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
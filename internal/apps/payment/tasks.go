@@ -17,6 +17,7 @@ limitations under the License.
 package payment
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -26,31 +27,113 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hibiken/asynq"
 	"github.com/linux-do/credit/internal/common"
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/linux-do/credit/internal/util"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// HandleReleaseExpiredHolds 处理到期的冻结：预授权订单全额退回付款方，担保交易自动放款给收款方
+func HandleReleaseExpiredHolds(ctx context.Context, _ *asynq.Task) error {
+	pageSize := 500
+	lastID := uint64(0)
+	totalReleased := 0
+	now := time.Now()
+
+	for {
+		var holds []model.PaymentHold
+		if err := db.DB(ctx).
+			Where("id > ? AND status = ? AND expires_at <= ?", lastID, model.PaymentHoldStatusHeld, now).
+			Order("id ASC").
+			Limit(pageSize).
+			Find(&holds).Error; err != nil {
+			logger.ErrorF(ctx, "查询超时预授权冻结失败: %v", err)
+			return err
+		}
+
+		if len(holds) == 0 {
+			break
+		}
+
+		for _, h := range holds {
+			released, err := releaseExpiredHold(ctx, h.ID)
+			if err != nil {
+				logger.ErrorF(ctx, "释放预授权冻结[ID:%d]失败: %v", h.ID, err)
+				continue
+			}
+			if released {
+				totalReleased++
+			}
+		}
+
+		lastID = holds[len(holds)-1].ID
+	}
+
+	logger.InfoF(ctx, "预授权冻结释放完成，共释放 %d 笔", totalReleased)
+	return nil
+}
+
+// releaseExpiredHold 处理单笔到期冻结并下发撤销事件，已被确认、撤销或争议中时返回 false
+func releaseExpiredHold(ctx context.Context, holdID uint64) (bool, error) {
+	var order model.Order
+	var released bool
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var hold model.PaymentHold
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
+			Where("id = ? AND status = ?", holdID, model.PaymentHoldStatusHeld).
+			First(&hold).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", hold.OrderID, model.OrderStatusAuthorized).
+			First(&order).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil // 争议中，待争议处理完成
+			}
+			return err
+		}
+
+		released = true
+		if order.Type == model.OrderTypeEscrow {
+			return service.ReleaseEscrow(tx, &hold, &order, model.OrderStatusSuccess)
+		}
+		if err := service.ReleaseHold(tx, &hold, &order, model.PaymentHoldStatusReleased, model.OrderStatusExpired); err != nil {
+			return err
+		}
+		return service.DispatchWebhookEvent(tx, order.ClientID, model.WebhookEventPaymentVoided, order.ID, service.OrderEventData(&order))
+	}); err != nil {
+		return false, err
+	}
+	return released, nil
+}
+
 // HandleMerchantPaymentNotify 处理商户支付回调任务
 func HandleMerchantPaymentNotify(ctx context.Context, t *asynq.Task) error {
 	// 解析任务参数
 	var payload struct {
 		OrderID  uint64 `json:"order_id"`
 		ClientID string `json:"client_id"`
+		Manual   bool   `json:"manual"`
 	}
 	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
 		logger.ErrorF(ctx, "解析商户回调任务参数失败: %v", err)
 		return fmt.Errorf("解析任务参数失败: %w", err)
 	}
 
-	// 查询订单信息
+	// 查询订单信息（部分退款的订单仍可重新投递支付成功通知，预授权订单投递待确认收款通知）
 	var order model.Order
-	if err := db.DB(ctx).Where("id = ? AND status = ?", payload.OrderID, model.OrderStatusSuccess).First(&order).Error; err != nil {
+	if err := db.DB(ctx).Where("id = ? AND status IN ?", payload.OrderID, append([]model.OrderStatus{model.OrderStatusAuthorized}, model.OrderPaidStatuses...)).First(&order).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.ErrorF(ctx, "订单[ID:%d]不存在，跳过回调", payload.OrderID)
 			return nil
@@ -74,13 +157,50 @@ func HandleMerchantPaymentNotify(ctx context.Context, t *asynq.Task) error {
 		"name":         order.OrderName,
 		"money":        order.Amount.Truncate(2).StringFixed(2),
 		"trade_status": "TRADE_SUCCESS",
-		"sign_type":    "MD5",
 	}
+	if order.Status == model.OrderStatusAuthorized {
+		callbackParams["trade_status"] = "TRADE_AUTHORIZED"
+	}
+
+	// 按应用配置的签名方式签名，HMAC-SHA256 额外携带 timestamp 与 nonce 供商户防重放
+	signType := apiKey.SignType
+	if signType == "" {
+		signType = model.SignTypeMD5
+	}
+	callbackParams["sign_type"] = string(signType)
+	if signType == model.SignTypeHMACSHA256 {
+		callbackParams["timestamp"] = strconv.FormatInt(time.Now().Unix(), 10)
+		callbackParams["nonce"] = util.GenerateUniqueIDSimple()
+	}
+
+	callbackParams["sign"] = GenerateSignature(callbackParams, apiKey.ClientSecret, signType)
 
-	callbackParams["sign"] = GenerateSignature(callbackParams, apiKey.ClientSecret)
+	retried, _ := asynq.GetRetryCount(ctx)
+	paramsJSON, _ := json.Marshal(callbackParams)
+	delivery := model.WebhookDelivery{
+		APIKeyID:   apiKey.ID,
+		OrderID:    order.ID,
+		URL:        apiKey.NotifyURL,
+		Params:     string(paramsJSON),
+		RetryCount: retried,
+		Manual:     payload.Manual,
+	}
+
+	start := time.Now()
+	statusCode, respBody, err := sendCallbackRequest(ctx, apiKey.NotifyURL, callbackParams)
+	delivery.LatencyMs = time.Since(start).Milliseconds()
+	delivery.StatusCode = statusCode
+	delivery.ResponseBody = truncateResponseBody(respBody)
+	delivery.Success = err == nil
+	if err != nil {
+		delivery.ErrorMsg = truncateResponseBody(err.Error())
+	}
+
+	if errCreate := db.DB(ctx).Create(&delivery).Error; errCreate != nil {
+		logger.ErrorF(ctx, "保存商户回调投递记录失败: 订单[ID:%d] 错误: %v", payload.OrderID, errCreate)
+	}
 
-	if err := sendCallbackRequest(ctx, apiKey.NotifyURL, callbackParams); err != nil {
-		retried, _ := asynq.GetRetryCount(ctx)
+	if err != nil {
 		logger.ErrorF(ctx, "商户回调失败: 订单[ID:%d] 重试次数[%d] 错误: %v",
 			payload.OrderID, retried+1, err)
 		return err
@@ -90,8 +210,114 @@ func HandleMerchantPaymentNotify(ctx context.Context, t *asynq.Task) error {
 	return nil
 }
 
-// sendCallbackRequest 发送HTTP回调请求
-func sendCallbackRequest(ctx context.Context, callbackURL string, params map[string]string) error {
+// HandleMerchantWebhookEvent 处理商户事件回调任务，以 JSON POST 投递已签名的事件
+func HandleMerchantWebhookEvent(ctx context.Context, t *asynq.Task) error {
+	// 解析任务参数
+	var payload struct {
+		APIKeyID uint64             `json:"api_key_id"`
+		OrderID  uint64             `json:"order_id"`
+		Event    model.WebhookEvent `json:"event"`
+		Body     string             `json:"body"`
+		Manual   bool               `json:"manual"`
+	}
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		logger.ErrorF(ctx, "解析商户事件回调任务参数失败: %v", err)
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	// 查询商户API Key信息，应用已删除时不再投递
+	var apiKey model.MerchantAPIKey
+	if err := apiKey.GetByID(db.DB(ctx), payload.APIKeyID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.ErrorF(ctx, "商户应用[ID:%d]不存在，跳过事件回调", payload.APIKeyID)
+			return nil
+		}
+		return fmt.Errorf("查询商户信息失败: %w", err)
+	}
+
+	var event service.WebhookEventPayload
+	if err := json.Unmarshal([]byte(payload.Body), &event); err != nil {
+		logger.ErrorF(ctx, "解析事件回调请求体失败: %v", err)
+		return nil
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	webhookURL := apiKey.EventWebhookURL()
+	delivery := model.WebhookDelivery{
+		APIKeyID:   apiKey.ID,
+		OrderID:    payload.OrderID,
+		Event:      payload.Event,
+		URL:        webhookURL,
+		Params:     payload.Body,
+		RetryCount: retried,
+		Manual:     payload.Manual,
+	}
+
+	// 每次投递使用当前时间戳签名，商户可据此拒绝过旧的请求
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	headers := map[string]string{
+		"User-Agent":           "LinuxDo-Credit/1.0",
+		"Content-Type":         "application/json",
+		WebhookEventHeader:     string(payload.Event),
+		WebhookEventIDHeader:   event.ID,
+		WebhookTimestampHeader: timestamp,
+		WebhookSignatureHeader: GenerateWebhookSignature(timestamp, []byte(payload.Body), apiKey.ClientSecret),
+	}
+
+	start := time.Now()
+	statusCode, respBody, err := sendEventRequest(ctx, webhookURL, []byte(payload.Body), headers)
+	delivery.LatencyMs = time.Since(start).Milliseconds()
+	delivery.StatusCode = statusCode
+	delivery.ResponseBody = truncateResponseBody(respBody)
+	delivery.Success = err == nil
+	if err != nil {
+		delivery.ErrorMsg = truncateResponseBody(err.Error())
+	}
+
+	if errCreate := db.DB(ctx).Create(&delivery).Error; errCreate != nil {
+		logger.ErrorF(ctx, "保存商户回调投递记录失败: 事件[%s] 错误: %v", event.ID, errCreate)
+	}
+
+	if err != nil {
+		logger.ErrorF(ctx, "商户事件回调失败: 事件[%s:%s] 重试次数[%d] 错误: %v",
+			payload.Event, event.ID, retried+1, err)
+		return err
+	}
+
+	logger.InfoF(ctx, "商户事件回调成功: 事件[%s:%s] 应用[ID:%d]", payload.Event, event.ID, apiKey.ID)
+	return nil
+}
+
+// sendEventRequest 以 JSON POST 发送事件回调，2xx 视为成功
+func sendEventRequest(ctx context.Context, webhookURL string, body []byte, headers map[string]string) (int, string, error) {
+	resp, err := util.Request(ctx, http.MethodPost, webhookURL, bytes.NewReader(body), headers, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 4*model.WebhookResponseBodyMaxLength))
+	if err != nil {
+		return resp.StatusCode, "", fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, string(respBody), fmt.Errorf("回调返回异常状态码: %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, string(respBody), nil
+}
+
+// truncateResponseBody 截断响应内容，保证不超过投递记录字段长度且为合法 UTF-8
+func truncateResponseBody(body string) string {
+	if len(body) <= model.WebhookResponseBodyMaxLength {
+		return body
+	}
+	return strings.ToValidUTF8(body[:model.WebhookResponseBodyMaxLength], "")
+}
+
+// sendCallbackRequest 发送HTTP回调请求，返回响应状态码与响应内容
+func sendCallbackRequest(ctx context.Context, callbackURL string, params map[string]string) (int, string, error) {
 	vals := url.Values{}
 	for k, v := range params {
 		vals.Add(k, v)
@@ -110,24 +336,24 @@ func sendCallbackRequest(ctx context.Context, callbackURL string, params map[str
 
 	resp, err := util.Request(ctx, http.MethodGet, targetURL, nil, headers, nil)
 	if err != nil {
-		return err
+		return 0, "", err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("回调返回异常状态码: %d", resp.StatusCode)
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 4*model.WebhookResponseBodyMaxLength))
+	if err != nil {
+		return resp.StatusCode, "", fmt.Errorf("读取响应失败: %w", err)
 	}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("读取响应失败: %w", err)
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, string(respBody), fmt.Errorf("回调返回异常状态码: %d", resp.StatusCode)
 	}
 
 	responseText := strings.TrimSpace(strings.ToLower(string(respBody)))
 	if responseText != "success" {
-		return fmt.Errorf("回调返回非成功响应: %s", string(respBody))
+		return resp.StatusCode, string(respBody), fmt.Errorf("回调返回非成功响应: %s", truncateResponseBody(string(respBody)))
 	}
 
 	logger.InfoF(ctx, "商户回调请求成功: URL[%s] 响应[%s]", callbackURL, string(respBody))
-	return nil
+	return resp.StatusCode, string(respBody), nil
 }
@@ -17,25 +17,35 @@ limitations under the License.
 package payment
 
 import (
+	"context"
+	"crypto/hmac"
 	"crypto/md5"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/linux-do/credit/internal/apps/oauth"
 	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/config"
 	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
 	"github.com/linux-do/credit/internal/service"
 	"github.com/linux-do/credit/internal/util"
 	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // HandleParseOrderNoError 处理 ParseOrderNo 返回的错误，返回对应的 HTTP 响应
@@ -151,15 +161,13 @@ func ParseOrderNo(c *gin.Context, orderNo string) (*OrderContext, error) {
 	return ctx, nil
 }
 
-// GenerateSignature 生成MD5签名
-func GenerateSignature(params map[string]string, secret string) string {
-	// 按key排序
+// buildSignContent 构建待签名字符串：参数按 key 排序，sign、sign_type 与空值不参与签名
+func buildSignContent(params map[string]string) string {
 	keys := make([]string, 0, len(params))
 	for k := range params {
 		if k == "sign" || k == "sign_type" {
 			continue
 		}
-		// 空值不参与签名
 		if params[k] == "" {
 			continue
 		}
@@ -168,7 +176,6 @@ func GenerateSignature(params map[string]string, secret string) string {
 
 	sort.Strings(keys)
 
-	// 拼接签名字符串
 	var builder strings.Builder
 	builder.Grow(256)
 	for i, k := range keys {
@@ -179,14 +186,48 @@ func GenerateSignature(params map[string]string, secret string) string {
 		builder.WriteByte('=')
 		builder.WriteString(params[k])
 	}
-	builder.WriteString(secret)
+	return builder.String()
+}
+
+// GenerateSignature 生成签名
+// MD5：md5(待签名字符串 + 密钥)；HMAC-SHA256：以密钥对待签名字符串计算 HMAC，均输出小写十六进制
+func GenerateSignature(params map[string]string, secret string, signType model.SignType) string {
+	content := buildSignContent(params)
+
+	if signType == model.SignTypeHMACSHA256 {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(content))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
 
-	// MD5加密
-	hash := md5.Sum([]byte(builder.String()))
+	hash := md5.Sum([]byte(content + secret))
 	return fmt.Sprintf("%x", hash)
 }
 
-// VerifySignature 验证MD5签名
+// GenerateWebhookSignature 生成事件回调签名：以密钥对 "timestamp.请求体" 计算 HMAC-SHA256，输出小写十六进制
+func GenerateWebhookSignature(timestamp string, body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// matchSignature 以任一签名密钥计算的签名与请求签名一致即通过，使用常量时间比较
+func matchSignature(params map[string]string, secrets []string, signType model.SignType, sign string) bool {
+	matched := false
+	for _, secret := range secrets {
+		expectedSign := GenerateSignature(params, secret, signType)
+		if subtle.ConstantTimeCompare([]byte(strings.ToLower(expectedSign)), []byte(strings.ToLower(sign))) == 1 {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// VerifySignature 验证签名
+// sign_type 为空时按 MD5 处理；签名请求必须携带 timestamp 与 nonce 以防止重放，
+// 仅开启旧版签名兼容的应用允许 MD5 签名不携带这两个字段
 func VerifySignature(c *gin.Context, apiKey *model.MerchantAPIKey) (*CreateOrderRequest, error) {
 	var req EPayRequest
 	if err := c.ShouldBindWith(&req, binding.FormPost); err != nil {
@@ -198,29 +239,591 @@ func VerifySignature(c *gin.Context, apiKey *model.MerchantAPIKey) (*CreateOrder
 		return nil, err
 	}
 
+	signType := model.SignType(strings.ToUpper(req.SignType))
+	switch signType {
+	case "":
+		signType = model.SignTypeMD5
+	case model.SignTypeMD5, model.SignTypeHMACSHA256:
+	default:
+		return nil, errors.New(SignTypeUnsupported)
+	}
+
+	// 查询商户API Key
 	if err := apiKey.GetByClientID(db.DB(c.Request.Context()), req.ClientID); err != nil {
 		return nil, err
 	}
 
+	// 已切换到 HMAC-SHA256 的应用不再接受 MD5 签名
+	if apiKey.SignType == model.SignTypeHMACSHA256 && signType != model.SignTypeHMACSHA256 {
+		return nil, errors.New(SignTypeMismatch)
+	}
+
 	// 构建签名参数
 	params := map[string]string{
-		"pid":          req.ClientID,
-		"type":         req.PayType,
-		"out_trade_no": util.DerefString(req.MerchantOrderNo),
-		"notify_url":   req.NotifyURL,
-		"return_url":   req.ReturnURL,
-		"name":         req.OrderName,
-		"money":        req.Amount.Truncate(2).StringFixed(2),
-		"device":       req.Device,
+		"pid":            req.ClientID,
+		"type":           req.PayType,
+		"out_trade_no":   util.DerefString(req.MerchantOrderNo),
+		"notify_url":     req.NotifyURL,
+		"return_url":     req.ReturnURL,
+		"name":           req.OrderName,
+		"money":          req.Amount.Truncate(2).StringFixed(2),
+		"device":         req.Device,
+		"timestamp":      req.Timestamp,
+		"nonce":          req.Nonce,
+		"expire_minutes": req.ExpireMinutes,
+	}
+
+	// 常量时间比较签名（防止时序攻击），签名密钥轮换重叠期内新旧密钥均可通过
+	if !matchSignature(params, apiKey.SigningSecrets(time.Now()), signType, req.Sign) {
+		return nil, errors.New(SignatureInvalid)
+	}
+
+	// 签名通过后再校验时间戳与 nonce，避免未签名请求占用 nonce
+	if requiresSignTimestamp(apiKey, signType, req.Timestamp, req.Nonce) {
+		if err := verifySignTimestamp(c.Request.Context(), req.ClientID, req.Timestamp, req.Nonce); err != nil {
+			return nil, err
+		}
+	}
+
+	createReq := req.ToCreateOrderRequest()
+	if req.ExpireMinutes != "" {
+		expireMinutes, err := strconv.Atoi(req.ExpireMinutes)
+		if err != nil || expireMinutes <= 0 {
+			return nil, errors.New(ExpireMinutesInvalid)
+		}
+		createReq.ExpireMinutes = expireMinutes
+	}
+
+	return createReq, nil
+}
+
+// requiresSignTimestamp 签名请求是否须校验 timestamp 与 nonce
+// HMAC-SHA256 始终校验；MD5 仅在应用开启旧版签名兼容且两个字段均未携带时跳过
+func requiresSignTimestamp(apiKey *model.MerchantAPIKey, signType model.SignType, timestamp, nonce string) bool {
+	if signType == model.SignTypeHMACSHA256 || timestamp != "" || nonce != "" {
+		return true
+	}
+	return !apiKey.LegacySignAllowed
+}
+
+// checkSignTimestamp 校验 timestamp 与 nonce 的格式及时间戳偏差
+func checkSignTimestamp(timestamp, nonce string, now time.Time, skew time.Duration) error {
+	if timestamp == "" || nonce == "" {
+		return errors.New(SignTimestampRequired)
+	}
+	if len(nonce) > signNonceMaxLength {
+		return errors.New(SignNonceInvalid)
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New(SignTimestampInvalid)
+	}
+
+	diff := now.Sub(time.Unix(ts, 0))
+	if diff > skew || diff < -skew {
+		return errors.New(SignTimestampInvalid)
+	}
+	return nil
+}
+
+// verifySignTimestamp 校验签名时间戳偏差，并通过 Redis 保证 nonce 在有效期内只能使用一次
+func verifySignTimestamp(ctx context.Context, clientID, timestamp, nonce string) error {
+	skewSeconds, err := model.GetIntByKey(ctx, model.ConfigKeySignTimestampSkewSeconds)
+	if err != nil {
+		return err
+	}
+	skew := time.Duration(skewSeconds) * time.Second
+
+	if err := checkSignTimestamp(timestamp, nonce, time.Now(), skew); err != nil {
+		return err
+	}
+
+	// nonce 保留两倍偏差窗口，覆盖时间戳可被接受的全部区间
+	nonceKey := db.PrefixedKey(fmt.Sprintf(SignNonceKeyFormat, clientID, nonce))
+	ok, err := db.Redis.SetNX(ctx, nonceKey, timestamp, 2*skew).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New(SignNonceReused)
+	}
+
+	return nil
+}
+
+// createMerchantOrder 创建待支付的商户订单，返回订单与收银台地址
+func createMerchantOrder(ctx context.Context, apiKey *model.MerchantAPIKey, req *CreateOrderRequest) (*model.Order, string, error) {
+	// 获取商户用户信息
+	var merchantUser model.User
+	if err := db.DB(ctx).Where("id = ? AND is_active = ?", apiKey.UserID, true).First(&merchantUser).Error; err != nil {
+		return nil, "", errors.New(MerchantInfoNotFound)
+	}
+
+	expireMinutes, errGet := resolveOrderExpireMinutes(ctx, apiKey, req.ExpireMinutes)
+	if errGet != nil {
+		return nil, "", errGet
+	}
+	expireDuration := time.Duration(expireMinutes) * time.Minute
+
+	order := model.Order{
+		OrderName:       req.OrderName,
+		ClientID:        apiKey.ClientID,
+		MerchantOrderNo: req.MerchantOrderNo,
+		PayeeUserID:     merchantUser.ID,
+		Amount:          req.Amount,
+		Status:          model.OrderStatusPending,
+		Type:            model.OrderTypePayment,
+		Remark:          req.Remark,
+		PaymentType:     req.PaymentType,
+		ManualCapture:   req.ManualCapture,
+		ExpiresAt:       time.Now().Add(expireDuration),
+	}
+
+	var payURL string
+	if err := db.DB(ctx).Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Create(&order).Error; err != nil {
+				return err
+			}
+
+			encryptString, err := util.Encrypt(merchantUser.SignKey, strconv.FormatUint(order.ID, 10))
+			if err != nil {
+				return err
+			}
+
+			merchantIDStr := strconv.FormatUint(merchantUser.ID, 10)
+			if errSet := db.Redis.Set(ctx, db.PrefixedKey(fmt.Sprintf(OrderMerchantIDCacheKeyFormat, encryptString)), merchantIDStr, expireDuration).Err(); errSet != nil {
+				return fmt.Errorf("failed to set redis key: %w", errSet)
+			}
+
+			// 记录订单ID对应的加密订单号，用于查询收银台地址及取消订单时清理缓存
+			tokenKey := db.PrefixedKey(fmt.Sprintf(OrderTokenKeyFormat, order.ID))
+			if errSet := db.Redis.Set(ctx, tokenKey, encryptString, expireDuration).Err(); errSet != nil {
+				return fmt.Errorf("failed to set order token key: %w", errSet)
+			}
+
+			expireKey := db.PrefixedKey(fmt.Sprintf(OrderExpireKeyFormat, order.ID))
+			if errSet := db.Redis.Set(ctx, expireKey, order.ID, expireDuration).Err(); errSet != nil {
+				return fmt.Errorf("failed to set order expire key: %w", errSet)
+			}
+
+			payURL = buildPayURL(encryptString)
+			return nil
+		},
+	); err != nil {
+		return nil, "", err
+	}
+
+	return &order, payURL, nil
+}
+
+// resolveOrderExpireMinutes 确定订单过期时间（分钟）
+// 优先使用请求指定值（超出系统范围时报错），其次使用应用默认值（按系统范围截断），最后使用系统默认值
+func resolveOrderExpireMinutes(ctx context.Context, apiKey *model.MerchantAPIKey, requested int) (int, error) {
+	if requested == 0 && apiKey.ExpireMinutes == 0 {
+		return model.GetIntByKey(ctx, model.ConfigKeyMerchantOrderExpireMinutes)
+	}
+
+	minMinutes, err := model.GetIntByKey(ctx, model.ConfigKeyMerchantOrderExpireMin)
+	if err != nil {
+		return 0, err
+	}
+	maxMinutes, err := model.GetIntByKey(ctx, model.ConfigKeyMerchantOrderExpireMax)
+	if err != nil {
+		return 0, err
+	}
+
+	if requested != 0 {
+		if requested < minMinutes || requested > maxMinutes {
+			return 0, errors.New(ExpireMinutesOutRange)
+		}
+		return requested, nil
+	}
+
+	return min(max(apiKey.ExpireMinutes, minMinutes), maxMinutes), nil
+}
+
+// buildPayURL 根据加密订单号构建收银台地址
+func buildPayURL(encryptString string) string {
+	return fmt.Sprintf("%s?order_no=%s", config.Config.App.FrontendPayURL, url.QueryEscape(encryptString))
+}
+
+// getOrderPayURL 获取待支付订单的收银台地址，订单已不可支付时返回空字符串
+func getOrderPayURL(ctx context.Context, orderID uint64) string {
+	encryptString, err := db.Redis.Get(ctx, db.PrefixedKey(fmt.Sprintf(OrderTokenKeyFormat, orderID))).Result()
+	if err != nil {
+		return ""
+	}
+	return buildPayURL(encryptString)
+}
+
+// refundMerchantOrder 商户退款，返回原订单与退款子订单，并下发退款事件
+func refundMerchantOrder(ctx context.Context, apiKey *model.MerchantAPIKey, tradeNo uint64, amount decimal.Decimal) (*model.Order, *model.Order, error) {
+	var order model.Order
+	var refundOrder *model.Order
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND client_id = ? AND status IN ? AND type IN ?", tradeNo, apiKey.ClientID, model.OrderPaidStatuses, []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline}).
+			First(&order).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(OrderNotFound)
+			}
+			return err
+		}
+
+		var merchantUser model.User
+		if err := tx.Where("id = ? AND is_active = ?", apiKey.UserID, true).First(&merchantUser).Error; err != nil {
+			return err
+		}
+
+		var merchantPayConfig model.UserPayConfig
+		if err := merchantPayConfig.GetByPayScore(tx, merchantUser.PayScore); err != nil {
+			return err
+		}
+
+		var err error
+		refundOrder, err = service.RefundOrder(tx, &order, amount, merchantPayConfig.ScoreRate)
+		if err != nil {
+			return err
+		}
+
+		return service.DispatchWebhookEvent(tx, apiKey.ClientID, model.WebhookEventPaymentRefunded, order.ID, service.RefundEventData(&order, refundOrder))
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	return &order, refundOrder, nil
+}
+
+// cancelMerchantOrder 商户取消待支付订单，并清理订单相关缓存使收银台地址失效
+func cancelMerchantOrder(ctx context.Context, apiKey *model.MerchantAPIKey, tradeNo uint64) (*model.Order, error) {
+	var order model.Order
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND client_id = ? AND status = ? AND type = ?", tradeNo, apiKey.ClientID, model.OrderStatusPending, model.OrderTypePayment).
+			First(&order).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(OrderNotCancelable)
+			}
+			return err
+		}
+
+		if err := tx.Model(&order).Update("status", model.OrderStatusCancelled).Error; err != nil {
+			return err
+		}
+
+		if err := service.DispatchWebhookEvent(tx, apiKey.ClientID, model.WebhookEventOrderCancelled, order.ID, service.OrderEventData(&order)); err != nil {
+			return err
+		}
+
+		// 与状态更新在同一事务内清理缓存，清理失败时回滚，避免订单已取消而过期监听仍在
+		tokenKey := db.PrefixedKey(fmt.Sprintf(OrderTokenKeyFormat, order.ID))
+		keys := []string{tokenKey, db.PrefixedKey(fmt.Sprintf(OrderExpireKeyFormat, order.ID))}
+		encryptString, errGet := db.Redis.Get(ctx, tokenKey).Result()
+		if errGet == nil {
+			keys = append(keys, db.PrefixedKey(fmt.Sprintf(OrderMerchantIDCacheKeyFormat, encryptString)))
+		} else if !errors.Is(errGet, redis.Nil) {
+			return errGet
+		}
+		return db.Redis.Del(ctx, keys...).Err()
+	}); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// authorizeMerchantOrder 预授权支付商户订单，冻结付款方积分，并在同一事务内下发商户回调与预授权事件，order 需由调用方加行锁
+func authorizeMerchantOrder(ctx context.Context, tx *gorm.DB, order *model.Order, payerUserID uint64, isTestMode bool) error {
+	holdHours, err := model.GetIntByKey(ctx, model.ConfigKeyPaymentHoldExpireHours)
+	if err != nil {
+		return err
+	}
+
+	order.Status = model.OrderStatusAuthorized
+	order.PayerUserID = payerUserID
+	order.TradeTime = time.Now()
+	if isTestMode {
+		order.Type = model.OrderTypeTest
+		order.Remark = common.TestModeOrderRemark
+	}
+	if err := tx.Save(order).Error; err != nil {
+		return err
 	}
 
-	// 生成期望的签名
-	expectedSign := GenerateSignature(params, apiKey.ClientSecret)
+	if _, err := service.AuthorizeOrder(tx, order, order.TradeTime.Add(time.Duration(holdHours)*time.Hour)); err != nil {
+		return err
+	}
+
+	if err := service.DispatchWebhookEvent(tx, order.ClientID, model.WebhookEventPaymentAuthorized, order.ID, service.OrderEventData(order)); err != nil {
+		return err
+	}
 
-	// 常量时间比较签名（防止时序攻击）
-	if subtle.ConstantTimeCompare([]byte(strings.ToLower(expectedSign)), []byte(strings.ToLower(req.Sign))) != 1 {
-		return nil, errors.New("签名验证失败")
+	expireKey := db.PrefixedKey(fmt.Sprintf(OrderExpireKeyFormat, order.ID))
+	if err := db.Redis.Del(ctx, expireKey).Err(); err != nil {
+		logger.ErrorF(ctx, "删除订单过期key失败: 订单[ID:%d] 错误: %v", order.ID, err)
+	}
+
+	return service.EnqueueMerchantNotify(tx, order.ID, order.ClientID)
+}
+
+// lockAuthorizedOrder 锁定商户的预授权订单及其冻结记录
+func lockAuthorizedOrder(tx *gorm.DB, apiKey *model.MerchantAPIKey, tradeNo uint64) (*model.Order, *model.PaymentHold, error) {
+	var order model.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND client_id = ? AND status = ?", tradeNo, apiKey.ClientID, model.OrderStatusAuthorized).
+		First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New(OrderNotAuthorized)
+		}
+		return nil, nil, err
 	}
 
-	return req.ToCreateOrderRequest(), nil
+	var hold model.PaymentHold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status = ?", order.ID, model.PaymentHoldStatusHeld).
+		First(&hold).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New(OrderNotAuthorized)
+		}
+		return nil, nil, err
+	}
+	return &order, &hold, nil
+}
+
+// captureMerchantOrder 商户确认收款，amount 为 0 时确认全部冻结金额，确认后下发商户回调与支付成功事件
+func captureMerchantOrder(ctx context.Context, apiKey *model.MerchantAPIKey, tradeNo uint64, amount decimal.Decimal) (*model.Order, *model.PaymentHold, error) {
+	var order *model.Order
+	var hold *model.PaymentHold
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, hold, err = lockAuthorizedOrder(tx, apiKey, tradeNo)
+		if err != nil {
+			return err
+		}
+
+		var merchantUser model.User
+		if err := tx.Where("id = ? AND is_active = ?", apiKey.UserID, true).First(&merchantUser).Error; err != nil {
+			return err
+		}
+
+		var merchantPayConfig model.UserPayConfig
+		if err := merchantPayConfig.GetByPayScore(tx, merchantUser.PayScore); err != nil {
+			return err
+		}
+
+		if amount.IsZero() {
+			amount = hold.Amount
+		}
+		if err := service.CaptureHold(tx, hold, order, amount, &merchantPayConfig); err != nil {
+			return err
+		}
+
+		if err := service.EnqueueMerchantNotify(tx, order.ID, order.ClientID); err != nil {
+			return err
+		}
+
+		return service.DispatchWebhookEvent(tx, apiKey.ClientID, model.WebhookEventPaymentSucceeded, order.ID, service.OrderEventData(order))
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	return order, hold, nil
+}
+
+// voidMerchantOrder 商户撤销预授权订单，冻结积分全额退回付款方，并下发撤销事件
+func voidMerchantOrder(ctx context.Context, apiKey *model.MerchantAPIKey, tradeNo uint64) (*model.Order, *model.PaymentHold, error) {
+	var order *model.Order
+	var hold *model.PaymentHold
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, hold, err = lockAuthorizedOrder(tx, apiKey, tradeNo)
+		if err != nil {
+			return err
+		}
+		if err := service.ReleaseHold(tx, hold, order, model.PaymentHoldStatusVoided, model.OrderStatusCancelled); err != nil {
+			return err
+		}
+
+		return service.DispatchWebhookEvent(tx, apiKey.ClientID, model.WebhookEventPaymentVoided, order.ID, service.OrderEventData(order))
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	return order, hold, nil
+}
+
+// transferInTx 在事务内完成用户间转账，锁定付款人并校验余额，返回转账订单
+func transferInTx(tx *gorm.DB, payerUserID uint64, recipient *model.User, amount decimal.Decimal, remark string) (*model.Order, error) {
+	var payer model.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
+		Where("id = ?", payerUserID).
+		First(&payer).Error; err != nil {
+		return nil, err
+	}
+
+	if payer.AvailableBalance.LessThan(amount) {
+		return nil, errors.New(common.InsufficientBalance)
+	}
+
+	// 创建转账订单
+	order := model.Order{
+		OrderName:   "转账",
+		PayerUserID: payer.ID,
+		PayeeUserID: recipient.ID,
+		Amount:      amount,
+		Status:      model.OrderStatusSuccess,
+		Type:        model.OrderTypeTransfer,
+		Remark:      remark,
+		TradeTime:   time.Now(),
+		ExpiresAt:   time.Now().Add(24 * time.Hour),
+	}
+
+	if err := tx.Create(&order).Error; err != nil {
+		return nil, err
+	}
+
+	// 扣减付款人余额
+	if err := service.UpdateBalance(tx, service.BalanceUpdateOptions{
+		UserID:        payer.ID,
+		CounterUserID: recipient.ID,
+		OrderID:       order.ID,
+		EntryType:     model.LedgerEntryTypeTransfer,
+		Amount:        amount,
+		Operation:     service.BalanceDeduct,
+		TotalField:    "total_transfer",
+		CheckBalance:  true,
+	}); err != nil {
+		return nil, err
+	}
+
+	// 增加收款人余额
+	if err := service.UpdateBalance(tx, service.BalanceUpdateOptions{
+		UserID:        recipient.ID,
+		CounterUserID: payer.ID,
+		OrderID:       order.ID,
+		EntryType:     model.LedgerEntryTypeTransfer,
+		Amount:        amount,
+		Operation:     service.BalanceAdd,
+		TotalField:    "total_receive",
+	}); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// distributeInTx 在事务内完成商户分发，校验收款人并按分发费率入账，并下发 distribute.succeeded 事件，返回分发订单
+func distributeInTx(tx *gorm.DB, apiKey *model.MerchantAPIKey, req *MerchantDistributeRequest) (*model.Order, error) {
+	var recipient model.User
+
+	// 验证收款人是否存在且用户名匹配
+	if err := tx.Where("id = ? AND username = ?", req.RecipientID, req.RecipientUsername).First(&recipient).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(RecipientNotFound)
+		}
+		return nil, err
+	}
+
+	// 获取商户用户信息
+	var merchantUser model.User
+	if err := tx.Where("id = ? AND is_active = ?", apiKey.UserID, true).
+		First(&merchantUser).Error; err != nil {
+		return nil, errors.New(MerchantInfoNotFound)
+	}
+
+	// 不能分发给自己
+	if recipient.ID == merchantUser.ID {
+		return nil, errors.New(CannotTransferToSelf)
+	}
+
+	// 检查应用分发限额
+	if err := service.CheckDistributeLimits(tx, apiKey, recipient.ID, req.Amount); err != nil {
+		return nil, err
+	}
+
+	// 获取商户支付配置（用于计算分发费率和分数）
+	var merchantPayConfig model.UserPayConfig
+	if err := merchantPayConfig.GetByPayScore(tx, merchantUser.PayScore); err != nil {
+		return nil, errors.New(PayConfigNotFound)
+	}
+
+	distributeFee, recipientAmount, distributePercent := service.CalculateFee(req.Amount, merchantPayConfig.DistributeRate)
+	merchantScore := req.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
+
+	order := model.Order{
+		OrderName:       "商户分发",
+		ClientID:        apiKey.ClientID,
+		MerchantOrderNo: req.MerchantOrderNo,
+		PayerUserID:     merchantUser.ID,
+		PayeeUserID:     recipient.ID,
+		Amount:          req.Amount,
+		Fee:             distributeFee,
+		Status:          model.OrderStatusSuccess,
+		Type:            model.OrderTypeDistribute,
+		Remark:          req.Remark,
+		TradeTime:       time.Now(),
+		ExpiresAt:       time.Now().Add(24 * time.Hour),
+	}
+
+	distributeRemark := fmt.Sprintf("[系统]: 分发费率%d%%", distributePercent)
+	if order.Remark != "" {
+		order.Remark = order.Remark + " " + distributeRemark
+	} else {
+		order.Remark = distributeRemark
+	}
+
+	if err := tx.Create(&order).Error; err != nil {
+		return nil, err
+	}
+
+	// 扣减商户余额，同时增加平台分数
+	if err := service.UpdateBalance(tx, service.BalanceUpdateOptions{
+		UserID:        merchantUser.ID,
+		CounterUserID: recipient.ID,
+		OrderID:       order.ID,
+		EntryType:     model.LedgerEntryTypeDistribute,
+		Amount:        req.Amount,
+		Operation:     service.BalanceDeduct,
+		ScoreChange:   merchantScore,
+		TotalField:    "total_payment",
+		CheckBalance:  true,
+	}); err != nil {
+		return nil, err
+	}
+
+	// 增加收款人余额（按分发费率计算后的金额）
+	if err := service.UpdateBalance(tx, service.BalanceUpdateOptions{
+		UserID:        recipient.ID,
+		CounterUserID: merchantUser.ID,
+		OrderID:       order.ID,
+		EntryType:     model.LedgerEntryTypeDistribute,
+		Amount:        recipientAmount,
+		Operation:     service.BalanceAdd,
+		TotalField:    "total_receive",
+		CheckBalance:  false,
+	}); err != nil {
+		return nil, err
+	}
+
+	// 分发手续费计入平台账户
+	if err := model.CreatePlatformLedgerEntry(tx, merchantUser.ID, order.ID, distributeFee, model.LedgerEntryTypeFee); err != nil {
+		return nil, err
+	}
+
+	if err := dispatchDistributeSucceeded(tx, apiKey, &order, &recipient); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// dispatchDistributeSucceeded 在事务内下发 distribute.succeeded 事件
+func dispatchDistributeSucceeded(tx *gorm.DB, apiKey *model.MerchantAPIKey, order *model.Order, recipient *model.User) error {
+	eventData := service.OrderEventData(order)
+	eventData["user_id"] = recipient.ID
+	eventData["username"] = recipient.Username
+	eventData["fee"] = order.Fee.StringFixed(2)
+	return service.DispatchWebhookEvent(tx, apiKey.ClientID, model.WebhookEventDistributeSucceeded, order.ID, eventData)
 }
@@ -0,0 +1,168 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payment
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linux-do/credit/internal/model"
+)
+
+// signTestParams 签名测试参数，sign、sign_type 与空值不参与签名
+func signTestParams() map[string]string {
+	return map[string]string{
+		"pid":          "client",
+		"type":         "epay",
+		"out_trade_no": "M1",
+		"name":         "test",
+		"money":        "10.00",
+		"timestamp":    "1700000000",
+		"nonce":        "abc123",
+		"notify_url":   "",
+		"sign":         "ignored",
+		"sign_type":    "MD5",
+	}
+}
+
+func TestBuildSignContent(t *testing.T) {
+	want := "money=10.00&name=test&nonce=abc123&out_trade_no=M1&pid=client&timestamp=1700000000&type=epay"
+	if got := buildSignContent(signTestParams()); got != want {
+		t.Errorf("buildSignContent() = %s, want %s", got, want)
+	}
+}
+
+func TestGenerateSignature(t *testing.T) {
+	tests := []struct {
+		signType model.SignType
+		want     string
+	}{
+		{model.SignTypeMD5, "d6fcc1593c4d07ba2ebe3993c3d83737"},
+		{"", "d6fcc1593c4d07ba2ebe3993c3d83737"},
+		{model.SignTypeHMACSHA256, "1659b65100096085883578811b457f0a6d254ee1e59f769e4aae026f22ea23ab"},
+	}
+	for _, tt := range tests {
+		if got := GenerateSignature(signTestParams(), "secret", tt.signType); got != tt.want {
+			t.Errorf("GenerateSignature(%q) = %s, want %s", tt.signType, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateWebhookSignature(t *testing.T) {
+	want := "3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11"
+	if got := GenerateWebhookSignature("1700000000", []byte(`{"id":1}`), "secret"); got != want {
+		t.Errorf("GenerateWebhookSignature() = %s, want %s", got, want)
+	}
+}
+
+func TestMatchSignature(t *testing.T) {
+	params := signTestParams()
+	current := GenerateSignature(params, "current", model.SignTypeHMACSHA256)
+	previous := GenerateSignature(params, "previous", model.SignTypeHMACSHA256)
+
+	tests := []struct {
+		name    string
+		secrets []string
+		sign    string
+		want    bool
+	}{
+		{name: "current key", secrets: []string{"current"}, sign: current, want: true},
+		{name: "uppercase sign", secrets: []string{"current"}, sign: strings.ToUpper(current), want: true},
+		{name: "previous key within overlap", secrets: []string{"current", "previous"}, sign: previous, want: true},
+		{name: "previous key revoked", secrets: []string{"current"}, sign: previous, want: false},
+		{name: "wrong key", secrets: []string{"other"}, sign: current, want: false},
+		{name: "empty sign", secrets: []string{"current"}, sign: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchSignature(params, tt.secrets, model.SignTypeHMACSHA256, tt.sign); got != tt.want {
+				t.Errorf("matchSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 篡改任一参与签名的参数后签名失效
+	params["money"] = "100.00"
+	if matchSignature(params, []string{"current"}, model.SignTypeHMACSHA256, current) {
+		t.Error("matchSignature() accepted tampered params")
+	}
+}
+
+func TestRequiresSignTimestamp(t *testing.T) {
+	legacy := &model.MerchantAPIKey{LegacySignAllowed: true}
+	strict := &model.MerchantAPIKey{}
+
+	tests := []struct {
+		name      string
+		apiKey    *model.MerchantAPIKey
+		signType  model.SignType
+		timestamp string
+		nonce     string
+		want      bool
+	}{
+		{name: "md5 without legacy flag", apiKey: strict, signType: model.SignTypeMD5, want: true},
+		{name: "md5 with legacy flag", apiKey: legacy, signType: model.SignTypeMD5, want: false},
+		{name: "md5 with legacy flag and timestamp", apiKey: legacy, signType: model.SignTypeMD5, timestamp: "1700000000", want: true},
+		{name: "md5 with legacy flag and nonce", apiKey: legacy, signType: model.SignTypeMD5, nonce: "abc", want: true},
+		{name: "hmac with legacy flag", apiKey: legacy, signType: model.SignTypeHMACSHA256, want: true},
+		{name: "hmac without legacy flag", apiKey: strict, signType: model.SignTypeHMACSHA256, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requiresSignTimestamp(tt.apiKey, tt.signType, tt.timestamp, tt.nonce); got != tt.want {
+				t.Errorf("requiresSignTimestamp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSignTimestamp(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	skew := 5 * time.Minute
+
+	tests := []struct {
+		name      string
+		timestamp string
+		nonce     string
+		want      string
+	}{
+		{name: "valid", timestamp: "1700000000", nonce: "abc"},
+		{name: "at past skew boundary", timestamp: "1699999700", nonce: "abc"},
+		{name: "at future skew boundary", timestamp: "1700000300", nonce: "abc"},
+		{name: "too old", timestamp: "1699999699", nonce: "abc", want: SignTimestampInvalid},
+		{name: "too far in future", timestamp: "1700000301", nonce: "abc", want: SignTimestampInvalid},
+		{name: "not a number", timestamp: "abc", nonce: "abc", want: SignTimestampInvalid},
+		{name: "milliseconds", timestamp: "1700000000000", nonce: "abc", want: SignTimestampInvalid},
+		{name: "missing timestamp", nonce: "abc", want: SignTimestampRequired},
+		{name: "missing nonce", timestamp: "1700000000", want: SignTimestampRequired},
+		{name: "nonce too long", timestamp: "1700000000", nonce: strings.Repeat("a", signNonceMaxLength+1), want: SignNonceInvalid},
+		{name: "nonce at max length", timestamp: "1700000000", nonce: strings.Repeat("a", signNonceMaxLength)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSignTimestamp(tt.timestamp, tt.nonce, now, skew)
+			var got string
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("checkSignTimestamp() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
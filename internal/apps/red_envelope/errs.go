@@ -0,0 +1,26 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package red_envelope
+
+const (
+	RedEnvelopeNotFound       = "红包不存在"
+	RedEnvelopeUnavailable    = "红包已领完或已过期"
+	RedEnvelopeAlreadyClaimed = "您已领取过该红包"
+	CannotClaimOwnEnvelope    = "不能领取自己发出的红包"
+	TrustLevelTooLow          = "您的信任等级不满足该红包的领取要求"
+	SharesExceedAmount        = "每份红包金额不能少于 0.01"
+)
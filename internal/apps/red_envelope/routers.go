@@ -0,0 +1,286 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package red_envelope
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateRedEnvelopeRequest 发红包请求
+type CreateRedEnvelopeRequest struct {
+	Amount        decimal.Decimal            `json:"amount" binding:"required"`
+	Shares        int                        `json:"shares" binding:"required,min=1,max=1000"`
+	SplitType     model.RedEnvelopeSplitType `json:"split_type" binding:"required,oneof=fixed random"`
+	MinTrustLevel model.TrustLevel           `json:"min_trust_level" binding:"max=4"`
+	Remark        string                     `json:"remark" binding:"max=100"`
+	PayKey        string                     `json:"pay_key" binding:"required,max=6"`
+	TOTPCode      string                     `json:"totp_code" binding:"max=16"`
+}
+
+// ListRedEnvelopesRequest 红包查询参数
+type ListRedEnvelopesRequest struct {
+	Page     int `form:"page" binding:"min=1"`
+	PageSize int `form:"page_size" binding:"min=1,max=100"`
+}
+
+// ListRedEnvelopesResponse 红包列表响应
+type ListRedEnvelopesResponse struct {
+	Total     int64               `json:"total"`
+	Envelopes []model.RedEnvelope `json:"envelopes"`
+}
+
+// RedEnvelopeDetail 红包详情
+type RedEnvelopeDetail struct {
+	model.RedEnvelope
+	CreatorUsername string                   `json:"creator_username"`
+	Claims          []model.RedEnvelopeClaim `json:"claims"`
+	MyClaim         *model.RedEnvelopeClaim  `json:"my_claim"`
+}
+
+// CreateRedEnvelope 发红包，积分立即从发起人扣除，过期未领完的部分自动退回
+// @Tags red_envelope
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "幂等键，重试时保持不变"
+// @Param request body CreateRedEnvelopeRequest true "发红包请求"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/red-envelopes [post]
+func CreateRedEnvelope(c *gin.Context) {
+	var req CreateRedEnvelopeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	if err := util.ValidateAmount(req.Amount); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	// 每份至少 0.01
+	if req.Amount.Shift(2).IntPart() < int64(req.Shares) {
+		c.JSON(http.StatusBadRequest, util.Err(SharesExceedAmount))
+		return
+	}
+
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if err := service.VerifyPayKey(c.Request.Context(), currentUser, req.PayKey); err != nil {
+		switch err.Error() {
+		case common.PayKeyIncorrect, common.PayKeyLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	expireHours, errGet := model.GetIntByKey(c.Request.Context(), model.ConfigKeyRedEnvelopeExpireHours)
+	if errGet != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(errGet.Error()))
+		return
+	}
+
+	envelope := model.RedEnvelope{
+		Code:          util.GenerateUniqueIDSimple(),
+		CreatorUserID: currentUser.ID,
+		TotalAmount:   req.Amount,
+		TotalShares:   req.Shares,
+		SplitType:     req.SplitType,
+		MinTrustLevel: req.MinTrustLevel,
+		Remark:        req.Remark,
+		ExpiresAt:     time.Now().Add(time.Duration(expireHours) * time.Hour),
+	}
+
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			// 超过阈值时校验两步验证码
+			if err := service.CheckPaymentTOTP(tx, currentUser, envelope.TotalAmount, req.TOTPCode, time.Now()); err != nil {
+				return err
+			}
+			return service.FundRedEnvelope(tx, &envelope)
+		},
+	); err != nil {
+		switch err.Error() {
+		case common.InsufficientBalance, common.TOTPRequired, common.TOTPIncorrect, common.TOTPLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(envelope))
+}
+
+// ListRedEnvelopes 获取当前用户发出的红包
+// @Tags red_envelope
+// @Produce json
+// @Param request query ListRedEnvelopesRequest true "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/red-envelopes [get]
+func ListRedEnvelopes(c *gin.Context) {
+	var req ListRedEnvelopesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	query := db.DB(c.Request.Context()).Model(&model.RedEnvelope{}).Where("creator_user_id = ?", currentUser.ID)
+
+	var response ListRedEnvelopesResponse
+	if err := query.Count(&response.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&response.Envelopes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
+
+// GetRedEnvelope 通过领取码查询红包详情与领取记录
+// @Tags red_envelope
+// @Produce json
+// @Param code path string true "红包领取码"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/red-envelopes/{code} [get]
+func GetRedEnvelope(c *gin.Context) {
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	var detail RedEnvelopeDetail
+	if err := db.DB(c.Request.Context()).
+		Model(&model.RedEnvelope{}).
+		Select("red_envelopes.*, users.username AS creator_username").
+		Joins("LEFT JOIN users ON users.id = red_envelopes.creator_user_id").
+		Where("red_envelopes.code = ?", c.Param("code")).
+		First(&detail).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, util.Err(RedEnvelopeNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	if err := db.DB(c.Request.Context()).
+		Model(&model.RedEnvelopeClaim{}).
+		Select("red_envelope_claims.*, users.username").
+		Joins("LEFT JOIN users ON users.id = red_envelope_claims.user_id").
+		Where("red_envelope_claims.red_envelope_id = ?", detail.ID).
+		Order("red_envelope_claims.created_at ASC").
+		Find(&detail.Claims).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	for i := range detail.Claims {
+		if detail.Claims[i].UserID == currentUser.ID {
+			detail.MyClaim = &detail.Claims[i]
+			break
+		}
+	}
+
+	c.JSON(http.StatusOK, util.OK(detail))
+}
+
+// ClaimRedEnvelope 领取红包，每人限领一份
+// @Tags red_envelope
+// @Produce json
+// @Param code path string true "红包领取码"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/red-envelopes/{code}/claim [post]
+func ClaimRedEnvelope(c *gin.Context) {
+	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	var claim *model.RedEnvelopeClaim
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			var envelope model.RedEnvelope
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("code = ?", c.Param("code")).
+				First(&envelope).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New(RedEnvelopeNotFound)
+				}
+				return err
+			}
+
+			if envelope.CreatorUserID == currentUser.ID {
+				return errors.New(CannotClaimOwnEnvelope)
+			}
+			if currentUser.TrustLevel < envelope.MinTrustLevel {
+				return errors.New(TrustLevelTooLow)
+			}
+
+			var count int64
+			if err := tx.Model(&model.RedEnvelopeClaim{}).
+				Where("red_envelope_id = ? AND user_id = ?", envelope.ID, currentUser.ID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return errors.New(RedEnvelopeAlreadyClaimed)
+			}
+
+			if envelope.Status != model.RedEnvelopeStatusActive || !envelope.ExpiresAt.After(time.Now()) {
+				return errors.New(RedEnvelopeUnavailable)
+			}
+
+			var err error
+			claim, err = service.ClaimRedEnvelope(tx, &envelope, currentUser.ID)
+			return err
+		},
+	); err != nil {
+		errMsg := err.Error()
+		switch errMsg {
+		case RedEnvelopeNotFound:
+			c.JSON(http.StatusNotFound, util.Err(errMsg))
+		case RedEnvelopeUnavailable, RedEnvelopeAlreadyClaimed, CannotClaimOwnEnvelope, TrustLevelTooLow:
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
+		}
+		return
+	}
+
+	claim.Username = currentUser.Username
+	c.JSON(http.StatusOK, util.OK(claim))
+}
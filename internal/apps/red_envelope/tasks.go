@@ -0,0 +1,79 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package red_envelope
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HandleRefundExpiredRedEnvelopes 处理过期红包，未领完的金额退回发起人
+func HandleRefundExpiredRedEnvelopes(ctx context.Context, _ *asynq.Task) error {
+	pageSize := 500
+	lastID := uint64(0)
+	totalRefunded := 0
+	now := time.Now()
+
+	for {
+		var envelopes []model.RedEnvelope
+		if err := db.DB(ctx).
+			Where("id > ? AND status = ? AND expires_at <= ?", lastID, model.RedEnvelopeStatusActive, now).
+			Order("id ASC").
+			Limit(pageSize).
+			Find(&envelopes).Error; err != nil {
+			logger.ErrorF(ctx, "查询过期红包失败: %v", err)
+			return err
+		}
+
+		if len(envelopes) == 0 {
+			break
+		}
+
+		for _, e := range envelopes {
+			if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+				var envelope model.RedEnvelope
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
+					Where("id = ? AND status = ?", e.ID, model.RedEnvelopeStatusActive).
+					First(&envelope).Error; err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil
+					}
+					return err
+				}
+				return service.RefundRedEnvelope(tx, &envelope)
+			}); err != nil {
+				logger.ErrorF(ctx, "退回过期红包[ID:%d]失败: %v", e.ID, err)
+				continue
+			}
+			totalRefunded++
+		}
+
+		lastID = envelopes[len(envelopes)-1].ID
+	}
+
+	logger.InfoF(ctx, "过期红包处理完成，共处理 %d 个", totalRefunded)
+	return nil
+}
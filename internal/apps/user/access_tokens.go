@@ -0,0 +1,202 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// maxActiveAccessTokens 单个用户有效访问令牌数量上限
+const maxActiveAccessTokens = 10
+
+type CreateAccessTokenRequest struct {
+	Name   string   `json:"name" binding:"required,max=32"`
+	Scopes []string `json:"scopes" binding:"required,min=1,unique,dive,oneof=read_transactions read_balance transfer"`
+	// 拥有转账权限时必填
+	TransferMaxAmount  decimal.Decimal `json:"transfer_max_amount"`
+	TransferDailyLimit decimal.Decimal `json:"transfer_daily_limit"`
+	// 有效天数，0 表示永不过期
+	ExpiresInDays int    `json:"expires_in_days" binding:"min=0,max=365"`
+	PayKey        string `json:"pay_key" binding:"required,max=6"`
+}
+
+// AccessTokenWithPlaintext 新建的访问令牌，明文仅返回一次
+type AccessTokenWithPlaintext struct {
+	model.UserAccessToken
+	Token string `json:"token"`
+}
+
+// BalanceResponse 账户余额
+type BalanceResponse struct {
+	AvailableBalance decimal.Decimal `json:"available_balance"`
+	HeldBalance      decimal.Decimal `json:"held_balance"`
+	CommunityBalance decimal.Decimal `json:"community_balance"`
+}
+
+// GetBalance 查询当前用户余额，可使用拥有 read_balance 权限的访问令牌
+// @Tags user
+// @Produce json
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/balance [get]
+func GetBalance(c *gin.Context) {
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	c.JSON(http.StatusOK, util.OK(BalanceResponse{
+		AvailableBalance: user.AvailableBalance,
+		HeldBalance:      user.HeldBalance,
+		CommunityBalance: user.CommunityBalance,
+	}))
+}
+
+// ListAccessTokens 获取当前用户的访问令牌列表
+// @Tags user
+// @Produce json
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/access-tokens [get]
+func ListAccessTokens(c *gin.Context) {
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	var tokens []model.UserAccessToken
+	if err := db.DB(c.Request.Context()).
+		Where("user_id = ?", user.ID).
+		Order("created_at DESC").
+		Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(tokens))
+}
+
+// CreateAccessToken 创建访问令牌，需验证支付密码
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body CreateAccessTokenRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/access-tokens [post]
+func CreateAccessToken(c *gin.Context) {
+	var req CreateAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	token := model.UserAccessToken{
+		Name:   req.Name,
+		Scopes: req.Scopes,
+	}
+	if token.HasScope(model.AccessTokenScopeTransfer) {
+		for _, amount := range []decimal.Decimal{req.TransferMaxAmount, req.TransferDailyLimit} {
+			if err := util.ValidateAmount(amount); err != nil {
+				c.JSON(http.StatusBadRequest, util.Err(TransferLimitRequired))
+				return
+			}
+		}
+		if req.TransferMaxAmount.GreaterThan(req.TransferDailyLimit) {
+			c.JSON(http.StatusBadRequest, util.Err(TransferLimitInvalid))
+			return
+		}
+		token.TransferMaxAmount = req.TransferMaxAmount
+		token.TransferDailyLimit = req.TransferDailyLimit
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+	token.UserID = user.ID
+
+	if err := service.VerifyPayKey(c.Request.Context(), user, req.PayKey); err != nil {
+		switch err.Error() {
+		case common.PayKeyIncorrect, common.PayKeyLocked:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	var plaintext string
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		// 锁定用户行，避免并发创建突破数量上限
+		if err := tx.Exec("SELECT id FROM users WHERE id = ? FOR UPDATE", user.ID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&model.UserAccessToken{}).
+			Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", user.ID, time.Now()).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= maxActiveAccessTokens {
+			return errors.New(AccessTokenLimit)
+		}
+
+		var err error
+		plaintext, err = service.CreateAccessToken(tx, &token)
+		return err
+	}); err != nil {
+		if err.Error() == AccessTokenLimit {
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(AccessTokenWithPlaintext{UserAccessToken: token, Token: plaintext}))
+}
+
+// RevokeAccessToken 吊销访问令牌
+// @Tags user
+// @Produce json
+// @Param id path uint64 true "Access Token ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/access-tokens/{id} [delete]
+func RevokeAccessToken(c *gin.Context) {
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	result := db.DB(c.Request.Context()).
+		Model(&model.UserAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", c.Param("id"), user.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(result.Error.Error()))
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, util.Err(AccessTokenNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
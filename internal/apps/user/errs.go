@@ -17,5 +17,15 @@ limitations under the License.
 package user
 
 const (
-	EncryptPayKeyFailed = "加密支付密码失败"
+	HashPayKeyFailed      = "保存支付密码失败"
+	OldPayKeyRequired     = "请输入原支付密码"
+	TOTPAlreadyEnabled    = "两步验证已启用"
+	TOTPNotEnabled        = "未启用两步验证"
+	TOTPNotEnrolled       = "请先生成两步验证密钥"
+	TOTPThresholdInvalid  = "验证阈值不能为负数"
+	SessionNotFound       = "会话不存在"
+	AccessTokenNotFound   = "访问令牌不存在"
+	AccessTokenLimit      = "有效访问令牌数量已达上限"
+	TransferLimitRequired = "转账权限需设置有效的单笔与每日上限"
+	TransferLimitInvalid  = "单笔转账上限不能超过每日上限"
 )
@@ -21,17 +21,21 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/common"
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/linux-do/credit/internal/util"
 )
 
 // UpdatePayKeyRequest 更新支付密钥请求
 type UpdatePayKeyRequest struct {
-	PayKey string `json:"pay_key" binding:"required,max=6"`
+	PayKey    string `json:"pay_key" binding:"required,len=6,numeric"`
+	OldPayKey string `json:"old_pay_key" binding:"omitempty,max=6"`
 }
 
 // UpdatePayKey 更新用户支付密钥
+// 已设置过支付密钥时需要提供原密钥，原密钥校验计入错误次数限制
 // @Tags user
 // @Accept json
 // @Produce json
@@ -47,17 +51,24 @@ func UpdatePayKey(c *gin.Context) {
 
 	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
 
-	encryptedPayKey, err := util.Encrypt(user.SignKey, req.PayKey)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, util.Err(EncryptPayKeyFailed))
-		return
+	if user.PayKey != "" {
+		if req.OldPayKey == "" {
+			c.JSON(http.StatusBadRequest, util.Err(OldPayKeyRequired))
+			return
+		}
+		if err := service.VerifyPayKey(c.Request.Context(), user, req.OldPayKey); err != nil {
+			switch err.Error() {
+			case common.PayKeyIncorrect, common.PayKeyLocked:
+				c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+			default:
+				c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+			}
+			return
+		}
 	}
 
-	if err := db.DB(c.Request.Context()).
-		Model(&model.User{}).
-		Where("id = ?", user.ID).
-		Update("pay_key", encryptedPayKey).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+	if err := service.SetPayKey(db.DB(c.Request.Context()), user, req.PayKey); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(HashPayKeyFailed))
 		return
 	}
 
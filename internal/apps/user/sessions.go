@@ -0,0 +1,99 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+)
+
+// ListSessions 列出当前用户的登录会话
+// @Tags user
+// @Produce json
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/sessions [get]
+func ListSessions(c *gin.Context) {
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	sessions, err := oauth.ListUserSessions(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	currentID := oauth.GetSessionID(c)
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+
+	c.JSON(http.StatusOK, util.OK(sessions))
+}
+
+// RevokeSession 吊销指定会话，吊销当前会话等同于退出登录
+// @Tags user
+// @Produce json
+// @Param id path string true "会话 ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/sessions/{id} [delete]
+func RevokeSession(c *gin.Context) {
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	sessionID := c.Param("id")
+	revoked, err := oauth.RevokeUserSession(c.Request.Context(), user.ID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, util.Err(SessionNotFound))
+		return
+	}
+
+	if sessionID == oauth.GetSessionID(c) {
+		if err := oauth.ClearSession(c); err != nil {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// RevokeAllSessions 退出所有设备，包括当前会话
+// @Tags user
+// @Produce json
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/sessions [delete]
+func RevokeAllSessions(c *gin.Context) {
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if err := oauth.RevokeUserSessions(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	if err := oauth.ClearSession(c); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
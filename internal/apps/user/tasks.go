@@ -30,6 +30,7 @@ import (
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/linux-do/credit/internal/task"
 	"github.com/linux-do/credit/internal/task/scheduler"
 	"github.com/shopspring/decimal"
@@ -164,9 +165,9 @@ func batchUpdateUserScores(ctx context.Context, userScores []model.LeaderboardUs
 			oldCommunityBalance := user.CommunityBalance
 			diff := newCommunityBalance.Sub(oldCommunityBalance)
 
-			createOrder := func(amount decimal.Decimal, remark string) error {
+			createOrder := func(amount decimal.Decimal, remark string) (*model.Order, error) {
 				order := model.Order{
-					OrderName:   "社区积分更新",
+					OrderName:   model.OrderNameCommunityUpdate,
 					PayerUserID: 0,
 					PayeeUserID: user.ID,
 					Amount:      amount,
@@ -177,9 +178,9 @@ func batchUpdateUserScores(ctx context.Context, userScores []model.LeaderboardUs
 					ExpiresAt:   now,
 				}
 				if err = tx.Create(&order).Error; err != nil {
-					return fmt.Errorf("创建用户[%s]订单失败: %w", user.Username, err)
+					return nil, fmt.Errorf("创建用户[%s]订单失败: %w", user.Username, err)
 				}
-				return nil
+				return &order, nil
 			}
 
 			if user.CommunityBalance.IsZero() && user.TotalCommunity.IsZero() {
@@ -195,7 +196,7 @@ func batchUpdateUserScores(ctx context.Context, userScores []model.LeaderboardUs
 			// 积分未变化
 			if diff.IsZero() {
 				remark := fmt.Sprintf("社区积分从 %s 更新到 %s，变化 %s", oldCommunityBalance.String(), newCommunityBalance.String(), diff.String())
-				if err = createOrder(decimal.Zero, remark); err != nil {
+				if _, err = createOrder(decimal.Zero, remark); err != nil {
 					return err
 				}
 				continue
@@ -212,7 +213,7 @@ func batchUpdateUserScores(ctx context.Context, userScores []model.LeaderboardUs
 					}
 					remark := fmt.Sprintf("社区积分从 %s 更新到 %s，变化 %s（保护期内，跳过扣分）",
 						oldCommunityBalance.String(), newCommunityBalance.String(), diff.String())
-					if err = createOrder(decimal.Zero, remark); err != nil {
+					if _, err = createOrder(decimal.Zero, remark); err != nil {
 						return err
 					}
 					logger.InfoF(ctx, "用户[%s]在保护期内，积分下降%s，跳过扣分", user.Username, diff.Abs().String())
@@ -220,19 +221,39 @@ func batchUpdateUserScores(ctx context.Context, userScores []model.LeaderboardUs
 				}
 			}
 
+			remark := fmt.Sprintf("社区积分从 %s 更新到 %s，变化 %s",
+				oldCommunityBalance.String(), newCommunityBalance.String(), diff.String())
+			order, errCreate := createOrder(diff, remark)
+			if errCreate != nil {
+				return errCreate
+			}
+
 			// 更新用户积分
 			if err = tx.Model(&user).UpdateColumns(map[string]interface{}{
 				"community_balance": newCommunityBalance,
 				"total_community":   gorm.Expr("total_community + ?", diff),
-				"total_receive":     gorm.Expr("total_receive + ?", diff),
-				"available_balance": gorm.Expr("available_balance + ?", diff),
 			}).Error; err != nil {
 				return fmt.Errorf("更新用户[%s]积分失败: %w", user.Username, err)
 			}
 
-			remark := fmt.Sprintf("社区积分从 %s 更新到 %s，变化 %s",
-				oldCommunityBalance.String(), newCommunityBalance.String(), diff.String())
-			if err = createOrder(diff, remark); err != nil {
+			operation := service.BalanceAdd
+			if diff.IsNegative() {
+				operation = service.BalanceDeduct
+			}
+			if err = service.UpdateBalance(tx, service.BalanceUpdateOptions{
+				UserID:        user.ID,
+				CounterUserID: model.PlatformAccountID,
+				OrderID:       order.ID,
+				EntryType:     model.LedgerEntryTypeCommunity,
+				Amount:        diff.Abs(),
+				Operation:     operation,
+				TotalField:    "total_receive",
+				RevertTotal:   diff.IsNegative(),
+			}); err != nil {
+				return fmt.Errorf("更新用户[%s]余额失败: %w", user.Username, err)
+			}
+
+			if err = model.CreatePlatformLedgerEntry(tx, user.ID, order.ID, diff.Neg(), model.LedgerEntryTypeCommunity); err != nil {
 				return err
 			}
 		}
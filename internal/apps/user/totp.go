@@ -0,0 +1,409 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/config"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TOTPStatusResponse 两步验证状态
+type TOTPStatusResponse struct {
+	Enabled                bool             `json:"enabled"`
+	Threshold              *decimal.Decimal `json:"threshold"`
+	DefaultThreshold       decimal.Decimal  `json:"default_threshold"`
+	RecoveryCodesRemaining int              `json:"recovery_codes_remaining"`
+}
+
+// EnrollTOTPRequest 生成两步验证密钥请求
+type EnrollTOTPRequest struct {
+	PayKey string `json:"pay_key" binding:"required,max=6"`
+}
+
+// EnrollTOTPResponse 待启用的两步验证密钥
+type EnrollTOTPResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TOTPCodeRequest 仅需验证码的请求，验证码可以是验证器生成的 6 位数字或恢复码
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required,max=16"`
+}
+
+// DisableTOTPRequest 关闭两步验证请求
+type DisableTOTPRequest struct {
+	PayKey string `json:"pay_key" binding:"required,max=6"`
+	Code   string `json:"code" binding:"required,max=16"`
+}
+
+// UpdateTOTPThresholdRequest 更新支付验证阈值请求，阈值为空表示使用系统默认值
+type UpdateTOTPThresholdRequest struct {
+	Threshold *decimal.Decimal `json:"threshold"`
+	Code      string           `json:"code" binding:"required,max=16"`
+}
+
+// RecoveryCodesResponse 新生成的恢复码，仅展示一次
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// GetTOTPStatus 查询两步验证状态
+// @Tags user
+// @Produce json
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/totp [get]
+func GetTOTPStatus(c *gin.Context) {
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	defaultThreshold, err := model.GetDecimalByKey(c.Request.Context(), model.ConfigKeyTOTPPaymentThreshold, 2)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	resp := TOTPStatusResponse{DefaultThreshold: defaultThreshold}
+	var totp model.UserTOTP
+	if err := db.DB(c.Request.Context()).Where("user_id = ? AND enabled = ?", user.ID, true).First(&totp).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+			return
+		}
+	} else {
+		resp.Enabled = true
+		resp.Threshold = totp.Threshold
+		resp.RecoveryCodesRemaining = len(totp.RecoveryCodes)
+	}
+
+	c.JSON(http.StatusOK, util.OK(resp))
+}
+
+// EnrollTOTP 生成待启用的两步验证密钥，需验证支付密码
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body EnrollTOTPRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/totp/enroll [post]
+func EnrollTOTP(c *gin.Context) {
+	var req EnrollTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if err := service.VerifyPayKey(c.Request.Context(), user, req.PayKey); err != nil {
+		respondTOTPError(c, err)
+		return
+	}
+
+	var secret string
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		secret, err = enrollTOTP(tx, user)
+		return err
+	}); err != nil {
+		respondTOTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(EnrollTOTPResponse{
+		Secret: secret,
+		URI:    util.TOTPURI(config.Config.App.AppName, user.Username, secret),
+	}))
+}
+
+// EnableTOTP 使用验证器生成的验证码确认启用两步验证，返回恢复码
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body TOTPCodeRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/totp/enable [post]
+func EnableTOTP(c *gin.Context) {
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	var codes []string
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = enableTOTP(tx, user, req.Code, time.Now())
+		return err
+	}); err != nil {
+		respondTOTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(RecoveryCodesResponse{RecoveryCodes: codes}))
+}
+
+// DisableTOTP 关闭两步验证，需验证支付密码与验证码
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body DisableTOTPRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/totp/disable [post]
+func DisableTOTP(c *gin.Context) {
+	var req DisableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if err := service.VerifyPayKey(c.Request.Context(), user, req.PayKey); err != nil {
+		respondTOTPError(c, err)
+		return
+	}
+
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		return disableTOTP(tx, user, req.Code, time.Now())
+	}); err != nil {
+		respondTOTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// UpdateTOTPThreshold 更新需要验证码的单笔支付金额阈值
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body UpdateTOTPThresholdRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/totp/threshold [put]
+func UpdateTOTPThreshold(c *gin.Context) {
+	var req UpdateTOTPThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	if req.Threshold != nil {
+		if req.Threshold.IsNegative() {
+			c.JSON(http.StatusBadRequest, util.Err(TOTPThresholdInvalid))
+			return
+		}
+		if req.Threshold.Exponent() < -2 {
+			c.JSON(http.StatusBadRequest, util.Err(common.AmountDecimalPlacesExceeded))
+			return
+		}
+	}
+
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		return updateTOTPThreshold(tx, user, req.Threshold, req.Code, time.Now())
+	}); err != nil {
+		respondTOTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，原有恢复码全部作废
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body TOTPCodeRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/totp/recovery-codes [post]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	var codes []string
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = regenerateRecoveryCodes(tx, user, req.Code, time.Now())
+		return err
+	}); err != nil {
+		respondTOTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(RecoveryCodesResponse{RecoveryCodes: codes}))
+}
+
+// enrollTOTP 生成新的待启用密钥并替换未启用的旧密钥，返回密钥明文
+func enrollTOTP(tx *gorm.DB, user *model.User) (string, error) {
+	var count int64
+	if err := tx.Model(&model.UserTOTP{}).Where("user_id = ? AND enabled = ?", user.ID, true).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "", errors.New(TOTPAlreadyEnabled)
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := util.Encrypt(user.SignKey, secret)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Where("user_id = ?", user.ID).Delete(&model.UserTOTP{}).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Create(&model.UserTOTP{UserID: user.ID, Secret: encrypted}).Error; err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// enableTOTP 校验首个验证码后启用两步验证，返回恢复码
+func enableTOTP(tx *gorm.DB, user *model.User, code string, now time.Time) ([]string, error) {
+	totp, err := lockTOTP(tx, user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(TOTPNotEnrolled)
+		}
+		return nil, err
+	}
+	if totp.Enabled {
+		return nil, errors.New(TOTPAlreadyEnabled)
+	}
+
+	if err := service.VerifyTOTP(tx, user, totp, code, now); err != nil {
+		return nil, err
+	}
+
+	codes, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&model.UserTOTP{}).
+		Where("user_id = ?", user.ID).
+		Updates(map[string]interface{}{
+			"enabled":        true,
+			"enabled_at":     now,
+			"recovery_codes": totp.RecoveryCodes,
+		}).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// disableTOTP 校验验证码后删除两步验证配置
+func disableTOTP(tx *gorm.DB, user *model.User, code string, now time.Time) error {
+	totp, err := lockEnabledTOTP(tx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := service.VerifyTOTP(tx, user, totp, code, now); err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", user.ID).Delete(&model.UserTOTP{}).Error
+}
+
+// updateTOTPThreshold 校验验证码后更新支付验证阈值
+func updateTOTPThreshold(tx *gorm.DB, user *model.User, threshold *decimal.Decimal, code string, now time.Time) error {
+	totp, err := lockEnabledTOTP(tx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := service.VerifyTOTP(tx, user, totp, code, now); err != nil {
+		return err
+	}
+	return tx.Model(&model.UserTOTP{}).Where("user_id = ?", user.ID).Update("threshold", threshold).Error
+}
+
+// regenerateRecoveryCodes 校验验证码后重新生成恢复码
+func regenerateRecoveryCodes(tx *gorm.DB, user *model.User, code string, now time.Time) ([]string, error) {
+	totp, err := lockEnabledTOTP(tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.VerifyTOTP(tx, user, totp, code, now); err != nil {
+		return nil, err
+	}
+
+	codes, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&model.UserTOTP{}).Where("user_id = ?", user.ID).Update("recovery_codes", totp.RecoveryCodes).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// lockTOTP 加锁读取用户的两步验证配置
+func lockTOTP(tx *gorm.DB, userID uint64) (*model.UserTOTP, error) {
+	var totp model.UserTOTP
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&totp).Error; err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// lockEnabledTOTP 加锁读取已启用的两步验证配置
+func lockEnabledTOTP(tx *gorm.DB, userID uint64) (*model.UserTOTP, error) {
+	totp, err := lockTOTP(tx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(TOTPNotEnabled)
+		}
+		return nil, err
+	}
+	if !totp.Enabled {
+		return nil, errors.New(TOTPNotEnabled)
+	}
+	return totp, nil
+}
+
+// respondTOTPError 将两步验证相关错误映射为响应
+func respondTOTPError(c *gin.Context, err error) {
+	switch err.Error() {
+	case common.PayKeyIncorrect, common.PayKeyLocked, common.TOTPIncorrect, common.TOTPLocked,
+		TOTPAlreadyEnabled, TOTPNotEnabled, TOTPNotEnrolled:
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+	}
+}
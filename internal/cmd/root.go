@@ -40,6 +40,8 @@ var rootCmd = &cobra.Command{
 			schedulerCmd.Run(schedulerCmd, args)
 		case "worker":
 			workerCmd.Run(workerCmd, args)
+		case "outbox":
+			outboxCmd.Run(outboxCmd, args)
 		default:
 			log.Fatal("[CMD] unknown app mode\n")
 		}
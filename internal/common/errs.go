@@ -23,12 +23,29 @@ const (
 	RateMustBeBetweenZeroAndOne = "比率必须在 0 到 1 之间"
 	RateDecimalPlacesExceeded   = "比率小数位数不能超过2位"
 	InsufficientBalance         = "余额不足"
+	RefundAmountExceeded        = "退款金额超过可退金额"
+	CaptureAmountExceeded       = "确认收款金额超过冻结金额"
 	DailyLimitExceeded          = "已超过每日限额"
+	DistributeAmountExceeded    = "单笔分发金额超过应用限额"
+	DistributeDailyExceeded     = "已超过应用每日分发限额"
+	DistributeMonthlyExceeded   = "已超过应用每月分发限额"
+	DistributeRecipientExceeded = "已超过应用对该收款人的每日分发限额"
 	PayKeyIncorrect             = "支付密钥错误"
+	PayKeyLocked                = "支付密钥错误次数过多，请稍后再试"
+	TOTPRequired                = "该笔支付需要两步验证码"
+	TOTPIncorrect               = "两步验证码错误"
+	TOTPLocked                  = "两步验证码错误次数过多，请稍后再试"
 	CannotPaySelf               = "不能给自己付款"
+	SubscriptionPartyInactive   = "订阅付款方或商户账号已被禁用"
 	TestModeCannotProcessOrder  = "测试模式下无法处理订单"
 	TestModeOrderRemark         = "[测试模式] 此订单为测试订单，未实际扣款"
 	UnAuthorized                = "未登录"
+	APISecretInvalid            = "认证失败"
+	APISecretScopeDenied        = "密钥无权访问该接口"
+	AccessTokenInvalid          = "访问令牌无效或已过期"
+	AccessTokenScopeDenied      = "访问令牌无权访问该接口"
+	AccessTokenAmountExceeded   = "单笔转账金额超过访问令牌限额"
+	AccessTokenDailyExceeded    = "已超过访问令牌每日转账限额"
 )
 
 const (
@@ -145,14 +145,20 @@ type schedulerConfig struct {
 	DisputeAutoRefundDispatchIntervalSeconds int    `mapstructure:"dispute_auto_refund_dispatch_interval_seconds"`
 	AutoRefundExpiredDisputesTaskCron        string `mapstructure:"auto_refund_expired_disputes_task_cron"`
 	SyncOrdersToClickHouseTaskCron           string `mapstructure:"sync_orders_to_clickhouse_task_cron"`
+	ReconcileBalancesTaskCron                string `mapstructure:"reconcile_balances_task_cron"`
+	ReleaseExpiredHoldsTaskCron              string `mapstructure:"release_expired_holds_task_cron"`
+	ChargeDueSubscriptionsTaskCron           string `mapstructure:"charge_due_subscriptions_task_cron"`
+	RefundExpiredRedEnvelopesTaskCron        string `mapstructure:"refund_expired_red_envelopes_task_cron"`
+	ExpireGiftCodeBatchesTaskCron            string `mapstructure:"expire_gift_code_batches_task_cron"`
 }
 
 // workerConfig 工作配置
 type workerConfig struct {
-	Concurrency                int             `mapstructure:"concurrency"`
-	StrictPriority             bool            `mapstructure:"strict_priority"`
-	Queues                     []QueueConfig   `mapstructure:"queues"`
-	GamificationScoreRateLimit RateLimitConfig `mapstructure:"gamification_score_rate_limit"`
+	Concurrency                int               `mapstructure:"concurrency"`
+	StrictPriority             bool              `mapstructure:"strict_priority"`
+	Queues                     []QueueConfig     `mapstructure:"queues"`
+	GamificationScoreRateLimit RateLimitConfig   `mapstructure:"gamification_score_rate_limit"`
+	OutboxRelay                OutboxRelayConfig `mapstructure:"outbox_relay"`
 }
 
 // QueueConfig 队列配置
@@ -167,6 +173,13 @@ type RateLimitConfig struct {
 	Period int `mapstructure:"period"` // 时间周期（秒）
 }
 
+// OutboxRelayConfig 任务发件箱中继配置
+type OutboxRelayConfig struct {
+	IntervalMs     int `mapstructure:"interval_ms"`     // 轮询间隔（毫秒）
+	BatchSize      int `mapstructure:"batch_size"`      // 单批投递数量
+	RetentionHours int `mapstructure:"retention_hours"` // 已投递记录保留时长（小时）
+}
+
 // linuxDoConfig
 type linuxDoConfig struct {
 	ApiKey string `mapstructure:"api_key"`
@@ -25,6 +25,7 @@ import (
 	"github.com/linux-do/credit/internal/config"
 	"github.com/linux-do/credit/internal/db"
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm/clause"
 )
 
 func Migrate() {
@@ -32,6 +33,11 @@ func Migrate() {
 		return
 	}
 
+	// 新增旧版签名兼容字段前创建的应用默认开启兼容，避免已接入的 MD5 客户端失效
+	backfillLegacySign := !db.DB(context.Background()).Migrator().HasColumn(&model.MerchantAPIKey{}, "LegacySignAllowed")
+	// 手续费字段新增时回填一次历史订单手续费，之后不再根据备注推算
+	backfillOrderFees := !db.DB(context.Background()).Migrator().HasColumn(&model.Order{}, "Fee")
+
 	if err := db.DB(context.Background()).AutoMigrate(
 		&model.User{},
 		&model.UserPayConfig{},
@@ -40,6 +46,26 @@ func Migrate() {
 		&model.Order{},
 		&model.SystemConfig{},
 		&model.Dispute{},
+		&model.LedgerEntry{},
+		&model.ReconciliationFinding{},
+		&model.WebhookDelivery{},
+		&model.OutboxEvent{},
+		&model.PaymentHold{},
+		&model.SubscriptionPlan{},
+		&model.Subscription{},
+		&model.PaymentRequest{},
+		&model.RedEnvelope{},
+		&model.RedEnvelopeClaim{},
+		&model.GiftCodeBatch{},
+		&model.GiftCode{},
+		&model.GiftCodeRedemption{},
+		&model.DistributeBatch{},
+		&model.DistributeBatchItem{},
+		&model.MerchantAPISecret{},
+		&model.MerchantMember{},
+		&model.MerchantAuditLog{},
+		&model.UserTOTP{},
+		&model.UserAccessToken{},
 	); err != nil {
 		log.Fatalf("[PostgreSQL] auto migrate failed: %v\n", err)
 	}
@@ -50,21 +76,30 @@ func Migrate() {
 
 	// 初始化用户支付配置数据
 	initUserPayConfigs()
-}
 
-// initSystemConfigs 初始化系统配置数据
-func initSystemConfigs() {
-	tx := db.DB(context.Background())
+	// 初始化账户期初余额分录
+	initLedgerOpeningBalances()
 
-	var count int64
-	if err := tx.Model(&model.SystemConfig{}).Count(&count).Error; err != nil {
-		log.Printf("[PostgreSQL] failed to check system_config table: %v\n", err)
-		return
+	// 回填历史订单手续费
+	if backfillOrderFees {
+		initOrderFees()
 	}
 
-	if count > 0 {
-		return
+	// 为历史应用创建默认接口密钥
+	initAPISecrets()
+
+	// 为历史应用补充创建者成员记录
+	initMerchantOwners()
+
+	// 为历史应用开启旧版签名兼容
+	if backfillLegacySign {
+		initLegacySignAllowed()
 	}
+}
+
+// initSystemConfigs 初始化系统配置数据，仅补充缺失的配置项，不覆盖已有值
+func initSystemConfigs() {
+	tx := db.DB(context.Background())
 
 	defaultConfigs := []model.SystemConfig{
 		{
@@ -72,6 +107,16 @@ func initSystemConfigs() {
 			Value:       "5",
 			Description: "商家订单过期时间（分钟）",
 		},
+		{
+			Key:         model.ConfigKeyMerchantOrderExpireMin,
+			Value:       "1",
+			Description: "商家创建订单或设置应用默认值时可指定的最短过期时间（分钟）",
+		},
+		{
+			Key:         model.ConfigKeyMerchantOrderExpireMax,
+			Value:       "60",
+			Description: "商家创建订单或设置应用默认值时可指定的最长过期时间（分钟）",
+		},
 		{
 			Key:         model.ConfigKeyWebsiteOrderExpireMinutes,
 			Value:       "10",
@@ -92,12 +137,73 @@ func initSystemConfigs() {
 			Value:       "30",
 			Description: "新用户保护期天数，期内积分下降不扣分",
 		},
+		{
+			Key:         model.ConfigKeyIdempotencyWindowMinutes,
+			Value:       "1440",
+			Description: "幂等键保留时间（分钟），窗口内相同 Idempotency-Key 的请求直接返回首次响应",
+		},
+		{
+			Key:         model.ConfigKeySignTimestampSkewSeconds,
+			Value:       "300",
+			Description: "签名请求 timestamp 与服务器时间允许的最大偏差（秒）",
+		},
+		{
+			Key:         model.ConfigKeyPaymentHoldExpireHours,
+			Value:       "168",
+			Description: "预授权订单冻结的积分在商户未确认收款时自动释放的时间（小时）",
+		},
+		{
+			Key:         model.ConfigKeyEscrowAutoReleaseDays,
+			Value:       "7",
+			Description: "担保交易付款方未确认收货且无争议时，自动放款给收款方的时间（天）",
+		},
+		{
+			Key:         model.ConfigKeySubscriptionRetryHours,
+			Value:       "24",
+			Description: "订阅扣款因余额不足或超出每日限额失败后，下次重试的间隔（小时）",
+		},
+		{
+			Key:         model.ConfigKeySubscriptionMaxRetries,
+			Value:       "3",
+			Description: "订阅扣款连续失败的重试次数上限，超过后自动取消订阅",
+		},
+		{
+			Key:         model.ConfigKeyPaymentRequestExpireHours,
+			Value:       "72",
+			Description: "收款请求发出后付款方未处理时自动过期的时间（小时）",
+		},
+		{
+			Key:         model.ConfigKeyRedEnvelopeExpireHours,
+			Value:       "24",
+			Description: "红包发出后的有效期（小时），过期未领取的金额退回发起人",
+		},
+		{
+			Key:         model.ConfigKeyDistributeBatchMaxItems,
+			Value:       "500",
+			Description: "商户批量分发接口单个批次允许的最大收款人数量",
+		},
+		{
+			Key:         model.ConfigKeyPayKeyMaxFailures,
+			Value:       "5",
+			Description: "支付密码连续输错的次数上限，达到后在锁定时长内禁止支付",
+		},
+		{
+			Key:         model.ConfigKeyPayKeyLockMinutes,
+			Value:       "30",
+			Description: "支付密码输错次数达到上限后的锁定时长（分钟），期间任意一次输错都会重新计时",
+		},
+		{
+			Key:         model.ConfigKeyTOTPPaymentThreshold,
+			Value:       "100",
+			Description: "已启用两步验证的用户单笔支付金额超过该值时需要验证码，用户可自行设置阈值覆盖",
+		},
 	}
 
-	if err := tx.Create(&defaultConfigs).Error; err != nil {
-		log.Printf("[PostgreSQL] failed to create default system configs: %v\n", err)
-	} else {
-		log.Printf("[PostgreSQL] initialized %d default system configs\n", len(defaultConfigs))
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&defaultConfigs)
+	if result.Error != nil {
+		log.Printf("[PostgreSQL] failed to create default system configs: %v\n", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("[PostgreSQL] initialized %d default system configs\n", result.RowsAffected)
 	}
 }
 
@@ -165,3 +271,177 @@ func initUserPayConfigs() {
 		log.Printf("[PostgreSQL] initialized %d default user pay configs\n", len(defaultConfigs))
 	}
 }
+
+// initLedgerOpeningBalances 为启用账户流水前已有余额的用户写入期初余额分录
+// 仅在 ledger_entries 表为空时执行，保证每个用户的可用余额等于其分录金额之和
+func initLedgerOpeningBalances() {
+	tx := db.DB(context.Background())
+
+	var count int64
+	if err := tx.Model(&model.LedgerEntry{}).Count(&count).Error; err != nil {
+		log.Printf("[PostgreSQL] failed to check ledger_entries table: %v\n", err)
+		return
+	}
+
+	if count > 0 {
+		return
+	}
+
+	pageSize := 1000
+	lastID := uint64(0)
+	total := 0
+
+	for {
+		var users []model.User
+		if err := tx.Select("id, available_balance").
+			Where("id > ? AND available_balance <> 0", lastID).
+			Order("id ASC").
+			Limit(pageSize).
+			Find(&users).Error; err != nil {
+			log.Printf("[PostgreSQL] failed to query user balances: %v\n", err)
+			return
+		}
+
+		if len(users) == 0 {
+			break
+		}
+
+		entries := make([]model.LedgerEntry, 0, len(users)*2)
+		for _, user := range users {
+			balance := user.AvailableBalance
+			entries = append(entries,
+				model.LedgerEntry{
+					UserID:        user.ID,
+					CounterUserID: model.PlatformAccountID,
+					Amount:        balance,
+					BalanceAfter:  &balance,
+					Type:          model.LedgerEntryTypeOpening,
+				},
+				model.LedgerEntry{
+					UserID:        model.PlatformAccountID,
+					CounterUserID: user.ID,
+					Amount:        balance.Neg(),
+					Type:          model.LedgerEntryTypeOpening,
+				},
+			)
+		}
+
+		if err := tx.Create(&entries).Error; err != nil {
+			log.Printf("[PostgreSQL] failed to create opening ledger entries: %v\n", err)
+			return
+		}
+
+		total += len(users)
+		lastID = users[len(users)-1].ID
+	}
+
+	if total > 0 {
+		log.Printf("[PostgreSQL] initialized opening ledger entries for %d users\n", total)
+	}
+}
+
+// initOrderFees 根据系统备注回填启用手续费字段前的历史订单手续费，仅在新增手续费字段时执行一次
+// 费率精度为两位小数，按备注中的整数百分比重新计算即可得到与当时一致的手续费
+// 系统备注总是追加在用户备注之后，仅匹配备注末尾的系统后缀，避免用户备注伪造费率
+func initOrderFees() {
+	tx := db.DB(context.Background())
+
+	backfills := []struct {
+		types   []model.OrderType
+		pattern string
+	}{
+		{types: []model.OrderType{model.OrderTypePayment, model.OrderTypeOnline}, pattern: `(?:^| )\[系统\]: 收取商家([1-9][0-9]*)%手续费$`},
+		{types: []model.OrderType{model.OrderTypeDistribute}, pattern: `(?:^| )\[系统\]: 分发费率([1-9][0-9]*)%$`},
+	}
+
+	for _, b := range backfills {
+		result := tx.Exec(
+			"UPDATE orders SET fee = ROUND(amount * CAST(substring(remark from ?) AS numeric) / 100, 2) "+
+				"WHERE fee = 0 AND type IN ? AND remark ~ ?",
+			b.pattern, b.types, b.pattern,
+		)
+		if result.Error != nil {
+			log.Printf("[PostgreSQL] failed to backfill order fees: %v\n", result.Error)
+			return
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("[PostgreSQL] backfilled fee for %d orders\n", result.RowsAffected)
+		}
+	}
+}
+
+// initAPISecrets 为尚无接口密钥的应用以 ClientSecret 创建 legacy 接口密钥，保持已接入的 key 认证方式可用
+// legacy 密钥不设过期时间，由商户在控制台自行轮换或吊销
+func initAPISecrets() {
+	tx := db.DB(context.Background())
+
+	var apiKeys []model.MerchantAPIKey
+	if err := tx.
+		Where("NOT EXISTS (SELECT 1 FROM merchant_api_secrets s WHERE s.api_key_id = merchant_api_keys.id)").
+		Find(&apiKeys).Error; err != nil {
+		log.Printf("[PostgreSQL] failed to query api keys without secrets: %v\n", err)
+		return
+	}
+
+	for i := range apiKeys {
+		secret := model.NewMerchantAPISecret(apiKeys[i].ID, "legacy", apiKeys[i].ClientSecret, model.AllAPISecretScopes)
+		if err := tx.Create(secret).Error; err != nil {
+			log.Printf("[PostgreSQL] failed to create legacy api secret for %d: %v\n", apiKeys[i].ID, err)
+			return
+		}
+	}
+
+	if len(apiKeys) > 0 {
+		log.Printf("[PostgreSQL] initialized legacy api secrets for %d api keys\n", len(apiKeys))
+	}
+}
+
+// initMerchantOwners 为尚无成员记录的应用以创建者身份补充 owner 成员
+func initMerchantOwners() {
+	tx := db.DB(context.Background())
+
+	var apiKeys []model.MerchantAPIKey
+	if err := tx.Select("id, user_id").
+		Where("NOT EXISTS (SELECT 1 FROM merchant_members m WHERE m.api_key_id = merchant_api_keys.id AND m.role = ?)", model.MerchantRoleOwner).
+		Find(&apiKeys).Error; err != nil {
+		log.Printf("[PostgreSQL] failed to query api keys without owner: %v\n", err)
+		return
+	}
+
+	if len(apiKeys) == 0 {
+		return
+	}
+
+	members := make([]model.MerchantMember, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		members = append(members, model.MerchantMember{
+			APIKeyID:  apiKey.ID,
+			UserID:    apiKey.UserID,
+			Role:      model.MerchantRoleOwner,
+			Status:    model.MerchantMemberStatusActive,
+			InvitedBy: apiKey.UserID,
+		})
+	}
+
+	if err := tx.CreateInBatches(&members, 1000).Error; err != nil {
+		log.Printf("[PostgreSQL] failed to create merchant owners: %v\n", err)
+		return
+	}
+
+	log.Printf("[PostgreSQL] initialized owners for %d api keys\n", len(members))
+}
+
+// initLegacySignAllowed 为新增字段前创建的应用开启旧版签名兼容，保持原有 MD5 签名方式可用
+func initLegacySignAllowed() {
+	result := db.DB(context.Background()).
+		Model(&model.MerchantAPIKey{}).
+		Where("sign_type = ?", model.SignTypeMD5).
+		Update("legacy_sign_allowed", true)
+	if result.Error != nil {
+		log.Printf("[PostgreSQL] failed to enable legacy sign for api keys: %v\n", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("[PostgreSQL] enabled legacy sign for %d api keys\n", result.RowsAffected)
+	}
+}
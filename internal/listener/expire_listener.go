@@ -35,12 +35,18 @@ import (
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // orderExpireKeyPrefix 订单过期 Key 前缀
 const orderExpireKeyPrefix = "payment:order:expire:"
 
+// paymentRequestExpireKeyPrefix 收款请求过期 Key 前缀
+const paymentRequestExpireKeyPrefix = "payment:request:expire:"
+
 // StartExpireListener 启动过期监听器
 func StartExpireListener(ctx context.Context) error {
 	if db.Redis == nil {
@@ -49,6 +55,7 @@ func StartExpireListener(ctx context.Context) error {
 
 	// 初始化时先处理已过期的订单
 	model.ExpirePendingOrders(ctx)
+	model.ExpirePendingPaymentRequests(ctx)
 
 	cfg := config.Config.Redis
 
@@ -130,6 +137,11 @@ func subscribeExpireEvents(ctx context.Context, pubSub *redis.PubSub, channelDes
 
 // handleExpiredKey 处理过期的 Redis key
 func handleExpiredKey(ctx context.Context, expiredKey string) {
+	if requestPrefix := db.PrefixedKey(paymentRequestExpireKeyPrefix); strings.HasPrefix(expiredKey, requestPrefix) {
+		handleExpiredPaymentRequest(ctx, strings.TrimPrefix(expiredKey, requestPrefix))
+		return
+	}
+
 	fullPrefix := db.PrefixedKey(orderExpireKeyPrefix)
 
 	// 只处理订单过期相关的 key
@@ -144,14 +156,45 @@ func handleExpiredKey(ctx context.Context, expiredKey string) {
 		return
 	}
 
-	// 更新订单状态为过期
-	result := db.DB(ctx).Model(&model.Order{}).
-		Where("id = ? AND status = ?", orderID, model.OrderStatusPending).
-		Update("status", model.OrderStatusExpired)
+	// 更新订单状态为过期，事件回调与状态变更在同一事务内写入
+	var order model.Order
+	var expired bool
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&order).
+			Clauses(clause.Returning{}).
+			Where("id = ? AND status = ?", orderID, model.OrderStatusPending).
+			Update("status", model.OrderStatusExpired)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		expired = true
+
+		return service.DispatchWebhookEvent(tx, order.ClientID, model.WebhookEventOrderExpired, order.ID, service.OrderEventData(&order))
+	}); err != nil {
+		logger.ErrorF(ctx, "更新订单状态为过期失败: order_id=%d, error=%v", orderID, err)
+	} else if expired {
+		logger.InfoF(ctx, "订单已过期: order_id=%d", orderID)
+	}
+}
+
+// handleExpiredPaymentRequest 将过期的 pending 收款请求设置为 expired
+func handleExpiredPaymentRequest(ctx context.Context, requestIDStr string) {
+	requestID, err := strconv.ParseUint(requestIDStr, 10, 64)
+	if err != nil {
+		logger.ErrorF(ctx, "解析收款请求ID失败: id=%s, error=%v", requestIDStr, err)
+		return
+	}
+
+	result := db.DB(ctx).Model(&model.PaymentRequest{}).
+		Where("id = ? AND status = ?", requestID, model.PaymentRequestStatusPending).
+		Update("status", model.PaymentRequestStatusExpired)
 
 	if result.Error != nil {
-		logger.ErrorF(ctx, "更新订单状态为过期失败: order_id=%d, error=%v", orderID, result.Error)
+		logger.ErrorF(ctx, "更新收款请求状态为过期失败: request_id=%d, error=%v", requestID, result.Error)
 	} else if result.RowsAffected > 0 {
-		logger.InfoF(ctx, "订单已过期: order_id=%d", orderID)
+		logger.InfoF(ctx, "收款请求已过期: request_id=%d", requestID)
 	}
 }
@@ -0,0 +1,90 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type DistributeBatchStatus string
+
+const (
+	DistributeBatchStatusPending    DistributeBatchStatus = "pending"
+	DistributeBatchStatusProcessing DistributeBatchStatus = "processing"
+	DistributeBatchStatusCompleted  DistributeBatchStatus = "completed" // 全部明细已处理（含失败）
+)
+
+type DistributeBatchItemStatus string
+
+const (
+	DistributeBatchItemStatusPending DistributeBatchItemStatus = "pending"
+	DistributeBatchItemStatusSuccess DistributeBatchItemStatus = "success"
+	DistributeBatchItemStatusFailed  DistributeBatchItemStatus = "failed"
+)
+
+// DistributeBatch 商户批量分发批次，提交后由异步任务逐条分发，每条明细独立成功或失败
+type DistributeBatch struct {
+	ID               uint64                `json:"id,string" gorm:"primaryKey"`
+	MerchantAPIKeyID uint64                `json:"merchant_api_key_id" gorm:"not null;index"`
+	ClientID         string                `json:"client_id" gorm:"size:64;not null;uniqueIndex:idx_distribute_batches_client_batch_no,priority:1"`
+	MerchantBatchNo  *string               `json:"out_batch_no" gorm:"size:64;uniqueIndex:idx_distribute_batches_client_batch_no,priority:2"`
+	TotalCount       int                   `json:"total_count" gorm:"not null"`
+	TotalAmount      decimal.Decimal       `json:"total_amount" gorm:"type:numeric(20,2);not null"`
+	SuccessCount     int                   `json:"success_count" gorm:"not null;default:0"`
+	SuccessAmount    decimal.Decimal       `json:"success_amount" gorm:"type:numeric(20,2);not null;default:0"`
+	FailedCount      int                   `json:"failed_count" gorm:"not null;default:0"`
+	Remark           string                `json:"remark" gorm:"size:100"`
+	Status           DistributeBatchStatus `json:"status" gorm:"type:varchar(16);not null;index"`
+	CompletedAt      *time.Time            `json:"completed_at"`
+	CreatedAt        time.Time             `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time             `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (b *DistributeBatch) BeforeCreate(*gorm.DB) error {
+	if b.ID == 0 {
+		b.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// DistributeBatchItem 批量分发明细
+type DistributeBatchItem struct {
+	ID                uint64                    `json:"id,string" gorm:"primaryKey"`
+	BatchID           uint64                    `json:"batch_id,string" gorm:"not null;uniqueIndex:idx_distribute_batch_items_batch_seq,priority:1"`
+	Seq               int                       `json:"seq" gorm:"not null;uniqueIndex:idx_distribute_batch_items_batch_seq,priority:2"`
+	RecipientID       uint64                    `json:"user_id" gorm:"not null"`
+	RecipientUsername string                    `json:"username" gorm:"size:255;not null"`
+	Amount            decimal.Decimal           `json:"amount" gorm:"type:numeric(20,2);not null"`
+	MerchantOrderNo   *string                   `json:"out_trade_no" gorm:"size:64"`
+	Remark            string                    `json:"remark" gorm:"size:100"`
+	Status            DistributeBatchItemStatus `json:"status" gorm:"type:varchar(16);not null"`
+	OrderID           *uint64                   `json:"trade_no,string"`
+	ErrorMsg          string                    `json:"error_msg" gorm:"size:255"`
+	CreatedAt         time.Time                 `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time                 `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (i *DistributeBatchItem) BeforeCreate(*gorm.DB) error {
+	if i.ID == 0 {
+		i.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type GiftCodeIssuerType string
+
+const (
+	GiftCodeIssuerAdmin    GiftCodeIssuerType = "admin"    // 管理员发行，由平台系统账户出资
+	GiftCodeIssuerMerchant GiftCodeIssuerType = "merchant" // 商户发行，由商户余额预先出资
+)
+
+type GiftCodeBatchStatus string
+
+const (
+	GiftCodeBatchStatusActive  GiftCodeBatchStatus = "active"
+	GiftCodeBatchStatusExpired GiftCodeBatchStatus = "expired" // 已过期，商户批次未兑换的金额已退回
+)
+
+// GiftCodeBatch 礼品码批次
+// 商户批次创建时按最大可兑换总额从商户余额转入平台系统账户，兑换时由平台系统账户转给兑换人，过期后未兑换的部分退回商户
+// 管理员批次不预先出资，兑换时直接由平台系统账户发放
+type GiftCodeBatch struct {
+	ID               uint64              `json:"id,string" gorm:"primaryKey"`
+	IssuerType       GiftCodeIssuerType  `json:"issuer_type" gorm:"type:varchar(16);not null;index"`
+	IssuerUserID     uint64              `json:"issuer_user_id" gorm:"not null;index"`
+	MerchantAPIKeyID *uint64             `json:"merchant_api_key_id" gorm:"index"`
+	Name             string              `json:"name" gorm:"size:50;not null"`
+	Amount           decimal.Decimal     `json:"amount" gorm:"type:numeric(20,2);not null"`
+	CodeCount        int                 `json:"code_count" gorm:"not null"`
+	MaxRedemptions   int                 `json:"max_redemptions" gorm:"not null;default:1"`
+	PerUserLimit     int                 `json:"per_user_limit" gorm:"not null;default:1"`
+	TotalAmount      decimal.Decimal     `json:"total_amount" gorm:"type:numeric(20,2);not null"`
+	RedeemedCount    int                 `json:"redeemed_count" gorm:"not null;default:0"`
+	RedeemedAmount   decimal.Decimal     `json:"redeemed_amount" gorm:"type:numeric(20,2);not null;default:0"`
+	FundOrderID      *uint64             `json:"fund_order_id,string" gorm:"uniqueIndex"`
+	Remark           string              `json:"remark" gorm:"size:100"`
+	Status           GiftCodeBatchStatus `json:"status" gorm:"type:varchar(16);not null;index:idx_gift_code_batches_status_expires,priority:1"`
+	ExpiresAt        time.Time           `json:"expires_at" gorm:"not null;index:idx_gift_code_batches_status_expires,priority:2"`
+	CreatedAt        time.Time           `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt        time.Time           `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (b *GiftCodeBatch) BeforeCreate(*gorm.DB) error {
+	if b.ID == 0 {
+		b.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// GiftCode 礼品码，单次码 MaxRedemptions 为 1，多次码可被不同用户兑换多次
+type GiftCode struct {
+	ID             uint64    `json:"id,string" gorm:"primaryKey"`
+	BatchID        uint64    `json:"batch_id,string" gorm:"not null;index"`
+	Code           string    `json:"code" gorm:"size:32;uniqueIndex;not null"`
+	MaxRedemptions int       `json:"max_redemptions" gorm:"not null"`
+	RedeemedCount  int       `json:"redeemed_count" gorm:"not null;default:0"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (g *GiftCode) BeforeCreate(*gorm.DB) error {
+	if g.ID == 0 {
+		g.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// GiftCodeRedemption 礼品码兑换记录，同一用户对同一礼品码仅可兑换一次
+type GiftCodeRedemption struct {
+	ID         uint64          `json:"id,string" gorm:"primaryKey"`
+	BatchID    uint64          `json:"batch_id,string" gorm:"not null;index:idx_gift_code_redemptions_batch_user,priority:1"`
+	GiftCodeID uint64          `json:"gift_code_id,string" gorm:"not null;uniqueIndex:idx_gift_code_redemptions_code_user,priority:1"`
+	UserID     uint64          `json:"user_id" gorm:"not null;index:idx_gift_code_redemptions_batch_user,priority:2;uniqueIndex:idx_gift_code_redemptions_code_user,priority:2"`
+	Username   string          `json:"username" gorm:"->"`
+	Code       string          `json:"code" gorm:"->"`
+	Amount     decimal.Decimal `json:"amount" gorm:"type:numeric(20,2);not null"`
+	OrderID    uint64          `json:"order_id,string" gorm:"not null"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (r *GiftCodeRedemption) BeforeCreate(*gorm.DB) error {
+	if r.ID == 0 {
+		r.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
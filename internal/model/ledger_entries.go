@@ -0,0 +1,78 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// PlatformAccountID 平台系统账户（手续费收入、社区积分发放等资金的对手方）
+const PlatformAccountID uint64 = 0
+
+type LedgerEntryType string
+
+const (
+	LedgerEntryTypePayment     LedgerEntryType = "payment"
+	LedgerEntryTypeFee         LedgerEntryType = "fee"
+	LedgerEntryTypeTransfer    LedgerEntryType = "transfer"
+	LedgerEntryTypeDistribute  LedgerEntryType = "distribute"
+	LedgerEntryTypeRefund      LedgerEntryType = "refund"
+	LedgerEntryTypeCommunity   LedgerEntryType = "community"
+	LedgerEntryTypeOpening     LedgerEntryType = "opening"      // 启用流水前的期初余额
+	LedgerEntryTypeHold        LedgerEntryType = "hold"         // 预授权冻结与释放，对手方为平台系统账户
+	LedgerEntryTypeRedEnvelope LedgerEntryType = "red_envelope" // 红包发起、领取与退回，资金经平台系统账户中转
+	LedgerEntryTypeGiftCode    LedgerEntryType = "gift_code"    // 礼品码出资、兑换与退回，资金经平台系统账户中转
+)
+
+// LedgerEntry 账户流水分录
+// 每一笔余额变动都会生成一条分录，同一订单下所有分录金额之和为 0（复式记账）
+// 平台系统账户的分录不记录变动后余额
+type LedgerEntry struct {
+	ID            uint64           `json:"id,string" gorm:"primaryKey"`
+	UserID        uint64           `json:"user_id" gorm:"not null;index:idx_ledger_entries_user_created,priority:1"`
+	CounterUserID uint64           `json:"counter_user_id" gorm:"not null;index"`
+	OrderID       uint64           `json:"order_id,string" gorm:"not null;index"`
+	Amount        decimal.Decimal  `json:"amount" gorm:"type:numeric(20,2);not null"`
+	BalanceAfter  *decimal.Decimal `json:"balance_after" gorm:"type:numeric(20,2)"`
+	Type          LedgerEntryType  `json:"type" gorm:"type:varchar(20);not null;index"`
+	CreatedAt     time.Time        `json:"created_at" gorm:"autoCreateTime;index:idx_ledger_entries_user_created,priority:2"`
+}
+
+func (e *LedgerEntry) BeforeCreate(*gorm.DB) error {
+	if e.ID == 0 {
+		e.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// CreatePlatformLedgerEntry 记录平台系统账户分录，金额为 0 时跳过
+func CreatePlatformLedgerEntry(tx *gorm.DB, counterUserID, orderID uint64, amount decimal.Decimal, entryType LedgerEntryType) error {
+	if amount.IsZero() {
+		return nil
+	}
+	return tx.Create(&LedgerEntry{
+		UserID:        PlatformAccountID,
+		CounterUserID: counterUserID,
+		OrderID:       orderID,
+		Amount:        amount,
+		Type:          entryType,
+	}).Error
+}
@@ -17,26 +17,51 @@ limitations under the License.
 package model
 
 import (
+	"net"
 	"time"
 
 	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
+type SignType string
+
+const (
+	SignTypeMD5        SignType = "MD5"
+	SignTypeHMACSHA256 SignType = "HMAC-SHA256"
+)
+
 type MerchantAPIKey struct {
-	ID             uint64         `json:"id,string" gorm:"primaryKey"`
-	UserID         uint64         `json:"user_id" gorm:"not null;index:idx_merchant_api_keys_user_created,priority:1"`
-	ClientID       string         `json:"client_id" gorm:"size:64;uniqueIndex;index:idx_client_credentials,priority:2;not null"`
-	ClientSecret   string         `json:"client_secret" gorm:"size:64;index:idx_client_credentials,priority:1;not null"`
-	AppName        string         `json:"app_name" gorm:"size:20;not null"`
-	AppHomepageURL string         `json:"app_homepage_url" gorm:"size:100;not null"`
-	AppDescription string         `json:"app_description" gorm:"size:100"`
-	RedirectURI    string         `json:"redirect_uri" gorm:"size:100"`
-	NotifyURL      string         `json:"notify_url" gorm:"size:100;not null"`
-	TestMode       bool           `json:"test_mode" gorm:"default:false"`
-	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime;index:idx_merchant_api_keys_user_created,priority:2"`
-	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID             uint64           `json:"id,string" gorm:"primaryKey"`
+	UserID         uint64           `json:"user_id" gorm:"not null;index:idx_merchant_api_keys_user_created,priority:1"`
+	ClientID       string           `json:"client_id" gorm:"size:64;uniqueIndex;index:idx_client_credentials,priority:2;not null"`
+	ClientSecret   string           `json:"-" gorm:"size:64;index:idx_client_credentials,priority:1;not null"` // 签名密钥，仅用于请求签名与回调签名，接口认证使用 MerchantAPISecret
+	AppName        string           `json:"app_name" gorm:"size:20;not null"`
+	AppHomepageURL string           `json:"app_homepage_url" gorm:"size:100;not null"`
+	AppDescription string           `json:"app_description" gorm:"size:100"`
+	RedirectURI    string           `json:"redirect_uri" gorm:"size:100"`
+	NotifyURL      string           `json:"notify_url" gorm:"size:100;not null"`
+	TestMode       bool             `json:"test_mode" gorm:"default:false"`
+	SignType       SignType         `json:"sign_type" gorm:"type:varchar(20);not null;default:'MD5'"`
+	ExpireMinutes  int              `json:"expire_minutes" gorm:"not null;default:0"`
+	WebhookURL     string           `json:"webhook_url" gorm:"size:255"`
+	WebhookEvents  util.StringArray `json:"webhook_events" gorm:"type:jsonb;not null;default:'[]'"`
+	// 允许 MD5 签名不携带 timestamp 与 nonce，仅用于兼容旧客户端，新建应用默认关闭
+	LegacySignAllowed bool `json:"legacy_sign_allowed" gorm:"not null;default:false"`
+	// 轮换签名密钥后，旧签名密钥在过期前仍可用于校验请求签名
+	PreviousClientSecret          string     `json:"-" gorm:"size:64;not null;default:''"`
+	PreviousClientSecretExpiresAt *time.Time `json:"previous_signing_key_expires_at"`
+	// 分发限额，0 表示不限制
+	DistributeMaxAmount           decimal.Decimal  `json:"distribute_max_amount" gorm:"type:numeric(20,2);not null;default:0"`
+	DistributeDailyLimit          decimal.Decimal  `json:"distribute_daily_limit" gorm:"type:numeric(20,2);not null;default:0"`
+	DistributeMonthlyLimit        decimal.Decimal  `json:"distribute_monthly_limit" gorm:"type:numeric(20,2);not null;default:0"`
+	DistributeRecipientDailyLimit decimal.Decimal  `json:"distribute_recipient_daily_limit" gorm:"type:numeric(20,2);not null;default:0"`
+	DistributeAllowedIPs          util.StringArray `json:"distribute_allowed_ips" gorm:"type:jsonb;not null;default:'[]'"`
+	CreatedAt                     time.Time        `json:"created_at" gorm:"autoCreateTime;index:idx_merchant_api_keys_user_created,priority:2"`
+	UpdatedAt                     time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt                     gorm.DeletedAt   `json:"deleted_at" gorm:"index"`
 }
 
 // GetByID 通过 ID 查询商户 API Key
@@ -49,6 +74,54 @@ func (m *MerchantAPIKey) GetByClientID(tx *gorm.DB, clientID string) error {
 	return tx.Where("client_id = ?", clientID).First(m).Error
 }
 
+// SigningSecrets 当前可用于校验请求签名的密钥，轮换重叠期内包含旧签名密钥
+func (m *MerchantAPIKey) SigningSecrets(now time.Time) []string {
+	secrets := []string{m.ClientSecret}
+	if m.PreviousClientSecret != "" && m.PreviousClientSecretExpiresAt != nil && m.PreviousClientSecretExpiresAt.After(now) {
+		secrets = append(secrets, m.PreviousClientSecret)
+	}
+	return secrets
+}
+
+// SubscribesTo 是否订阅了指定事件
+func (m *MerchantAPIKey) SubscribesTo(event WebhookEvent) bool {
+	for _, e := range m.WebhookEvents {
+		if WebhookEvent(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// EventWebhookURL 事件回调地址，未单独配置时使用 notify_url
+func (m *MerchantAPIKey) EventWebhookURL() string {
+	if m.WebhookURL != "" {
+		return m.WebhookURL
+	}
+	return m.NotifyURL
+}
+
+// AllowsDistributeIP 请求来源 IP 是否在分发白名单内，白名单为空时不限制，条目可为 IP 或 CIDR
+func (m *MerchantAPIKey) AllowsDistributeIP(ip string) bool {
+	if len(m.DistributeAllowedIPs) == 0 {
+		return true
+	}
+	clientIP := net.ParseIP(ip)
+	if clientIP == nil {
+		return false
+	}
+	for _, entry := range m.DistributeAllowedIPs {
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			if ipNet.Contains(clientIP) {
+				return true
+			}
+		} else if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(clientIP) {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *MerchantAPIKey) BeforeCreate(*gorm.DB) error {
 	if m.ID == 0 {
 		m.ID = idgen.NextUint64ID()
@@ -0,0 +1,54 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMerchantAPIKeySigningSecrets(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		previous  string
+		expiresAt *time.Time
+		want      []string
+	}{
+		{name: "no previous key", want: []string{"current"}},
+		{name: "previous key within overlap", previous: "old", expiresAt: &future, want: []string{"current", "old"}},
+		{name: "previous key expired", previous: "old", expiresAt: &past, want: []string{"current"}},
+		{name: "previous key expires now", previous: "old", expiresAt: &now, want: []string{"current"}},
+		{name: "previous key without expiry", previous: "old", want: []string{"current"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKey := MerchantAPIKey{
+				ClientSecret:                  "current",
+				PreviousClientSecret:          tt.previous,
+				PreviousClientSecretExpiresAt: tt.expiresAt,
+			}
+			if got := apiKey.SigningSecrets(now); !slices.Equal(got, tt.want) {
+				t.Errorf("SigningSecrets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
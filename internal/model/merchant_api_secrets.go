@@ -0,0 +1,98 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+)
+
+type APISecretScope string
+
+const (
+	APISecretScopeCreateOrder APISecretScope = "create_order"
+	APISecretScopeQuery       APISecretScope = "query"
+	APISecretScopeRefund      APISecretScope = "refund"
+	APISecretScopeDistribute  APISecretScope = "distribute"
+)
+
+// AllAPISecretScopes 全部权限，应用默认密钥拥有全部权限
+var AllAPISecretScopes = util.StringArray{
+	string(APISecretScopeCreateOrder),
+	string(APISecretScopeQuery),
+	string(APISecretScopeRefund),
+	string(APISecretScopeDistribute),
+}
+
+// MerchantAPISecret 商户应用的接口密钥，仅保存哈希，明文只在创建时返回一次
+// 轮换时旧密钥设置过期时间，过期前新旧密钥同时可用
+type MerchantAPISecret struct {
+	ID         uint64           `json:"id,string" gorm:"primaryKey"`
+	APIKeyID   uint64           `json:"api_key_id,string" gorm:"not null;index"`
+	Name       string           `json:"name" gorm:"size:32;not null"`
+	SecretHash string           `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	Prefix     string           `json:"prefix" gorm:"size:8;not null"`
+	Scopes     util.StringArray `json:"scopes" gorm:"type:jsonb;not null;default:'[]'"`
+	ExpiresAt  *time.Time       `json:"expires_at"`
+	LastUsedAt *time.Time       `json:"last_used_at"`
+	RevokedAt  *time.Time       `json:"revoked_at"`
+	CreatedAt  time.Time        `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (s *MerchantAPISecret) BeforeCreate(*gorm.DB) error {
+	if s.ID == 0 {
+		s.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// NewMerchantAPISecret 根据明文构造密钥记录
+func NewMerchantAPISecret(apiKeyID uint64, name, plaintext string, scopes util.StringArray) *MerchantAPISecret {
+	return &MerchantAPISecret{
+		APIKeyID:   apiKeyID,
+		Name:       name,
+		SecretHash: HashAPISecret(plaintext),
+		Prefix:     plaintext[:8],
+		Scopes:     scopes,
+	}
+}
+
+// HashAPISecret 计算密钥哈希，密钥为 256 位随机数，无需加盐
+func HashAPISecret(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsActive 密钥未吊销且未过期
+func (s *MerchantAPISecret) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && (s.ExpiresAt == nil || s.ExpiresAt.After(now))
+}
+
+// HasScope 是否拥有指定权限
+func (s *MerchantAPISecret) HasScope(scope APISecretScope) bool {
+	for _, sc := range s.Scopes {
+		if APISecretScope(sc) == scope {
+			return true
+		}
+	}
+	return false
+}
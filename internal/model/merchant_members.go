@@ -0,0 +1,105 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"gorm.io/gorm"
+)
+
+type MerchantRole string
+
+const (
+	MerchantRoleOwner     MerchantRole = "owner"     // 应用创建者，拥有全部权限
+	MerchantRoleDeveloper MerchantRole = "developer" // 管理应用配置、密钥与回调
+	MerchantRoleSupport   MerchantRole = "support"   // 查看订单并处理退款争议
+	MerchantRoleFinance   MerchantRole = "finance"   // 查看订单并执行退款与分发
+)
+
+type MerchantMemberStatus string
+
+const (
+	MerchantMemberStatusPending MerchantMemberStatus = "pending" // 已邀请，待对方接受
+	MerchantMemberStatusActive  MerchantMemberStatus = "active"
+)
+
+// MerchantMember 商户应用成员，应用创建者以 owner 角色加入
+type MerchantMember struct {
+	ID          uint64               `json:"id,string" gorm:"primaryKey"`
+	APIKeyID    uint64               `json:"api_key_id,string" gorm:"not null;uniqueIndex:idx_merchant_members_key_user,priority:1"`
+	UserID      uint64               `json:"user_id" gorm:"not null;uniqueIndex:idx_merchant_members_key_user,priority:2;index"`
+	Username    string               `json:"username" gorm:"->"`
+	Role        MerchantRole         `json:"role" gorm:"type:varchar(20);not null"`
+	Status      MerchantMemberStatus `json:"status" gorm:"type:varchar(20);not null"`
+	InvitedBy   uint64               `json:"invited_by" gorm:"not null"`
+	AppName     string               `json:"app_name,omitempty" gorm:"->"`
+	InviterName string               `json:"inviter_name,omitempty" gorm:"->"`
+	CreatedAt   time.Time            `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time            `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (m *MerchantMember) BeforeCreate(*gorm.DB) error {
+	if m.ID == 0 {
+		m.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// HasRole 成员是否拥有任一指定角色，owner 拥有全部权限
+func (m *MerchantMember) HasRole(roles ...MerchantRole) bool {
+	if m.Role == MerchantRoleOwner {
+		return true
+	}
+	for _, role := range roles {
+		if m.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// MemberClientIDs 当前用户以指定角色（含 owner）参与的应用 ClientID 子查询
+func MemberClientIDs(tx *gorm.DB, userID uint64, roles ...MerchantRole) *gorm.DB {
+	return tx.Model(&MerchantAPIKey{}).
+		Select("merchant_api_keys.client_id").
+		Joins("JOIN merchant_members ON merchant_members.api_key_id = merchant_api_keys.id").
+		Where("merchant_members.user_id = ? AND merchant_members.status = ? AND merchant_members.role IN ?",
+			userID, MerchantMemberStatusActive, append([]MerchantRole{MerchantRoleOwner}, roles...))
+}
+
+// MerchantAuditLog 商户应用操作审计记录
+type MerchantAuditLog struct {
+	ID         uint64       `json:"id,string" gorm:"primaryKey"`
+	APIKeyID   uint64       `json:"api_key_id,string" gorm:"not null;index:idx_merchant_audit_logs_key_created,priority:1"`
+	UserID     uint64       `json:"user_id" gorm:"not null;index"`
+	Username   string       `json:"username" gorm:"->"`
+	Role       MerchantRole `json:"role" gorm:"type:varchar(20);not null"`
+	Action     string       `json:"action" gorm:"size:128;not null"` // 请求方法与路由，如 POST /secrets/:secretId/rotate
+	Target     string       `json:"target" gorm:"size:128"`          // 路由中的资源 ID
+	StatusCode int          `json:"status_code" gorm:"not null"`
+	IP         string       `json:"ip" gorm:"size:64"`
+	CreatedAt  time.Time    `json:"created_at" gorm:"autoCreateTime;index:idx_merchant_audit_logs_key_created,priority:2"`
+}
+
+func (l *MerchantAuditLog) BeforeCreate(*gorm.DB) error {
+	if l.ID == 0 {
+		l.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
@@ -32,25 +32,43 @@ import (
 type OrderType string
 
 const (
-	OrderTypeReceive    OrderType = "receive"
-	OrderTypePayment    OrderType = "payment"
-	OrderTypeTransfer   OrderType = "transfer"
-	OrderTypeCommunity  OrderType = "community"
-	OrderTypeOnline     OrderType = "online"
-	OrderTypeTest       OrderType = "test"
-	OrderTypeDistribute OrderType = "distribute"
+	OrderTypeReceive     OrderType = "receive"
+	OrderTypePayment     OrderType = "payment"
+	OrderTypeTransfer    OrderType = "transfer"
+	OrderTypeCommunity   OrderType = "community"
+	OrderTypeOnline      OrderType = "online"
+	OrderTypeTest        OrderType = "test"
+	OrderTypeDistribute  OrderType = "distribute"
+	OrderTypeRefund      OrderType = "refund"       // 退款子订单，关联原订单
+	OrderTypeEscrow      OrderType = "escrow"       // 担保交易，付款方确认收货后放款
+	OrderTypeRedEnvelope OrderType = "red_envelope" // 红包：发起、领取与过期退回
+	OrderTypeGiftCode    OrderType = "gift_code"    // 礼品码：商户出资、兑换与过期退回
 )
 
 type OrderStatus string
 
 const (
-	OrderStatusSuccess   OrderStatus = "success"
-	OrderStatusFailed    OrderStatus = "failed"
-	OrderStatusPending   OrderStatus = "pending"
-	OrderStatusExpired   OrderStatus = "expired"
-	OrderStatusDisputing OrderStatus = "disputing"
-	OrderStatusRefund    OrderStatus = "refund"
-	OrderStatusRefused   OrderStatus = "refused"
+	OrderStatusSuccess           OrderStatus = "success"
+	OrderStatusFailed            OrderStatus = "failed"
+	OrderStatusPending           OrderStatus = "pending"
+	OrderStatusExpired           OrderStatus = "expired"
+	OrderStatusDisputing         OrderStatus = "disputing"
+	OrderStatusRefund            OrderStatus = "refund"
+	OrderStatusRefused           OrderStatus = "refused"
+	OrderStatusPartiallyRefunded OrderStatus = "partially_refunded"
+	OrderStatusCancelled         OrderStatus = "cancelled"
+	OrderStatusAuthorized        OrderStatus = "authorized" // 预授权：付款方积分已冻结，待商户确认收款
+)
+
+// OrderPaidStatuses 已支付且未全额退款的订单状态
+var OrderPaidStatuses = []OrderStatus{OrderStatusSuccess, OrderStatusPartiallyRefunded}
+
+const (
+	OrderNameNewUserReward   = "新用户注册奖励"
+	OrderNameCommunityUpdate = "社区积分更新"
+	OrderNameGiftCodeFund    = "礼品码出资"
+	OrderNameGiftCodeRedeem  = "兑换礼品码"
+	OrderNameGiftCodeRefund  = "礼品码退回"
 )
 
 type Order struct {
@@ -64,11 +82,16 @@ type Order struct {
 	PayerUsername   string          `json:"payer_username" gorm:"->"`
 	PayeeUsername   string          `json:"payee_username" gorm:"->"`
 	Amount          decimal.Decimal `json:"amount" gorm:"type:numeric(20,2);not null;index"`
+	Fee             decimal.Decimal `json:"fee" gorm:"type:numeric(20,2);not null;default:0"`
+	RefundedAmount  decimal.Decimal `json:"refunded_amount" gorm:"type:numeric(20,2);not null;default:0"`
+	ParentOrderID   *uint64         `json:"parent_order_id,string" gorm:"index"`
 	Status          OrderStatus     `json:"status" gorm:"type:varchar(20);not null;index:idx_orders_payee_status_type_created,priority:2;index:idx_orders_payer_status_type_created,priority:2;index:idx_orders_client_status_created,priority:2;index:idx_orders_payer_status_type_trade,priority:2;index:idx_orders_payment_link_status,priority:2"`
 	Type            OrderType       `json:"type" gorm:"type:varchar(20);not null;index:idx_orders_payee_status_type_created,priority:3;index:idx_orders_payer_status_type_created,priority:3;index:idx_orders_payer_status_type_trade,priority:3"`
 	Remark          string          `json:"remark" gorm:"size:255"`
 	PaymentType     string          `json:"payment_type" gorm:"size:20"`
 	PaymentLinkID   *uint64         `json:"payment_link_id,string" gorm:"index:idx_orders_payment_link_status,priority:1"`
+	SubscriptionID  *uint64         `json:"subscription_id,string" gorm:"index"`
+	ManualCapture   bool            `json:"manual_capture" gorm:"not null;default:false"`
 	TradeTime       time.Time       `json:"trade_time" gorm:"index:idx_orders_payer_status_type_trade,priority:4"`
 	ExpiresAt       time.Time       `json:"expires_at" gorm:"not null"`
 	CreatedAt       time.Time       `json:"created_at" gorm:"autoCreateTime;index:idx_orders_payee_status_type_created,priority:4;index:idx_orders_payer_status_type_created,priority:4;index:idx_orders_client_status_created,priority:3"`
@@ -0,0 +1,92 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/db/idgen"
+	"gorm.io/gorm"
+)
+
+type OutboxEventStatus string
+
+const (
+	OutboxEventStatusPending    OutboxEventStatus = "pending"
+	OutboxEventStatusDispatched OutboxEventStatus = "dispatched"
+)
+
+// OutboxEvent 事务发件箱
+// 与业务数据在同一事务内写入，由 outbox 中继进程投递到 asynq（至少一次）
+type OutboxEvent struct {
+	ID             uint64            `json:"id,string" gorm:"primaryKey"`
+	TaskType       string            `json:"task_type" gorm:"type:varchar(64);not null"`
+	Payload        string            `json:"payload" gorm:"type:text;not null"`
+	Queue          string            `json:"queue" gorm:"type:varchar(32);not null"`
+	TaskID         string            `json:"task_id" gorm:"type:varchar(128);not null;default:''"`
+	MaxRetry       int               `json:"max_retry" gorm:"not null;default:0"`
+	TimeoutSeconds int               `json:"timeout_seconds" gorm:"not null;default:0"`
+	ProcessAt      *time.Time        `json:"process_at"`
+	Status         OutboxEventStatus `json:"status" gorm:"type:varchar(16);not null;index"`
+	Attempts       int               `json:"attempts" gorm:"not null;default:0"`
+	LastError      string            `json:"last_error" gorm:"type:text"`
+	NextAttemptAt  *time.Time        `json:"next_attempt_at" gorm:"index"`
+	CreatedAt      time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	DispatchedAt   *time.Time        `json:"dispatched_at" gorm:"index"`
+}
+
+func (e *OutboxEvent) BeforeCreate(*gorm.DB) error {
+	if e.ID == 0 {
+		e.ID = idgen.NextUint64ID()
+	}
+	if e.Status == "" {
+		e.Status = OutboxEventStatusPending
+	}
+	return nil
+}
+
+// CreateOutboxEvent 在事务内写入待投递任务
+func CreateOutboxEvent(tx *gorm.DB, e *OutboxEvent) error {
+	if err := tx.Create(e).Error; err != nil {
+		return fmt.Errorf("写入任务发件箱失败: %w", err)
+	}
+	return nil
+}
+
+// Task 构建 asynq 任务及投递选项
+// 未指定 TaskID 时使用发件箱 ID，避免中继重复投递时产生重复任务
+func (e *OutboxEvent) Task() (*asynq.Task, []asynq.Option) {
+	taskID := e.TaskID
+	if taskID == "" {
+		taskID = fmt.Sprintf("outbox_%d", e.ID)
+	}
+
+	opts := []asynq.Option{
+		asynq.Queue(e.Queue),
+		asynq.MaxRetry(e.MaxRetry),
+		asynq.TaskID(taskID),
+	}
+	if e.TimeoutSeconds > 0 {
+		opts = append(opts, asynq.Timeout(time.Duration(e.TimeoutSeconds)*time.Second))
+	}
+	if e.ProcessAt != nil {
+		opts = append(opts, asynq.ProcessAt(*e.ProcessAt))
+	}
+	return asynq.NewTask(e.TaskType, []byte(e.Payload)), opts
+}
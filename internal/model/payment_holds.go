@@ -0,0 +1,57 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type PaymentHoldStatus string
+
+const (
+	PaymentHoldStatusHeld     PaymentHoldStatus = "held"
+	PaymentHoldStatusCaptured PaymentHoldStatus = "captured"
+	PaymentHoldStatusVoided   PaymentHoldStatus = "voided"
+	PaymentHoldStatusReleased PaymentHoldStatus = "released" // 超时未确认收款，自动释放
+)
+
+// PaymentHold 预授权冻结记录
+// 付款时冻结付款方积分，商户确认收款（全额或部分）后结算，撤销或超时后全额退回付款方
+type PaymentHold struct {
+	ID             uint64            `json:"id,string" gorm:"primaryKey"`
+	OrderID        uint64            `json:"order_id,string" gorm:"not null;uniqueIndex"`
+	ClientID       string            `json:"client_id" gorm:"size:64;not null"`
+	PayerUserID    uint64            `json:"payer_user_id" gorm:"not null;index"`
+	PayeeUserID    uint64            `json:"payee_user_id" gorm:"not null"`
+	Amount         decimal.Decimal   `json:"amount" gorm:"type:numeric(20,2);not null"`
+	CapturedAmount decimal.Decimal   `json:"captured_amount" gorm:"type:numeric(20,2);not null;default:0"`
+	Status         PaymentHoldStatus `json:"status" gorm:"type:varchar(16);not null;index:idx_payment_holds_status_expires,priority:1"`
+	ExpiresAt      time.Time         `json:"expires_at" gorm:"not null;index:idx_payment_holds_status_expires,priority:2"`
+	CreatedAt      time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (h *PaymentHold) BeforeCreate(*gorm.DB) error {
+	if h.ID == 0 {
+		h.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
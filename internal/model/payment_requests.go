@@ -0,0 +1,73 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type PaymentRequestStatus string
+
+const (
+	PaymentRequestStatusPending  PaymentRequestStatus = "pending"
+	PaymentRequestStatusPaid     PaymentRequestStatus = "paid"
+	PaymentRequestStatusDeclined PaymentRequestStatus = "declined"
+	PaymentRequestStatusExpired  PaymentRequestStatus = "expired"
+)
+
+// PaymentRequest 收款请求，由收款方向指定用户发起，付款方支付后生成转账订单
+type PaymentRequest struct {
+	ID                uint64               `json:"id,string" gorm:"primaryKey"`
+	RequesterUserID   uint64               `json:"requester_user_id" gorm:"not null;index:idx_payment_requests_requester_created,priority:1"`
+	PayerUserID       uint64               `json:"payer_user_id" gorm:"not null;index:idx_payment_requests_payer_created,priority:1"`
+	RequesterUsername string               `json:"requester_username" gorm:"->"`
+	PayerUsername     string               `json:"payer_username" gorm:"->"`
+	Amount            decimal.Decimal      `json:"amount" gorm:"type:numeric(20,2);not null"`
+	Remark            string               `json:"remark" gorm:"size:100"`
+	Status            PaymentRequestStatus `json:"status" gorm:"type:varchar(16);not null;index"`
+	OrderID           *uint64              `json:"order_id,string"`
+	ExpiresAt         time.Time            `json:"expires_at" gorm:"not null"`
+	CreatedAt         time.Time            `json:"created_at" gorm:"autoCreateTime;index:idx_payment_requests_requester_created,priority:2;index:idx_payment_requests_payer_created,priority:2"`
+	UpdatedAt         time.Time            `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (r *PaymentRequest) BeforeCreate(*gorm.DB) error {
+	if r.ID == 0 {
+		r.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// ExpirePendingPaymentRequests 将已过期且 pending 状态的收款请求设置为 expired
+func ExpirePendingPaymentRequests(ctx context.Context) {
+	result := db.DB(ctx).Model(&PaymentRequest{}).
+		Where("status = ? AND expires_at <= ?", PaymentRequestStatusPending, time.Now()).
+		Update("status", PaymentRequestStatusExpired)
+
+	if result.Error != nil {
+		logger.ErrorF(ctx, "过期 pending 收款请求失败: %v", result.Error)
+	} else {
+		logger.InfoF(ctx, "已将 %d 个已过期的 pending 收款请求设置为 expired", result.RowsAffected)
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type ReconciliationField string
+
+const (
+	ReconciliationFieldAvailableBalance ReconciliationField = "available_balance"
+	ReconciliationFieldTotalReceive     ReconciliationField = "total_receive"
+	ReconciliationFieldTotalPayment     ReconciliationField = "total_payment"
+	ReconciliationFieldTotalTransfer    ReconciliationField = "total_transfer"
+	ReconciliationFieldTotalCommunity   ReconciliationField = "total_community"
+	ReconciliationFieldHeldBalance      ReconciliationField = "held_balance"
+	ReconciliationFieldLedgerBalance    ReconciliationField = "ledger_balance" // 可用余额与流水分录合计不一致
+)
+
+// ReconciliationFinding 对账差异记录
+// 同一次对账任务产生的记录共享 RunID，Diff = Actual - Expected
+type ReconciliationFinding struct {
+	ID        uint64              `json:"id,string" gorm:"primaryKey"`
+	RunID     uint64              `json:"run_id,string" gorm:"not null;index"`
+	UserID    uint64              `json:"user_id" gorm:"not null;index"`
+	Field     ReconciliationField `json:"field" gorm:"type:varchar(32);not null"`
+	Expected  decimal.Decimal     `json:"expected" gorm:"type:numeric(20,2);not null"`
+	Actual    decimal.Decimal     `json:"actual" gorm:"type:numeric(20,2);not null"`
+	Diff      decimal.Decimal     `json:"diff" gorm:"type:numeric(20,2);not null"`
+	CreatedAt time.Time           `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (f *ReconciliationFinding) BeforeCreate(*gorm.DB) error {
+	if f.ID == 0 {
+		f.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type RedEnvelopeSplitType string
+
+const (
+	RedEnvelopeSplitFixed  RedEnvelopeSplitType = "fixed"  // 每份金额相同
+	RedEnvelopeSplitRandom RedEnvelopeSplitType = "random" // 拼手气，每份金额随机
+)
+
+type RedEnvelopeStatus string
+
+const (
+	RedEnvelopeStatusActive   RedEnvelopeStatus = "active"
+	RedEnvelopeStatusFinished RedEnvelopeStatus = "finished" // 已领完
+	RedEnvelopeStatusExpired  RedEnvelopeStatus = "expired"  // 已过期，剩余金额已退回发起人
+)
+
+// RedEnvelope 红包
+// 发起时积分从发起人转入平台系统账户，领取时由平台系统账户转给领取人，过期未领完的部分退回发起人
+// 发起、领取与退回分别生成红包类型订单，领取与退回订单通过 ParentOrderID 关联发起订单
+type RedEnvelope struct {
+	ID              uint64               `json:"id,string" gorm:"primaryKey"`
+	Code            string               `json:"code" gorm:"size:64;uniqueIndex;not null"`
+	CreatorUserID   uint64               `json:"creator_user_id" gorm:"not null;index"`
+	OrderID         uint64               `json:"order_id,string" gorm:"not null;uniqueIndex"`
+	TotalAmount     decimal.Decimal      `json:"total_amount" gorm:"type:numeric(20,2);not null"`
+	TotalShares     int                  `json:"total_shares" gorm:"not null"`
+	RemainingAmount decimal.Decimal      `json:"remaining_amount" gorm:"type:numeric(20,2);not null"`
+	RemainingShares int                  `json:"remaining_shares" gorm:"not null"`
+	SplitType       RedEnvelopeSplitType `json:"split_type" gorm:"type:varchar(16);not null"`
+	MinTrustLevel   TrustLevel           `json:"min_trust_level" gorm:"not null;default:0"`
+	Remark          string               `json:"remark" gorm:"size:100"`
+	Status          RedEnvelopeStatus    `json:"status" gorm:"type:varchar(16);not null;index:idx_red_envelopes_status_expires,priority:1"`
+	ExpiresAt       time.Time            `json:"expires_at" gorm:"not null;index:idx_red_envelopes_status_expires,priority:2"`
+	CreatedAt       time.Time            `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt       time.Time            `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (e *RedEnvelope) BeforeCreate(*gorm.DB) error {
+	if e.ID == 0 {
+		e.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// RedEnvelopeClaim 红包领取记录，每个用户每个红包仅可领取一次
+type RedEnvelopeClaim struct {
+	ID            uint64          `json:"id,string" gorm:"primaryKey"`
+	RedEnvelopeID uint64          `json:"red_envelope_id,string" gorm:"not null;uniqueIndex:idx_red_envelope_claims_envelope_user,priority:1"`
+	UserID        uint64          `json:"user_id" gorm:"not null;uniqueIndex:idx_red_envelope_claims_envelope_user,priority:2;index"`
+	Username      string          `json:"username" gorm:"->"`
+	Amount        decimal.Decimal `json:"amount" gorm:"type:numeric(20,2);not null"`
+	OrderID       uint64          `json:"order_id,string" gorm:"not null"`
+	CreatedAt     time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (c *RedEnvelopeClaim) BeforeCreate(*gorm.DB) error {
+	if c.ID == 0 {
+		c.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type SubscriptionInterval string
+
+const (
+	SubscriptionIntervalDay   SubscriptionInterval = "day"
+	SubscriptionIntervalWeek  SubscriptionInterval = "week"
+	SubscriptionIntervalMonth SubscriptionInterval = "month"
+	SubscriptionIntervalYear  SubscriptionInterval = "year"
+)
+
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusActive    SubscriptionStatus = "active"
+	SubscriptionStatusPastDue   SubscriptionStatus = "past_due" // 扣款失败，等待重试
+	SubscriptionStatusCancelled SubscriptionStatus = "cancelled"
+)
+
+// SubscriptionPlan 商户订阅计划
+// 修改或删除计划不影响已订阅的用户，订阅时的金额与周期保存在订阅记录中
+type SubscriptionPlan struct {
+	ID               uint64               `json:"id,string" gorm:"primaryKey"`
+	MerchantAPIKeyID uint64               `json:"merchant_api_key_id,string" gorm:"not null;index"`
+	Token            string               `json:"token" gorm:"size:64;uniqueIndex;not null"`
+	Name             string               `json:"name" gorm:"size:30;not null"`
+	Amount           decimal.Decimal      `json:"amount" gorm:"type:numeric(20,2);not null"`
+	Interval         SubscriptionInterval `json:"interval" gorm:"type:varchar(16);not null"`
+	IntervalCount    int                  `json:"interval_count" gorm:"not null;default:1"`
+	Remark           string               `json:"remark" gorm:"size:100"`
+	CreatedAt        time.Time            `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt        time.Time            `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt        gorm.DeletedAt       `json:"deleted_at" gorm:"index"`
+}
+
+// GetByToken 通过 Token 查询订阅计划
+func (p *SubscriptionPlan) GetByToken(tx *gorm.DB, token string) error {
+	return tx.Where("token = ?", token).First(p).Error
+}
+
+func (p *SubscriptionPlan) BeforeCreate(*gorm.DB) error {
+	if p.ID == 0 {
+		p.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// Subscription 用户订阅（代扣授权）
+// 用户确认订阅并完成首期支付后生成，此后每期到期由定时任务自动扣款，同一用户对同一计划仅可有一个未取消的订阅
+type Subscription struct {
+	ID               uint64               `json:"id,string" gorm:"primaryKey"`
+	PlanID           uint64               `json:"plan_id,string" gorm:"not null;uniqueIndex:idx_subscriptions_plan_payer,priority:1,where:status <> 'cancelled'"`
+	MerchantAPIKeyID uint64               `json:"merchant_api_key_id,string" gorm:"not null;index"`
+	ClientID         string               `json:"client_id" gorm:"size:64;not null"`
+	PayerUserID      uint64               `json:"payer_user_id" gorm:"not null;index;uniqueIndex:idx_subscriptions_plan_payer,priority:2"`
+	PayeeUserID      uint64               `json:"payee_user_id" gorm:"not null"`
+	Name             string               `json:"name" gorm:"size:30;not null"`
+	Amount           decimal.Decimal      `json:"amount" gorm:"type:numeric(20,2);not null"`
+	Interval         SubscriptionInterval `json:"interval" gorm:"type:varchar(16);not null"`
+	IntervalCount    int                  `json:"interval_count" gorm:"not null;default:1"`
+	Status           SubscriptionStatus   `json:"status" gorm:"type:varchar(16);not null;index:idx_subscriptions_status_next,priority:1"`
+	NextBillingAt    time.Time            `json:"next_billing_at" gorm:"not null;index:idx_subscriptions_status_next,priority:2"`
+	FailedAttempts   int                  `json:"failed_attempts" gorm:"not null;default:0"`
+	LastError        string               `json:"last_error" gorm:"size:255"`
+	LastOrderID      *uint64              `json:"last_order_id,string"`
+	CancelledAt      *time.Time           `json:"cancelled_at"`
+	CreatedAt        time.Time            `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt        time.Time            `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (s *Subscription) BeforeCreate(*gorm.DB) error {
+	if s.ID == 0 {
+		s.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// NextPeriod 计算 from 之后下一期的扣款时间
+func (s *Subscription) NextPeriod(from time.Time) time.Time {
+	switch s.Interval {
+	case SubscriptionIntervalDay:
+		return from.AddDate(0, 0, s.IntervalCount)
+	case SubscriptionIntervalWeek:
+		return from.AddDate(0, 0, 7*s.IntervalCount)
+	case SubscriptionIntervalYear:
+		return from.AddDate(s.IntervalCount, 0, 0)
+	default:
+		return from.AddDate(0, s.IntervalCount, 0)
+	}
+}
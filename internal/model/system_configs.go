@@ -32,10 +32,24 @@ import (
 // 配置键常量 - 所有系统配置的 key 定义
 const (
 	ConfigKeyMerchantOrderExpireMinutes = "merchant_order_expire_minutes" // 商家订单过期时间（分钟）
+	ConfigKeyMerchantOrderExpireMin     = "merchant_order_expire_min"     // 商家自定义订单过期时间下限（分钟）
+	ConfigKeyMerchantOrderExpireMax     = "merchant_order_expire_max"     // 商家自定义订单过期时间上限（分钟）
 	ConfigKeyWebsiteOrderExpireMinutes  = "website_order_expire_minutes"  // 网站订单过期时间（分钟）
 	ConfigKeyDisputeTimeWindowHours     = "dispute_time_window_hours"     // 商家争议时间窗口（小时）
 	ConfigKeyNewUserInitialCredit       = "new_user_initial_credit"       // 新用户注册初始积分
 	ConfigKeyNewUserProtectionDays      = "new_user_protection_days"      // 新用户保护期天数（期内不扣分）
+	ConfigKeyIdempotencyWindowMinutes   = "idempotency_window_minutes"    // 幂等键保留时间（分钟）
+	ConfigKeySignTimestampSkewSeconds   = "sign_timestamp_skew_seconds"   // 签名时间戳允许的偏差（秒）
+	ConfigKeyPaymentHoldExpireHours     = "payment_hold_expire_hours"     // 预授权冻结自动释放时间（小时）
+	ConfigKeyEscrowAutoReleaseDays      = "escrow_auto_release_days"      // 担保交易自动放款时间（天）
+	ConfigKeySubscriptionRetryHours     = "subscription_retry_hours"      // 订阅扣款失败后的重试间隔（小时）
+	ConfigKeySubscriptionMaxRetries     = "subscription_max_retries"      // 订阅扣款连续失败次数上限，超过后自动取消
+	ConfigKeyPaymentRequestExpireHours  = "payment_request_expire_hours"  // 收款请求过期时间（小时）
+	ConfigKeyRedEnvelopeExpireHours     = "red_envelope_expire_hours"     // 红包过期时间（小时），过期后剩余金额退回
+	ConfigKeyDistributeBatchMaxItems    = "distribute_batch_max_items"    // 批量分发单批最大明细数
+	ConfigKeyPayKeyMaxFailures          = "pay_key_max_failures"          // 支付密码连续错误次数上限，达到后锁定
+	ConfigKeyPayKeyLockMinutes          = "pay_key_lock_minutes"          // 支付密码错误锁定时长（分钟）
+	ConfigKeyTOTPPaymentThreshold       = "totp_payment_threshold"        // 启用两步验证后单笔支付需要验证码的默认金额阈值
 )
 
 const (
@@ -0,0 +1,78 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type AccessTokenScope string
+
+const (
+	AccessTokenScopeReadTransactions AccessTokenScope = "read_transactions"
+	AccessTokenScopeReadBalance      AccessTokenScope = "read_balance"
+	AccessTokenScopeTransfer         AccessTokenScope = "transfer"
+)
+
+// AccessTokenPrefix 个人访问令牌明文前缀，便于识别与密钥扫描
+const AccessTokenPrefix = "ldc_pat_"
+
+// UserAccessToken 用户个人访问令牌，用于脚本以 Authorization: Bearer 访问用户接口
+// 仅保存哈希，明文只在创建时返回一次；拥有转账权限的令牌必须设置单笔与每日转账上限
+type UserAccessToken struct {
+	ID                 uint64           `json:"id,string" gorm:"primaryKey"`
+	UserID             uint64           `json:"-" gorm:"not null;index"`
+	Name               string           `json:"name" gorm:"size:32;not null"`
+	TokenHash          string           `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	Prefix             string           `json:"prefix" gorm:"size:16;not null"`
+	Scopes             util.StringArray `json:"scopes" gorm:"type:jsonb;not null;default:'[]'"`
+	TransferMaxAmount  decimal.Decimal  `json:"transfer_max_amount" gorm:"type:numeric(20,2);not null;default:0"`
+	TransferDailyLimit decimal.Decimal  `json:"transfer_daily_limit" gorm:"type:numeric(20,2);not null;default:0"`
+	TransferredToday   decimal.Decimal  `json:"transferred_today" gorm:"type:numeric(20,2);not null;default:0"`
+	LastTransferAt     *time.Time       `json:"last_transfer_at"`
+	ExpiresAt          *time.Time       `json:"expires_at"`
+	LastUsedAt         *time.Time       `json:"last_used_at"`
+	RevokedAt          *time.Time       `json:"revoked_at"`
+	CreatedAt          time.Time        `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (t *UserAccessToken) BeforeCreate(*gorm.DB) error {
+	if t.ID == 0 {
+		t.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// IsActive 令牌未吊销且未过期
+func (t *UserAccessToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || t.ExpiresAt.After(now))
+}
+
+// HasScope 是否拥有指定权限
+func (t *UserAccessToken) HasScope(scope AccessTokenScope) bool {
+	for _, sc := range t.Scopes {
+		if AccessTokenScope(sc) == scope {
+			return true
+		}
+	}
+	return false
+}
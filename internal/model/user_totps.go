@@ -0,0 +1,88 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+)
+
+// TOTPRecoveryCodeCount 每次生成的恢复码数量
+const TOTPRecoveryCodeCount = 10
+
+// UserTOTP 用户两步验证（TOTP）配置
+// 密钥使用用户的 SignKey 加密保存；恢复码仅保存哈希，每个只能使用一次
+// 启用后单笔支付金额超过阈值时需要额外提供验证码，未设置阈值时使用系统默认值
+type UserTOTP struct {
+	UserID        uint64           `json:"user_id,string" gorm:"primaryKey"`
+	Secret        string           `json:"-" gorm:"size:128;not null"`
+	Enabled       bool             `json:"enabled" gorm:"not null;default:false"`
+	RecoveryCodes util.StringArray `json:"-" gorm:"type:jsonb;not null;default:'[]'"`
+	Threshold     *decimal.Decimal `json:"threshold" gorm:"type:numeric(20,2)"`
+	LastUsedStep  int64            `json:"-" gorm:"not null;default:0"`
+	EnabledAt     *time.Time       `json:"enabled_at"`
+	CreatedAt     time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// GenerateRecoveryCodes 生成一组新的恢复码并替换原有恢复码，返回仅此一次可见的明文
+func (t *UserTOTP) GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, TOTPRecoveryCodeCount)
+	hashes := make(util.StringArray, TOTPRecoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		raw := hex.EncodeToString(buf)
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = HashAPISecret(raw)
+	}
+	t.RecoveryCodes = hashes
+	return codes, nil
+}
+
+// ConsumeRecoveryCode 匹配恢复码并将其从列表中移除，忽略大小写与分隔符
+func (t *UserTOTP) ConsumeRecoveryCode(code string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if normalized == "" {
+		return false
+	}
+	hash := HashAPISecret(normalized)
+	for i, h := range t.RecoveryCodes {
+		if h == hash {
+			remaining := make(util.StringArray, 0, len(t.RecoveryCodes)-1)
+			remaining = append(remaining, t.RecoveryCodes[:i]...)
+			t.RecoveryCodes = append(remaining, t.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// PaymentThreshold 需要验证码的单笔支付金额阈值，用户未设置时使用系统默认值
+func (t *UserTOTP) PaymentThreshold(defaultThreshold decimal.Decimal) decimal.Decimal {
+	if t.Threshold != nil {
+		return *t.Threshold
+	}
+	return defaultThreshold
+}
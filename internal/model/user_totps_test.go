@@ -0,0 +1,57 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUserTOTPConsumeRecoveryCode(t *testing.T) {
+	var totp UserTOTP
+	codes, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error: %v", err)
+	}
+	if len(codes) != TOTPRecoveryCodeCount || len(totp.RecoveryCodes) != TOTPRecoveryCodeCount {
+		t.Fatalf("GenerateRecoveryCodes() returned %d codes and %d hashes, want %d", len(codes), len(totp.RecoveryCodes), TOTPRecoveryCodeCount)
+	}
+
+	if !totp.ConsumeRecoveryCode(codes[0]) {
+		t.Fatal("ConsumeRecoveryCode() rejected a valid code")
+	}
+	if totp.ConsumeRecoveryCode(codes[0]) {
+		t.Error("ConsumeRecoveryCode() accepted a code twice")
+	}
+	if len(totp.RecoveryCodes) != TOTPRecoveryCodeCount-1 {
+		t.Errorf("remaining codes = %d, want %d", len(totp.RecoveryCodes), TOTPRecoveryCodeCount-1)
+	}
+
+	// 忽略大小写与分隔符
+	if !totp.ConsumeRecoveryCode(strings.ToUpper(strings.ReplaceAll(codes[1], "-", " "))) {
+		t.Error("ConsumeRecoveryCode() rejected a code with different case and separators")
+	}
+
+	for _, code := range []string{"", "-", "00000-00000"} {
+		if totp.ConsumeRecoveryCode(code) {
+			t.Errorf("ConsumeRecoveryCode(%q) = true, want false", code)
+		}
+	}
+	if len(totp.RecoveryCodes) != TOTPRecoveryCodeCount-2 {
+		t.Errorf("remaining codes = %d, want %d", len(totp.RecoveryCodes), TOTPRecoveryCodeCount-2)
+	}
+}
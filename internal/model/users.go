@@ -27,12 +27,10 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/hibiken/asynq"
 	"github.com/linux-do/credit/internal/common"
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/task"
-	"github.com/linux-do/credit/internal/task/scheduler"
 	"github.com/linux-do/credit/internal/util"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
@@ -107,6 +105,7 @@ type User struct {
 	TotalCommunity   decimal.Decimal `json:"total_community" gorm:"type:numeric(20,2);default:0"`
 	CommunityBalance decimal.Decimal `json:"community_balance" gorm:"type:numeric(20,2);default:0"`
 	AvailableBalance decimal.Decimal `json:"available_balance" gorm:"type:numeric(20,2);default:0"`
+	HeldBalance      decimal.Decimal `json:"held_balance" gorm:"type:numeric(20,2);default:0"`
 	IsActive         bool            `json:"is_active" gorm:"default:true"`
 	IsAdmin          bool            `json:"is_admin" gorm:"default:false"`
 	LastLoginAt      time.Time       `json:"last_login_at" gorm:"index"`
@@ -131,11 +130,15 @@ func GetByIDs(tx *gorm.DB, ids []uint64) ([]User, error) {
 }
 
 // VerifyPayKey 验证用户支付密码
-// 使用用户的 SignKey 解密存储的加密密码，然后与输入的明文密码比较
+// 优先按 argon2id 哈希校验；旧数据使用用户的 SignKey 解密后与输入的明文密码比较
 func (u *User) VerifyPayKey(inputPayKey string) bool {
 	if u.PayKey == "" {
 		return false
 	}
+	if util.IsPasswordHash(u.PayKey) {
+		ok, err := util.VerifyPasswordHash(u.PayKey, inputPayKey)
+		return err == nil && ok
+	}
 	decrypted, err := util.Decrypt(u.SignKey, u.PayKey)
 	if err != nil {
 		return false
@@ -144,6 +147,11 @@ func (u *User) VerifyPayKey(inputPayKey string) bool {
 	return subtle.ConstantTimeCompare([]byte(decrypted), []byte(inputPayKey)) == 1
 }
 
+// PayKeyNeedsRehash 支付密码是否仍为旧的可逆加密格式
+func (u *User) PayKeyNeedsRehash() bool {
+	return u.PayKey != "" && !util.IsPasswordHash(u.PayKey)
+}
+
 func (u *User) GetUserGamificationScore(ctx context.Context) (*UserGamificationScoreResponse, error) {
 	url := fmt.Sprintf("https://linux.do/u/%s.json", u.Username)
 	resp, err := util.Request(ctx, http.MethodGet, url, nil, nil, nil)
@@ -201,23 +209,26 @@ func (u *User) CheckActive() error {
 	return nil
 }
 
-// EnqueueBadgeScoreTask 为用户下发积分计算任务
-func (u *User) EnqueueBadgeScoreTask(ctx context.Context, delay time.Duration) error {
+// EnqueueBadgeScoreTask 在事务内写入用户积分计算任务，由 outbox 中继投递
+func (u *User) EnqueueBadgeScoreTask(tx *gorm.DB, delay time.Duration) error {
 	payload, _ := json.Marshal(map[string]interface{}{
 		"user_id": u.ID,
 	})
 
-	opts := []asynq.Option{
-		asynq.Queue(task.QueueWhitelistOnly),
-		asynq.MaxRetry(5),
-		asynq.TaskID(fmt.Sprintf("user_gamification_score_%d", u.ID)),
+	event := OutboxEvent{
+		TaskType: task.UpdateSingleUserGamificationScoreTask,
+		Payload:  string(payload),
+		Queue:    task.QueueWhitelistOnly,
+		TaskID:   fmt.Sprintf("user_gamification_score_%d", u.ID),
+		MaxRetry: 5,
 	}
 	if delay > 0 {
-		opts = append(opts, asynq.ProcessIn(delay))
+		processAt := time.Now().Add(delay)
+		event.ProcessAt = &processAt
 	}
 
-	if _, err := scheduler.AsynqClient.Enqueue(asynq.NewTask(task.UpdateSingleUserGamificationScoreTask, payload), opts...); err != nil {
-		logger.ErrorF(ctx, "下发用户[%s]积分计算任务失败: %v", u.Username, err)
+	if err := CreateOutboxEvent(tx, &event); err != nil {
+		logger.ErrorF(tx.Statement.Context, "下发用户[%s]积分计算任务失败: %v", u.Username, err)
 		return err
 	}
 	return nil
@@ -261,7 +272,7 @@ func (u *User) CreateWithInitialCredit(ctx context.Context, oauthInfo *OAuthUser
 		}
 
 		order := Order{
-			OrderName:   "新用户注册奖励",
+			OrderName:   OrderNameNewUserReward,
 			PayerUserID: 0,
 			PayeeUserID: newUser.ID,
 			Amount:      newUserInitialCredit,
@@ -275,8 +286,24 @@ func (u *User) CreateWithInitialCredit(ctx context.Context, oauthInfo *OAuthUser
 			return err
 		}
 
+		if newUserInitialCredit.IsPositive() {
+			if err = tx.Create(&LedgerEntry{
+				UserID:        newUser.ID,
+				CounterUserID: PlatformAccountID,
+				OrderID:       order.ID,
+				Amount:        newUserInitialCredit,
+				BalanceAfter:  &newUserInitialCredit,
+				Type:          LedgerEntryTypeCommunity,
+			}).Error; err != nil {
+				return err
+			}
+			if err = CreatePlatformLedgerEntry(tx, newUser.ID, order.ID, newUserInitialCredit.Neg(), LedgerEntryTypeCommunity); err != nil {
+				return err
+			}
+		}
+
 		*u = newUser
 
-		return u.EnqueueBadgeScoreTask(ctx, 0)
+		return u.EnqueueBadgeScoreTask(tx, 0)
 	})
 }
@@ -0,0 +1,72 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"gorm.io/gorm"
+)
+
+// WebhookResponseBodyMaxLength 投递记录中保存的响应内容最大长度
+const WebhookResponseBodyMaxLength = 1024
+
+type WebhookEvent string
+
+const (
+	WebhookEventPaymentSucceeded      WebhookEvent = "payment.succeeded"
+	WebhookEventPaymentRefunded       WebhookEvent = "payment.refunded"
+	WebhookEventPaymentAuthorized     WebhookEvent = "payment.authorized"
+	WebhookEventPaymentVoided         WebhookEvent = "payment.voided"
+	WebhookEventDisputeOpened         WebhookEvent = "dispute.opened"
+	WebhookEventDisputeResolved       WebhookEvent = "dispute.resolved"
+	WebhookEventOrderExpired          WebhookEvent = "order.expired"
+	WebhookEventOrderCancelled        WebhookEvent = "order.cancelled"
+	WebhookEventDistributeSucceeded   WebhookEvent = "distribute.succeeded"
+	WebhookEventDistributeBatchDone   WebhookEvent = "distribute.batch_completed"
+	WebhookEventSubscriptionCreated   WebhookEvent = "subscription.created"
+	WebhookEventSubscriptionRenewed   WebhookEvent = "subscription.renewed"
+	WebhookEventSubscriptionFailed    WebhookEvent = "subscription.payment_failed"
+	WebhookEventSubscriptionCancelled WebhookEvent = "subscription.cancelled"
+)
+
+// WebhookDelivery 商户回调投递记录，每次请求（含重试与手动重新投递）记录一条
+// Event 为空表示 EPay 支付成功通知，否则为 JSON 事件回调，Params 保存请求体
+type WebhookDelivery struct {
+	ID           uint64       `json:"id,string" gorm:"primaryKey"`
+	APIKeyID     uint64       `json:"api_key_id,string" gorm:"not null;index:idx_webhook_deliveries_key_created,priority:1"`
+	OrderID      uint64       `json:"order_id,string" gorm:"not null;index"`
+	Event        WebhookEvent `json:"event" gorm:"type:varchar(32);not null;default:''"`
+	URL          string       `json:"url" gorm:"size:255;not null"`
+	Params       string       `json:"params" gorm:"type:text"`
+	StatusCode   int          `json:"status_code"`
+	ResponseBody string       `json:"response_body" gorm:"size:1024"`
+	ErrorMsg     string       `json:"error_msg" gorm:"size:1024"`
+	Success      bool         `json:"success" gorm:"not null;default:false"`
+	LatencyMs    int64        `json:"latency_ms"`
+	RetryCount   int          `json:"retry_count"`
+	Manual       bool         `json:"manual" gorm:"not null;default:false"`
+	CreatedAt    time.Time    `json:"created_at" gorm:"autoCreateTime;index:idx_webhook_deliveries_key_created,priority:2"`
+}
+
+func (d *WebhookDelivery) BeforeCreate(*gorm.DB) error {
+	if d.ID == 0 {
+		d.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
@@ -28,14 +28,21 @@ import (
 	"time"
 
 	"github.com/linux-do/credit/internal/apps/admin"
+	admin_reconciliation "github.com/linux-do/credit/internal/apps/admin/reconciliation"
 	admin_task "github.com/linux-do/credit/internal/apps/admin/task"
 	admin_user "github.com/linux-do/credit/internal/apps/admin/user"
 	publicconfig "github.com/linux-do/credit/internal/apps/config"
 	"github.com/linux-do/credit/internal/apps/dispute"
+	"github.com/linux-do/credit/internal/apps/gift_code"
 	"github.com/linux-do/credit/internal/apps/health"
 	"github.com/linux-do/credit/internal/apps/merchant/api_key"
 	"github.com/linux-do/credit/internal/apps/merchant/link"
+	"github.com/linux-do/credit/internal/apps/merchant/member"
+	"github.com/linux-do/credit/internal/apps/merchant/subscription"
+	"github.com/linux-do/credit/internal/apps/merchant/webhook"
+	"github.com/linux-do/credit/internal/apps/red_envelope"
 	"github.com/linux-do/credit/internal/listener"
+	"github.com/linux-do/credit/internal/model"
 	"github.com/linux-do/credit/internal/util"
 
 	"github.com/linux-do/credit/internal/apps/payment"
@@ -106,9 +113,12 @@ func Serve() {
 	// 查询订单
 	r.GET("/api.php", payment.QueryMerchantOrder)
 	// 退款接口
-	r.POST("/api.php", payment.RefundMerchantOrder)
+	r.POST("/api.php", payment.RequireEPayRefundAuth(), payment.RequireIdempotency(), payment.RefundMerchantOrder)
 	// 商户分发接口
-	r.POST("/pay/distribute", payment.RequireMerchantAuth(), payment.MerchantDistribute)
+	r.POST("/pay/distribute", payment.RequireMerchantAuth(model.APISecretScopeDistribute), payment.RequireIdempotency(), payment.MerchantDistribute)
+	// 商户批量分发接口
+	r.POST("/pay/distribute/batch", payment.RequireMerchantAuth(model.APISecretScopeDistribute), payment.RequireIdempotency(), payment.MerchantDistributeBatch)
+	r.GET("/pay/distribute/batch", payment.RequireMerchantAuth(model.APISecretScopeDistribute), payment.QueryDistributeBatch)
 
 	apiGroup := r.Group(config.Config.App.APIPrefix)
 	{
@@ -134,8 +144,25 @@ func Serve() {
 			userRouter.Use(oauth.LoginRequired())
 			{
 				userRouter.PUT("/pay-key", user.UpdatePayKey)
+				userRouter.GET("/totp", user.GetTOTPStatus)
+				userRouter.POST("/totp/enroll", user.EnrollTOTP)
+				userRouter.POST("/totp/enable", user.EnableTOTP)
+				userRouter.POST("/totp/disable", user.DisableTOTP)
+				userRouter.PUT("/totp/threshold", user.UpdateTOTPThreshold)
+				userRouter.POST("/totp/recovery-codes", user.RegenerateRecoveryCodes)
+				userRouter.GET("/sessions", user.ListSessions)
+				userRouter.DELETE("/sessions", user.RevokeAllSessions)
+				userRouter.DELETE("/sessions/:id", user.RevokeSession)
+				userRouter.GET("/access-tokens", user.ListAccessTokens)
+				userRouter.POST("/access-tokens", user.CreateAccessToken)
+				userRouter.DELETE("/access-tokens/:id", user.RevokeAccessToken)
 			}
 
+			// 支持个人访问令牌的接口
+			apiV1Router.GET("/user/balance", oauth.LoginOrTokenRequired(model.AccessTokenScopeReadBalance), user.GetBalance)
+			apiV1Router.POST("/order/transactions", oauth.LoginOrTokenRequired(model.AccessTokenScopeReadTransactions), order.ListTransactions)
+			apiV1Router.POST("/payment/transfer", oauth.LoginOrTokenRequired(model.AccessTokenScopeTransfer), payment.RequireIdempotency(), payment.Transfer)
+
 			// Dashboard
 			dashboardRouter := apiV1Router.Group("/dashboard")
 			dashboardRouter.Use(oauth.LoginRequired())
@@ -148,7 +175,6 @@ func Serve() {
 			orderRouter := apiV1Router.Group("/order")
 			orderRouter.Use(oauth.LoginRequired())
 			{
-				orderRouter.POST("/transactions", order.ListTransactions)
 				orderRouter.POST("/dispute", dispute.CreateDispute)
 				orderRouter.POST("/disputes/merchant", dispute.ListMerchantDisputes)
 				orderRouter.POST("/disputes", dispute.ListDisputes)
@@ -160,7 +186,31 @@ func Serve() {
 			paymentRouter := apiV1Router.Group("/payment")
 			paymentRouter.Use(oauth.LoginRequired())
 			{
-				paymentRouter.POST("/transfer", payment.Transfer)
+				paymentRouter.POST("/escrow", payment.RequireIdempotency(), payment.EscrowTransfer)
+				paymentRouter.POST("/escrow/confirm", payment.ConfirmEscrow)
+				paymentRouter.POST("/requests", payment.CreatePaymentRequest)
+				paymentRouter.GET("/requests", payment.ListPaymentRequests)
+				paymentRouter.POST("/requests/:id/pay", payment.RequireIdempotency(), payment.PayPaymentRequest)
+				paymentRouter.POST("/requests/:id/decline", payment.DeclinePaymentRequest)
+				paymentRouter.GET("/subscriptions", subscription.ListUserSubscriptions)
+				paymentRouter.POST("/subscriptions/:id/cancel", subscription.CancelUserSubscription)
+			}
+
+			// Red Envelope
+			redEnvelopeRouter := apiV1Router.Group("/red-envelopes")
+			redEnvelopeRouter.Use(oauth.LoginRequired())
+			{
+				redEnvelopeRouter.POST("", payment.RequireIdempotency(), red_envelope.CreateRedEnvelope)
+				redEnvelopeRouter.GET("", red_envelope.ListRedEnvelopes)
+				redEnvelopeRouter.GET("/:code", red_envelope.GetRedEnvelope)
+				redEnvelopeRouter.POST("/:code/claim", red_envelope.ClaimRedEnvelope)
+			}
+
+			// Gift Code
+			giftCodeRouter := apiV1Router.Group("/gift-codes")
+			giftCodeRouter.Use(oauth.LoginRequired())
+			{
+				giftCodeRouter.POST("/redeem", gift_code.RedeemGiftCode)
 			}
 
 			// Config (public)
@@ -177,24 +227,115 @@ func Serve() {
 				merchantRouter.GET("/api-keys", oauth.LoginRequired(), api_key.ListAPIKeys)
 
 				apiKeyRouter := merchantRouter.Group("/api-keys/:id")
-				apiKeyRouter.Use(oauth.LoginRequired(), api_key.RequireAPIKey())
+				apiKeyRouter.Use(oauth.LoginRequired(), api_key.RequireAPIKey(), api_key.AuditWrites())
 				{
 					apiKeyRouter.GET("", api_key.GetAPIKey)
-					apiKeyRouter.PUT("", api_key.UpdateAPIKey)
-					apiKeyRouter.DELETE("", api_key.DeleteAPIKey)
+					apiKeyRouter.PUT("", api_key.RequireRole(model.MerchantRoleDeveloper), api_key.UpdateAPIKey)
+					apiKeyRouter.DELETE("", api_key.RequireRole(), api_key.DeleteAPIKey)
+					apiKeyRouter.GET("/signing-key", api_key.RequireRole(model.MerchantRoleDeveloper), api_key.AuditRead(), api_key.GetSigningKey)
+					apiKeyRouter.POST("/signing-key/rotate", api_key.RequireRole(model.MerchantRoleDeveloper), api_key.RotateSigningKey)
+					apiKeyRouter.POST("/signing-key/revoke-previous", api_key.RequireRole(model.MerchantRoleDeveloper), api_key.RevokePreviousSigningKey)
+
+					// API Secrets
+					secretRouter := apiKeyRouter.Group("/secrets")
+					secretRouter.Use(api_key.RequireRole(model.MerchantRoleDeveloper))
+					{
+						secretRouter.GET("", api_key.ListAPISecrets)
+						secretRouter.POST("", api_key.CreateAPISecret)
+						secretRouter.POST("/:secretId/rotate", api_key.RotateAPISecret)
+						secretRouter.POST("/:secretId/revoke", api_key.RevokeAPISecret)
+					}
+
+					// Members
+					memberRouter := apiKeyRouter.Group("/members")
+					{
+						memberRouter.GET("", member.ListMembers)
+						memberRouter.POST("", api_key.RequireRole(), member.InviteMember)
+						memberRouter.PUT("/:memberId", api_key.RequireRole(), member.UpdateMember)
+						memberRouter.DELETE("/:memberId", member.RemoveMember)
+					}
+					apiKeyRouter.GET("/audit-logs", api_key.RequireRole(), member.ListAuditLogs)
+
+					// Orders
+					memberOrderRouter := apiKeyRouter.Group("")
+					memberOrderRouter.Use(payment.BindMemberAPIKey())
+					{
+						memberOrderRouter.GET("/orders", api_key.RequireRole(model.MerchantRoleSupport, model.MerchantRoleFinance), payment.ListOrders)
+						memberOrderRouter.POST("/orders/:tradeNo/refund", api_key.RequireRole(model.MerchantRoleFinance), payment.RequireIdempotency(), payment.RefundOrder)
+						memberOrderRouter.POST("/distribute", api_key.RequireRole(model.MerchantRoleFinance), payment.RequireIdempotency(), payment.MerchantDistribute)
+					}
 
 					// Payment Links
 					linkRouter := apiKeyRouter.Group("/payment-links")
+					linkRouter.Use(api_key.RequireRole(model.MerchantRoleDeveloper))
 					{
 						linkRouter.GET("", link.ListPaymentLinks)
 						linkRouter.POST("", link.CreatePaymentLink)
 						linkRouter.PUT("/:linkId", link.UpdatePaymentLink)
 						linkRouter.DELETE("/:linkId", link.DeletePaymentLink)
 					}
+
+					// Subscription Plans
+					planRouter := apiKeyRouter.Group("/subscription-plans")
+					planRouter.Use(api_key.RequireRole(model.MerchantRoleDeveloper))
+					{
+						planRouter.GET("", subscription.ListSubscriptionPlans)
+						planRouter.POST("", subscription.CreateSubscriptionPlan)
+						planRouter.PUT("/:planId", subscription.UpdateSubscriptionPlan)
+						planRouter.DELETE("/:planId", subscription.DeleteSubscriptionPlan)
+					}
+
+					// Subscriptions
+					subscriptionRouter := apiKeyRouter.Group("/subscriptions")
+					{
+						subscriptionRouter.GET("", api_key.RequireRole(model.MerchantRoleDeveloper, model.MerchantRoleSupport, model.MerchantRoleFinance), subscription.ListMerchantSubscriptions)
+						subscriptionRouter.POST("/:subscriptionId/cancel", api_key.RequireRole(model.MerchantRoleSupport), subscription.CancelMerchantSubscription)
+					}
+
+					// Gift Code Batches，出资使用创建者余额与支付密码，仅创建者可发行
+					giftCodeBatchRouter := apiKeyRouter.Group("/gift-code-batches")
+					{
+						giftCodeBatchRouter.GET("", api_key.RequireRole(model.MerchantRoleSupport, model.MerchantRoleFinance), gift_code.ListMerchantGiftCodeBatches)
+						giftCodeBatchRouter.POST("", api_key.RequireRole(), gift_code.CreateMerchantGiftCodeBatch)
+						giftCodeBatchRouter.GET("/:batchId/codes", api_key.RequireRole(model.MerchantRoleSupport, model.MerchantRoleFinance), gift_code.ListMerchantGiftCodes)
+						giftCodeBatchRouter.GET("/:batchId/redemptions", api_key.RequireRole(model.MerchantRoleSupport, model.MerchantRoleFinance), gift_code.ListMerchantGiftCodeRedemptions)
+					}
+
+					// Webhook Deliveries
+					webhookRouter := apiKeyRouter.Group("/webhooks")
+					webhookRouter.Use(api_key.RequireRole(model.MerchantRoleDeveloper))
+					{
+						webhookRouter.GET("", webhook.ListDeliveries)
+						webhookRouter.POST("/:deliveryId/redeliver", webhook.RedeliverWebhook)
+					}
+				}
+
+				// Member Invitations
+				invitationRouter := merchantRouter.Group("/invitations")
+				invitationRouter.Use(oauth.LoginRequired())
+				{
+					invitationRouter.GET("", member.ListInvitations)
+					invitationRouter.POST("/:memberId/accept", member.AcceptInvitation)
+					invitationRouter.POST("/:memberId/decline", member.DeclineInvitation)
 				}
 
 				merchantRouter.GET("/payment-links/:token", oauth.LoginRequired(), link.GetPaymentLinkByToken)
 				merchantRouter.POST("/payment-links/pay", oauth.LoginRequired(), link.PayByLink)
+				merchantRouter.GET("/subscription-plans/:token", oauth.LoginRequired(), subscription.GetSubscriptionPlanByToken)
+				merchantRouter.POST("/subscription-plans/subscribe", oauth.LoginRequired(), subscription.Subscribe)
+
+				// Merchant Orders
+				merchantOrderRouter := merchantRouter.Group("/orders")
+				{
+					merchantOrderRouter.POST("", payment.RequireMerchantAuth(model.APISecretScopeCreateOrder), payment.RequireIdempotency(), payment.CreateOrder)
+					merchantOrderRouter.GET("", payment.RequireMerchantAuth(model.APISecretScopeQuery), payment.ListOrders)
+					merchantOrderRouter.GET("/:tradeNo", payment.RequireMerchantAuth(model.APISecretScopeQuery), payment.GetOrder)
+					merchantOrderRouter.GET("/out-trade-no/:outTradeNo", payment.RequireMerchantAuth(model.APISecretScopeQuery), payment.GetOrderByOutTradeNo)
+					merchantOrderRouter.POST("/:tradeNo/refund", payment.RequireMerchantAuth(model.APISecretScopeRefund), payment.RequireIdempotency(), payment.RefundOrder)
+					merchantOrderRouter.POST("/:tradeNo/cancel", payment.RequireMerchantAuth(model.APISecretScopeCreateOrder), payment.CancelOrder)
+					merchantOrderRouter.POST("/:tradeNo/capture", payment.RequireMerchantAuth(model.APISecretScopeCreateOrder), payment.RequireIdempotency(), payment.CaptureOrder)
+					merchantOrderRouter.POST("/:tradeNo/void", payment.RequireMerchantAuth(model.APISecretScopeCreateOrder), payment.VoidOrder)
+				}
 
 				// MerchantAPIKey Payment
 				MerchantPaymentRouter := merchantRouter.Group("/payment")
@@ -216,6 +357,15 @@ func Serve() {
 				adminRouter.GET("/users", admin_user.ListUsers)
 				adminRouter.PUT("/users/:id/status", admin_user.UpdateUserStatus)
 
+				// Reconciliation
+				adminRouter.GET("/reconciliation/findings", admin_reconciliation.ListFindings)
+
+				// Gift Code Batches
+				adminRouter.POST("/gift-code-batches", gift_code.CreateAdminGiftCodeBatch)
+				adminRouter.GET("/gift-code-batches", gift_code.ListAdminGiftCodeBatches)
+				adminRouter.GET("/gift-code-batches/:batchId/codes", gift_code.ListAdminGiftCodes)
+				adminRouter.GET("/gift-code-batches/:batchId/redemptions", gift_code.ListAdminGiftCodeRedemptions)
+
 				// System Config
 				adminRouter.POST("/system-configs", system_config.CreateSystemConfig)
 				adminRouter.GET("/system-configs", system_config.ListSystemConfigs)
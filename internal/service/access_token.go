@@ -0,0 +1,103 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// accessTokenTouchInterval 最近使用时间的刷新间隔，避免每次请求都写库
+const accessTokenTouchInterval = time.Minute
+
+// CreateAccessToken 保存新令牌，返回仅此一次可见的明文
+func CreateAccessToken(tx *gorm.DB, token *model.UserAccessToken) (string, error) {
+	plaintext := model.AccessTokenPrefix + util.GenerateUniqueIDSimple()
+	token.TokenHash = model.HashAPISecret(plaintext)
+	token.Prefix = plaintext[:len(model.AccessTokenPrefix)+4]
+	if err := tx.Create(token).Error; err != nil {
+		return "", err
+	}
+	return plaintext, nil
+}
+
+// AuthenticateAccessToken 校验个人访问令牌，并要求令牌拥有指定权限
+func AuthenticateAccessToken(tx *gorm.DB, plaintext string, scope model.AccessTokenScope) (*model.UserAccessToken, error) {
+	var token model.UserAccessToken
+	if err := tx.Where("token_hash = ?", model.HashAPISecret(plaintext)).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(common.AccessTokenInvalid)
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !token.IsActive(now) {
+		return nil, errors.New(common.AccessTokenInvalid)
+	}
+	if !token.HasScope(scope) {
+		return nil, errors.New(common.AccessTokenScopeDenied)
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= accessTokenTouchInterval {
+		if err := tx.Model(&model.UserAccessToken{}).
+			Where("id = ?", token.ID).
+			UpdateColumn("last_used_at", now).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &token, nil
+}
+
+// ConsumeAccessTokenTransfer 校验并累计令牌的转账额度，需在转账事务中调用
+func ConsumeAccessTokenTransfer(tx *gorm.DB, tokenID uint64, amount decimal.Decimal) error {
+	var token model.UserAccessToken
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", tokenID).
+		First(&token).Error; err != nil {
+		return err
+	}
+
+	if amount.GreaterThan(token.TransferMaxAmount) {
+		return errors.New(common.AccessTokenAmountExceeded)
+	}
+
+	now := time.Now()
+	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	transferred := token.TransferredToday
+	if token.LastTransferAt == nil || token.LastTransferAt.Before(todayStart) {
+		transferred = decimal.Zero
+	}
+	if transferred.Add(amount).GreaterThan(token.TransferDailyLimit) {
+		return errors.New(common.AccessTokenDailyExceeded)
+	}
+
+	return tx.Model(&model.UserAccessToken{}).
+		Where("id = ?", token.ID).
+		Updates(map[string]interface{}{
+			"transferred_today": transferred.Add(amount),
+			"last_transfer_at":  now,
+		}).Error
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+)
+
+// apiSecretTouchInterval 最近使用时间的刷新间隔，避免每次请求都写库
+const apiSecretTouchInterval = time.Minute
+
+// CreateAPISecret 为应用生成新密钥，返回仅此一次可见的明文
+func CreateAPISecret(tx *gorm.DB, apiKeyID uint64, name string, scopes util.StringArray) (*model.MerchantAPISecret, string, error) {
+	plaintext := util.GenerateUniqueIDSimple()
+	secret := model.NewMerchantAPISecret(apiKeyID, name, plaintext, scopes)
+	if err := tx.Create(secret).Error; err != nil {
+		return nil, "", err
+	}
+	return secret, plaintext, nil
+}
+
+// CreateDefaultAPISecret 为新应用生成拥有全部权限的默认密钥，与签名密钥相互独立，返回仅此一次可见的明文
+func CreateDefaultAPISecret(tx *gorm.DB, apiKeyID uint64) (*model.MerchantAPISecret, string, error) {
+	return CreateAPISecret(tx, apiKeyID, "default", model.AllAPISecretScopes)
+}
+
+// AuthenticateAPISecret 校验 ClientID 与密钥，并要求密钥拥有指定权限
+func AuthenticateAPISecret(tx *gorm.DB, clientID, plaintext string, scope model.APISecretScope) (*model.MerchantAPIKey, error) {
+	var apiKey model.MerchantAPIKey
+	if err := apiKey.GetByClientID(tx, clientID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(common.APISecretInvalid)
+		}
+		return nil, err
+	}
+
+	var secret model.MerchantAPISecret
+	if err := tx.Where("api_key_id = ? AND secret_hash = ?", apiKey.ID, model.HashAPISecret(plaintext)).
+		First(&secret).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(common.APISecretInvalid)
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !secret.IsActive(now) {
+		return nil, errors.New(common.APISecretInvalid)
+	}
+	if !secret.HasScope(scope) {
+		return nil, errors.New(common.APISecretScopeDenied)
+	}
+
+	if secret.LastUsedAt == nil || now.Sub(*secret.LastUsedAt) >= apiSecretTouchInterval {
+		if err := tx.Model(&model.MerchantAPISecret{}).
+			Where("id = ?", secret.ID).
+			UpdateColumn("last_used_at", now).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &apiKey, nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"github.com/linux-do/credit/internal/model"
+	"gorm.io/gorm"
+)
+
+// ReleaseEscrow 担保交易放款，冻结积分由平台系统账户划给收款方，hold 与 order 需由调用方加行锁
+// 付款方计入累计转账，收款方计入累计收入
+func ReleaseEscrow(tx *gorm.DB, hold *model.PaymentHold, order *model.Order, orderStatus model.OrderStatus) error {
+	if err := tx.Model(&model.User{}).
+		Where("id = ?", order.PayerUserID).
+		UpdateColumns(map[string]interface{}{
+			"held_balance":   gorm.Expr("held_balance - ?", hold.Amount),
+			"total_transfer": gorm.Expr("total_transfer + ?", hold.Amount),
+		}).Error; err != nil {
+		return err
+	}
+
+	if err := model.CreatePlatformLedgerEntry(tx, order.PayeeUserID, order.ID, hold.Amount.Neg(), model.LedgerEntryTypeHold); err != nil {
+		return err
+	}
+	if err := UpdateBalance(tx, BalanceUpdateOptions{
+		UserID:        order.PayeeUserID,
+		CounterUserID: order.PayerUserID,
+		OrderID:       order.ID,
+		EntryType:     model.LedgerEntryTypeTransfer,
+		Amount:        hold.Amount,
+		Operation:     BalanceAdd,
+		TotalField:    "total_receive",
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Model(order).Update("status", orderStatus).Error; err != nil {
+		return err
+	}
+
+	hold.CapturedAmount = hold.Amount
+	hold.Status = model.PaymentHoldStatusCaptured
+	return tx.Save(hold).Error
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/model"
+	"gorm.io/gorm"
+)
+
+// CreateGiftCodeBatch 创建礼品码批次及其礼品码
+// 商户批次按最大可兑换总额从商户余额转入平台系统账户并生成出资订单，出资金额计入商户累计转账
+func CreateGiftCodeBatch(tx *gorm.DB, batch *model.GiftCodeBatch, codes []model.GiftCode) error {
+	if batch.IssuerType == model.GiftCodeIssuerMerchant {
+		order := model.Order{
+			OrderName:   model.OrderNameGiftCodeFund,
+			PayerUserID: batch.IssuerUserID,
+			PayeeUserID: model.PlatformAccountID,
+			Amount:      batch.TotalAmount,
+			Status:      model.OrderStatusSuccess,
+			Type:        model.OrderTypeGiftCode,
+			Remark:      batch.Name,
+			TradeTime:   time.Now(),
+			ExpiresAt:   batch.ExpiresAt,
+		}
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+
+		if err := UpdateBalance(tx, BalanceUpdateOptions{
+			UserID:        batch.IssuerUserID,
+			CounterUserID: model.PlatformAccountID,
+			OrderID:       order.ID,
+			EntryType:     model.LedgerEntryTypeGiftCode,
+			Amount:        batch.TotalAmount,
+			Operation:     BalanceDeduct,
+			TotalField:    "total_transfer",
+			CheckBalance:  true,
+		}); err != nil {
+			return err
+		}
+		if err := model.CreatePlatformLedgerEntry(tx, batch.IssuerUserID, order.ID, batch.TotalAmount, model.LedgerEntryTypeGiftCode); err != nil {
+			return err
+		}
+
+		batch.FundOrderID = &order.ID
+	}
+
+	batch.Status = model.GiftCodeBatchStatusActive
+	if err := tx.Create(batch).Error; err != nil {
+		return err
+	}
+
+	for i := range codes {
+		codes[i].BatchID = batch.ID
+	}
+	return tx.CreateInBatches(codes, 500).Error
+}
+
+// giftCodePayer 礼品码兑换订单的付款方，管理员批次为平台系统账户
+func giftCodePayer(batch *model.GiftCodeBatch) uint64 {
+	if batch.IssuerType == model.GiftCodeIssuerMerchant {
+		return batch.IssuerUserID
+	}
+	return model.PlatformAccountID
+}
+
+// RedeemGiftCode 兑换礼品码，由平台系统账户转给兑换人并生成兑换订单，batch 与 code 需由调用方加行锁
+func RedeemGiftCode(tx *gorm.DB, batch *model.GiftCodeBatch, code *model.GiftCode, userID uint64) (*model.GiftCodeRedemption, error) {
+	now := time.Now()
+	order := model.Order{
+		OrderName:     model.OrderNameGiftCodeRedeem,
+		PayerUserID:   giftCodePayer(batch),
+		PayeeUserID:   userID,
+		Amount:        batch.Amount,
+		Status:        model.OrderStatusSuccess,
+		Type:          model.OrderTypeGiftCode,
+		Remark:        batch.Name,
+		ParentOrderID: batch.FundOrderID,
+		TradeTime:     now,
+		ExpiresAt:     now,
+	}
+	if err := tx.Create(&order).Error; err != nil {
+		return nil, err
+	}
+
+	if err := UpdateBalance(tx, BalanceUpdateOptions{
+		UserID:        userID,
+		CounterUserID: order.PayerUserID,
+		OrderID:       order.ID,
+		EntryType:     model.LedgerEntryTypeGiftCode,
+		Amount:        batch.Amount,
+		Operation:     BalanceAdd,
+		TotalField:    "total_receive",
+	}); err != nil {
+		return nil, err
+	}
+	if err := model.CreatePlatformLedgerEntry(tx, userID, order.ID, batch.Amount.Neg(), model.LedgerEntryTypeGiftCode); err != nil {
+		return nil, err
+	}
+
+	redemption := model.GiftCodeRedemption{
+		BatchID:    batch.ID,
+		GiftCodeID: code.ID,
+		UserID:     userID,
+		Code:       code.Code,
+		Amount:     batch.Amount,
+		OrderID:    order.ID,
+	}
+	if err := tx.Create(&redemption).Error; err != nil {
+		return nil, err
+	}
+
+	code.RedeemedCount++
+	if err := tx.Model(&model.GiftCode{}).
+		Where("id = ?", code.ID).
+		UpdateColumns(map[string]interface{}{
+			"redeemed_count": code.RedeemedCount,
+			"updated_at":     now,
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	batch.RedeemedCount++
+	batch.RedeemedAmount = batch.RedeemedAmount.Add(batch.Amount)
+	if err := tx.Model(&model.GiftCodeBatch{}).
+		Where("id = ?", batch.ID).
+		UpdateColumns(map[string]interface{}{
+			"redeemed_count":  batch.RedeemedCount,
+			"redeemed_amount": batch.RedeemedAmount,
+			"updated_at":      now,
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	return &redemption, nil
+}
+
+// ExpireGiftCodeBatch 礼品码批次过期，商户批次未兑换的金额退回商户并冲正累计转账，batch 需由调用方加行锁
+// 退回订单的付款方为平台系统账户
+func ExpireGiftCodeBatch(tx *gorm.DB, batch *model.GiftCodeBatch) error {
+	now := time.Now()
+	remaining := batch.TotalAmount.Sub(batch.RedeemedAmount)
+	if batch.IssuerType == model.GiftCodeIssuerMerchant && remaining.IsPositive() {
+		order := model.Order{
+			OrderName:     model.OrderNameGiftCodeRefund,
+			PayerUserID:   model.PlatformAccountID,
+			PayeeUserID:   batch.IssuerUserID,
+			Amount:        remaining,
+			Status:        model.OrderStatusSuccess,
+			Type:          model.OrderTypeGiftCode,
+			Remark:        batch.Name,
+			ParentOrderID: batch.FundOrderID,
+			TradeTime:     now,
+			ExpiresAt:     now,
+		}
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+
+		if err := UpdateBalance(tx, BalanceUpdateOptions{
+			UserID:        batch.IssuerUserID,
+			CounterUserID: model.PlatformAccountID,
+			OrderID:       order.ID,
+			EntryType:     model.LedgerEntryTypeGiftCode,
+			Amount:        remaining,
+			Operation:     BalanceAdd,
+			TotalField:    "total_transfer",
+			RevertTotal:   true,
+		}); err != nil {
+			return err
+		}
+		if err := model.CreatePlatformLedgerEntry(tx, batch.IssuerUserID, order.ID, remaining.Neg(), model.LedgerEntryTypeGiftCode); err != nil {
+			return err
+		}
+	}
+
+	batch.Status = model.GiftCodeBatchStatusExpired
+	return tx.Model(&model.GiftCodeBatch{}).
+		Where("id = ?", batch.ID).
+		UpdateColumns(map[string]interface{}{
+			"status":     batch.Status,
+			"updated_at": now,
+		}).Error
+}
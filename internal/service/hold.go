@@ -0,0 +1,173 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// AuthorizeOrder 预授权支付，冻结付款方积分并生成冻结记录，order 需由调用方加行锁并已更新为预授权状态
+// 冻结的积分以平台系统账户为对手方记账，测试订单不实际冻结
+func AuthorizeOrder(tx *gorm.DB, order *model.Order, expiresAt time.Time) (*model.PaymentHold, error) {
+	hold := model.PaymentHold{
+		OrderID:     order.ID,
+		ClientID:    order.ClientID,
+		PayerUserID: order.PayerUserID,
+		PayeeUserID: order.PayeeUserID,
+		Amount:      order.Amount,
+		Status:      model.PaymentHoldStatusHeld,
+		ExpiresAt:   expiresAt,
+	}
+	if err := tx.Create(&hold).Error; err != nil {
+		return nil, err
+	}
+
+	if order.Type == model.OrderTypeTest {
+		return &hold, nil
+	}
+
+	if err := UpdateBalance(tx, BalanceUpdateOptions{
+		UserID:        order.PayerUserID,
+		CounterUserID: model.PlatformAccountID,
+		OrderID:       order.ID,
+		EntryType:     model.LedgerEntryTypeHold,
+		Amount:        order.Amount,
+		Operation:     BalanceDeduct,
+		CheckBalance:  true,
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&model.User{}).
+		Where("id = ?", order.PayerUserID).
+		UpdateColumn("held_balance", gorm.Expr("held_balance + ?", order.Amount)).Error; err != nil {
+		return nil, err
+	}
+	if err := model.CreatePlatformLedgerEntry(tx, order.PayerUserID, order.ID, order.Amount, model.LedgerEntryTypeHold); err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+// CaptureHold 确认收款，按确认金额向商户结算并收取手续费，未确认部分退回付款方
+// hold 与 order 需由调用方加行锁，确认后订单金额变为确认金额，后续退款与争议均以此为准
+func CaptureHold(tx *gorm.DB, hold *model.PaymentHold, order *model.Order, amount decimal.Decimal, merchantPayConfig *model.UserPayConfig) error {
+	if !amount.IsPositive() || amount.GreaterThan(hold.Amount) {
+		return errors.New(common.CaptureAmountExceeded)
+	}
+	releaseAmount := hold.Amount.Sub(amount)
+
+	if order.Type != model.OrderTypeTest {
+		fee, merchantAmount, feePercent := CalculateFee(amount, merchantPayConfig.FeeRate)
+
+		// 付款方：解除冻结并计入累计支付
+		if err := tx.Model(&model.User{}).
+			Where("id = ?", order.PayerUserID).
+			UpdateColumns(map[string]interface{}{
+				"held_balance":  gorm.Expr("held_balance - ?", hold.Amount),
+				"total_payment": gorm.Expr("total_payment + ?", amount),
+				"pay_score":     gorm.Expr("pay_score + ?", amount.Round(0).IntPart()),
+			}).Error; err != nil {
+			return err
+		}
+		if err := releaseHeldAmount(tx, order, releaseAmount); err != nil {
+			return err
+		}
+
+		// 商户：由平台系统账户划出确认金额，扣除手续费后入账
+		if err := model.CreatePlatformLedgerEntry(tx, order.PayeeUserID, order.ID, amount.Neg(), model.LedgerEntryTypeHold); err != nil {
+			return err
+		}
+		if err := UpdateBalance(tx, BalanceUpdateOptions{
+			UserID:        order.PayeeUserID,
+			CounterUserID: order.PayerUserID,
+			OrderID:       order.ID,
+			EntryType:     model.LedgerEntryTypePayment,
+			Amount:        merchantAmount,
+			Operation:     BalanceAdd,
+			ScoreChange:   amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart(),
+			TotalField:    "total_receive",
+		}); err != nil {
+			return err
+		}
+		if err := model.CreatePlatformLedgerEntry(tx, order.PayeeUserID, order.ID, fee, model.LedgerEntryTypeFee); err != nil {
+			return err
+		}
+
+		order.Fee = fee
+		feeRemark := fmt.Sprintf("[系统]: 收取商家%d%%手续费", feePercent)
+		if order.Remark != "" {
+			order.Remark = order.Remark + " " + feeRemark
+		} else {
+			order.Remark = feeRemark
+		}
+	}
+
+	order.Amount = amount
+	order.Status = model.OrderStatusSuccess
+	if err := tx.Save(order).Error; err != nil {
+		return err
+	}
+
+	hold.CapturedAmount = amount
+	hold.Status = model.PaymentHoldStatusCaptured
+	return tx.Save(hold).Error
+}
+
+// ReleaseHold 撤销或超时释放冻结，冻结积分全额退回付款方，hold 与 order 需由调用方加行锁
+func ReleaseHold(tx *gorm.DB, hold *model.PaymentHold, order *model.Order, holdStatus model.PaymentHoldStatus, orderStatus model.OrderStatus) error {
+	if order.Type != model.OrderTypeTest {
+		if err := tx.Model(&model.User{}).
+			Where("id = ?", order.PayerUserID).
+			UpdateColumn("held_balance", gorm.Expr("held_balance - ?", hold.Amount)).Error; err != nil {
+			return err
+		}
+		if err := releaseHeldAmount(tx, order, hold.Amount); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(order).Update("status", orderStatus).Error; err != nil {
+		return err
+	}
+	return tx.Model(hold).Update("status", holdStatus).Error
+}
+
+// releaseHeldAmount 由平台系统账户将冻结积分退回付款方可用余额，金额为 0 时跳过
+func releaseHeldAmount(tx *gorm.DB, order *model.Order, amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return nil
+	}
+	if err := model.CreatePlatformLedgerEntry(tx, order.PayerUserID, order.ID, amount.Neg(), model.LedgerEntryTypeHold); err != nil {
+		return err
+	}
+	return UpdateBalance(tx, BalanceUpdateOptions{
+		UserID:        order.PayerUserID,
+		CounterUserID: model.PlatformAccountID,
+		OrderID:       order.ID,
+		EntryType:     model.LedgerEntryTypeHold,
+		Amount:        amount,
+		Operation:     BalanceAdd,
+	})
+}
@@ -0,0 +1,360 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linux-do/credit/internal/model"
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const (
+	ledgerPayerID    uint64 = 1001
+	ledgerMerchantID uint64 = 2001
+	ledgerClaimerID  uint64 = 3001
+)
+
+var (
+	ledgerFeeRate = decimal.RequireFromString("0.03")
+	ledgerAmount  = decimal.RequireFromString("33.33")
+)
+
+// ledgerTestDriver 测试用数据库驱动，不连接真实数据库
+// 写操作均视为影响一行；查询用户时按条件中的 ID 返回，查询支付配置时返回固定费率，其余查询返回空结果
+type ledgerTestDriver struct{}
+
+type ledgerTestConn struct{}
+
+type ledgerTestResult struct{}
+
+type ledgerTestRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func init() {
+	sql.Register("ledgertest", ledgerTestDriver{})
+}
+
+func (ledgerTestDriver) Open(string) (driver.Conn, error) { return ledgerTestConn{}, nil }
+
+func (ledgerTestConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (ledgerTestConn) Close() error { return nil }
+
+func (ledgerTestConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (ledgerTestConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return ledgerTestResult{}, nil
+}
+
+func (ledgerTestConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.HasPrefix(query, `SELECT * FROM "users"`):
+		return &ledgerTestRows{columns: []string{"id"}, values: [][]driver.Value{{args[0].Value}}}, nil
+	case strings.HasPrefix(query, `SELECT * FROM "user_pay_configs"`):
+		return &ledgerTestRows{columns: []string{"fee_rate"}, values: [][]driver.Value{{ledgerFeeRate.String()}}}, nil
+	}
+	return &ledgerTestRows{}, nil
+}
+
+func (ledgerTestResult) LastInsertId() (int64, error) { return 0, nil }
+
+func (ledgerTestResult) RowsAffected() (int64, error) { return 1, nil }
+
+func (r *ledgerTestRows) Columns() []string { return r.columns }
+
+func (r *ledgerTestRows) Close() error { return nil }
+
+func (r *ledgerTestRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// newLedgerTestDB 创建测试数据库并记录写入的流水分录
+func newLedgerTestDB(t *testing.T) (*gorm.DB, *[]model.LedgerEntry) {
+	t.Helper()
+	tx, err := gorm.Open(postgres.New(postgres.Config{DriverName: "ledgertest"}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+
+	entries := &[]model.LedgerEntry{}
+	if err = tx.Callback().Create().After("gorm:create").Register("test:record_ledger", func(db *gorm.DB) {
+		if entry, ok := db.Statement.Dest.(*model.LedgerEntry); ok {
+			*entries = append(*entries, *entry)
+		}
+	}); err != nil {
+		t.Fatalf("register create callback: %v", err)
+	}
+	return tx, entries
+}
+
+// assertLedgerBalanced 校验每个订单下的分录金额之和为 0，返回各订单的分录
+func assertLedgerBalanced(t *testing.T, entries []model.LedgerEntry) map[uint64][]model.LedgerEntry {
+	t.Helper()
+	if len(entries) == 0 {
+		t.Fatal("no ledger entries written")
+	}
+	byOrder := make(map[uint64][]model.LedgerEntry)
+	for _, entry := range entries {
+		if entry.OrderID == 0 {
+			t.Errorf("ledger entry %+v has no order", entry)
+		}
+		if entry.Amount.IsZero() {
+			t.Errorf("ledger entry %+v has zero amount", entry)
+		}
+		if (entry.UserID == model.PlatformAccountID) != (entry.BalanceAfter == nil) {
+			t.Errorf("ledger entry %+v: balance_after must be set for users only", entry)
+		}
+		byOrder[entry.OrderID] = append(byOrder[entry.OrderID], entry)
+	}
+	for orderID, orderEntries := range byOrder {
+		sum := decimal.Zero
+		for _, entry := range orderEntries {
+			sum = sum.Add(entry.Amount)
+		}
+		if !sum.IsZero() {
+			t.Errorf("order %d ledger entries sum to %s, want 0: %+v", orderID, sum, orderEntries)
+		}
+	}
+	return byOrder
+}
+
+// netByUser 汇总每个账户的净变动
+func netByUser(entries []model.LedgerEntry) map[uint64]decimal.Decimal {
+	net := make(map[uint64]decimal.Decimal)
+	for _, entry := range entries {
+		net[entry.UserID] = net[entry.UserID].Add(entry.Amount)
+	}
+	return net
+}
+
+func TestLedgerEntriesBalance(t *testing.T) {
+	fee, merchantAmount, _ := CalculateFee(ledgerAmount, ledgerFeeRate)
+	partial := decimal.RequireFromString("10.01")
+	partialFee, partialMerchantAmount, _ := CalculateFee(partial, ledgerFeeRate)
+
+	newOrder := func(orderType model.OrderType) *model.Order {
+		return &model.Order{
+			ID:          1,
+			PayerUserID: ledgerPayerID,
+			PayeeUserID: ledgerMerchantID,
+			Amount:      ledgerAmount,
+			Fee:         fee,
+			Status:      model.OrderStatusSuccess,
+			Type:        orderType,
+		}
+	}
+	newHold := func(order *model.Order) *model.PaymentHold {
+		return &model.PaymentHold{ID: 1, OrderID: order.ID, Amount: order.Amount, Status: model.PaymentHoldStatusHeld}
+	}
+	payConfig := &model.UserPayConfig{FeeRate: ledgerFeeRate}
+
+	tests := []struct {
+		name string
+		run  func(tx *gorm.DB) error
+		// 全部流程结束后各账户的净变动
+		wantNet map[uint64]decimal.Decimal
+	}{
+		{
+			name: "refund in two parts",
+			run: func(tx *gorm.DB) error {
+				order := newOrder(model.OrderTypeOnline)
+				if _, err := RefundOrder(tx, order, decimal.RequireFromString("10.00"), decimal.Zero); err != nil {
+					return err
+				}
+				_, err := RefundOrder(tx, order, ledgerAmount.Sub(decimal.RequireFromString("10.00")), decimal.Zero)
+				return err
+			},
+			wantNet: map[uint64]decimal.Decimal{
+				ledgerPayerID:           ledgerAmount,
+				ledgerMerchantID:        merchantAmount.Neg(),
+				model.PlatformAccountID: fee.Neg(),
+			},
+		},
+		{
+			name: "hold authorize and partial capture",
+			run: func(tx *gorm.DB) error {
+				order := newOrder(model.OrderTypeOnline)
+				hold, err := AuthorizeOrder(tx, order, time.Now().Add(time.Hour))
+				if err != nil {
+					return err
+				}
+				return CaptureHold(tx, hold, order, partial, payConfig)
+			},
+			wantNet: map[uint64]decimal.Decimal{
+				ledgerPayerID:           partial.Neg(),
+				ledgerMerchantID:        partialMerchantAmount,
+				model.PlatformAccountID: partialFee,
+			},
+		},
+		{
+			name: "hold authorize and release",
+			run: func(tx *gorm.DB) error {
+				order := newOrder(model.OrderTypeOnline)
+				hold, err := AuthorizeOrder(tx, order, time.Now().Add(time.Hour))
+				if err != nil {
+					return err
+				}
+				return ReleaseHold(tx, hold, order, model.PaymentHoldStatusVoided, model.OrderStatusFailed)
+			},
+			wantNet: map[uint64]decimal.Decimal{
+				ledgerPayerID:           decimal.Zero,
+				model.PlatformAccountID: decimal.Zero,
+			},
+		},
+		{
+			name: "escrow release",
+			run: func(tx *gorm.DB) error {
+				order := newOrder(model.OrderTypeEscrow)
+				if _, err := AuthorizeOrder(tx, order, time.Now().Add(time.Hour)); err != nil {
+					return err
+				}
+				return ReleaseEscrow(tx, newHold(order), order, model.OrderStatusSuccess)
+			},
+			wantNet: map[uint64]decimal.Decimal{
+				ledgerPayerID:           ledgerAmount.Neg(),
+				ledgerMerchantID:        ledgerAmount,
+				model.PlatformAccountID: decimal.Zero,
+			},
+		},
+		{
+			name: "red envelope fund, claim and refund",
+			run: func(tx *gorm.DB) error {
+				envelope := &model.RedEnvelope{
+					ID:            1,
+					CreatorUserID: ledgerPayerID,
+					TotalAmount:   ledgerAmount,
+					TotalShares:   3,
+					SplitType:     model.RedEnvelopeSplitRandom,
+				}
+				if err := FundRedEnvelope(tx, envelope); err != nil {
+					return err
+				}
+				if _, err := ClaimRedEnvelope(tx, envelope, ledgerClaimerID); err != nil {
+					return err
+				}
+				return RefundRedEnvelope(tx, envelope)
+			},
+			wantNet: map[uint64]decimal.Decimal{
+				model.PlatformAccountID: decimal.Zero,
+			},
+		},
+		{
+			name: "merchant gift code fund, redeem and expire",
+			run: func(tx *gorm.DB) error {
+				batch := &model.GiftCodeBatch{
+					ID:           1,
+					IssuerType:   model.GiftCodeIssuerMerchant,
+					IssuerUserID: ledgerMerchantID,
+					Amount:       decimal.RequireFromString("5.55"),
+					TotalAmount:  decimal.RequireFromString("16.65"),
+				}
+				if err := CreateGiftCodeBatch(tx, batch, []model.GiftCode{{ID: 1, Code: "CODE"}}); err != nil {
+					return err
+				}
+				if _, err := RedeemGiftCode(tx, batch, &model.GiftCode{ID: 1, Code: "CODE"}, ledgerClaimerID); err != nil {
+					return err
+				}
+				return ExpireGiftCodeBatch(tx, batch)
+			},
+			wantNet: map[uint64]decimal.Decimal{
+				ledgerMerchantID:        decimal.RequireFromString("-5.55"),
+				ledgerClaimerID:         decimal.RequireFromString("5.55"),
+				model.PlatformAccountID: decimal.Zero,
+			},
+		},
+		{
+			name: "admin gift code redeem",
+			run: func(tx *gorm.DB) error {
+				batch := &model.GiftCodeBatch{
+					ID:          1,
+					IssuerType:  model.GiftCodeIssuerAdmin,
+					Amount:      decimal.RequireFromString("5.55"),
+					TotalAmount: decimal.RequireFromString("16.65"),
+				}
+				if err := CreateGiftCodeBatch(tx, batch, []model.GiftCode{{ID: 1, Code: "CODE"}}); err != nil {
+					return err
+				}
+				_, err := RedeemGiftCode(tx, batch, &model.GiftCode{ID: 1, Code: "CODE"}, ledgerClaimerID)
+				return err
+			},
+			wantNet: map[uint64]decimal.Decimal{
+				ledgerClaimerID:         decimal.RequireFromString("5.55"),
+				model.PlatformAccountID: decimal.RequireFromString("-5.55"),
+			},
+		},
+		{
+			name: "subscription charge",
+			run: func(tx *gorm.DB) error {
+				sub := &model.Subscription{
+					ID:          1,
+					PayerUserID: ledgerPayerID,
+					PayeeUserID: ledgerMerchantID,
+					Amount:      ledgerAmount,
+				}
+				_, err := ChargeSubscription(tx, sub, &model.MerchantAPIKey{ClientID: "client"}, time.Now())
+				return err
+			},
+			wantNet: map[uint64]decimal.Decimal{
+				ledgerPayerID:           ledgerAmount.Neg(),
+				ledgerMerchantID:        merchantAmount,
+				model.PlatformAccountID: fee,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, entries := newLedgerTestDB(t)
+			if err := tt.run(tx); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			assertLedgerBalanced(t, *entries)
+
+			net := netByUser(*entries)
+			for userID, want := range tt.wantNet {
+				if got := net[userID]; !got.Equal(want) {
+					t.Errorf("account %d net change = %s, want %s", userID, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// PayKeyFailureCacheKeyFormat 支付密码连续错误次数计数器
+const PayKeyFailureCacheKeyFormat = "pay_key:failures:%d"
+
+// payAttemptReserveScript 原子地占用一次校验机会：计数加一并刷新锁定时长，返回占用后的次数
+// 超过上限后不再刷新过期时间，锁定时长从最后一次允许的尝试起计算
+var payAttemptReserveScript = redis.NewScript(`
+local attempts = redis.call('INCR', KEYS[1])
+if attempts <= tonumber(ARGV[1]) or redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return attempts
+`)
+
+// payFailureLimiter 支付凭证（支付密码、两步验证码）错误次数限制，达到上限后在锁定时长内拒绝校验
+// 校验前先占用一次尝试次数，校验成功后清零，并发请求无法绕过上限；
+// 不同凭证使用各自的计数器，避免一种凭证校验成功时清零另一种凭证的错误次数
+type payFailureLimiter struct {
+	key         string
+	lockedErr   string
+	maxFailures int
+	attempts    int64
+}
+
+// reservePayAttempt 占用用户的一次校验机会，已达到错误次数上限时返回 lockedErr
+func reservePayAttempt(ctx context.Context, keyFormat string, userID uint64, lockedErr string) (*payFailureLimiter, error) {
+	maxFailures, err := model.GetIntByKey(ctx, model.ConfigKeyPayKeyMaxFailures)
+	if err != nil {
+		return nil, err
+	}
+	lockMinutes, err := model.GetIntByKey(ctx, model.ConfigKeyPayKeyLockMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &payFailureLimiter{
+		key:         db.PrefixedKey(fmt.Sprintf(keyFormat, userID)),
+		lockedErr:   lockedErr,
+		maxFailures: maxFailures,
+	}
+	lockTTL := time.Duration(lockMinutes) * time.Minute
+	l.attempts, err = payAttemptReserveScript.Run(ctx, db.Redis, []string{l.key}, maxFailures, lockTTL.Milliseconds()).Int64()
+	if err != nil {
+		return nil, err
+	}
+	if l.attempts > int64(l.maxFailures) {
+		return nil, errors.New(lockedErr)
+	}
+	return l, nil
+}
+
+// fail 校验失败，占用的次数即计为错误，达到上限时返回 lockedErr，否则返回 wrongErr
+func (l *payFailureLimiter) fail(wrongErr string) error {
+	if l.attempts >= int64(l.maxFailures) {
+		return errors.New(l.lockedErr)
+	}
+	return errors.New(wrongErr)
+}
+
+// reset 校验成功后清零计数，包括本次占用的次数
+func (l *payFailureLimiter) reset(ctx context.Context) {
+	if err := db.Redis.Del(ctx, l.key).Err(); err != nil {
+		logger.ErrorF(ctx, "清除支付凭证错误计数失败: key=%s, error=%v", l.key, err)
+	}
+}
+
+// VerifyPayKey 校验支付密码并限制错误次数
+// 校验前先占用一次尝试次数，达到上限后在锁定时长内拒绝校验；校验成功时清零计数，并将旧的可逆加密格式迁移为哈希
+func VerifyPayKey(ctx context.Context, user *model.User, payKey string) error {
+	limiter, err := reservePayAttempt(ctx, PayKeyFailureCacheKeyFormat, user.ID, common.PayKeyLocked)
+	if err != nil {
+		return err
+	}
+
+	if !user.VerifyPayKey(payKey) {
+		return limiter.fail(common.PayKeyIncorrect)
+	}
+	limiter.reset(ctx)
+
+	if user.PayKeyNeedsRehash() {
+		if err := SetPayKey(db.DB(ctx), user, payKey); err != nil {
+			logger.ErrorF(ctx, "迁移用户[ID:%d]支付密码哈希失败: %v", user.ID, err)
+		}
+	}
+	return nil
+}
+
+// SetPayKey 以 argon2id 哈希保存用户的支付密码
+func SetPayKey(tx *gorm.DB, user *model.User, payKey string) error {
+	hashed, err := util.HashPassword(payKey)
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(&model.User{}).Where("id = ?", user.ID).Update("pay_key", hashed).Error; err != nil {
+		return err
+	}
+	user.PayKey = hashed
+	return nil
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	"github.com/linux-do/credit/internal/common"
+)
+
+func TestPayFailureLimiterFail(t *testing.T) {
+	const maxFailures = 5
+	tests := []struct {
+		attempts int64
+		want     string
+	}{
+		{1, common.TOTPIncorrect},
+		{maxFailures - 1, common.TOTPIncorrect},
+		{maxFailures, common.TOTPLocked},
+	}
+	for _, tt := range tests {
+		l := &payFailureLimiter{lockedErr: common.TOTPLocked, maxFailures: maxFailures, attempts: tt.attempts}
+		if err := l.fail(common.TOTPIncorrect); err == nil || err.Error() != tt.want {
+			t.Errorf("fail() after %d attempts = %v, want %s", tt.attempts, err, tt.want)
+		}
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/linux-do/credit/internal/task/scheduler"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BalanceOperation 余额操作类型
@@ -41,26 +42,36 @@ const (
 
 // BalanceUpdateOptions 余额更新选项
 type BalanceUpdateOptions struct {
-	UserID       uint64
-	Amount       decimal.Decimal
-	Operation    BalanceOperation
-	ScoreChange  int64
-	TotalField   string // 累计字段：total_payment / total_receive / total_transfer
-	CheckBalance bool
+	UserID        uint64
+	CounterUserID uint64 // 对手方账户，PlatformAccountID 表示平台系统账户
+	OrderID       uint64
+	EntryType     model.LedgerEntryType
+	Amount        decimal.Decimal
+	Operation     BalanceOperation
+	ScoreChange   int64
+	TotalField    string // 累计字段：total_payment / total_receive / total_transfer
+	RevertTotal   bool   // 冲正累计字段（退款等场景下累计字段扣减）
+	CheckBalance  bool
 }
 
-// UpdateBalance 通用余额更新函数
+// UpdateBalance 通用余额更新函数，同时在同一事务内写入账户流水分录
 func UpdateBalance(tx *gorm.DB, opts BalanceUpdateOptions) error {
 	updates := make(map[string]interface{})
 
+	signedAmount := opts.Amount
 	if opts.Operation == BalanceAdd {
 		updates["available_balance"] = gorm.Expr("available_balance + ?", opts.Amount)
 	} else {
 		updates["available_balance"] = gorm.Expr("available_balance - ?", opts.Amount)
+		signedAmount = opts.Amount.Neg()
 	}
 
 	if opts.TotalField != "" {
-		updates[opts.TotalField] = gorm.Expr(opts.TotalField+" + ?", opts.Amount)
+		if opts.RevertTotal {
+			updates[opts.TotalField] = gorm.Expr(opts.TotalField+" - ?", opts.Amount)
+		} else {
+			updates[opts.TotalField] = gorm.Expr(opts.TotalField+" + ?", opts.Amount)
+		}
 	}
 
 	if opts.ScoreChange != 0 {
@@ -79,8 +90,115 @@ func UpdateBalance(tx *gorm.DB, opts BalanceUpdateOptions) error {
 	if opts.CheckBalance && result.RowsAffected == 0 {
 		return errors.New(common.InsufficientBalance)
 	}
+	// 未更新任何行时余额未变动，无需记录流水
+	if result.RowsAffected == 0 {
+		return nil
+	}
 
-	return nil
+	// 行锁已由上面的 UPDATE 持有，此处读取到的即为本次变动后的余额
+	var balanceAfter decimal.Decimal
+	if err := tx.Model(&model.User{}).
+		Select("available_balance").
+		Where("id = ?", opts.UserID).
+		Scan(&balanceAfter).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&model.LedgerEntry{
+		UserID:        opts.UserID,
+		CounterUserID: opts.CounterUserID,
+		OrderID:       opts.OrderID,
+		Amount:        signedAmount,
+		BalanceAfter:  &balanceAfter,
+		Type:          opts.EntryType,
+	}).Error
+}
+
+// RefundOrder 订单退款，支持部分退款与多次退款，order 需由调用方加行锁
+// 每次退款创建一笔关联原订单的退款子订单，手续费与积分按累计退款比例冲正，全额退款后与原订单完全抵消
+func RefundOrder(tx *gorm.DB, order *model.Order, amount decimal.Decimal, merchantScoreRate decimal.Decimal) (*model.Order, error) {
+	refundedBefore := order.RefundedAmount
+	refundedAfter := refundedBefore.Add(amount)
+	if !amount.IsPositive() || refundedAfter.GreaterThan(order.Amount) {
+		return nil, errors.New(common.RefundAmountExceeded)
+	}
+
+	// 按累计比例计算冲正额，避免多次部分退款的舍入误差累积
+	refundFee := order.Fee.Mul(refundedAfter).Div(order.Amount).Round(2).
+		Sub(order.Fee.Mul(refundedBefore).Div(order.Amount).Round(2))
+	merchantScoreDecrease := refundedAfter.Mul(merchantScoreRate).Round(0).IntPart() -
+		refundedBefore.Mul(merchantScoreRate).Round(0).IntPart()
+	payerScoreDecrease := refundedAfter.Round(0).IntPart() - refundedBefore.Round(0).IntPart()
+	merchantAmount := amount.Sub(refundFee)
+
+	now := time.Now()
+	refundOrder := model.Order{
+		OrderName:     order.OrderName,
+		ClientID:      order.ClientID,
+		PayerUserID:   order.PayeeUserID,
+		PayeeUserID:   order.PayerUserID,
+		Amount:        amount,
+		Fee:           refundFee,
+		Status:        model.OrderStatusSuccess,
+		Type:          model.OrderTypeRefund,
+		Remark:        fmt.Sprintf("[系统]: 订单 %d 退款", order.ID),
+		ParentOrderID: &order.ID,
+		TradeTime:     now,
+		ExpiresAt:     now,
+	}
+	if err := tx.Create(&refundOrder).Error; err != nil {
+		return nil, err
+	}
+
+	// 商户扣回实收部分，平台退回对应手续费，付款方收到全额退款
+	if err := UpdateBalance(tx, BalanceUpdateOptions{
+		UserID:        order.PayeeUserID,
+		CounterUserID: order.PayerUserID,
+		OrderID:       refundOrder.ID,
+		EntryType:     model.LedgerEntryTypeRefund,
+		Amount:        merchantAmount,
+		Operation:     BalanceDeduct,
+		ScoreChange:   -merchantScoreDecrease,
+		TotalField:    "total_receive",
+		RevertTotal:   true,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := model.CreatePlatformLedgerEntry(tx, order.PayeeUserID, refundOrder.ID, refundFee.Neg(), model.LedgerEntryTypeFee); err != nil {
+		return nil, err
+	}
+
+	if err := UpdateBalance(tx, BalanceUpdateOptions{
+		UserID:        order.PayerUserID,
+		CounterUserID: order.PayeeUserID,
+		OrderID:       refundOrder.ID,
+		EntryType:     model.LedgerEntryTypeRefund,
+		Amount:        amount,
+		Operation:     BalanceAdd,
+		ScoreChange:   -payerScoreDecrease,
+		TotalField:    "total_payment",
+		RevertTotal:   true,
+	}); err != nil {
+		return nil, err
+	}
+
+	status := model.OrderStatusPartiallyRefunded
+	if refundedAfter.Equal(order.Amount) {
+		status = model.OrderStatusRefund
+	}
+	if err := tx.Model(&model.Order{}).
+		Where("id = ?", order.ID).
+		UpdateColumns(map[string]interface{}{
+			"refunded_amount": refundedAfter,
+			"status":          status,
+		}).Error; err != nil {
+		return nil, err
+	}
+	order.RefundedAmount = refundedAfter
+	order.Status = status
+
+	return &refundOrder, nil
 }
 
 // CheckDailyLimit 检查用户每日支付限额
@@ -115,20 +233,87 @@ func GetTodayUsedAmount(db *gorm.DB, userID uint64) (decimal.Decimal, error) {
 	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	todayEnd := todayStart.Add(24 * time.Hour)
 
+	// 预授权冻结中的订单同样占用额度
+	statuses := append([]model.OrderStatus{model.OrderStatusAuthorized}, model.OrderPaidStatuses...)
+
 	var total decimal.Decimal
 	err := db.Model(&model.Order{}).
-		Where("payer_user_id = ? AND status = ? AND type IN ? AND trade_time >= ? AND trade_time < ?",
+		Where("payer_user_id = ? AND status IN ? AND type IN ? AND trade_time >= ? AND trade_time < ?",
 			userID,
-			model.OrderStatusSuccess,
+			statuses,
 			[]model.OrderType{model.OrderTypePayment, model.OrderTypeOnline},
 			todayStart,
 			todayEnd).
-		Select("COALESCE(SUM(amount), 0)").
+		Select("COALESCE(SUM(amount - refunded_amount), 0)").
 		Scan(&total).Error
 
 	return total, err
 }
 
+// CheckDistributeLimits 检查商户应用的分发限额，锁定应用记录以保证并发分发时额度判断准确
+// 返回 nil 表示未超限额，返回 error 表示超限或查询失败
+func CheckDistributeLimits(tx *gorm.DB, apiKey *model.MerchantAPIKey, recipientID uint64, amount decimal.Decimal) error {
+	if apiKey.DistributeMaxAmount.IsPositive() && amount.GreaterThan(apiKey.DistributeMaxAmount) {
+		return errors.New(common.DistributeAmountExceeded)
+	}
+
+	if !apiKey.DistributeDailyLimit.IsPositive() &&
+		!apiKey.DistributeMonthlyLimit.IsPositive() &&
+		!apiKey.DistributeRecipientDailyLimit.IsPositive() {
+		return nil
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", apiKey.ID).
+		First(&model.MerchantAPIKey{}).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	checks := []struct {
+		limit       decimal.Decimal
+		since       time.Time
+		recipientID uint64
+		errMsg      string
+	}{
+		{apiKey.DistributeDailyLimit, todayStart, 0, common.DistributeDailyExceeded},
+		{apiKey.DistributeMonthlyLimit, monthStart, 0, common.DistributeMonthlyExceeded},
+		{apiKey.DistributeRecipientDailyLimit, todayStart, recipientID, common.DistributeRecipientExceeded},
+	}
+	for _, check := range checks {
+		if !check.limit.IsPositive() {
+			continue
+		}
+		used, err := GetDistributedAmount(tx, apiKey.ClientID, check.recipientID, check.since)
+		if err != nil {
+			return err
+		}
+		if used.Add(amount).GreaterThan(check.limit) {
+			return errors.New(check.errMsg)
+		}
+	}
+
+	return nil
+}
+
+// GetDistributedAmount 获取商户应用自 since 起的分发总额，recipientID 为 0 时统计全部收款人
+func GetDistributedAmount(db *gorm.DB, clientID string, recipientID uint64, since time.Time) (decimal.Decimal, error) {
+	query := db.Model(&model.Order{}).
+		Where("client_id = ? AND type = ? AND status = ? AND trade_time >= ?",
+			clientID, model.OrderTypeDistribute, model.OrderStatusSuccess, since)
+	if recipientID != 0 {
+		query = query.Where("payee_user_id = ?", recipientID)
+	}
+
+	var total decimal.Decimal
+	err := query.Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+	return total, err
+}
+
 // CalculateFee 计算手续费和商户实收金额
 // 返回：手续费、商户实收金额、手续费百分比
 func CalculateFee(amount decimal.Decimal, feeRate decimal.Decimal) (fee decimal.Decimal, merchantAmount decimal.Decimal, feePercent int64) {
@@ -151,16 +336,35 @@ func ValidateTestModePayment(currentUserID, merchantUserID uint64, isTestMode bo
 	return nil
 }
 
-// EnqueueMerchantNotify 下发商户回调任务
-func EnqueueMerchantNotify(orderID uint64, clientID string) error {
+// EnqueueMerchantNotify 在事务内写入商户回调任务，由 outbox 中继投递
+func EnqueueMerchantNotify(tx *gorm.DB, orderID uint64, clientID string) error {
+	notifyPayload, _ := json.Marshal(map[string]interface{}{
+		"order_id":  orderID,
+		"client_id": clientID,
+	})
+	if err := model.CreateOutboxEvent(tx, &model.OutboxEvent{
+		TaskType:       task.MerchantPaymentNotifyTask,
+		Payload:        string(notifyPayload),
+		Queue:          task.QueueWebhook,
+		MaxRetry:       10,
+		TimeoutSeconds: 30,
+	}); err != nil {
+		return fmt.Errorf("下发商户回调任务失败: %w", err)
+	}
+	return nil
+}
+
+// RedeliverMerchantNotify 商户手动重新投递回调，仅请求一次，结果记录在投递记录中
+func RedeliverMerchantNotify(orderID uint64, clientID string) error {
 	notifyPayload, _ := json.Marshal(map[string]interface{}{
 		"order_id":  orderID,
 		"client_id": clientID,
+		"manual":    true,
 	})
 	if _, err := scheduler.AsynqClient.Enqueue(
 		asynq.NewTask(task.MerchantPaymentNotifyTask, notifyPayload),
 		asynq.Queue(task.QueueWebhook),
-		asynq.MaxRetry(10),
+		asynq.MaxRetry(0),
 		asynq.Timeout(30*time.Second),
 	); err != nil {
 		return fmt.Errorf("下发商户回调任务失败: %w", err)
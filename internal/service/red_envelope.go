@@ -0,0 +1,205 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/linux-do/credit/internal/model"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	OrderNameRedEnvelope       = "红包"
+	OrderNameRedEnvelopeClaim  = "领取红包"
+	OrderNameRedEnvelopeRefund = "红包退回"
+)
+
+// FundRedEnvelope 发起红包，积分从发起人转入平台系统账户并生成发起订单
+// 发起订单的收款方为平台系统账户，发起金额计入发起人累计转账
+func FundRedEnvelope(tx *gorm.DB, envelope *model.RedEnvelope) error {
+	now := time.Now()
+	order := model.Order{
+		OrderName:   OrderNameRedEnvelope,
+		PayerUserID: envelope.CreatorUserID,
+		PayeeUserID: model.PlatformAccountID,
+		Amount:      envelope.TotalAmount,
+		Status:      model.OrderStatusSuccess,
+		Type:        model.OrderTypeRedEnvelope,
+		Remark:      envelope.Remark,
+		TradeTime:   now,
+		ExpiresAt:   envelope.ExpiresAt,
+	}
+	if err := tx.Create(&order).Error; err != nil {
+		return err
+	}
+
+	if err := UpdateBalance(tx, BalanceUpdateOptions{
+		UserID:        envelope.CreatorUserID,
+		CounterUserID: model.PlatformAccountID,
+		OrderID:       order.ID,
+		EntryType:     model.LedgerEntryTypeRedEnvelope,
+		Amount:        envelope.TotalAmount,
+		Operation:     BalanceDeduct,
+		TotalField:    "total_transfer",
+		CheckBalance:  true,
+	}); err != nil {
+		return err
+	}
+	if err := model.CreatePlatformLedgerEntry(tx, envelope.CreatorUserID, order.ID, envelope.TotalAmount, model.LedgerEntryTypeRedEnvelope); err != nil {
+		return err
+	}
+
+	envelope.OrderID = order.ID
+	envelope.RemainingAmount = envelope.TotalAmount
+	envelope.RemainingShares = envelope.TotalShares
+	envelope.Status = model.RedEnvelopeStatusActive
+	return tx.Create(envelope).Error
+}
+
+// NextRedEnvelopeShare 计算下一份红包金额，最后一份领取全部剩余金额
+// 拼手气红包每份在 0.01 与剩余平均值的两倍之间随机，并保证其余每份至少 0.01
+func NextRedEnvelopeShare(envelope *model.RedEnvelope) decimal.Decimal {
+	if envelope.RemainingShares <= 1 {
+		return envelope.RemainingAmount
+	}
+	if envelope.SplitType == model.RedEnvelopeSplitFixed {
+		return envelope.TotalAmount.Div(decimal.NewFromInt(int64(envelope.TotalShares))).RoundDown(2)
+	}
+
+	remainingCents := envelope.RemainingAmount.Shift(2).IntPart()
+	shares := int64(envelope.RemainingShares)
+	maxCents := min(remainingCents*2/shares, remainingCents-(shares-1))
+	if maxCents <= 1 {
+		return decimal.New(1, -2)
+	}
+	return decimal.New(1+rand.Int63n(maxCents), -2)
+}
+
+// ClaimRedEnvelope 领取一份红包，由平台系统账户转给领取人并生成领取订单，envelope 需由调用方加行锁
+func ClaimRedEnvelope(tx *gorm.DB, envelope *model.RedEnvelope, userID uint64) (*model.RedEnvelopeClaim, error) {
+	amount := NextRedEnvelopeShare(envelope)
+
+	now := time.Now()
+	order := model.Order{
+		OrderName:     OrderNameRedEnvelopeClaim,
+		PayerUserID:   envelope.CreatorUserID,
+		PayeeUserID:   userID,
+		Amount:        amount,
+		Status:        model.OrderStatusSuccess,
+		Type:          model.OrderTypeRedEnvelope,
+		Remark:        envelope.Remark,
+		ParentOrderID: &envelope.OrderID,
+		TradeTime:     now,
+		ExpiresAt:     now,
+	}
+	if err := tx.Create(&order).Error; err != nil {
+		return nil, err
+	}
+
+	if err := UpdateBalance(tx, BalanceUpdateOptions{
+		UserID:        userID,
+		CounterUserID: envelope.CreatorUserID,
+		OrderID:       order.ID,
+		EntryType:     model.LedgerEntryTypeRedEnvelope,
+		Amount:        amount,
+		Operation:     BalanceAdd,
+		TotalField:    "total_receive",
+	}); err != nil {
+		return nil, err
+	}
+	if err := model.CreatePlatformLedgerEntry(tx, userID, order.ID, amount.Neg(), model.LedgerEntryTypeRedEnvelope); err != nil {
+		return nil, err
+	}
+
+	claim := model.RedEnvelopeClaim{
+		RedEnvelopeID: envelope.ID,
+		UserID:        userID,
+		Amount:        amount,
+		OrderID:       order.ID,
+	}
+	if err := tx.Create(&claim).Error; err != nil {
+		return nil, err
+	}
+
+	envelope.RemainingAmount = envelope.RemainingAmount.Sub(amount)
+	envelope.RemainingShares--
+	if envelope.RemainingShares == 0 {
+		envelope.Status = model.RedEnvelopeStatusFinished
+	}
+	if err := tx.Model(&model.RedEnvelope{}).
+		Where("id = ?", envelope.ID).
+		UpdateColumns(map[string]interface{}{
+			"remaining_amount": envelope.RemainingAmount,
+			"remaining_shares": envelope.RemainingShares,
+			"status":           envelope.Status,
+			"updated_at":       now,
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	return &claim, nil
+}
+
+// RefundRedEnvelope 过期红包退回剩余金额给发起人，并冲正发起人累计转账，envelope 需由调用方加行锁
+// 退回订单的付款方为平台系统账户
+func RefundRedEnvelope(tx *gorm.DB, envelope *model.RedEnvelope) error {
+	now := time.Now()
+	if envelope.RemainingAmount.IsPositive() {
+		order := model.Order{
+			OrderName:     OrderNameRedEnvelopeRefund,
+			PayerUserID:   model.PlatformAccountID,
+			PayeeUserID:   envelope.CreatorUserID,
+			Amount:        envelope.RemainingAmount,
+			Status:        model.OrderStatusSuccess,
+			Type:          model.OrderTypeRedEnvelope,
+			Remark:        envelope.Remark,
+			ParentOrderID: &envelope.OrderID,
+			TradeTime:     now,
+			ExpiresAt:     now,
+		}
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+
+		if err := UpdateBalance(tx, BalanceUpdateOptions{
+			UserID:        envelope.CreatorUserID,
+			CounterUserID: model.PlatformAccountID,
+			OrderID:       order.ID,
+			EntryType:     model.LedgerEntryTypeRedEnvelope,
+			Amount:        envelope.RemainingAmount,
+			Operation:     BalanceAdd,
+			TotalField:    "total_transfer",
+			RevertTotal:   true,
+		}); err != nil {
+			return err
+		}
+		if err := model.CreatePlatformLedgerEntry(tx, envelope.CreatorUserID, order.ID, envelope.RemainingAmount.Neg(), model.LedgerEntryTypeRedEnvelope); err != nil {
+			return err
+		}
+	}
+
+	envelope.Status = model.RedEnvelopeStatusExpired
+	return tx.Model(&model.RedEnvelope{}).
+		Where("id = ?", envelope.ID).
+		UpdateColumns(map[string]interface{}{
+			"status":     envelope.Status,
+			"updated_at": now,
+		}).Error
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	"github.com/linux-do/credit/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// claimAll 按领取顺序拆分红包，返回每份金额
+func claimAll(t *testing.T, envelope model.RedEnvelope) []decimal.Decimal {
+	t.Helper()
+	envelope.RemainingAmount = envelope.TotalAmount
+	envelope.RemainingShares = envelope.TotalShares
+
+	minShare := decimal.New(1, -2)
+	shares := make([]decimal.Decimal, 0, envelope.TotalShares)
+	for envelope.RemainingShares > 0 {
+		share := NextRedEnvelopeShare(&envelope)
+		if share.LessThan(minShare) {
+			t.Fatalf("share %s is less than 0.01", share)
+		}
+		if !share.Equal(share.Round(2)) {
+			t.Fatalf("share %s has more than 2 decimal places", share)
+		}
+		envelope.RemainingAmount = envelope.RemainingAmount.Sub(share)
+		envelope.RemainingShares--
+		if envelope.RemainingAmount.Shift(2).IntPart() < int64(envelope.RemainingShares) {
+			t.Fatalf("remaining %s cannot cover %d more shares", envelope.RemainingAmount, envelope.RemainingShares)
+		}
+		shares = append(shares, share)
+	}
+	if !envelope.RemainingAmount.IsZero() {
+		t.Fatalf("remaining amount = %s after all shares claimed, want 0", envelope.RemainingAmount)
+	}
+	return shares
+}
+
+func TestNextRedEnvelopeShareFixed(t *testing.T) {
+	tests := []struct {
+		total  string
+		shares int
+		each   string
+		last   string
+	}{
+		{"10", 4, "2.5", "2.5"},
+		{"10", 3, "3.33", "3.34"},
+		{"0.05", 5, "0.01", "0.01"},
+		{"1", 1, "1", "1"},
+	}
+	for _, tt := range tests {
+		got := claimAll(t, model.RedEnvelope{
+			TotalAmount: decimal.RequireFromString(tt.total),
+			TotalShares: tt.shares,
+			SplitType:   model.RedEnvelopeSplitFixed,
+		})
+		for i, share := range got[:len(got)-1] {
+			if !share.Equal(decimal.RequireFromString(tt.each)) {
+				t.Errorf("total %s / %d: share %d = %s, want %s", tt.total, tt.shares, i, share, tt.each)
+			}
+		}
+		if last := got[len(got)-1]; !last.Equal(decimal.RequireFromString(tt.last)) {
+			t.Errorf("total %s / %d: last share = %s, want %s", tt.total, tt.shares, last, tt.last)
+		}
+	}
+}
+
+func TestNextRedEnvelopeShareRandom(t *testing.T) {
+	tests := []struct {
+		total  string
+		shares int
+	}{
+		{"100", 10},
+		{"0.10", 10},
+		{"0.11", 10},
+		{"1", 100},
+		{"5000", 1000},
+		{"3.14", 1},
+	}
+	for _, tt := range tests {
+		for range 200 {
+			got := claimAll(t, model.RedEnvelope{
+				TotalAmount: decimal.RequireFromString(tt.total),
+				TotalShares: tt.shares,
+				SplitType:   model.RedEnvelopeSplitRandom,
+			})
+			if len(got) != tt.shares {
+				t.Fatalf("total %s / %d: got %d shares", tt.total, tt.shares, len(got))
+			}
+		}
+	}
+}
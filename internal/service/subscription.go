@@ -0,0 +1,170 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/config"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+)
+
+// ChargeSubscription 扣款一期订阅，生成订单并结算给商户，sub 需由调用方加行锁
+// 扣款成功后订阅恢复正常并顺延至下一期；测试模式应用仅生成测试订单，不实际扣款
+func ChargeSubscription(tx *gorm.DB, sub *model.Subscription, apiKey *model.MerchantAPIKey, now time.Time) (*model.Order, error) {
+	// 付款方或商户账号被禁用时不再扣款，由调用方取消订阅
+	var payer model.User
+	if err := tx.Where("id = ? AND is_active = ?", sub.PayerUserID, true).First(&payer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(common.SubscriptionPartyInactive)
+		}
+		return nil, err
+	}
+	var merchantUser model.User
+	if err := tx.Where("id = ? AND is_active = ?", sub.PayeeUserID, true).First(&merchantUser).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(common.SubscriptionPartyInactive)
+		}
+		return nil, err
+	}
+
+	var merchantPayConfig model.UserPayConfig
+	if err := merchantPayConfig.GetByPayScore(tx, merchantUser.PayScore); err != nil {
+		return nil, err
+	}
+	var payerPayConfig model.UserPayConfig
+	if err := payerPayConfig.GetByPayScore(tx, payer.PayScore); err != nil {
+		return nil, err
+	}
+
+	isTestMode := apiKey.TestMode
+	if !isTestMode {
+		if err := CheckDailyLimit(tx, payer.ID, sub.Amount, payerPayConfig.DailyLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	fee, merchantAmount, feePercent := CalculateFee(sub.Amount, merchantPayConfig.FeeRate)
+
+	order := model.Order{
+		OrderName:      sub.Name,
+		PayerUserID:    payer.ID,
+		PayeeUserID:    merchantUser.ID,
+		ClientID:       apiKey.ClientID,
+		Amount:         sub.Amount,
+		Status:         model.OrderStatusSuccess,
+		SubscriptionID: &sub.ID,
+		TradeTime:      now,
+		ExpiresAt:      now,
+	}
+	if isTestMode {
+		order.Type = model.OrderTypeTest
+		order.Remark = common.TestModeOrderRemark
+	} else {
+		order.Type = model.OrderTypeOnline
+		order.Fee = fee
+		order.Remark = fmt.Sprintf("[系统]: 订阅自动扣款，收取商家%d%%手续费", feePercent)
+	}
+	if err := tx.Create(&order).Error; err != nil {
+		return nil, err
+	}
+
+	if !isTestMode {
+		if err := UpdateBalance(tx, BalanceUpdateOptions{
+			UserID:        payer.ID,
+			CounterUserID: merchantUser.ID,
+			OrderID:       order.ID,
+			EntryType:     model.LedgerEntryTypePayment,
+			Amount:        sub.Amount,
+			Operation:     BalanceDeduct,
+			ScoreChange:   sub.Amount.Round(0).IntPart(),
+			TotalField:    "total_payment",
+			CheckBalance:  true,
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := UpdateBalance(tx, BalanceUpdateOptions{
+			UserID:        merchantUser.ID,
+			CounterUserID: payer.ID,
+			OrderID:       order.ID,
+			EntryType:     model.LedgerEntryTypePayment,
+			Amount:        merchantAmount,
+			Operation:     BalanceAdd,
+			ScoreChange:   sub.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart(),
+			TotalField:    "total_receive",
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := model.CreatePlatformLedgerEntry(tx, merchantUser.ID, order.ID, fee, model.LedgerEntryTypeFee); err != nil {
+			return nil, err
+		}
+	}
+
+	nextBillingAt := sub.NextPeriod(now)
+	if err := tx.Model(&model.Subscription{}).
+		Where("id = ?", sub.ID).
+		UpdateColumns(map[string]interface{}{
+			"status":          model.SubscriptionStatusActive,
+			"next_billing_at": nextBillingAt,
+			"failed_attempts": 0,
+			"last_error":      "",
+			"last_order_id":   order.ID,
+			"updated_at":      now,
+		}).Error; err != nil {
+		return nil, err
+	}
+	sub.Status = model.SubscriptionStatusActive
+	sub.NextBillingAt = nextBillingAt
+	sub.FailedAttempts = 0
+	sub.LastError = ""
+	sub.LastOrderID = &order.ID
+
+	if config.Config.App.IsProduction() && util.IsLocalhost(apiKey.NotifyURL) {
+		return &order, nil
+	}
+	if err := EnqueueMerchantNotify(tx, order.ID, apiKey.ClientID); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// CancelSubscription 取消订阅，已取消的订阅不会再扣款，sub 需由调用方加行锁
+func CancelSubscription(tx *gorm.DB, sub *model.Subscription, reason string) error {
+	now := time.Now()
+	if err := tx.Model(&model.Subscription{}).
+		Where("id = ?", sub.ID).
+		UpdateColumns(map[string]interface{}{
+			"status":       model.SubscriptionStatusCancelled,
+			"last_error":   reason,
+			"cancelled_at": now,
+			"updated_at":   now,
+		}).Error; err != nil {
+		return err
+	}
+	sub.Status = model.SubscriptionStatusCancelled
+	sub.LastError = reason
+	sub.CancelledAt = &now
+	return nil
+}
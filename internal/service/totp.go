@@ -0,0 +1,96 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// TOTPFailureCacheKeyFormat 两步验证码连续错误次数计数器
+const TOTPFailureCacheKeyFormat = "totp:failures:%d"
+
+// VerifyTOTP 校验两步验证码或恢复码
+// 验证码只能使用一次（记录已使用的时间步长），恢复码使用后作废；错误次数与支付密码共用上限和锁定时长
+func VerifyTOTP(tx *gorm.DB, user *model.User, totp *model.UserTOTP, code string, now time.Time) error {
+	ctx := tx.Statement.Context
+	limiter, err := reservePayAttempt(ctx, TOTPFailureCacheKeyFormat, user.ID, common.TOTPLocked)
+	if err != nil {
+		return err
+	}
+
+	secret, err := util.Decrypt(user.SignKey, totp.Secret)
+	if err != nil {
+		return err
+	}
+
+	// 以原值作为条件更新，并发请求中只有一个能消耗同一验证码或恢复码
+	var result *gorm.DB
+	if step, ok := util.ValidateTOTP(secret, code, now, totp.LastUsedStep); ok {
+		result = tx.Model(&model.UserTOTP{}).
+			Where("user_id = ? AND last_used_step = ?", totp.UserID, totp.LastUsedStep).
+			Update("last_used_step", step)
+		totp.LastUsedStep = step
+	} else {
+		previous := totp.RecoveryCodes
+		if !totp.ConsumeRecoveryCode(code) {
+			return limiter.fail(common.TOTPIncorrect)
+		}
+		result = tx.Model(&model.UserTOTP{}).
+			Where("user_id = ? AND recovery_codes = ?", totp.UserID, previous).
+			Update("recovery_codes", totp.RecoveryCodes)
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return limiter.fail(common.TOTPIncorrect)
+	}
+
+	limiter.reset(ctx)
+	return nil
+}
+
+// CheckPaymentTOTP 已启用两步验证的用户单笔支付金额超过阈值时，要求提供验证码
+func CheckPaymentTOTP(tx *gorm.DB, user *model.User, amount decimal.Decimal, code string, now time.Time) error {
+	var totp model.UserTOTP
+	if err := tx.Where("user_id = ? AND enabled = ?", user.ID, true).First(&totp).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	defaultThreshold, err := model.GetDecimalByKey(tx.Statement.Context, model.ConfigKeyTOTPPaymentThreshold, 2)
+	if err != nil {
+		return err
+	}
+	if amount.LessThanOrEqual(totp.PaymentThreshold(defaultThreshold)) {
+		return nil
+	}
+
+	if code == "" {
+		return errors.New(common.TOTPRequired)
+	}
+	return VerifyTOTP(tx, user, &totp, code, now)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/config"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/task"
+	"github.com/linux-do/credit/internal/task/scheduler"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+)
+
+// WebhookEventPayload 事件回调请求体
+type WebhookEventPayload struct {
+	ID      string             `json:"id"`
+	Type    model.WebhookEvent `json:"type"`
+	Created int64              `json:"created"`
+	Data    interface{}        `json:"data"`
+}
+
+// OrderEventData 构建事件回调中的订单数据
+func OrderEventData(order *model.Order) map[string]interface{} {
+	return map[string]interface{}{
+		"trade_no":       strconv.FormatUint(order.ID, 10),
+		"out_trade_no":   util.DerefString(order.MerchantOrderNo),
+		"name":           order.OrderName,
+		"money":          order.Amount.Truncate(2).StringFixed(2),
+		"refunded_money": order.RefundedAmount.Truncate(2).StringFixed(2),
+		"status":         order.Status,
+	}
+}
+
+// RefundEventData 构建退款事件数据
+func RefundEventData(order, refundOrder *model.Order) map[string]interface{} {
+	data := OrderEventData(order)
+	data["refund_no"] = strconv.FormatUint(refundOrder.ID, 10)
+	data["refund_money"] = refundOrder.Amount.Truncate(2).StringFixed(2)
+	return data
+}
+
+// DisputeEventData 构建争议事件数据
+func DisputeEventData(dispute *model.Dispute, order *model.Order) map[string]interface{} {
+	data := OrderEventData(order)
+	data["dispute_id"] = strconv.FormatUint(dispute.ID, 10)
+	data["dispute_status"] = dispute.Status
+	data["reason"] = dispute.Reason
+	return data
+}
+
+// SubscriptionEventData 构建订阅事件数据，order 为本期扣款订单，可为 nil
+func SubscriptionEventData(sub *model.Subscription, order *model.Order) map[string]interface{} {
+	data := map[string]interface{}{
+		"subscription_id": strconv.FormatUint(sub.ID, 10),
+		"plan_id":         strconv.FormatUint(sub.PlanID, 10),
+		"payer_user_id":   sub.PayerUserID,
+		"name":            sub.Name,
+		"money":           sub.Amount.Truncate(2).StringFixed(2),
+		"interval":        sub.Interval,
+		"interval_count":  sub.IntervalCount,
+		"status":          sub.Status,
+		"next_billing_at": sub.NextBillingAt.Unix(),
+		"failed_attempts": sub.FailedAttempts,
+	}
+	if sub.LastError != "" {
+		data["reason"] = sub.LastError
+	}
+	if order != nil {
+		data["trade_no"] = strconv.FormatUint(order.ID, 10)
+	}
+	return data
+}
+
+// DispatchWebhookEvent 向订阅了该事件的商户应用下发事件回调，未订阅时跳过
+// 需在业务事务内调用，事件与业务数据一同提交后由 outbox 中继投递
+func DispatchWebhookEvent(tx *gorm.DB, clientID string, event model.WebhookEvent, orderID uint64, data interface{}) error {
+	if clientID == "" {
+		return nil
+	}
+
+	var apiKey model.MerchantAPIKey
+	if err := apiKey.GetByClientID(tx, clientID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !apiKey.SubscribesTo(event) {
+		return nil
+	}
+	if config.Config.App.IsProduction() && util.IsLocalhost(apiKey.EventWebhookURL()) {
+		return nil
+	}
+
+	body, err := json.Marshal(WebhookEventPayload{
+		ID:      util.GenerateUniqueIDSimple(),
+		Type:    event,
+		Created: time.Now().Unix(),
+		Data:    data,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := model.CreateOutboxEvent(tx, &model.OutboxEvent{
+		TaskType:       task.MerchantWebhookEventTask,
+		Payload:        webhookEventTaskPayload(apiKey.ID, orderID, event, string(body), false),
+		Queue:          task.QueueWebhook,
+		MaxRetry:       10,
+		TimeoutSeconds: 30,
+	}); err != nil {
+		return fmt.Errorf("下发商户事件回调任务失败: %w", err)
+	}
+	return nil
+}
+
+// RedeliverWebhookEvent 商户手动重新投递事件回调，请求体与原投递一致，仅请求一次
+func RedeliverWebhookEvent(delivery *model.WebhookDelivery) error {
+	if _, err := scheduler.AsynqClient.Enqueue(
+		asynq.NewTask(task.MerchantWebhookEventTask, []byte(webhookEventTaskPayload(delivery.APIKeyID, delivery.OrderID, delivery.Event, delivery.Params, true))),
+		asynq.Queue(task.QueueWebhook),
+		asynq.MaxRetry(0),
+		asynq.Timeout(30*time.Second),
+	); err != nil {
+		return fmt.Errorf("下发商户事件回调任务失败: %w", err)
+	}
+	return nil
+}
+
+func webhookEventTaskPayload(apiKeyID, orderID uint64, event model.WebhookEvent, body string, manual bool) string {
+	eventPayload, _ := json.Marshal(map[string]interface{}{
+		"api_key_id": apiKeyID,
+		"order_id":   orderID,
+		"event":      event,
+		"body":       body,
+		"manual":     manual,
+	})
+	return string(eventPayload)
+}
@@ -22,7 +22,14 @@ const (
 	AutoRefundExpiredDisputesTask         = "dispute:auto_refund_expired"
 	AutoRefundSingleDisputeTask           = "dispute:auto_refund_single"
 	MerchantPaymentNotifyTask             = "payment:merchant_notify"
+	MerchantWebhookEventTask              = "payment:merchant_webhook_event"
 	SyncOrdersToClickHouseTask            = "order:sync_to_clickhouse"
+	ReconcileBalancesTask                 = "order:reconcile_balances"
+	ReleaseExpiredHoldsTask               = "payment:release_expired_holds"
+	ChargeDueSubscriptionsTask            = "payment:charge_due_subscriptions"
+	RefundExpiredRedEnvelopesTask         = "red_envelope:refund_expired"
+	ExpireGiftCodeBatchesTask             = "gift_code:expire_batches"
+	DistributeBatchTask                   = "payment:distribute_batch"
 )
 
 const (
@@ -36,6 +43,7 @@ const (
 	TaskTypeOrderSync        = "order_sync"
 	TaskTypeUserGamification = "user_gamification"
 	TaskTypeDisputeRefund    = "dispute_auto_refund"
+	TaskTypeReconcile        = "balance_reconcile"
 )
 
 // TaskMeta 任务元数据
@@ -78,6 +86,15 @@ var DispatchableTasks = []TaskMeta{
 		MaxRetry:     5,
 		Queue:        QueueDefault,
 	},
+	{
+		Type:         TaskTypeReconcile,
+		AsynqTask:    ReconcileBalancesTask,
+		Name:         "余额对账",
+		Description:  "根据订单重新计算用户余额与累计字段，记录不一致项",
+		SupportsTime: false,
+		MaxRetry:     3,
+		Queue:        QueueDefault,
+	},
 }
 
 // GetTaskMeta 根据任务类型获取元数据
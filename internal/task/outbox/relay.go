@@ -0,0 +1,174 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/config"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/task/scheduler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultIntervalMs     = 500
+	defaultBatchSize      = 100
+	defaultRetentionHours = 72
+	cleanupInterval       = time.Hour
+	maxRelayBackoff       = 30 * time.Second
+	maxEventBackoff       = 5 * time.Minute
+)
+
+// StartRelay 启动发件箱中继，轮询待投递任务并投递到 asynq，直到 ctx 取消
+// 投递成功后才标记为已投递，进程中断时任务会被重新投递（至少一次）
+func StartRelay(ctx context.Context) error {
+	cfg := config.Config.Worker.OutboxRelay
+	interval := time.Duration(cfg.IntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultIntervalMs * time.Millisecond
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	retention := time.Duration(cfg.RetentionHours) * time.Hour
+	if retention <= 0 {
+		retention = defaultRetentionHours * time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastCleanup time.Time
+	var backoff time.Duration
+	for {
+		// 单批满载时立即处理下一批，否则等待下一个轮询周期
+		for {
+			n, err := relayBatch(ctx, batchSize)
+			if err != nil {
+				logger.ErrorF(ctx, "[Outbox] 投递任务失败: %v", err)
+				backoff = nextRelayBackoff(backoff, interval)
+				break
+			}
+			backoff = 0
+			if n < batchSize {
+				break
+			}
+		}
+
+		if time.Since(lastCleanup) >= cleanupInterval {
+			if err := cleanupDispatched(ctx, retention); err != nil {
+				logger.ErrorF(ctx, "[Outbox] 清理已投递任务失败: %v", err)
+			}
+			lastCleanup = time.Now()
+		}
+
+		// 投递失败时退避等待，避免 Redis 或数据库不可用时持续重试
+		var wait <-chan time.Time = ticker.C
+		if backoff > 0 {
+			wait = time.After(backoff)
+		}
+		select {
+		case <-ctx.Done():
+			log.Println("[Outbox] 中继已停止")
+			return nil
+		case <-wait:
+		}
+	}
+}
+
+// nextRelayBackoff 计算中继下一次退避时长，从轮询间隔开始翻倍，上限为 maxRelayBackoff
+func nextRelayBackoff(current, interval time.Duration) time.Duration {
+	if current <= 0 {
+		return interval
+	}
+	return min(current*2, maxRelayBackoff)
+}
+
+// eventRetryDelay 计算单条任务投递失败后的重试延迟，按已尝试次数指数增长，上限为 maxEventBackoff
+func eventRetryDelay(attempts int) time.Duration {
+	if attempts >= 9 {
+		return maxEventBackoff
+	}
+	return min(time.Second<<attempts, maxEventBackoff)
+}
+
+// relayBatch 锁定一批到期的待投递任务并逐条投递，返回成功投递的数量
+// 使用 SKIP LOCKED 以支持多个中继进程并行运行
+// 单条投递失败时记录尝试次数与错误并推迟其下次投递时间，随后结束本批，避免阻塞其它任务
+func relayBatch(ctx context.Context, batchSize int) (int, error) {
+	var dispatched int
+	var enqueueErr error
+	err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var events []model.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", model.OutboxEventStatusPending).
+			Where("next_attempt_at IS NULL OR next_attempt_at <= ?", now).
+			Order("id ASC").
+			Limit(batchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		for i := range events {
+			event := &events[i]
+			t, opts := event.Task()
+			_, errEnqueue := scheduler.AsynqClient.EnqueueContext(ctx, t, opts...)
+
+			// 任务 ID 冲突说明任务已在队列中，视为投递成功
+			if errEnqueue != nil && !errors.Is(errEnqueue, asynq.ErrTaskIDConflict) {
+				enqueueErr = fmt.Errorf("任务[ID:%d, 类型:%s]: %w", event.ID, event.TaskType, errEnqueue)
+				return tx.Model(event).Updates(map[string]interface{}{
+					"attempts":        gorm.Expr("attempts + 1"),
+					"last_error":      errEnqueue.Error(),
+					"next_attempt_at": now.Add(eventRetryDelay(event.Attempts)),
+				}).Error
+			}
+
+			if err := tx.Model(event).Updates(map[string]interface{}{
+				"status":        model.OutboxEventStatusDispatched,
+				"attempts":      gorm.Expr("attempts + 1"),
+				"dispatched_at": now,
+			}).Error; err != nil {
+				return err
+			}
+			dispatched++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return dispatched, enqueueErr
+}
+
+// cleanupDispatched 删除超过保留时长的已投递任务
+func cleanupDispatched(ctx context.Context, retention time.Duration) error {
+	return db.DB(ctx).
+		Where("status = ? AND dispatched_at < ?", model.OutboxEventStatusDispatched, time.Now().Add(-retention)).
+		Delete(&model.OutboxEvent{}).Error
+}
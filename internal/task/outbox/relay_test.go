@@ -0,0 +1,59 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package outbox
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{5, 32 * time.Second},
+		{8, 256 * time.Second},
+		{9, maxEventBackoff},
+		{64, maxEventBackoff},
+	}
+	for _, tt := range tests {
+		if got := eventRetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("eventRetryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestNextRelayBackoff(t *testing.T) {
+	interval := 500 * time.Millisecond
+	tests := []struct {
+		current time.Duration
+		want    time.Duration
+	}{
+		{0, interval},
+		{interval, 2 * interval},
+		{20 * time.Second, maxRelayBackoff},
+		{maxRelayBackoff, maxRelayBackoff},
+	}
+	for _, tt := range tests {
+		if got := nextRelayBackoff(tt.current, interval); got != tt.want {
+			t.Errorf("nextRelayBackoff(%v) = %v, want %v", tt.current, got, tt.want)
+		}
+	}
+}
@@ -83,6 +83,56 @@ func StartScheduler() error {
 			return
 		}
 
+		// 余额对账任务
+		if _, err = scheduler.Register(
+			config.Config.Scheduler.ReconcileBalancesTaskCron,
+			asynq.NewTask(task.ReconcileBalancesTask, nil),
+			asynq.MaxRetry(3),
+			asynq.Unique(23*time.Hour),
+		); err != nil {
+			return
+		}
+
+		// 预授权冻结超时释放任务
+		if _, err = scheduler.Register(
+			config.Config.Scheduler.ReleaseExpiredHoldsTaskCron,
+			asynq.NewTask(task.ReleaseExpiredHoldsTask, nil),
+			asynq.MaxRetry(3),
+			asynq.Unique(5*time.Minute),
+		); err != nil {
+			return
+		}
+
+		// 订阅到期自动扣款任务
+		if _, err = scheduler.Register(
+			config.Config.Scheduler.ChargeDueSubscriptionsTaskCron,
+			asynq.NewTask(task.ChargeDueSubscriptionsTask, nil),
+			asynq.MaxRetry(3),
+			asynq.Unique(5*time.Minute),
+		); err != nil {
+			return
+		}
+
+		// 过期红包退回任务
+		if _, err = scheduler.Register(
+			config.Config.Scheduler.RefundExpiredRedEnvelopesTaskCron,
+			asynq.NewTask(task.RefundExpiredRedEnvelopesTask, nil),
+			asynq.MaxRetry(3),
+			asynq.Unique(5*time.Minute),
+		); err != nil {
+			return
+		}
+
+		// 过期礼品码批次处理任务
+		if _, err = scheduler.Register(
+			config.Config.Scheduler.ExpireGiftCodeBatchesTaskCron,
+			asynq.NewTask(task.ExpireGiftCodeBatchesTask, nil),
+			asynq.MaxRetry(3),
+			asynq.Unique(5*time.Minute),
+		); err != nil {
+			return
+		}
+
 		// 启动调度器
 		err = scheduler.Run()
 	})
@@ -24,8 +24,11 @@ import (
 
 	"github.com/hibiken/asynq"
 	"github.com/linux-do/credit/internal/apps/dispute"
+	"github.com/linux-do/credit/internal/apps/gift_code"
+	"github.com/linux-do/credit/internal/apps/merchant/subscription"
 	"github.com/linux-do/credit/internal/apps/order"
 	"github.com/linux-do/credit/internal/apps/payment"
+	"github.com/linux-do/credit/internal/apps/red_envelope"
 	"github.com/linux-do/credit/internal/apps/user"
 	"github.com/linux-do/credit/internal/config"
 	"github.com/linux-do/credit/internal/task"
@@ -76,7 +79,14 @@ func StartWorker() error {
 	mux.HandleFunc(task.AutoRefundExpiredDisputesTask, dispute.HandleAutoRefundExpiredDisputes)
 	mux.HandleFunc(task.AutoRefundSingleDisputeTask, dispute.HandleAutoRefundSingleDispute)
 	mux.HandleFunc(task.MerchantPaymentNotifyTask, payment.HandleMerchantPaymentNotify)
+	mux.HandleFunc(task.MerchantWebhookEventTask, payment.HandleMerchantWebhookEvent)
 	mux.HandleFunc(task.SyncOrdersToClickHouseTask, order.HandleSyncOrdersToClickHouse)
+	mux.HandleFunc(task.ReconcileBalancesTask, order.HandleReconcileBalances)
+	mux.HandleFunc(task.ReleaseExpiredHoldsTask, payment.HandleReleaseExpiredHolds)
+	mux.HandleFunc(task.ChargeDueSubscriptionsTask, subscription.HandleChargeDueSubscriptions)
+	mux.HandleFunc(task.RefundExpiredRedEnvelopesTask, red_envelope.HandleRefundExpiredRedEnvelopes)
+	mux.HandleFunc(task.ExpireGiftCodeBatchesTask, gift_code.HandleExpireGiftCodeBatches)
+	mux.HandleFunc(task.DistributeBatchTask, payment.HandleDistributeBatch)
 	// 启动服务器
 	return asynqServer.Run(mux)
 }
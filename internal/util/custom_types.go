@@ -26,13 +26,22 @@ import (
 type StringArray []string
 
 func (sa *StringArray) Scan(value interface{}) error {
-	bytesValue, ok := value.([]byte)
-	if !ok {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, sa)
+	case string:
+		return json.Unmarshal([]byte(v), sa)
+	case nil:
+		*sa = nil
+		return nil
+	default:
 		return fmt.Errorf("invalid value: %v", value)
 	}
-	return json.Unmarshal(bytesValue, sa)
 }
 
 func (sa StringArray) Value() (driver.Value, error) {
+	if sa == nil {
+		return []byte("[]"), nil
+	}
 	return json.Marshal(sa)
 }
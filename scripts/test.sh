@@ -0,0 +1,7 @@
+#!/bin/sh
+
+# 单元测试使用 internal/config/testdata/config.yaml，外部依赖均保持禁用
+
+set -ex
+
+CONFIG_PATH="$(pwd)/internal/config/testdata/config.yaml" go test ./...